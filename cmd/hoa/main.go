@@ -1,19 +1,39 @@
 package main
 
 import (
+	"DBPrototyping/pkg/announcements"
+	"DBPrototyping/pkg/appointments"
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/categories"
 	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/complaints"
 	"DBPrototyping/pkg/handlers"
+	"DBPrototyping/pkg/handlers/apiv1"
+	"DBPrototyping/pkg/inventory"
+	"DBPrototyping/pkg/maintenance"
+	"DBPrototyping/pkg/ratings"
 	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/requests/intake"
 	"DBPrototyping/pkg/residence"
 	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/apitoken"
+	"DBPrototyping/pkg/userdata/credentials"
 	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/userdata/signup"
+	"DBPrototyping/pkg/userdata/throttle"
+	"DBPrototyping/pkg/userdata/twofactor"
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/redis"
+	redisstore "github.com/gin-contrib/sessions/redis"
 	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
@@ -53,74 +73,322 @@ func main() {
 		&residence.ResidentPg{},
 		&residence.HousePg{},
 		&residence.ResidentHousePg{},
+		&residence.HouseLinkRequestPg{},
 		&company.StaffMemberPg{},
 		&company.SpecializationPg{},
 		&company.StaffMemberSpecializationPg{},
+		&company.OrganizationPg{},
+		&company.OrganizationCategoryPg{},
+		&company.OrganizationContractPg{},
+		&company.OrganizationRepresentativePg{},
+		&company.WorkShiftPg{},
+		&company.AbsencePg{},
+		&company.StaffStatusChangePg{},
 		&requests.RequestPg{},
+		&requests.RequestUpdatePg{},
 		&userdata.UserPg{},
+		&userdata.PasswordResetTokenPg{},
+		&signup.SignupPg{},
+		&twofactor.TwoFactorPg{},
+		&twofactor.RecoveryCodePg{},
+		&apitoken.TokenPg{},
+		&billing.LineItemPg{},
+		&billing.AccountantPg{},
+		&announcements.AnnouncementPg{},
+		&announcements.AnnouncementHousePg{},
+		&ratings.RatingPg{},
+		&appointments.SlotPg{},
+		&appointments.FeedTokenPg{},
+		&maintenance.PlanPg{},
+		&inventory.LocationPg{},
+		&inventory.ItemPg{},
+		&inventory.StockPg{},
+		&inventory.MovementPg{},
+		&categories.CategoryPg{},
+		&categories.CategorySpecializationPg{},
+		&complaints.ComplaintPg{},
 	); errAuto != nil {
 		logger.Errorf("AutoMigrate failed: %v", errAuto)
 		return
 	}
 
+	if errPhones := credentials.MigratePhonesToE164(db, logger,
+		userdata.UserPg{}.TableName(),
+		userdata.PasswordResetTokenPg{}.TableName(),
+		twofactor.TwoFactorPg{}.TableName(),
+		twofactor.RecoveryCodePg{}.TableName(),
+		apitoken.TokenPg{}.TableName(),
+		residence.ResidentPg{}.TableName(),
+		company.StaffMemberPg{}.TableName(),
+	); errPhones != nil {
+		logger.Errorf("phone number migration failed: %v", errPhones)
+		return
+	}
+
 	pageHandler := &handlers.PageHandler{Logger: logger}
 
+	// a missing or malformed flag keeps two-factor authentication optional
+	requireStaff2FA, _ := strconv.ParseBool(os.Getenv("REQUIRE_STAFF_2FA"))
+
 	r := gin.Default()
-	store, errRedisStore := redis.NewStore(10, "tcp", "localhost:6379", "", os.Getenv("REDIS_PASSWORD"), []byte(os.Getenv("UNIFIED_PASSWORD")))
+	redisPool := &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", "localhost:6379", redis.DialPassword(os.Getenv("REDIS_PASSWORD")))
+		},
+	}
+	defer redisPool.Close()
+
+	store, errRedisStore := redisstore.NewStoreWithPool(redisPool, []byte(os.Getenv("UNIFIED_PASSWORD")))
 	if errRedisStore != nil {
 		fmt.Println("Error initializing redis store:", errRedisStore)
 		log.Fatal(errRedisStore)
 	}
-	r.Use(sessions.Sessions("hoa_project", store))
+
+	// unset or malformed timeouts fall back to the session package defaults
+	idleTimeout, _ := time.ParseDuration(os.Getenv("SESSION_IDLE_TIMEOUT"))
+	absoluteTimeout, _ := time.ParseDuration(os.Getenv("SESSION_ABSOLUTE_TIMEOUT"))
 
 	sm := &session.GinSessionManager{
-		Logger: logger,
+		Logger:          logger,
+		Registry:        session.NewRedisRegistry(redisPool),
+		IdleTimeout:     idleTimeout,
+		AbsoluteTimeout: absoluteTimeout,
+	}
+
+	cookieMaxAge := session.DefaultAbsoluteTimeout
+	if absoluteTimeout > 0 {
+		cookieMaxAge = absoluteTimeout
 	}
+	store.Options(sessions.Options{
+		Path:     "/",
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	r.Use(sessions.Sessions("hoa_project", store))
+
+	loginGuard := throttle.NewLoginGuard(
+		logger,
+		throttle.NewRedisAttemptStore(redisPool),
+		throttle.NewMemoryAttemptStore(),
+		throttle.DefaultPhonePolicy,
+		throttle.DefaultIPPolicy,
+	)
 
 	userRepo := userdata.NewUserRepoPg(db, logger)
+	twoFactorRepo := twofactor.NewTwoFactorPgRepo(logger, db)
 	residentsRepo := residence.NewResidentPgRepo(logger, db)
 	staffRepo := company.NewStaffRepoPostgres(logger, db)
 	reqRepo := requests.NewRequestPgRepo(logger, db)
 
+	tokenRepo := apitoken.NewTokenPgRepo(logger, db)
+	billingRepo := billing.NewBillingPgRepo(logger, db)
+	signupRepo := signup.NewSignupPgRepo(logger, db)
+	announcementsRepo := announcements.NewAnnouncementsPgRepo(logger, db)
+	ratingsRepo := ratings.NewRatingsPgRepo(logger, db)
+	appointmentsRepo := appointments.NewAppointmentsPgRepo(logger, db)
+	maintenanceRepo := maintenance.NewMaintenancePgRepo(logger, db)
+	inventoryRepo := inventory.NewInventoryPgRepo(logger, db)
+	categoriesRepo := categories.NewCategoriesPgRepo(logger, db)
+	complaintsRepo := complaints.NewComplaintsPgRepo(logger, db)
+
+	// only accountants appoint accountants, so the first one comes from the configuration
+	if accountantPhone := os.Getenv("BOOTSTRAP_ACCOUNTANT_PHONE"); accountantPhone != "" {
+		if errSeed := billing.SeedAccountant(logger, billingRepo, staffRepo, accountantPhone); errSeed != nil {
+			logger.Errorf("failed to seed the first accountant %s: %v", accountantPhone, errSeed)
+		}
+	}
+
+	statementFontPath := os.Getenv("STATEMENT_FONT_PATH")
+	if statementFontPath == "" {
+		statementFontPath = "web/fonts/DejaVuSans.ttf"
+	}
+	statementFont, errFont := os.ReadFile(statementFontPath)
+	if errFont != nil {
+		logger.Warnf("statement font %s is not loaded, PDF statements fall back to a font without Cyrillic: %v", statementFontPath, errFont)
+	}
+
+	sm.Roles = &handlers.AccountRoleResolver{
+		StaffRepo:     staffRepo,
+		ResidentsRepo: residentsRepo,
+	}
+
+	tokenAuth := &apitoken.Authenticator{
+		Logger: logger,
+		Tokens: tokenRepo,
+		Roles:  sm.Roles,
+	}
+
 	userHandler := handlers.UserHandler{
+		SessionManager:  sm,
+		StaffRepo:       staffRepo,
+		ResidentsRepo:   residentsRepo,
+		UserRepo:        userRepo,
+		SignupRepo:      signupRepo,
+		ResetSender:     &userdata.LogResetTokenSender{Logger: logger},
+		ResetTokenTTL:   30 * time.Minute,
+		LoginLimiter:    loginGuard,
+		PasswordPolicy:  credentials.LoadPasswordPolicy(),
+		TwoFactorRepo:   twoFactorRepo,
+		APITokens:       tokenRepo,
+		RequireStaff2FA: requireStaff2FA,
+		Logger:          logger,
+	}
+
+	requestIntake := &intake.Service{
+		RequestsRepo:      reqRepo,
+		StaffRepo:         staffRepo,
+		AnnouncementsRepo: announcementsRepo,
+		Logger:            logger,
+	}
+
+	reqHandler := handlers.RequestsHandler{
+		RequestsRepo:      reqRepo,
+		Logger:            logger,
+		StaffRepo:         staffRepo,
+		UserRepo:          userRepo,
+		ResidentsRepo:     residentsRepo,
+		BillingRepo:       billingRepo,
+		AnnouncementsRepo: announcementsRepo,
+		RatingsRepo:       ratingsRepo,
+		AppointmentsRepo:  appointmentsRepo,
+		CategoriesRepo:    categoriesRepo,
+		Intake:            requestIntake,
+	}
+
+	staffHandler := handlers.StaffHandler{
+		StaffRepo:   staffRepo,
+		RatingsRepo: ratingsRepo,
+		// the login moves with every table keyed by the phone number
+		PhoneRenamer: &credentials.PgPhoneRenamer{
+			DB:     db,
+			Logger: logger,
+			Tables: []string{
+				userdata.UserPg{}.TableName(),
+				userdata.PasswordResetTokenPg{}.TableName(),
+				twofactor.TwoFactorPg{}.TableName(),
+				twofactor.RecoveryCodePg{}.TableName(),
+				apitoken.TokenPg{}.TableName(),
+				residence.ResidentPg{}.TableName(),
+				company.StaffMemberPg{}.TableName(),
+				company.OrganizationRepresentativePg{}.TableName(),
+			},
+		},
 		SessionManager: sm,
-		StaffRepo:      staffRepo,
-		ResidentsRepo:  residentsRepo,
-		UserRepo:       userRepo,
 		Logger:         logger,
 	}
 
-	reqHandler := handlers.RequestsHandler{
-		RequestsRepo:  reqRepo,
+	resHandler := handlers.ResidentsHandler{
+		ResidentsRepo: residentsRepo,
 		Logger:        logger,
-		StaffRepo:     staffRepo,
-		UserRepo:      userRepo,
+	}
+
+	contractorHandler := handlers.ContractorHandler{
+		RequestsRepo: reqRepo,
+		StaffRepo:    staffRepo,
+		BillingRepo:  billingRepo,
+		Logger:       logger,
+	}
+
+	announcementsHandler := handlers.AnnouncementsHandler{
+		AnnouncementsRepo: announcementsRepo,
+		ResidentsRepo:     residentsRepo,
+		Logger:            logger,
+	}
+
+	ratingsHandler := handlers.RatingsHandler{
+		RatingsRepo:   ratingsRepo,
 		ResidentsRepo: residentsRepo,
+		Logger:        logger,
 	}
 
-	staffHandler := handlers.StaffHandler{
-		StaffRepo: staffRepo,
-		Logger:    logger,
+	appointmentsHandler := handlers.AppointmentsHandler{
+		AppointmentsRepo: appointmentsRepo,
+		RequestsRepo:     reqRepo,
+		StaffRepo:        staffRepo,
+		ResidentsRepo:    residentsRepo,
+		Logger:           logger,
 	}
 
-	resHandler := handlers.ResidentsHandler{
+	// an unset or malformed interval falls back to the scheduler default
+	maintenanceInterval, _ := time.ParseDuration(os.Getenv("MAINTENANCE_SCHEDULER_INTERVAL"))
+	maintenanceScheduler := &maintenance.Scheduler{
+		Repo:         maintenanceRepo,
+		StaffRepo:    staffRepo,
+		RequestsRepo: reqRepo,
+		Interval:     maintenanceInterval,
+		Logger:       logger,
+	}
+
+	maintenanceHandler := handlers.MaintenanceHandler{
+		MaintenanceRepo: maintenanceRepo,
+		Scheduler:       maintenanceScheduler,
+		Logger:          logger,
+	}
+
+	inventoryHandler := handlers.InventoryHandler{
+		InventoryRepo: inventoryRepo,
+		Logger:        logger,
+	}
+
+	categoriesHandler := handlers.CategoriesHandler{
+		CategoriesRepo: categoriesRepo,
+		Logger:         logger,
+	}
+
+	complaintsHandler := handlers.ComplaintsHandler{
+		ComplaintsRepo: complaintsRepo,
+		ResidentsRepo:  residentsRepo,
+		Logger:         logger,
+	}
+
+	billingHandler := handlers.BillingHandler{
+		BillingRepo:   billingRepo,
+		StaffRepo:     staffRepo,
 		ResidentsRepo: residentsRepo,
+		RequestsRepo:  reqRepo,
+		FontBytes:     statementFont,
 		Logger:        logger,
 	}
 
+	apiV1Handler := &apiv1.Handler{
+		RequestsRepo:   reqRepo,
+		ResidentsRepo:  residentsRepo,
+		StaffRepo:      staffRepo,
+		UserRepo:       userRepo,
+		CategoriesRepo: categoriesRepo,
+		Intake:         requestIntake,
+		Logger:         logger,
+	}
+
 	r.Use(sm.UserFromSession())
+	r.Use(tokenAuth.BearerAuth())
+	r.Use(userHandler.ResolveLanguage())
+	// token endpoints authenticate by the body only, the session cookie plays no part there
+	r.Use(sm.CSRFProtect("/api/token", "/api/token/refresh", "/api/token/revoke"))
 
 	api := r.Group("/api")
 	staffGroup := r.Group("/staff")
 	residentGroup := r.Group("/resident")
+	contractorGroup := r.Group("/contractor")
 
 	staffApiGroup := api.Group("/staff")
 	residentApiGroup := api.Group("/resident")
+	contractorApiGroup := api.Group("/contractor")
 
-	staffGroup.Use(sm.UserFromSession(), sm.RequireRoles(session.StaffRole))
-	staffApiGroup.Use(sm.UserFromSession(), sm.RequireRoles(session.StaffRole))
-	residentGroup.Use(sm.UserFromSession(), sm.RequireRoles(session.ResidentRole, session.StaffRole))
-	residentApiGroup.Use(sm.UserFromSession(), sm.RequireRoles(session.ResidentRole, session.StaffRole))
+	staffGroup.Use(sm.RequireRoles(session.StaffRole))
+	staffApiGroup.Use(sm.RequireRoles(session.StaffRole))
+	// the resident group also carries the account pages (password, sessions, tokens) shared by every role,
+	// request handlers there look the resident up by phone, so contractors get nothing from them
+	residentGroup.Use(sm.RequireRoles(session.ResidentRole, session.StaffRole, session.ContractorRole))
+	residentApiGroup.Use(sm.RequireRoles(session.ResidentRole, session.StaffRole, session.ContractorRole))
+	contractorGroup.Use(sm.RequireRoles(session.ContractorRole))
+	contractorApiGroup.Use(sm.RequireRoles(session.ContractorRole))
+
+	// roles of /api/v1 are checked per route so that the errors come in the v1 envelope
+	apiV1Handler.Register(api.Group("/v1"))
 
 	r.Static("/static", "./web/static")
 	pageHandler.InitHTML()
@@ -130,10 +398,57 @@ func main() {
 
 	api.POST("/login", userHandler.Login())
 	staffApiGroup.POST("/register", userHandler.Register())
+	r.GET("/signup", pageHandler.SignupPage())
+	api.POST("/signup", userHandler.SignUp())
+	api.POST("/signup/status", userHandler.GetSignupStatus())
+	staffGroup.GET("/signups", pageHandler.SignupsPage())
+	staffApiGroup.GET("/signups", userHandler.GetSignups())
+	staffApiGroup.POST("/signups/review", userHandler.ReviewSignup())
 	r.GET("/login", pageHandler.LoginPage())
 	residentGroup.GET("/create-request", pageHandler.CreateRequestPage())
 	residentApiGroup.POST("/create-request", reqHandler.CreateRequest())
-	r.GET("/logout", userHandler.Logout())
+	residentApiGroup.GET("/categories", categoriesHandler.GetActiveTree())
+	residentGroup.GET("/complaints", pageHandler.ComplaintsPage())
+	residentApiGroup.GET("/complaints", complaintsHandler.GetMyComplaints())
+	residentApiGroup.POST("/complaints", complaintsHandler.CreateComplaint())
+	residentApiGroup.GET("/complaints/warnings", complaintsHandler.GetMyWarnings())
+	// logout changes the session, so it is a POST that the CSRF check covers
+	r.POST("/logout", userHandler.Logout())
+
+	r.GET("/2fa/verify", pageHandler.TwoFactorVerifyPage())
+	r.GET("/2fa/setup", pageHandler.TwoFactorSetupPage())
+	api.POST("/2fa/verify", userHandler.VerifyTwoFactor())
+	api.GET("/2fa/status", userHandler.GetTwoFactorStatus())
+	api.POST("/2fa/enroll", userHandler.BeginTwoFactorEnrollment())
+	api.POST("/2fa/enroll/confirm", userHandler.ConfirmTwoFactorEnrollment())
+	staffApiGroup.POST("/2fa/recovery-codes", userHandler.RegenerateRecoveryCodes())
+	staffApiGroup.POST("/2fa/disable", userHandler.DisableTwoFactor())
+
+	api.POST("/token", userHandler.IssueTokens())
+	api.POST("/token/refresh", userHandler.RefreshTokens())
+	api.POST("/token/revoke", userHandler.RevokeRefreshToken())
+	residentGroup.GET("/tokens", pageHandler.APITokensPage())
+	residentApiGroup.GET("/tokens", userHandler.GetMyTokens())
+	residentApiGroup.DELETE("/tokens/:id", userHandler.RevokeMyToken())
+	staffApiGroup.POST("/tokens", userHandler.CreatePersonalToken())
+
+	r.GET("/password/reset", pageHandler.PasswordResetPage())
+	api.POST("/password/forgot", userHandler.RequestPasswordReset())
+	api.POST("/password/reset", userHandler.ResetPassword())
+	residentGroup.GET("/change-password", pageHandler.ChangePasswordPage())
+	residentApiGroup.POST("/password/change", userHandler.ChangePassword())
+	residentApiGroup.POST("/language", userHandler.SetMyLanguage())
+	residentGroup.GET("/sessions", pageHandler.SessionsPage())
+	residentApiGroup.GET("/sessions", userHandler.GetMySessions())
+	residentApiGroup.DELETE("/sessions", userHandler.RevokeMyOtherSessions())
+	residentApiGroup.DELETE("/sessions/:id", userHandler.RevokeMySession())
+
+	residentGroup.GET("/profile", pageHandler.ProfilePage())
+	residentApiGroup.GET("/profile", resHandler.GetMyProfile())
+	residentApiGroup.POST("/profile", resHandler.UpdateMyProfile())
+	residentApiGroup.GET("/houses", resHandler.GetHouses())
+	residentApiGroup.POST("/houses/link-requests", resHandler.RequestHouseLink())
+	residentApiGroup.GET("/announcements", announcementsHandler.GetMyAnnouncements())
 	r.GET("/", pageHandler.MainPage())
 	staffGroup.GET("/register", pageHandler.RegisterPage())
 	staffGroup.GET("/admin-panel", pageHandler.AdminPage())
@@ -142,6 +457,12 @@ func main() {
 	staffApiGroup.DELETE("/users/delete/:phoneNumber", userHandler.DeleteUser())
 
 	staffApiGroup.GET("/users/info/:phoneNumber", userHandler.GetUserDetails())
+	staffApiGroup.POST("/users/password-reset/:phoneNumber", userHandler.StaffResetPassword())
+	staffApiGroup.POST("/users/2fa/reset", userHandler.ResetStaffTwoFactor())
+	staffApiGroup.GET("/users/sessions/:phoneNumber", userHandler.GetUserSessions())
+	staffApiGroup.DELETE("/users/sessions/:phoneNumber", userHandler.RevokeUserSessions())
+	staffApiGroup.GET("/users/tokens/:phoneNumber", userHandler.GetUserTokens())
+	staffApiGroup.DELETE("/users/tokens/:phoneNumber", userHandler.RevokeUserTokens())
 
 	staffApiGroup.GET("/users/resident/info", resHandler.GetHousesForResident())
 	staffApiGroup.DELETE("/users/resident/remove-house", resHandler.DeleteHouseForResident())
@@ -152,10 +473,29 @@ func main() {
 	staffApiGroup.GET("/users/staff/info", staffHandler.GetSpecializationsForStaffMember())
 	staffApiGroup.DELETE("/users/staff/delete-spec", staffHandler.DeactivateSpecialization())
 	staffApiGroup.POST("/users/staff/add-specialization", staffHandler.AddStaffSpecialization())
+	staffApiGroup.GET("/users/staff/list", staffHandler.GetStaffMembers())
+	staffApiGroup.GET("/users/staff/profile", staffHandler.GetStaffProfile())
+	staffApiGroup.POST("/users/staff/profile", staffHandler.UpdateStaffProfile())
+	staffApiGroup.POST("/users/staff/status", staffHandler.ChangeStaffStatus())
+	staffApiGroup.GET("/users/staff/status-history", staffHandler.GetStaffStatusHistory())
+	staffApiGroup.GET("/users/staff/schedule", staffHandler.GetStaffSchedule())
+	staffApiGroup.POST("/users/staff/schedule", staffHandler.SetStaffShift())
+	staffApiGroup.DELETE("/users/staff/schedule", staffHandler.DeleteStaffShift())
+	staffApiGroup.POST("/users/staff/absences", staffHandler.AddStaffAbsence())
+	staffApiGroup.DELETE("/users/staff/absences/:id", staffHandler.DeleteStaffAbsence())
+
+	staffGroup.GET("/calendar", pageHandler.CalendarPage())
+	staffApiGroup.GET("/calendar", staffHandler.GetDutyCalendar())
 
 	staffApiGroup.GET("/organizations/list", staffHandler.GetOrganizations())
 	staffApiGroup.POST("/organizations/create", staffHandler.CreateOrganization())
 	staffApiGroup.POST("/organizations/update", staffHandler.UpdateOrganizationName())
+	staffApiGroup.GET("/organizations/info", staffHandler.GetOrganizationInfo())
+	staffApiGroup.POST("/organizations/contacts", staffHandler.UpdateOrganizationContacts())
+	staffApiGroup.POST("/organizations/categories", staffHandler.SetOrganizationCategories())
+	staffApiGroup.POST("/organizations/contracts", staffHandler.AddOrganizationContract())
+	staffApiGroup.DELETE("/organizations/contracts/:id", staffHandler.DeactivateOrganizationContract())
+	staffApiGroup.GET("/organizations/representatives", staffHandler.GetRepresentatives())
 	staffGroup.GET("/organizations/panel", pageHandler.OrganizationsPage())
 
 	staffApiGroup.GET("/specializations/list", staffHandler.GetAllSpecs())
@@ -165,14 +505,104 @@ func main() {
 	staffApiGroup.GET("/houses/list", resHandler.GetHouses())
 	staffApiGroup.POST("/houses/create", resHandler.CreateHouse())
 	staffGroup.GET("/houses/info", pageHandler.HousesPage())
+	staffApiGroup.GET("/houses/link-requests", resHandler.GetHouseLinkRequests())
+	staffApiGroup.POST("/houses/link-requests/review", resHandler.ReviewHouseLinkRequest())
+
+	staffGroup.GET("/announcements", pageHandler.AnnouncementsPage())
+	staffApiGroup.GET("/announcements", announcementsHandler.GetAnnouncements())
+	staffApiGroup.POST("/announcements", announcementsHandler.CreateAnnouncement())
+	staffApiGroup.POST("/announcements/finish", announcementsHandler.FinishAnnouncement())
+	staffApiGroup.DELETE("/announcements/:id", announcementsHandler.DeleteAnnouncement())
 
 	staffApiGroup.GET("/requests/panel", reqHandler.GetRequestsForAdmin())
 	staffApiGroup.POST("/requests/panel/update", reqHandler.UpdateRequest())
-	staffApiGroup.GET("/requests/panel/update/random-assign", staffHandler.GetLeastBusyByJobID())
+	staffApiGroup.POST("/requests/panel/update/random-assign", staffHandler.GetLeastBusyByJobID())
 	staffApiGroup.DELETE("/requests/panel/delete/:id", reqHandler.DeleteRequest())
+	staffApiGroup.POST("/requests/panel/transfer", reqHandler.TransferRequest())
+	staffApiGroup.POST("/requests/panel/merge", reqHandler.MergeRequests())
+	staffApiGroup.POST("/requests/panel/unmerge", reqHandler.UnmergeRequest())
+	staffApiGroup.POST("/requests/panel/contractor/accept", reqHandler.RecordContractorAcceptance())
+	staffApiGroup.POST("/requests/panel/contractor/complete", reqHandler.RecordContractorCompletion())
+	staffApiGroup.GET("/requests/panel/updates", reqHandler.GetRequestUpdates())
+	staffApiGroup.GET("/requests/panel/costs", billingHandler.GetRequestCosts())
+	staffApiGroup.POST("/requests/panel/costs", billingHandler.AddRequestCost())
+	staffApiGroup.DELETE("/requests/panel/costs/:id", billingHandler.DeleteRequestCost())
+
+	staffGroup.GET("/billing/panel", pageHandler.BillingPage())
+	staffApiGroup.GET("/billing/pending", billingHandler.RequireAccountant(), billingHandler.GetPendingCosts())
+	staffApiGroup.POST("/billing/review", billingHandler.RequireAccountant(), billingHandler.ReviewCost())
+	staffApiGroup.GET("/billing/statement", billingHandler.GetResidentStatement())
+	staffApiGroup.POST("/users/staff/accountant", billingHandler.RequireAccountant(), billingHandler.GrantAccountant())
+	staffApiGroup.DELETE("/users/staff/accountant", billingHandler.RequireAccountant(), billingHandler.RevokeAccountant())
+	residentApiGroup.GET("/billing/statement", billingHandler.GetMyStatement())
+
+	residentApiGroup.POST("/requests/rate", ratingsHandler.RateRequest())
+	staffGroup.GET("/analytics/ratings", pageHandler.RatingsPage())
+	staffApiGroup.GET("/analytics/ratings", ratingsHandler.GetRatingAnalytics())
+	staffApiGroup.GET("/analytics/ratings/list", ratingsHandler.GetRatings())
+
+	staffApiGroup.GET("/requests/panel/slots", appointmentsHandler.GetRequestSlots())
+	staffApiGroup.POST("/requests/panel/slots", appointmentsHandler.ProposeSlots())
+	staffApiGroup.POST("/requests/panel/slots/cancel", appointmentsHandler.CancelSlotByStaff())
+	staffApiGroup.GET("/appointments", appointmentsHandler.GetMyStaffAppointments())
+	staffApiGroup.POST("/appointments/feed", appointmentsHandler.RotateStaffFeed())
+	residentApiGroup.GET("/appointments", appointmentsHandler.GetMyResidentAppointments())
+	residentApiGroup.POST("/appointments/pick", appointmentsHandler.PickSlot())
+	residentApiGroup.POST("/appointments/cancel", appointmentsHandler.CancelSlotByResident())
+	residentApiGroup.POST("/appointments/feed", appointmentsHandler.RotateResidentFeed())
+	// calendar apps fetch the feed without a session, the token in the path is the credential
+	r.GET("/calendar/:token", appointmentsHandler.GetCalendarFeed())
+
+	staffGroup.GET("/maintenance", pageHandler.MaintenancePage())
+	staffApiGroup.GET("/maintenance/plans", maintenanceHandler.GetPlans())
+	staffApiGroup.POST("/maintenance/plans", maintenanceHandler.CreatePlan())
+	staffApiGroup.POST("/maintenance/plans/active", maintenanceHandler.SetPlanActive())
+	staffApiGroup.DELETE("/maintenance/plans/:id", maintenanceHandler.DeletePlan())
+	staffApiGroup.GET("/maintenance/preview", maintenanceHandler.PreviewRule())
+	staffApiGroup.POST("/maintenance/run", maintenanceHandler.RunScheduler())
+
+	staffGroup.GET("/inventory", pageHandler.InventoryPage())
+	staffApiGroup.GET("/inventory/locations", inventoryHandler.GetLocations())
+	staffApiGroup.POST("/inventory/locations", inventoryHandler.CreateLocation())
+	staffApiGroup.DELETE("/inventory/locations/:id", inventoryHandler.DeleteLocation())
+	staffApiGroup.GET("/inventory/items", inventoryHandler.GetStock())
+	staffApiGroup.POST("/inventory/items", inventoryHandler.CreateItem())
+	staffApiGroup.POST("/inventory/items/update", inventoryHandler.UpdateItem())
+	staffApiGroup.POST("/inventory/receive", inventoryHandler.Receive())
+	staffApiGroup.POST("/inventory/writeoff", inventoryHandler.WriteOff())
+	staffApiGroup.POST("/inventory/transfer", inventoryHandler.Transfer())
+	staffApiGroup.GET("/inventory/movements", inventoryHandler.GetMovements())
+	staffApiGroup.GET("/inventory/report", inventoryHandler.GetMovementReport())
+	staffApiGroup.GET("/requests/panel/materials", inventoryHandler.GetRequestMaterials())
+	staffApiGroup.POST("/requests/panel/materials", inventoryHandler.UseMaterials())
+	staffApiGroup.POST("/requests/panel/materials/return", inventoryHandler.ReturnMaterials())
+
+	staffGroup.GET("/categories", pageHandler.CategoriesPage())
+	staffApiGroup.GET("/categories", categoriesHandler.GetTree())
+	staffApiGroup.POST("/categories", categoriesHandler.CreateCategory())
+	staffApiGroup.POST("/categories/update", categoriesHandler.UpdateCategory())
+	staffApiGroup.POST("/categories/active", categoriesHandler.SetCategoryActive())
+
+	staffGroup.GET("/complaints", pageHandler.AdminComplaintsPage())
+	staffApiGroup.GET("/complaints", complaintsHandler.GetComplaints())
+	staffApiGroup.POST("/complaints/move", complaintsHandler.MoveComplaint())
+
+	contractorGroup.GET("/requests", pageHandler.ContractorRequestsPage())
+	contractorApiGroup.GET("/requests", contractorHandler.GetRequests())
+	contractorApiGroup.GET("/requests/updates", contractorHandler.GetRequestUpdates())
+	contractorApiGroup.POST("/requests/accept", contractorHandler.AcceptRequest())
+	contractorApiGroup.POST("/requests/decline", contractorHandler.DeclineRequest())
+	contractorApiGroup.POST("/requests/updates", contractorHandler.PostUpdate())
+	contractorApiGroup.POST("/requests/complete", contractorHandler.CompleteRequest())
 
 	staffGroup.GET("/requests/panel", pageHandler.AdminRequestsPage())
 	staffGroup.GET("/users/panel", pageHandler.UsersManagerPage())
 
+	staffApiGroup.GET("/security/lockouts", userHandler.GetLoginLockouts())
+	staffApiGroup.DELETE("/security/lockouts", userHandler.ClearLoginLockout())
+	staffGroup.GET("/security/lockouts", pageHandler.LockoutsPage())
+
+	go maintenanceScheduler.Run(context.Background())
+
 	log.Fatal(r.Run(":8000"))
 }
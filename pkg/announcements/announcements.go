@@ -0,0 +1,76 @@
+package announcements
+
+import "time"
+
+type Announcement struct {
+	ID        string    `gorm:"type:char(40);primaryKey"`
+	Kind      Kind      `gorm:"type:varchar(20);not null"`
+	Title     string    `gorm:"type:varchar(100);not null"`
+	Body      string    `gorm:"type:varchar(1000);not null;default:''"`
+	StartsAt  time.Time `gorm:"column:starts_at;type:timestamp;not null;index"`
+	EndsAt    time.Time `gorm:"column:ends_at;type:timestamp;not null;index"`
+	CreatedBy string    `gorm:"column:created_by;type:varchar(40);not null"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+	HouseIDs  []int     `gorm:"-"`
+}
+
+// IsActive tells whether the notice is in force at the moment, EndsAt is exclusive.
+func (a *Announcement) IsActive(moment time.Time) bool {
+	return !moment.Before(a.StartsAt) && moment.Before(a.EndsAt)
+}
+
+// AnnouncementHouse links a notice to a house it affects.
+type AnnouncementHouse struct {
+	AnnouncementID string `gorm:"column:id_announcement;type:char(40);primaryKey"`
+	HouseID        int    `gorm:"column:id_house;type:bigint;primaryKey;index"`
+}
+
+type NewAnnouncement struct {
+	Kind      Kind
+	Title     string
+	Body      string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	HouseIDs  []int
+	CreatedBy string
+}
+
+type AnnouncementsRepo interface {
+	Create(announcement NewAnnouncement) (*Announcement, error)
+	// GetAll lists notices newest first, houseID narrows to one house and activeAt to the notices in force then
+	GetAll(houseID *int, activeAt *time.Time, limit, offset int) ([]*Announcement, int, error)
+	GetActiveForHouses(houseIDs []int, kind *Kind, moment time.Time) ([]*Announcement, error)
+	// Finish ends a notice early, e.g. when the water is back before the announced time
+	Finish(id string, at time.Time) error
+	Delete(id string) error
+}
+
+type Kind string
+
+const (
+	KindNotice Kind = "объявление"
+	KindOutage Kind = "отключение"
+)
+
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindNotice, KindOutage:
+		return true
+	}
+	return false
+}
+
+// Code is the stable machine name of the kind for clients and translations, the stored value stays Russian.
+func (k Kind) Code() string {
+	switch k {
+	case KindNotice:
+		return "notice"
+	case KindOutage:
+		return "outage"
+	default:
+		return ""
+	}
+}
+
+// Kinds lists every kind, e.g. to translate them all.
+var Kinds = []Kind{KindNotice, KindOutage}
@@ -0,0 +1,276 @@
+package announcements
+
+import (
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+	ErrCreatingAnnouncement = errors.New("error creating announcement")
+	ErrAlreadyFinished      = errors.New("announcement is already over")
+)
+
+type AnnouncementPg Announcement
+
+func (AnnouncementPg) TableName() string {
+	return "announcements"
+}
+
+type AnnouncementHousePg AnnouncementHouse
+
+func (AnnouncementHousePg) TableName() string {
+	return "announcement_houses"
+}
+
+type AnnouncementsPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewAnnouncementsPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *AnnouncementsPgRepo {
+	return &AnnouncementsPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (repo *AnnouncementsPgRepo) Create(announcement NewAnnouncement) (*Announcement, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&residence.HousePg{}).Where("id IN ?", announcement.HouseIDs).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check houses %v: %v", announcement.HouseIDs, err)
+		return nil, err
+	}
+	if int(count) != len(announcement.HouseIDs) {
+		return nil, residence.ErrNoHouseFound
+	}
+
+	announcementPg := AnnouncementPg{
+		Kind:      announcement.Kind,
+		Title:     announcement.Title,
+		Body:      announcement.Body,
+		StartsAt:  announcement.StartsAt,
+		EndsAt:    announcement.EndsAt,
+		CreatedBy: announcement.CreatedBy,
+		CreatedAt: time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < retries && !createdFlag; i++ {
+		announcementID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate announcement ID, %v", err)
+			continue
+		}
+
+		announcementPg.ID = announcementID
+
+		err = repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			upsertRes := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&announcementPg)
+			if upsertRes.Error != nil {
+				return upsertRes.Error
+			}
+			if upsertRes.RowsAffected != 1 {
+				return ErrCreatingAnnouncement
+			}
+
+			links := make([]AnnouncementHousePg, len(announcement.HouseIDs))
+			for j, houseID := range announcement.HouseIDs {
+				links[j] = AnnouncementHousePg{AnnouncementID: announcementID, HouseID: houseID}
+			}
+
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+		})
+		if err != nil {
+			repo.logger.Warnf("failed to create announcement: %v", err)
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingAnnouncement
+	}
+
+	created := Announcement(announcementPg)
+	created.HouseIDs = announcement.HouseIDs
+
+	return &created, nil
+}
+
+// attachHouses fills HouseIDs of the loaded notices with one query.
+func (repo *AnnouncementsPgRepo) attachHouses(ctx context.Context, list []*Announcement) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(list))
+	byID := make(map[string]*Announcement, len(list))
+	for i, a := range list {
+		ids[i] = a.ID
+		a.HouseIDs = []int{}
+		byID[a.ID] = a
+	}
+
+	var links []AnnouncementHousePg
+	if err := repo.db.WithContext(ctx).Where("id_announcement IN ?", ids).Order("id_house").Find(&links).Error; err != nil {
+		repo.logger.Warnf("failed to get houses of announcements: %v", err)
+		return err
+	}
+
+	for _, link := range links {
+		if a, ok := byID[link.AnnouncementID]; ok {
+			a.HouseIDs = append(a.HouseIDs, link.HouseID)
+		}
+	}
+
+	return nil
+}
+
+func (repo *AnnouncementsPgRepo) GetAll(houseID *int, activeAt *time.Time, limit, offset int) ([]*Announcement, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).Model(&AnnouncementPg{})
+
+	if houseID != nil {
+		query = query.Where("id IN (?)", repo.db.Model(&AnnouncementHousePg{}).Select("id_announcement").Where("id_house = ?", *houseID))
+	}
+	if activeAt != nil {
+		query = query.Where("starts_at <= ? AND ends_at > ?", *activeAt, *activeAt)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count announcements: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*Announcement{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var announcementsPg []AnnouncementPg
+	if err := query.Order("starts_at DESC").Find(&announcementsPg).Error; err != nil {
+		repo.logger.Warnf("failed to query announcements: %v", err)
+		return nil, int(total), err
+	}
+
+	list := make([]*Announcement, len(announcementsPg))
+	for i := range announcementsPg {
+		list[i] = (*Announcement)(&announcementsPg[i])
+	}
+
+	if err := repo.attachHouses(ctx, list); err != nil {
+		return nil, int(total), err
+	}
+
+	return list, int(total), nil
+}
+
+func (repo *AnnouncementsPgRepo) GetActiveForHouses(houseIDs []int, kind *Kind, moment time.Time) ([]*Announcement, error) {
+	if len(houseIDs) == 0 {
+		return []*Announcement{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).
+		Where("id IN (?)", repo.db.Model(&AnnouncementHousePg{}).Select("id_announcement").Where("id_house IN ?", houseIDs)).
+		Where("starts_at <= ? AND ends_at > ?", moment, moment)
+
+	if kind != nil {
+		query = query.Where("kind = ?", *kind)
+	}
+
+	var announcementsPg []AnnouncementPg
+	if err := query.Order("starts_at DESC").Find(&announcementsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get active announcements for houses %v: %v", houseIDs, err)
+		return nil, err
+	}
+
+	list := make([]*Announcement, len(announcementsPg))
+	for i := range announcementsPg {
+		list[i] = (*Announcement)(&announcementsPg[i])
+	}
+
+	if err := repo.attachHouses(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (repo *AnnouncementsPgRepo) Finish(id string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// a notice that has not started yet ends together with its start, so it never shows up
+	res := repo.db.WithContext(ctx).Model(&AnnouncementPg{}).
+		Where("id = ? AND ends_at > ?", id, at).
+		Update("ends_at", gorm.Expr("GREATEST(starts_at, ?)", at))
+	if res.Error != nil {
+		repo.logger.Warnf("failed to finish announcement %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := repo.db.WithContext(ctx).Model(&AnnouncementPg{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrAnnouncementNotFound
+		}
+		return ErrAlreadyFinished
+	}
+
+	return nil
+}
+
+func (repo *AnnouncementsPgRepo) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_announcement = ?", id).Delete(&AnnouncementHousePg{}).Error; err != nil {
+			repo.logger.Warnf("failed to delete houses of announcement %s: %v", id, err)
+			return err
+		}
+
+		res := tx.Where("id = ?", id).Delete(&AnnouncementPg{})
+		if res.Error != nil {
+			repo.logger.Warnf("failed to delete announcement %s: %v", id, res.Error)
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAnnouncementNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+package appointments
+
+import "time"
+
+// Slot is a visit window proposed by the responsible staff member, the resident picks one of them.
+type Slot struct {
+	ID            string     `gorm:"type:char(40);primaryKey"`
+	RequestID     string     `gorm:"column:id_request;type:char(40);not null;index"`
+	StaffMemberID int        `gorm:"column:id_staff_member;type:bigint;not null;index"`
+	StartsAt      time.Time  `gorm:"column:starts_at;type:timestamp;not null"`
+	EndsAt        time.Time  `gorm:"column:ends_at;type:timestamp;not null"`
+	Status        SlotStatus `gorm:"type:varchar(20);not null;index"`
+	CreatedAt     time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+	ChosenAt      *time.Time `gorm:"column:chosen_at;type:timestamp"`
+	CancelledBy   *string    `gorm:"column:cancelled_by;type:varchar(40)"`
+	CancelReason  *string    `gorm:"column:cancel_reason;type:varchar(200)"`
+}
+
+// Window is a time range of a visit, EndsAt is exclusive.
+type Window struct {
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// Appointment is a chosen slot with what the visit is about, it becomes an event of the calendar feeds.
+type Appointment struct {
+	Slot
+	ResidentID string `gorm:"column:id_resident"`
+	HouseID    int    `gorm:"column:id_house"`
+	Address    string `gorm:"column:address"`
+	Complaint  string `gorm:"column:complaint"`
+	StaffName  string `gorm:"column:staff_name"`
+}
+
+// FeedToken opens the calendar feed of a staff member or a resident, only the hash of the token is stored.
+type FeedToken struct {
+	TokenHash string    `gorm:"type:char(64);column:token_hash;primaryKey" json:"-"`
+	OwnerKind OwnerKind `gorm:"column:owner_kind;type:varchar(20);not null;uniqueIndex:idx_feed_owner"`
+	OwnerID   string    `gorm:"column:owner_id;type:varchar(40);not null;uniqueIndex:idx_feed_owner"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+type AppointmentsRepo interface {
+	// ProposeSlots adds visit windows to an open apartment request, only its responsible may propose them
+	ProposeSlots(requestID string, staffMemberID int, windows []Window) ([]*Slot, error)
+	GetSlots(requestID string) ([]*Slot, error)
+	GetSlotsByRequestIDs(requestIDs []string) (map[string][]*Slot, error)
+	// PickSlot chooses the visit time, a slot chosen before goes back to the proposed ones
+	PickSlot(slotID, residentID string) (*Slot, error)
+	// CancelSlot withdraws a proposed slot or calls off a chosen visit so that another time is agreed
+	CancelSlot(slotID, cancelledBy string, reason *string) (*Slot, error)
+	GetSlotByID(slotID string) (*Slot, error)
+	GetStaffAppointments(staffMemberID int, since time.Time) ([]*Appointment, error)
+	GetResidentAppointments(residentID string, since time.Time) ([]*Appointment, error)
+	// RotateFeedToken replaces the owner's feed token, the old link stops working
+	RotateFeedToken(kind OwnerKind, ownerID string) (string, error)
+	GetFeedOwner(token string) (*FeedToken, error)
+}
+
+type SlotStatus string
+
+const (
+	SlotProposed  SlotStatus = "предложено"
+	SlotChosen    SlotStatus = "выбрано"
+	SlotCancelled SlotStatus = "отменено"
+)
+
+func (s SlotStatus) IsValid() bool {
+	switch s {
+	case SlotProposed, SlotChosen, SlotCancelled:
+		return true
+	}
+	return false
+}
+
+// Code is the stable machine name of the slot status for clients and translations, the stored value stays Russian.
+func (s SlotStatus) Code() string {
+	switch s {
+	case SlotProposed:
+		return "proposed"
+	case SlotChosen:
+		return "chosen"
+	case SlotCancelled:
+		return "cancelled"
+	default:
+		return ""
+	}
+}
+
+// SlotStatuses lists every slot status, e.g. to translate them all.
+var SlotStatuses = []SlotStatus{SlotProposed, SlotChosen, SlotCancelled}
+
+type OwnerKind string
+
+const (
+	OwnerStaff    OwnerKind = "staff"
+	OwnerResident OwnerKind = "resident"
+)
+
+func (k OwnerKind) IsValid() bool {
+	switch k {
+	case OwnerStaff, OwnerResident:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,408 @@
+package appointments
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSlotNotFound        = errors.New("slot not found")
+	ErrCreatingSlot        = errors.New("error creating slot")
+	ErrNotApartmentRequest = errors.New("visits are agreed only for apartment repairs")
+	ErrNotResponsible      = errors.New("only the staff member assigned to the request can manage its visit times")
+	ErrInvalidWindow       = errors.New("a visit window must start in the future and end after it starts")
+	ErrSlotOverlap         = errors.New("the staff member already has a visit at this time")
+	ErrSlotNotAvailable    = errors.New("slot can not be chosen anymore")
+	ErrFeedNotFound        = errors.New("calendar feed not found")
+)
+
+const feedTokenPrefix = "cal_"
+
+type SlotPg Slot
+
+func (SlotPg) TableName() string {
+	return "appointment_slots"
+}
+
+type FeedTokenPg FeedToken
+
+func (FeedTokenPg) TableName() string {
+	return "calendar_feed_tokens"
+}
+
+type AppointmentsPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewAppointmentsPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *AppointmentsPgRepo {
+	return &AppointmentsPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// checkBusy fails when the staff member has a chosen visit overlapping the window, exceptID skips the slot itself.
+func checkBusy(tx *gorm.DB, staffMemberID int, window Window, exceptID string) error {
+	var count int64
+	err := tx.Model(&SlotPg{}).
+		Where("id_staff_member = ? AND status = ? AND id <> ?", staffMemberID, SlotChosen, exceptID).
+		Where("starts_at < ? AND ends_at > ?", window.EndsAt, window.StartsAt).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSlotOverlap
+	}
+	return nil
+}
+
+func lockRequest(tx *gorm.DB, requestID string) (*requests.RequestPg, error) {
+	var reqPg requests.RequestPg
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", requestID).First(&reqPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, requests.ErrNoRequestsFound
+		}
+		return nil, err
+	}
+	return &reqPg, nil
+}
+
+func (repo *AppointmentsPgRepo) ProposeSlots(requestID string, staffMemberID int, windows []Window) ([]*Slot, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result := make([]*Slot, 0, len(windows))
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reqPg, err := lockRequest(tx, requestID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case reqPg.RequestType != requests.TypeApartmentInternal:
+			return ErrNotApartmentRequest
+		case reqPg.ParentID != nil:
+			return requests.ErrMergedRequest
+		case !reqPg.Status.IsOpen():
+			return requests.ErrRequestClosed
+		case reqPg.ResponsibleID == nil || *reqPg.ResponsibleID != staffMemberID:
+			return ErrNotResponsible
+		}
+
+		for _, window := range windows {
+			if !window.StartsAt.After(now) || !window.EndsAt.After(window.StartsAt) {
+				return ErrInvalidWindow
+			}
+			if err := checkBusy(tx, staffMemberID, window, ""); err != nil {
+				return err
+			}
+
+			slotPg := SlotPg{
+				RequestID:     requestID,
+				StaffMemberID: staffMemberID,
+				StartsAt:      window.StartsAt,
+				EndsAt:        window.EndsAt,
+				Status:        SlotProposed,
+				CreatedAt:     now,
+			}
+
+			createdFlag := false
+			for i := 0; i < retries && !createdFlag; i++ {
+				slotID, err := utils.GenerateID()
+				if err != nil {
+					repo.logger.Warnf("failed to generate slot ID, %v", err)
+					continue
+				}
+
+				slotPg.ID = slotID
+
+				upsertRes := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&slotPg)
+				if upsertRes.Error != nil {
+					return upsertRes.Error
+				}
+				createdFlag = upsertRes.RowsAffected == 1
+			}
+
+			if !createdFlag {
+				return ErrCreatingSlot
+			}
+
+			slot := Slot(slotPg)
+			result = append(result, &slot)
+		}
+
+		return nil
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to propose slots for request %s: %v", requestID, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *AppointmentsPgRepo) GetSlots(requestID string) ([]*Slot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var slotsPg []SlotPg
+	if err := repo.db.WithContext(ctx).Where("id_request = ?", requestID).Order("starts_at").Find(&slotsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get slots of request %s: %v", requestID, err)
+		return nil, err
+	}
+
+	result := make([]*Slot, len(slotsPg))
+	for i := range slotsPg {
+		result[i] = (*Slot)(&slotsPg[i])
+	}
+
+	return result, nil
+}
+
+// GetSlotsByRequestIDs leaves out the cancelled slots, the residents' list only needs the current ones.
+func (repo *AppointmentsPgRepo) GetSlotsByRequestIDs(requestIDs []string) (map[string][]*Slot, error) {
+	result := make(map[string][]*Slot, len(requestIDs))
+	if len(requestIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var slotsPg []SlotPg
+	err := repo.db.WithContext(ctx).
+		Where("id_request IN ? AND status <> ?", requestIDs, SlotCancelled).
+		Order("starts_at").
+		Find(&slotsPg).Error
+	if err != nil {
+		repo.logger.Warnf("failed to get slots of %d requests: %v", len(requestIDs), err)
+		return nil, err
+	}
+
+	for i := range slotsPg {
+		result[slotsPg[i].RequestID] = append(result[slotsPg[i].RequestID], (*Slot)(&slotsPg[i]))
+	}
+
+	return result, nil
+}
+
+func (repo *AppointmentsPgRepo) GetSlotByID(slotID string) (*Slot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var slotPg SlotPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", slotID).First(&slotPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSlotNotFound
+		}
+		repo.logger.Warnf("failed to get slot %s: %v", slotID, err)
+		return nil, err
+	}
+
+	slot := Slot(slotPg)
+	return &slot, nil
+}
+
+func (repo *AppointmentsPgRepo) PickSlot(slotID, residentID string) (*Slot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var slotPg SlotPg
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", slotID).First(&slotPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSlotNotFound
+			}
+			return err
+		}
+
+		// the request row serializes the choices made for it
+		reqPg, err := lockRequest(tx, slotPg.RequestID)
+		if err != nil {
+			return err
+		}
+		if reqPg.ResidentID != residentID {
+			return ErrSlotNotFound
+		}
+		if !reqPg.Status.IsOpen() {
+			return requests.ErrRequestClosed
+		}
+
+		if err := tx.Where("id = ?", slotID).First(&slotPg).Error; err != nil {
+			return err
+		}
+		// slots of a former responsible stay in the history but can not be chosen
+		if slotPg.Status != SlotProposed || !slotPg.StartsAt.After(time.Now()) ||
+			reqPg.ResponsibleID == nil || *reqPg.ResponsibleID != slotPg.StaffMemberID {
+			return ErrSlotNotAvailable
+		}
+
+		if err := checkBusy(tx, slotPg.StaffMemberID, Window{StartsAt: slotPg.StartsAt, EndsAt: slotPg.EndsAt}, slotID); err != nil {
+			return err
+		}
+
+		err = tx.Model(&SlotPg{}).
+			Where("id_request = ? AND status = ?", slotPg.RequestID, SlotChosen).
+			Updates(map[string]interface{}{"status": SlotProposed, "chosen_at": nil}).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		slotPg.Status = SlotChosen
+		slotPg.ChosenAt = &now
+
+		return tx.Model(&SlotPg{}).Where("id = ?", slotID).
+			Updates(map[string]interface{}{"status": SlotChosen, "chosen_at": now}).Error
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to pick slot %s: %v", slotID, err)
+		return nil, err
+	}
+
+	slot := Slot(slotPg)
+	return &slot, nil
+}
+
+func (repo *AppointmentsPgRepo) CancelSlot(slotID, cancelledBy string, reason *string) (*Slot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates := map[string]interface{}{
+		"status":        SlotCancelled,
+		"cancelled_by":  cancelledBy,
+		"cancel_reason": reason,
+	}
+
+	res := repo.db.WithContext(ctx).Model(&SlotPg{}).
+		Where("id = ? AND status <> ?", slotID, SlotCancelled).
+		Updates(updates)
+	if res.Error != nil {
+		repo.logger.Warnf("failed to cancel slot %s: %v", slotID, res.Error)
+		return nil, res.Error
+	}
+
+	slot, err := repo.GetSlotByID(slotID)
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrSlotNotAvailable
+	}
+
+	return slot, nil
+}
+
+func (repo *AppointmentsPgRepo) appointments(ctx context.Context, since time.Time) *gorm.DB {
+	return repo.db.WithContext(ctx).
+		Table(SlotPg{}.TableName()+" AS s").
+		Select("s.*, req.id_resident, req.id_house, house.address, req.complaint, member.full_name AS staff_name").
+		Joins("JOIN "+requests.RequestPg{}.TableName()+" AS req ON req.id = s.id_request").
+		Joins("JOIN "+residence.HousePg{}.TableName()+" AS house ON house.id = req.id_house").
+		Joins("JOIN "+company.StaffMemberPg{}.TableName()+" AS member ON member.id = s.id_staff_member").
+		Where("s.status = ? AND s.ends_at >= ?", SlotChosen, since).
+		Order("s.starts_at")
+}
+
+func (repo *AppointmentsPgRepo) GetStaffAppointments(staffMemberID int, since time.Time) ([]*Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result []*Appointment
+	if err := repo.appointments(ctx, since).Where("s.id_staff_member = ?", staffMemberID).Scan(&result).Error; err != nil {
+		repo.logger.Warnf("failed to get appointments of staff member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	if result == nil {
+		result = []*Appointment{}
+	}
+
+	return result, nil
+}
+
+func (repo *AppointmentsPgRepo) GetResidentAppointments(residentID string, since time.Time) ([]*Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result []*Appointment
+	if err := repo.appointments(ctx, since).Where("req.id_resident = ?", residentID).Scan(&result).Error; err != nil {
+		repo.logger.Warnf("failed to get appointments of resident %s: %v", residentID, err)
+		return nil, err
+	}
+
+	if result == nil {
+		result = []*Appointment{}
+	}
+
+	return result, nil
+}
+
+func (repo *AppointmentsPgRepo) RotateFeedToken(kind OwnerKind, ownerID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	raw, err := utils.GenerateID()
+	if err != nil {
+		repo.logger.Warnf("failed to generate feed token: %v", err)
+		return "", err
+	}
+	token := feedTokenPrefix + raw
+
+	err = repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_kind = ? AND owner_id = ?", kind, ownerID).Delete(&FeedTokenPg{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&FeedTokenPg{
+			TokenHash: utils.HashToken(token),
+			OwnerKind: kind,
+			OwnerID:   ownerID,
+			CreatedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to rotate feed token of %s %s: %v", kind, ownerID, err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (repo *AppointmentsPgRepo) GetFeedOwner(token string) (*FeedToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var feedPg FeedTokenPg
+	if err := repo.db.WithContext(ctx).Where("token_hash = ?", utils.HashToken(token)).First(&feedPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeedNotFound
+		}
+		repo.logger.Warnf("failed to find feed token: %v", err)
+		return nil, err
+	}
+
+	feed := FeedToken(feedPg)
+	return &feed, nil
+}
@@ -0,0 +1,81 @@
+package appointments
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icalStampLayout = "20060102T150405Z"
+	// visit times are written as floating local times, the columns hold the wall clock without a zone
+	icalTimeLayout = "20060102T150405"
+	icalLineLimit  = 75
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+
+// foldLine splits a content line into 75 octet pieces as RFC 5545 asks, not breaking UTF-8 sequences.
+func foldLine(line string) string {
+	if len(line) <= icalLineLimit {
+		return line + "\r\n"
+	}
+
+	var b strings.Builder
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// WriteCalendar renders the appointments as an iCalendar feed, the slot ID keeps an event stable across refreshes.
+func WriteCalendar(w io.Writer, name string, items []*Appointment) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//HOA//Visits//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + icalEscaper.Replace(name),
+	}
+
+	stamp := time.Now().UTC().Format(icalStampLayout)
+	for _, item := range items {
+		description := fmt.Sprintf("Request %s\n%s\nStaff: %s", item.RequestID, item.Complaint, item.StaffName)
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+item.ID+"@hoa",
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+item.StartsAt.Format(icalTimeLayout),
+			"DTEND:"+item.EndsAt.Format(icalTimeLayout),
+			"SUMMARY:"+icalEscaper.Replace("Repair visit: "+item.Address),
+			"LOCATION:"+icalEscaper.Replace(item.Address),
+			"DESCRIPTION:"+icalEscaper.Replace(description),
+			"STATUS:CONFIRMED",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package billing
+
+import (
+	"DBPrototyping/pkg/requests"
+	"time"
+)
+
+type LineItem struct {
+	ID          string         `gorm:"type:char(40);primaryKey"`
+	RequestID   string         `gorm:"column:id_request;type:char(40);not null;index"`
+	Kind        ItemKind       `gorm:"type:varchar(40);not null"`
+	Description string         `gorm:"type:varchar(200);not null"`
+	Quantity    float64        `gorm:"type:numeric(10,2);not null"`
+	UnitPrice   float64        `gorm:"column:unit_price;type:numeric(10,2);not null"`
+	Amount      float64        `gorm:"type:numeric(12,2);not null"`
+	Payer       Payer          `gorm:"type:varchar(20);not null"`
+	Status      ApprovalStatus `gorm:"type:varchar(20);not null;index"`
+	CreatedBy   string         `gorm:"column:created_by;type:varchar(40);not null"`
+	CreatedAt   time.Time      `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+
+	ReviewedBy    *string    `gorm:"column:reviewed_by;type:varchar(40)"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at;type:timestamp"`
+	ReviewComment *string    `gorm:"column:review_comment;type:varchar(200)"`
+}
+
+type NewLineItem struct {
+	RequestID   string
+	Kind        ItemKind
+	Description string
+	Quantity    float64
+	UnitPrice   float64
+	Payer       Payer
+	CreatedBy   string
+}
+
+// Accountant marks a staff member allowed to approve line items.
+type Accountant struct {
+	MemberID  int       `gorm:"column:id_member;type:bigint;primaryKey"`
+	GrantedAt time.Time `gorm:"column:granted_at;type:timestamp;not null;default:now()"`
+}
+
+type StatementLine struct {
+	LineItem
+	HouseID          int                  `gorm:"column:id_house"`
+	RequestType      requests.RequestType `gorm:"column:request_type"`
+	RequestCreatedAt time.Time            `gorm:"column:request_created_at"`
+}
+
+// Statement lists the approved items a resident pays for, From is inclusive and To is exclusive.
+type Statement struct {
+	ResidentID   string
+	From         time.Time
+	To           time.Time
+	Lines        []*StatementLine
+	TotalsByKind map[ItemKind]float64
+	Total        float64
+}
+
+type BillingRepo interface {
+	AddLineItem(item NewLineItem) (*LineItem, error)
+	GetLineItems(requestID string) ([]*LineItem, error)
+	GetPendingLineItems(limit, offset int) ([]*LineItem, int, error)
+	DeleteLineItem(id string) error
+	ReviewLineItem(id, reviewerPhone string, approve bool, comment string) error
+	GetResidentStatement(residentID string, from, to time.Time) (*Statement, error)
+	GrantAccountant(staffMemberID int) error
+	RevokeAccountant(staffMemberID int) error
+	IsAccountant(staffMemberID int) (bool, error)
+	HasAccountants() (bool, error)
+}
+
+type ItemKind string
+
+const (
+	KindMaterials         ItemKind = "материалы"
+	KindLabor             ItemKind = "работа"
+	KindContractorInvoice ItemKind = "счет_подрядчика"
+)
+
+func (k ItemKind) IsValid() bool {
+	switch k {
+	case KindMaterials, KindLabor, KindContractorInvoice:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the item kind for clients and translations, the stored value stays Russian.
+func (k ItemKind) Code() string {
+	switch k {
+	case KindMaterials:
+		return "materials"
+	case KindLabor:
+		return "labor"
+	case KindContractorInvoice:
+		return "contractor_invoice"
+	default:
+		return ""
+	}
+}
+
+// ItemKinds lists every item kind, e.g. to translate them all.
+var ItemKinds = []ItemKind{KindMaterials, KindLabor, KindContractorInvoice}
+
+type Payer string
+
+const (
+	PayerHOAFund  Payer = "фонд_тсж"
+	PayerResident Payer = "житель"
+)
+
+func (p Payer) IsValid() bool {
+	switch p {
+	case PayerHOAFund, PayerResident:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the payer for clients and translations, the stored value stays Russian.
+func (p Payer) Code() string {
+	switch p {
+	case PayerHOAFund:
+		return "hoa_fund"
+	case PayerResident:
+		return "resident"
+	default:
+		return ""
+	}
+}
+
+// Payers lists every payer, e.g. to translate them all.
+var Payers = []Payer{PayerHOAFund, PayerResident}
+
+// DefaultPayer is who normally pays for a request type: the resident for work inside the apartment,
+// the HOA fund for the common property.
+func DefaultPayer(requestType requests.RequestType) Payer {
+	if requestType == requests.TypeApartmentInternal {
+		return PayerResident
+	}
+	return PayerHOAFund
+}
+
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "на_проверке"
+	ApprovalApproved ApprovalStatus = "одобрено"
+	ApprovalRejected ApprovalStatus = "отклонено"
+)
+
+// Code is the stable machine name of the approval status for clients and translations, the stored value stays Russian.
+func (s ApprovalStatus) Code() string {
+	switch s {
+	case ApprovalPending:
+		return "pending"
+	case ApprovalApproved:
+		return "approved"
+	case ApprovalRejected:
+		return "rejected"
+	default:
+		return ""
+	}
+}
+
+// ApprovalStatuses lists every approval status, e.g. to translate them all.
+var ApprovalStatuses = []ApprovalStatus{ApprovalPending, ApprovalApproved, ApprovalRejected}
@@ -0,0 +1,333 @@
+package billing
+
+import (
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrLineItemNotFound   = errors.New("line item not found")
+	ErrCreatingLineItem   = errors.New("error creating line item")
+	ErrAlreadyReviewed    = errors.New("line item is already reviewed")
+	ErrSelfReview         = errors.New("line item can not be reviewed by its author")
+	ErrAccountantNotFound = errors.New("accountant not found")
+)
+
+type LineItemPg LineItem
+
+func (LineItemPg) TableName() string {
+	return "request_line_items"
+}
+
+type AccountantPg Accountant
+
+func (AccountantPg) TableName() string {
+	return "accountants"
+}
+
+type BillingPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewBillingPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *BillingPgRepo {
+	return &BillingPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func (repo *BillingPgRepo) AddLineItem(item NewLineItem) (*LineItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&requests.RequestPg{}).Where("id = ?", item.RequestID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check request %s: %v", item.RequestID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, requests.ErrNoRequestsFound
+	}
+
+	return CreateLineItem(repo.db.WithContext(ctx), item)
+}
+
+// CreateLineItem inserts a pending item with db, which lets other packages put the item in their own
+// transaction. The request is expected to be checked by the caller.
+func CreateLineItem(db *gorm.DB, item NewLineItem) (*LineItem, error) {
+	itemPg := LineItemPg{
+		RequestID:   item.RequestID,
+		Kind:        item.Kind,
+		Description: item.Description,
+		Quantity:    roundMoney(item.Quantity),
+		UnitPrice:   roundMoney(item.UnitPrice),
+		Amount:      roundMoney(item.Quantity * item.UnitPrice),
+		Payer:       item.Payer,
+		Status:      ApprovalPending,
+		CreatedBy:   item.CreatedBy,
+		CreatedAt:   time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+		itemID, err := utils.GenerateID()
+		if err != nil {
+			continue
+		}
+
+		itemPg.ID = itemID
+
+		upsertRes := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&itemPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingLineItem
+	}
+
+	lineItem := LineItem(itemPg)
+
+	return &lineItem, nil
+}
+
+func (repo *BillingPgRepo) GetLineItems(requestID string) ([]*LineItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var itemsPg []LineItemPg
+	if err := repo.db.WithContext(ctx).Where("id_request = ?", requestID).Order("created_at").Find(&itemsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get line items of request %s: %v", requestID, err)
+		return nil, err
+	}
+
+	items := make([]*LineItem, len(itemsPg))
+	for i := range itemsPg {
+		items[i] = (*LineItem)(&itemsPg[i])
+	}
+
+	return items, nil
+}
+
+func (repo *BillingPgRepo) GetPendingLineItems(limit, offset int) ([]*LineItem, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).Model(&LineItemPg{}).Where("status = ?", ApprovalPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count pending line items: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*LineItem{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var itemsPg []LineItemPg
+	if err := query.Order("created_at").Find(&itemsPg).Error; err != nil {
+		repo.logger.Warnf("failed to query pending line items: %v", err)
+		return nil, int(total), err
+	}
+
+	items := make([]*LineItem, len(itemsPg))
+	for i := range itemsPg {
+		items[i] = (*LineItem)(&itemsPg[i])
+	}
+
+	return items, int(total), nil
+}
+
+// DeleteLineItem removes an item that was not reviewed yet, reviewed items stay for the audit.
+func (repo *BillingPgRepo) DeleteLineItem(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).Where("id = ? AND status = ?", id, ApprovalPending).Delete(&LineItemPg{})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to delete line item %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := repo.db.WithContext(ctx).Model(&LineItemPg{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrLineItemNotFound
+		}
+		return ErrAlreadyReviewed
+	}
+
+	return nil
+}
+
+// ReviewLineItem approves or rejects a pending item and recalculates Request.Cost as the sum of the approved items.
+func (repo *BillingPgRepo) ReviewLineItem(id, reviewerPhone string, approve bool, comment string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var itemPg LineItemPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&itemPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrLineItemNotFound
+			}
+			return err
+		}
+
+		if itemPg.Status != ApprovalPending {
+			return ErrAlreadyReviewed
+		}
+		if itemPg.CreatedBy == reviewerPhone {
+			return ErrSelfReview
+		}
+
+		status := ApprovalRejected
+		if approve {
+			status = ApprovalApproved
+		}
+
+		updates := map[string]interface{}{
+			"status":         status,
+			"reviewed_by":    reviewerPhone,
+			"reviewed_at":    time.Now(),
+			"review_comment": nil,
+		}
+		if comment != "" {
+			updates["review_comment"] = comment
+		}
+
+		if err := tx.Model(&LineItemPg{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			repo.logger.Warnf("failed to review line item %s: %v", id, err)
+			return err
+		}
+
+		// the subquery gives NULL when nothing is approved, which keeps the old "no cost" meaning
+		costQuery := tx.Model(&LineItemPg{}).Select("SUM(amount)").Where("id_request = ? AND status = ?", itemPg.RequestID, ApprovalApproved)
+		if err := tx.Model(&requests.RequestPg{}).Where("id = ?", itemPg.RequestID).Update("cost", costQuery).Error; err != nil {
+			repo.logger.Warnf("failed to recalculate cost of request %s: %v", itemPg.RequestID, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (repo *BillingPgRepo) GetResidentStatement(residentID string, from, to time.Time) (*Statement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	itemTable := LineItemPg{}.TableName()
+	reqTable := requests.RequestPg{}.TableName()
+
+	var lines []*StatementLine
+	err := repo.db.WithContext(ctx).
+		Table(itemTable+" AS item").
+		Select("item.*, req.id_house, req.type AS request_type, req.created_at AS request_created_at").
+		Joins("JOIN "+reqTable+" AS req ON req.id = item.id_request").
+		Where("req.id_resident = ? AND item.payer = ? AND item.status = ?", residentID, PayerResident, ApprovalApproved).
+		Where("item.created_at >= ? AND item.created_at < ?", from, to).
+		Order("item.created_at").
+		Scan(&lines).Error
+	if err != nil {
+		repo.logger.Warnf("failed to build statement of resident %s: %v", residentID, err)
+		return nil, err
+	}
+
+	statement := &Statement{
+		ResidentID:   residentID,
+		From:         from,
+		To:           to,
+		Lines:        lines,
+		TotalsByKind: make(map[ItemKind]float64),
+	}
+
+	for _, line := range lines {
+		statement.TotalsByKind[line.Kind] = roundMoney(statement.TotalsByKind[line.Kind] + line.Amount)
+		statement.Total = roundMoney(statement.Total + line.Amount)
+	}
+
+	return statement, nil
+}
+
+func (repo *BillingPgRepo) GrantAccountant(staffMemberID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountantPg := AccountantPg{
+		MemberID:  staffMemberID,
+		GrantedAt: time.Now(),
+	}
+
+	if err := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&accountantPg).Error; err != nil {
+		repo.logger.Warnf("failed to grant accountant to staff member %d: %v", staffMemberID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *BillingPgRepo) RevokeAccountant(staffMemberID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).Where("id_member = ?", staffMemberID).Delete(&AccountantPg{})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to revoke accountant from staff member %d: %v", staffMemberID, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAccountantNotFound
+	}
+
+	return nil
+}
+
+func (repo *BillingPgRepo) IsAccountant(staffMemberID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&AccountantPg{}).Where("id_member = ?", staffMemberID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check accountant %d: %v", staffMemberID, err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (repo *BillingPgRepo) HasAccountants() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&AccountantPg{}).Limit(1).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to count accountants: %v", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,41 @@
+package billing
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/userdata/credentials"
+
+	"go.uber.org/zap"
+)
+
+// SeedAccountant grants the accountant role to the staff member with the phone while nobody holds it. The API
+// lets only accountants grant the role, so this is the one way to appoint the first of them. Once any
+// accountant exists it does nothing.
+func SeedAccountant(logger *zap.SugaredLogger, repo BillingRepo, staffRepo company.StaffRepo, phone string) error {
+	hasAccountants, err := repo.HasAccountants()
+	if err != nil {
+		return err
+	}
+	if hasAccountants {
+		return nil
+	}
+
+	normalized, err := credentials.NormalizePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	member, err := staffRepo.GetStaffMemberByPhoneNumber(normalized)
+	if err != nil {
+		return err
+	}
+	if member.Status == company.StatusInactive {
+		return company.ErrStaffMemberNotFound
+	}
+
+	if err := repo.GrantAccountant(member.ID); err != nil {
+		return err
+	}
+
+	logger.Warnf("accountant role seeded for staff member %d (%s)", member.ID, normalized)
+	return nil
+}
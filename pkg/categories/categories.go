@@ -0,0 +1,73 @@
+package categories
+
+import (
+	"DBPrototyping/pkg/requests"
+	"time"
+)
+
+// Category classifies requests, e.g. plumbing or elevator. The two request types are the top level of the tree,
+// every category belongs to one of them and subcategories belong to the type of their parent.
+type Category struct {
+	ID       string               `gorm:"type:char(40);primaryKey"`
+	ParentID *string              `gorm:"column:id_parent;type:char(40);index"`
+	Group    requests.RequestType `gorm:"column:type;type:request_type;not null"`
+	Title    string               `gorm:"type:varchar(100);not null"`
+	// SLAHours is the time given to complete a request of the category, empty takes the one of the parent
+	SLAHours  *int      `gorm:"column:sla_hours;type:integer"`
+	IsActive  bool      `gorm:"column:is_active;not null;default:true"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// CategorySpecialization is a specialization the requests of the category are assigned to by default.
+type CategorySpecialization struct {
+	CategoryID       string `gorm:"column:id_category;type:char(40);primaryKey"`
+	SpecializationID string `gorm:"column:id_specialization;type:char(40);primaryKey"`
+}
+
+type NewCategory struct {
+	// ParentID is empty for a category right under the request type
+	ParentID          *string
+	Group             requests.RequestType
+	Title             string
+	SLAHours          *int
+	SpecializationIDs []string
+}
+
+// CategoryUpdate replaces the title, the SLA and the default specializations, the place in the tree stays.
+type CategoryUpdate struct {
+	Title             string
+	SLAHours          *int
+	SpecializationIDs []string
+}
+
+type Node struct {
+	Category
+	SpecializationIDs []string
+	Children          []*Node
+}
+
+// Group is a request type with the categories under it.
+type Group struct {
+	Type       requests.RequestType
+	Categories []*Node
+}
+
+// Defaults is what a new request of the category gets, with the gaps filled from the parent categories.
+type Defaults struct {
+	Category          Category
+	SLAHours          *int
+	SpecializationIDs []string
+}
+
+type CategoriesRepo interface {
+	CreateCategory(category NewCategory) (*Category, error)
+	UpdateCategory(id string, update CategoryUpdate) error
+	// SetActive hides the category with its subcategories from new requests, the requests it has keep it
+	SetActive(id string, active bool) error
+	GetByID(id string) (*Category, error)
+	GetTree(includeInactive bool) ([]*Group, error)
+	// GetDefaults fails with ErrCategoryInactive when the category or any of its parents is hidden
+	GetDefaults(id string) (*Defaults, error)
+	// GetSubtreeIDs lists the category with all its subcategories, e.g. to filter requests by a branch
+	GetSubtreeIDs(id string) ([]string, error)
+}
@@ -0,0 +1,385 @@
+package categories
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryInactive       = errors.New("category is not in use")
+	ErrCreatingCategory       = errors.New("error creating category")
+	ErrDuplicateTitle         = errors.New("category with this title already exists at this level")
+	ErrSpecializationNotFound = errors.New("specialization not found")
+	ErrNonPositiveSLA         = errors.New("SLA must be positive")
+)
+
+type CategoryPg Category
+
+func (CategoryPg) TableName() string {
+	return "request_categories"
+}
+
+type CategorySpecializationPg CategorySpecialization
+
+func (CategorySpecializationPg) TableName() string {
+	return "request_category_specializations"
+}
+
+type CategoriesPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewCategoriesPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *CategoriesPgRepo {
+	return &CategoriesPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// checkSpecializations drops repeated IDs and makes sure every specialization exists.
+func checkSpecializations(db *gorm.DB, ids []string) ([]string, error) {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	var count int64
+	if err := db.Model(&company.SpecializationPg{}).Where("id IN ?", unique).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if int(count) != len(unique) {
+		return nil, ErrSpecializationNotFound
+	}
+	return unique, nil
+}
+
+func checkTitle(db *gorm.DB, parentID *string, group requests.RequestType, title, excludeID string) error {
+	query := db.Model(&CategoryPg{}).Where("LOWER(title) = LOWER(?) AND id <> ?", title, excludeID)
+	if parentID != nil {
+		query = query.Where("id_parent = ?", *parentID)
+	} else {
+		query = query.Where("id_parent IS NULL AND type = ?", group)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateTitle
+	}
+	return nil
+}
+
+func setSpecializations(tx *gorm.DB, categoryID string, specializationIDs []string) error {
+	if err := tx.Where("id_category = ?", categoryID).Delete(&CategorySpecializationPg{}).Error; err != nil {
+		return err
+	}
+	if len(specializationIDs) == 0 {
+		return nil
+	}
+
+	assocs := make([]CategorySpecializationPg, len(specializationIDs))
+	for i, specID := range specializationIDs {
+		assocs[i] = CategorySpecializationPg{CategoryID: categoryID, SpecializationID: specID}
+	}
+	return tx.Create(&assocs).Error
+}
+
+func (repo *CategoriesPgRepo) CreateCategory(category NewCategory) (*Category, error) {
+	if category.SLAHours != nil && *category.SLAHours <= 0 {
+		return nil, ErrNonPositiveSLA
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	categoryPg := CategoryPg{
+		ParentID:  category.ParentID,
+		Group:     category.Group,
+		Title:     category.Title,
+		SLAHours:  category.SLAHours,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// a subcategory always belongs to the request type of its parent
+		if category.ParentID != nil {
+			var parent CategoryPg
+			if err := tx.Where("id = ?", *category.ParentID).First(&parent).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrCategoryNotFound
+				}
+				return err
+			}
+			categoryPg.Group = parent.Group
+		}
+
+		if err := checkTitle(tx, categoryPg.ParentID, categoryPg.Group, categoryPg.Title, ""); err != nil {
+			return err
+		}
+
+		specializationIDs, err := checkSpecializations(tx, category.SpecializationIDs)
+		if err != nil {
+			return err
+		}
+
+		createdFlag := false
+		for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+			categoryID, err := utils.GenerateID()
+			if err != nil {
+				repo.logger.Warnf("failed to generate category ID, %v", err)
+				continue
+			}
+			categoryPg.ID = categoryID
+
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&categoryPg)
+			if res.Error != nil {
+				return res.Error
+			}
+			createdFlag = res.RowsAffected == 1
+		}
+		if !createdFlag {
+			return ErrCreatingCategory
+		}
+
+		return setSpecializations(tx, categoryPg.ID, specializationIDs)
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to create category %s: %v", category.Title, err)
+		return nil, err
+	}
+
+	created := Category(categoryPg)
+	return &created, nil
+}
+
+func (repo *CategoriesPgRepo) UpdateCategory(id string, update CategoryUpdate) error {
+	if update.SLAHours != nil && *update.SLAHours <= 0 {
+		return ErrNonPositiveSLA
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var categoryPg CategoryPg
+		if err := tx.Where("id = ?", id).First(&categoryPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			return err
+		}
+
+		if err := checkTitle(tx, categoryPg.ParentID, categoryPg.Group, update.Title, id); err != nil {
+			return err
+		}
+
+		specializationIDs, err := checkSpecializations(tx, update.SpecializationIDs)
+		if err != nil {
+			return err
+		}
+
+		// a map writes the empty SLA too, the category then inherits it again
+		updates := map[string]interface{}{
+			"title":     update.Title,
+			"sla_hours": update.SLAHours,
+		}
+		if err := tx.Model(&CategoryPg{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return setSpecializations(tx, id, specializationIDs)
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to update category %s: %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *CategoriesPgRepo) SetActive(id string, active bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).Model(&CategoryPg{}).Where("id = ?", id).Update("is_active", active)
+	if res.Error != nil {
+		repo.logger.Warnf("failed to set category %s active %t: %v", id, active, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+func (repo *CategoriesPgRepo) GetByID(id string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var categoryPg CategoryPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&categoryPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		repo.logger.Warnf("failed to get category %s: %v", id, err)
+		return nil, err
+	}
+
+	category := Category(categoryPg)
+	return &category, nil
+}
+
+// GetTree returns both request types even without categories, so that a client can always offer the types.
+// A hidden category is left out together with its subcategories unless includeInactive is set.
+func (repo *CategoriesPgRepo) GetTree(includeInactive bool) ([]*Group, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var categoriesPg []CategoryPg
+	if err := repo.db.WithContext(ctx).Order("title ASC").Find(&categoriesPg).Error; err != nil {
+		repo.logger.Warnf("failed to get categories: %v", err)
+		return nil, err
+	}
+
+	var assocs []CategorySpecializationPg
+	if err := repo.db.WithContext(ctx).Order("id_specialization ASC").Find(&assocs).Error; err != nil {
+		repo.logger.Warnf("failed to get category specializations: %v", err)
+		return nil, err
+	}
+
+	nodes := make(map[string]*Node, len(categoriesPg))
+	for _, c := range categoriesPg {
+		nodes[c.ID] = &Node{Category: Category(c), SpecializationIDs: []string{}, Children: []*Node{}}
+	}
+	for _, a := range assocs {
+		if node, ok := nodes[a.CategoryID]; ok {
+			node.SpecializationIDs = append(node.SpecializationIDs, a.SpecializationID)
+		}
+	}
+
+	groups := []*Group{
+		{Type: requests.TypeApartmentInternal, Categories: []*Node{}},
+		{Type: requests.TypeHouseCommon, Categories: []*Node{}},
+	}
+
+	for _, c := range categoriesPg {
+		node := nodes[c.ID]
+		if !includeInactive && !node.IsActive {
+			continue
+		}
+
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+			}
+			continue
+		}
+
+		for _, g := range groups {
+			if g.Type == node.Group {
+				g.Categories = append(g.Categories, node)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+func (repo *CategoriesPgRepo) GetDefaults(id string) (*Defaults, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	table := CategoryPg{}.TableName()
+
+	// the category first, then its parents up to the top of the tree
+	var path []CategoryPg
+	err := repo.db.WithContext(ctx).Raw(`WITH RECURSIVE path AS (
+			SELECT c.*, 0 AS depth FROM `+table+` c WHERE c.id = ?
+			UNION ALL
+			SELECT p.*, path.depth + 1 FROM `+table+` p JOIN path ON p.id = path.id_parent
+		)
+		SELECT * FROM path ORDER BY depth`, id).Scan(&path).Error
+	if err != nil {
+		repo.logger.Warnf("failed to get path of category %s: %v", id, err)
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	ids := make([]string, len(path))
+	for i, c := range path {
+		if !c.IsActive {
+			return nil, ErrCategoryInactive
+		}
+		ids[i] = c.ID
+	}
+
+	var assocs []CategorySpecializationPg
+	if err := repo.db.WithContext(ctx).Where("id_category IN ?", ids).Order("id_specialization ASC").Find(&assocs).Error; err != nil {
+		repo.logger.Warnf("failed to get specializations of category %s: %v", id, err)
+		return nil, err
+	}
+
+	specializations := make(map[string][]string, len(path))
+	for _, a := range assocs {
+		specializations[a.CategoryID] = append(specializations[a.CategoryID], a.SpecializationID)
+	}
+
+	defaults := &Defaults{Category: Category(path[0]), SpecializationIDs: []string{}}
+	for _, c := range path {
+		if defaults.SLAHours == nil && c.SLAHours != nil {
+			defaults.SLAHours = c.SLAHours
+		}
+		if len(defaults.SpecializationIDs) == 0 && len(specializations[c.ID]) > 0 {
+			defaults.SpecializationIDs = specializations[c.ID]
+		}
+	}
+
+	return defaults, nil
+}
+
+func (repo *CategoriesPgRepo) GetSubtreeIDs(id string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	table := CategoryPg{}.TableName()
+
+	var ids []string
+	err := repo.db.WithContext(ctx).Raw(`WITH RECURSIVE subtree AS (
+			SELECT id FROM `+table+` WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM `+table+` c JOIN subtree ON c.id_parent = subtree.id
+		)
+		SELECT id FROM subtree`, id).Scan(&ids).Error
+	if err != nil {
+		repo.logger.Warnf("failed to get subcategories of category %s: %v", id, err)
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	return ids, nil
+}
@@ -1,5 +1,10 @@
 package company
 
+import (
+	"DBPrototyping/pkg/requests"
+	"time"
+)
+
 type StaffMember struct {
 	ID              int                         `gorm:"type:bigint;primaryKey"`
 	FullName        string                      `gorm:"type:varchar(40);not null"`
@@ -16,8 +21,129 @@ type Specialization struct {
 }
 
 type Organization struct {
-	ID   string `gorm:"type:char(40);primaryKey"`
-	Name string `gorm:"type:varchar(40);not null"`
+	ID            string `gorm:"type:char(40);primaryKey"`
+	Name          string `gorm:"type:varchar(40);not null"`
+	ContactPerson string `gorm:"column:contact_person;type:varchar(80);not null;default:''"`
+	Phone         string `gorm:"column:phone_number;type:varchar(40);not null;default:''"`
+	Email         string `gorm:"type:varchar(120);not null;default:''"`
+
+	// Categories and Contracts live in their own tables and are filled by the repo
+	Categories []requests.RequestType `gorm:"-"`
+	Contracts  []OrganizationContract `gorm:"-"`
+}
+
+// OrganizationRepresentative is a login of a contractor's employee, it sees only its organization's requests.
+type OrganizationRepresentative struct {
+	Phone          string    `gorm:"type:varchar(40);column:phone_number;primaryKey"`
+	FullName       string    `gorm:"type:varchar(40);not null"`
+	OrganizationID string    `gorm:"column:id_organization;type:char(40);not null;index"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+type OrganizationContacts struct {
+	ContactPerson string
+	Phone         string
+	Email         string
+}
+
+// OrganizationCategory marks a request type the organization is hired to serve.
+type OrganizationCategory struct {
+	OrganizationID string               `gorm:"column:id_organization;type:char(40);primaryKey"`
+	RequestType    requests.RequestType `gorm:"column:type;type:request_type;primaryKey"`
+}
+
+type OrganizationContract struct {
+	ID             string     `gorm:"type:char(40);primaryKey"`
+	OrganizationID string     `gorm:"column:id_organization;type:char(40);not null;index"`
+	Number         string     `gorm:"type:varchar(60);not null"`
+	StartsAt       time.Time  `gorm:"column:starts_at;type:date;not null"`
+	EndsAt         *time.Time `gorm:"column:ends_at;type:date"`
+	IsActive       bool       `gorm:"column:is_active;not null;default:true"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// CoversAt reports whether the contract is in force on the given day.
+func (contract OrganizationContract) CoversAt(moment time.Time) bool {
+	if !contract.IsActive || moment.Before(contract.StartsAt) {
+		return false
+	}
+	return contract.EndsAt == nil || !moment.After(contract.EndsAt.Add(24*time.Hour-time.Nanosecond))
+}
+
+// StaffStatusChange is an entry of a staff member's status history.
+type StaffStatusChange struct {
+	ID        string            `gorm:"type:char(40);primaryKey"`
+	MemberID  int               `gorm:"column:id_member;type:bigint;not null;index"`
+	From      StaffMemberStatus `gorm:"column:from_status;type:varchar(20);not null"`
+	To        StaffMemberStatus `gorm:"column:to_status;type:varchar(20);not null"`
+	ChangedBy string            `gorm:"column:changed_by;type:varchar(40);not null"`
+	Reason    *string           `gorm:"type:varchar(200)"`
+	CreatedAt time.Time         `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// StatusChangeOutcome tells what happened to the open requests of a member taken out of work:
+// Reassigned maps a request ID to its new responsible, Unassigned lists requests nobody could take.
+type StatusChangeOutcome struct {
+	Change                     *StaffStatusChange
+	Reassigned                 map[string]int
+	Unassigned                 []string
+	DeactivatedSpecializations int
+}
+
+// WorkShift is the weekly shift of a staff member on one weekday, minutes are counted from midnight.
+// A shift whose end is not after its start runs over midnight into the next day.
+type WorkShift struct {
+	MemberID    int          `gorm:"column:id_member;type:bigint;primaryKey"`
+	Weekday     time.Weekday `gorm:"column:weekday;type:smallint;primaryKey"`
+	StartMinute int          `gorm:"column:start_minute;type:smallint;not null"`
+	EndMinute   int          `gorm:"column:end_minute;type:smallint;not null"`
+}
+
+// Window gives the bounds of the shift when it starts on the given day.
+func (shift WorkShift) Window(day time.Time) (time.Time, time.Time) {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	startsAt := midnight.Add(time.Duration(shift.StartMinute) * time.Minute)
+	endsAt := midnight.Add(time.Duration(shift.EndMinute) * time.Minute)
+	if shift.EndMinute <= shift.StartMinute {
+		endsAt = endsAt.AddDate(0, 0, 1)
+	}
+
+	return startsAt, endsAt
+}
+
+// Absence is a period when a staff member is not available regardless of the schedule, EndsAt is exclusive.
+type Absence struct {
+	ID        string      `gorm:"type:char(40);primaryKey"`
+	MemberID  int         `gorm:"column:id_member;type:bigint;not null;index"`
+	Kind      AbsenceKind `gorm:"type:varchar(20);not null"`
+	StartsAt  time.Time   `gorm:"column:starts_at;type:timestamp;not null"`
+	EndsAt    time.Time   `gorm:"column:ends_at;type:timestamp;not null"`
+	Comment   *string     `gorm:"type:varchar(200)"`
+	CreatedAt time.Time   `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// Overlaps reports whether the absence intersects [from, to).
+func (absence Absence) Overlaps(from, to time.Time) bool {
+	return absence.StartsAt.Before(to) && absence.EndsAt.After(from)
+}
+
+// DutyShift is one staff member on duty in one specialization. Staff without any weekly shift are
+// Unscheduled and count as on duty the whole day, the same way the assignment treats them.
+type DutyShift struct {
+	MemberID         int
+	FullName         string
+	Phone            string
+	SpecializationID string
+	Specialization   string
+	StartsAt         time.Time
+	EndsAt           time.Time
+	Unscheduled      bool
+}
+
+type DutyDay struct {
+	Date   time.Time
+	Shifts []*DutyShift
 }
 
 type StaffMemberSpecialization struct {
@@ -35,13 +161,38 @@ type StaffRepo interface {
 	RegisterNewSpecialization(jobTitle string) (*Specialization, error)
 	GetStaffMemberByPhoneNumber(phoneNumber string) (*StaffMember, error)
 	DeleteByPhone(phoneNumber string) error
-	FindLeastBusyByJobID(jobID string) (*StaffMember, error)
+	// FindLeastBusyByJobID picks the responsible for a request of the priority among the staff available now
+	FindLeastBusyByJobID(jobID string, priority requests.RequestPriority) (*StaffMember, error)
+	// FindLeastBusy does the same among the staff having any of the specializations
+	FindLeastBusy(jobIDs []string, priority requests.RequestPriority) (*StaffMember, error)
 	FindCurrentSpecializations(staffMemberID int) ([]*Specialization, error)
 	DeactivateStaffMemberSpecialization(staffMemberID int, jobID string) error
 	GetSpecializations(pattern string, limit, offset int) ([]*Specialization, int, error)
 	CreateOrganization(name string) (*Organization, error)
 	GetOrganizationsByPattern(pattern string, limit, offset int) ([]*Organization, int, error)
 	UpdateOrganizationByID(organizationID, name string) error
+	GetOrganizationByID(organizationID string) (*Organization, error)
+	UpdateOrganizationContacts(organizationID string, contacts OrganizationContacts) error
+	SetOrganizationCategories(organizationID string, categories []requests.RequestType) error
+	AddOrganizationContract(organizationID, number string, startsAt time.Time, endsAt *time.Time) (*OrganizationContract, error)
+	DeactivateOrganizationContract(organizationID, contractID string) error
+	ValidateOrganizationCoverage(organizationID string, requestType requests.RequestType) error
+	RegisterRepresentative(phone, fullName, organizationID string) (*OrganizationRepresentative, error)
+	GetRepresentativeByPhone(phone string) (*OrganizationRepresentative, error)
+	GetRepresentatives(organizationID string) ([]*OrganizationRepresentative, error)
+	DeleteRepresentativeByPhone(phone string) error
+	GetWorkSchedule(staffMemberID int) ([]*WorkShift, error)
+	SetWorkShift(shift WorkShift) error
+	DeleteWorkShift(staffMemberID int, weekday time.Weekday) error
+	AddAbsence(staffMemberID int, kind AbsenceKind, startsAt, endsAt time.Time, comment *string) (*Absence, error)
+	GetAbsences(staffMemberID int, since time.Time) ([]*Absence, error)
+	DeleteAbsence(staffMemberID int, absenceID string) error
+	GetDutyCalendar(from time.Time, days int, specializationID string) ([]*DutyDay, error)
+	GetStaffMembers(pattern string, status *StaffMemberStatus, limit, offset int) ([]*StaffMember, int, error)
+	GetStaffMemberByID(staffMemberID int) (*StaffMember, error)
+	UpdateStaffMemberName(staffMemberID int, fullName string) error
+	ChangeStaffMemberStatus(staffMemberID int, status StaffMemberStatus, changedBy string, reason *string) (*StatusChangeOutcome, error)
+	GetStatusHistory(staffMemberID int) ([]*StaffStatusChange, error)
 }
 
 type StaffMemberStatus string
@@ -60,3 +211,77 @@ func (s StaffMemberStatus) IsValid() bool {
 		return false
 	}
 }
+
+// Code is the stable machine name of the status for clients and translations, the stored value stays Russian.
+func (s StaffMemberStatus) Code() string {
+	switch s {
+	case StatusActive:
+		return "active"
+	case StatusInactive:
+		return "dismissed"
+	case StatusSuspended:
+		return "unavailable"
+	default:
+		return ""
+	}
+}
+
+// StaffMemberStatuses lists every staff member status, e.g. to translate them all.
+var StaffMemberStatuses = []StaffMemberStatus{StatusActive, StatusSuspended, StatusInactive}
+
+// StaffMemberStatusByCode finds the status by its Code.
+func StaffMemberStatusByCode(code string) (StaffMemberStatus, bool) {
+	for _, s := range StaffMemberStatuses {
+		if s.Code() == code {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// CanTransitionTo reports whether the status may change to next: a working member may be suspended or dismissed,
+// a suspended one may come back or be dismissed, dismissal is final.
+func (s StaffMemberStatus) CanTransitionTo(next StaffMemberStatus) bool {
+	switch s {
+	case StatusActive:
+		return next == StatusSuspended || next == StatusInactive
+	case StatusSuspended:
+		return next == StatusActive || next == StatusInactive
+	default:
+		return false
+	}
+}
+
+type AbsenceKind string
+
+const (
+	AbsenceVacation AbsenceKind = "отпуск"
+	AbsenceSick     AbsenceKind = "больничный"
+	AbsenceDayOff   AbsenceKind = "отгул"
+)
+
+func (k AbsenceKind) IsValid() bool {
+	switch k {
+	case AbsenceVacation, AbsenceSick, AbsenceDayOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the absence kind for clients and translations, the stored value stays Russian.
+func (k AbsenceKind) Code() string {
+	switch k {
+	case AbsenceVacation:
+		return "vacation"
+	case AbsenceSick:
+		return "sick_leave"
+	case AbsenceDayOff:
+		return "day_off"
+	default:
+		return ""
+	}
+}
+
+// AbsenceKinds lists every absence kind, e.g. to translate them all.
+var AbsenceKinds = []AbsenceKind{AbsenceVacation, AbsenceSick, AbsenceDayOff}
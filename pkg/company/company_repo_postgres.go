@@ -19,6 +19,13 @@ var (
 	ErrCreatingOrganization   = errors.New("error creating organization")
 	ErrStaffMemberNotFound    = errors.New("staff member not found")
 	ErrCreatingMember         = errors.New("error creating a new member")
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrContractNotFound       = errors.New("contract not found")
+	ErrCreatingContract       = errors.New("error creating contract")
+	ErrTypeNotCovered         = errors.New("organization does not serve this request type")
+	ErrNoActiveContract       = errors.New("organization has no contract in force")
+	ErrRepresentativeNotFound = errors.New("organization representative not found")
+	ErrCreatingRepresentative = errors.New("error creating organization representative")
 )
 
 type StaffMemberPg StaffMember
@@ -45,6 +52,24 @@ func (OrganizationPg) TableName() string {
 	return "organizations"
 }
 
+type OrganizationCategoryPg OrganizationCategory
+
+func (OrganizationCategoryPg) TableName() string {
+	return "organization_categories"
+}
+
+type OrganizationContractPg OrganizationContract
+
+func (OrganizationContractPg) TableName() string {
+	return "organization_contracts"
+}
+
+type OrganizationRepresentativePg OrganizationRepresentative
+
+func (OrganizationRepresentativePg) TableName() string {
+	return "organization_representatives"
+}
+
 type StaffRepoPostgres struct {
 	logger *zap.SugaredLogger
 	db     *gorm.DB
@@ -192,6 +217,16 @@ func (repo *StaffRepoPostgres) DeleteByPhone(phoneNumber string) error {
 			return del.Error
 		}
 
+		if del := tx.Where("id_member = ?", member.ID).Delete(&WorkShiftPg{}); del.Error != nil {
+			repo.logger.Errorf("failed to delete work schedule for member %d: %v", member.ID, del.Error)
+			return del.Error
+		}
+
+		if del := tx.Where("id_member = ?", member.ID).Delete(&AbsencePg{}); del.Error != nil {
+			repo.logger.Errorf("failed to delete absences for member %d: %v", member.ID, del.Error)
+			return del.Error
+		}
+
 		deleteRes := tx.Where("id = ?", member.ID).Delete(&StaffMember{})
 		if deleteRes.Error != nil {
 			repo.logger.Errorf("failed to delete staff member: %v", deleteRes.Error)
@@ -212,25 +247,51 @@ func (repo *StaffRepoPostgres) DeleteByPhone(phoneNumber string) error {
 	return nil
 }
 
-func (repo *StaffRepoPostgres) FindLeastBusyByJobID(jobID string) (*StaffMember, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	var staffPg StaffMemberPg
-
+// leastBusyQuery selects among working staff with any of the active specializations who are on shift at the moment
+// and not absent, the one with the fewest assigned requests. An emergency skips the queue: it goes to the one with
+// the fewest emergencies in hand, whatever the number of ordinary requests waiting behind them. excludeMemberID of
+// 0 excludes nobody.
+func leastBusyQuery(db *gorm.DB, jobIDs []string, excludeMemberID int, moment time.Time, priority requests.RequestPriority) *gorm.DB {
 	staffTable := StaffMemberPg{}.TableName()
 	specAssocTable := StaffMemberSpecializationPg{}.TableName()
 	reqTable := requests.RequestPg{}.TableName()
 
-	query := repo.db.WithContext(ctx).
+	availableSQL, availableArgs := availableAtCondition("staff.id", moment)
+
+	order := "active_count ASC"
+	if priority == requests.PriorityEmergency {
+		order = "emergency_count ASC, active_count ASC"
+	}
+
+	return db.
 		Table(staffTable+" AS staff").
-		Select("staff.*, COALESCE(COUNT(req.id), 0) AS active_count").
+		Select("staff.*, COUNT(DISTINCT req.id) AS active_count, COUNT(DISTINCT req.id) FILTER (WHERE req.priority = ?) AS emergency_count",
+			string(requests.PriorityEmergency)).
 		Joins("JOIN "+specAssocTable+" AS staffspec ON staffspec.id_member = staff.id").
 		Joins("LEFT JOIN "+reqTable+" AS req ON req.id_responsible = staff.id AND req.status = ?", string(requests.StatusAssigned)).
-		Where("staffspec.id_specialization = ?", jobID).
+		Where("staffspec.id_specialization IN ? AND staffspec.is_active = ?", jobIDs, true).
+		Where("staff.status = ? AND staff.id <> ?", StatusActive, excludeMemberID).
+		Where(availableSQL, availableArgs...).
 		Group("staff.id").
-		Order("active_count ASC").
+		Order(order).
 		Limit(1)
+}
+
+func (repo *StaffRepoPostgres) FindLeastBusyByJobID(jobID string, priority requests.RequestPriority) (*StaffMember, error) {
+	return repo.FindLeastBusy([]string{jobID}, priority)
+}
+
+func (repo *StaffRepoPostgres) FindLeastBusy(jobIDs []string, priority requests.RequestPriority) (*StaffMember, error) {
+	if len(jobIDs) == 0 {
+		return nil, ErrStaffMemberNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var staffPg StaffMemberPg
+
+	query := leastBusyQuery(repo.db.WithContext(ctx), jobIDs, 0, time.Now(), priority)
 
 	if err := query.Scan(&staffPg).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -416,6 +477,10 @@ func (repo *StaffRepoPostgres) GetOrganizationsByPattern(pattern string, limit,
 		orgs[i] = (*Organization)(&orgsPg[i])
 	}
 
+	if err := repo.fillOrganizationDetails(ctx, orgs); err != nil {
+		return nil, int(total), err
+	}
+
 	return orgs, int(total), nil
 }
 
@@ -440,3 +505,316 @@ func (repo *StaffRepoPostgres) UpdateOrganizationByID(organizationID, name strin
 
 	return nil
 }
+
+// fillOrganizationDetails loads categories and contracts of all given organizations with one query per table.
+func (repo *StaffRepoPostgres) fillOrganizationDetails(ctx context.Context, orgs []*Organization) error {
+	if len(orgs) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*Organization, len(orgs))
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		org.Categories = []requests.RequestType{}
+		org.Contracts = []OrganizationContract{}
+		byID[org.ID] = org
+		ids[i] = org.ID
+	}
+
+	var categoriesPg []OrganizationCategoryPg
+	if err := repo.db.WithContext(ctx).Where("id_organization IN ?", ids).Order("type").Find(&categoriesPg).Error; err != nil {
+		repo.logger.Warnf("failed to query organization categories: %v", err)
+		return err
+	}
+	for _, category := range categoriesPg {
+		if org, ok := byID[category.OrganizationID]; ok {
+			org.Categories = append(org.Categories, category.RequestType)
+		}
+	}
+
+	var contractsPg []OrganizationContractPg
+	if err := repo.db.WithContext(ctx).Where("id_organization IN ?", ids).Order("starts_at DESC").Find(&contractsPg).Error; err != nil {
+		repo.logger.Warnf("failed to query organization contracts: %v", err)
+		return err
+	}
+	for _, contract := range contractsPg {
+		if org, ok := byID[contract.OrganizationID]; ok {
+			org.Contracts = append(org.Contracts, OrganizationContract(contract))
+		}
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) GetOrganizationByID(organizationID string) (*Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var orgPg OrganizationPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", organizationID).First(&orgPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+
+		repo.logger.Warnf("failed to get organization %s: %v", organizationID, err)
+		return nil, err
+	}
+
+	org := Organization(orgPg)
+	if err := repo.fillOrganizationDetails(ctx, []*Organization{&org}); err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+func (repo *StaffRepoPostgres) UpdateOrganizationContacts(organizationID string, contacts OrganizationContacts) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// a map so that cleared contacts are written as empty strings too
+	updateRes := repo.db.WithContext(ctx).
+		Model(&OrganizationPg{}).
+		Where("id = ?", organizationID).
+		Updates(map[string]interface{}{
+			"contact_person": contacts.ContactPerson,
+			"phone_number":   contacts.Phone,
+			"email":          contacts.Email,
+		})
+
+	if updateRes.Error != nil {
+		repo.logger.Errorf("failed to update contacts of organization %s: %v", organizationID, updateRes.Error)
+		return updateRes.Error
+	}
+	if updateRes.RowsAffected != 1 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) SetOrganizationCategories(organizationID string, categories []requests.RequestType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&OrganizationPg{}).Where("id = ?", organizationID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrOrganizationNotFound
+		}
+
+		if err := tx.Where("id_organization = ?", organizationID).Delete(&OrganizationCategoryPg{}).Error; err != nil {
+			repo.logger.Errorf("failed to clear categories of organization %s: %v", organizationID, err)
+			return err
+		}
+
+		if len(categories) == 0 {
+			return nil
+		}
+
+		rows := make([]OrganizationCategoryPg, len(categories))
+		for i, category := range categories {
+			rows[i] = OrganizationCategoryPg{OrganizationID: organizationID, RequestType: category}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			repo.logger.Errorf("failed to set categories of organization %s: %v", organizationID, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (repo *StaffRepoPostgres) AddOrganizationContract(organizationID, number string, startsAt time.Time, endsAt *time.Time) (*OrganizationContract, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&OrganizationPg{}).Where("id = ?", organizationID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check organization %s: %v", organizationID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrOrganizationNotFound
+	}
+
+	contractPg := OrganizationContractPg{
+		OrganizationID: organizationID,
+		Number:         number,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		IsActive:       true,
+		CreatedAt:      time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < retries && !createdFlag; i++ {
+		contractID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate contract ID, %v", err)
+			continue
+		}
+
+		contractPg.ID = contractID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&contractPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingContract
+	}
+
+	contract := OrganizationContract(contractPg)
+
+	return &contract, nil
+}
+
+func (repo *StaffRepoPostgres) DeactivateOrganizationContract(organizationID, contractID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateRes := repo.db.WithContext(ctx).
+		Model(&OrganizationContractPg{}).
+		Where("id = ? AND id_organization = ? AND is_active = true", contractID, organizationID).
+		Update("is_active", false)
+
+	if updateRes.Error != nil {
+		repo.logger.Errorf("failed to deactivate contract %s: %v", contractID, updateRes.Error)
+		return updateRes.Error
+	}
+	if updateRes.RowsAffected != 1 {
+		return ErrContractNotFound
+	}
+
+	return nil
+}
+
+// ValidateOrganizationCoverage checks that the organization may take a request of the given type today:
+// the type is among its categories and at least one of its contracts is in force.
+func (repo *StaffRepoPostgres) ValidateOrganizationCoverage(organizationID string, requestType requests.RequestType) error {
+	org, err := repo.GetOrganizationByID(organizationID)
+	if err != nil {
+		return err
+	}
+
+	covered := false
+	for _, category := range org.Categories {
+		if category == requestType {
+			covered = true
+			break
+		}
+	}
+	if !covered {
+		return ErrTypeNotCovered
+	}
+
+	now := time.Now()
+	for _, contract := range org.Contracts {
+		if contract.CoversAt(now) {
+			return nil
+		}
+	}
+
+	return ErrNoActiveContract
+}
+
+func (repo *StaffRepoPostgres) RegisterRepresentative(phone, fullName, organizationID string) (*OrganizationRepresentative, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&OrganizationPg{}).Where("id = ?", organizationID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check organization %s: %v", organizationID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrOrganizationNotFound
+	}
+
+	repPg := OrganizationRepresentativePg{
+		Phone:          phone,
+		FullName:       fullName,
+		OrganizationID: organizationID,
+		CreatedAt:      time.Now(),
+	}
+
+	upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&repPg)
+	if upsertRes.Error != nil {
+		return nil, upsertRes.Error
+	}
+	if upsertRes.RowsAffected != 1 {
+		repo.logger.Warnf("failed to insert representative %s, already exists", phone)
+		return nil, ErrCreatingRepresentative
+	}
+
+	rep := OrganizationRepresentative(repPg)
+
+	return &rep, nil
+}
+
+func (repo *StaffRepoPostgres) GetRepresentativeByPhone(phone string) (*OrganizationRepresentative, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var repPg OrganizationRepresentativePg
+	if err := repo.db.WithContext(ctx).Where("phone_number = ?", phone).First(&repPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRepresentativeNotFound
+		}
+
+		repo.logger.Warnf("failed to get representative %s: %v", phone, err)
+		return nil, err
+	}
+
+	rep := OrganizationRepresentative(repPg)
+
+	return &rep, nil
+}
+
+func (repo *StaffRepoPostgres) GetRepresentatives(organizationID string) ([]*OrganizationRepresentative, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var repsPg []OrganizationRepresentativePg
+	if err := repo.db.WithContext(ctx).Where("id_organization = ?", organizationID).Order("full_name").Find(&repsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get representatives of organization %s: %v", organizationID, err)
+		return nil, err
+	}
+
+	reps := make([]*OrganizationRepresentative, len(repsPg))
+	for i := range repsPg {
+		reps[i] = (*OrganizationRepresentative)(&repsPg[i])
+	}
+
+	return reps, nil
+}
+
+func (repo *StaffRepoPostgres) DeleteRepresentativeByPhone(phone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).Where("phone_number = ?", phone).Delete(&OrganizationRepresentativePg{})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to delete representative %s: %v", phone, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRepresentativeNotFound
+	}
+
+	return nil
+}
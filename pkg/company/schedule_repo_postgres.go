@@ -0,0 +1,340 @@
+package company
+
+import (
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+const maxCalendarDays = 31
+
+var (
+	ErrShiftNotFound   = errors.New("work shift not found")
+	ErrAbsenceNotFound = errors.New("absence not found")
+	ErrCreatingAbsence = errors.New("error creating absence")
+)
+
+type WorkShiftPg WorkShift
+
+func (WorkShiftPg) TableName() string {
+	return "staff_work_shifts"
+}
+
+type AbsencePg Absence
+
+func (AbsencePg) TableName() string {
+	return "staff_absences"
+}
+
+// availableAtCondition builds the WHERE part telling that the member behind memberColumn is not absent at the
+// moment and is on shift: by today's shift, by yesterday's shift running over midnight or by having no
+// schedule at all.
+func availableAtCondition(memberColumn string, moment time.Time) (string, []interface{}) {
+	shiftTable := WorkShiftPg{}.TableName()
+	absenceTable := AbsencePg{}.TableName()
+
+	minute := moment.Hour()*60 + moment.Minute()
+	today := int(moment.Weekday())
+	yesterday := (today + 6) % 7
+
+	sql := "NOT EXISTS (SELECT 1 FROM " + absenceTable + " AS absence WHERE absence.id_member = " + memberColumn +
+		" AND absence.starts_at <= ? AND absence.ends_at > ?)" +
+		" AND (NOT EXISTS (SELECT 1 FROM " + shiftTable + " AS shift WHERE shift.id_member = " + memberColumn + ")" +
+		" OR EXISTS (SELECT 1 FROM " + shiftTable + " AS shift WHERE shift.id_member = " + memberColumn + " AND (" +
+		"(shift.weekday = ? AND shift.start_minute <= ? AND (shift.end_minute > ? OR shift.end_minute <= shift.start_minute))" +
+		" OR (shift.weekday = ? AND shift.end_minute <= shift.start_minute AND shift.end_minute > ?))))"
+
+	return sql, []interface{}{moment, moment, today, minute, minute, yesterday, minute}
+}
+
+func (repo *StaffRepoPostgres) memberExists(ctx context.Context, staffMemberID int) error {
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&StaffMemberPg{}).Where("id = ?", staffMemberID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check staff member %d: %v", staffMemberID, err)
+		return err
+	}
+	if count == 0 {
+		return ErrStaffMemberNotFound
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) GetWorkSchedule(staffMemberID int) ([]*WorkShift, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.memberExists(ctx, staffMemberID); err != nil {
+		return nil, err
+	}
+
+	var shiftsPg []WorkShiftPg
+	if err := repo.db.WithContext(ctx).Where("id_member = ?", staffMemberID).Order("weekday").Find(&shiftsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get work schedule of member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	shifts := make([]*WorkShift, len(shiftsPg))
+	for i := range shiftsPg {
+		shifts[i] = (*WorkShift)(&shiftsPg[i])
+	}
+
+	return shifts, nil
+}
+
+// SetWorkShift creates or replaces the member's shift on the shift's weekday.
+func (repo *StaffRepoPostgres) SetWorkShift(shift WorkShift) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.memberExists(ctx, shift.MemberID); err != nil {
+		return err
+	}
+
+	shiftPg := WorkShiftPg(shift)
+
+	upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id_member"}, {Name: "weekday"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start_minute", "end_minute"}),
+	}).Create(&shiftPg)
+	if upsertRes.Error != nil {
+		repo.logger.Warnf("failed to set shift of member %d on %s: %v", shift.MemberID, shift.Weekday, upsertRes.Error)
+		return upsertRes.Error
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) DeleteWorkShift(staffMemberID int, weekday time.Weekday) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deleteRes := repo.db.WithContext(ctx).Where("id_member = ? AND weekday = ?", staffMemberID, weekday).Delete(&WorkShiftPg{})
+	if deleteRes.Error != nil {
+		repo.logger.Warnf("failed to delete shift of member %d on %s: %v", staffMemberID, weekday, deleteRes.Error)
+		return deleteRes.Error
+	}
+	if deleteRes.RowsAffected == 0 {
+		return ErrShiftNotFound
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) AddAbsence(staffMemberID int, kind AbsenceKind, startsAt, endsAt time.Time, comment *string) (*Absence, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.memberExists(ctx, staffMemberID); err != nil {
+		return nil, err
+	}
+
+	absencePg := AbsencePg{
+		MemberID:  staffMemberID,
+		Kind:      kind,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Comment:   comment,
+		CreatedAt: time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < retries && !createdFlag; i++ {
+		absenceID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate absence ID, %v", err)
+			continue
+		}
+
+		absencePg.ID = absenceID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&absencePg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingAbsence
+	}
+
+	absence := Absence(absencePg)
+
+	return &absence, nil
+}
+
+// GetAbsences lists the member's absences that are not over by since.
+func (repo *StaffRepoPostgres) GetAbsences(staffMemberID int, since time.Time) ([]*Absence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.memberExists(ctx, staffMemberID); err != nil {
+		return nil, err
+	}
+
+	var absencesPg []AbsencePg
+	if err := repo.db.WithContext(ctx).
+		Where("id_member = ? AND ends_at > ?", staffMemberID, since).
+		Order("starts_at").
+		Find(&absencesPg).Error; err != nil {
+		repo.logger.Warnf("failed to get absences of member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	absences := make([]*Absence, len(absencesPg))
+	for i := range absencesPg {
+		absences[i] = (*Absence)(&absencesPg[i])
+	}
+
+	return absences, nil
+}
+
+func (repo *StaffRepoPostgres) DeleteAbsence(staffMemberID int, absenceID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deleteRes := repo.db.WithContext(ctx).Where("id = ? AND id_member = ?", absenceID, staffMemberID).Delete(&AbsencePg{})
+	if deleteRes.Error != nil {
+		repo.logger.Warnf("failed to delete absence %s of member %d: %v", absenceID, staffMemberID, deleteRes.Error)
+		return deleteRes.Error
+	}
+	if deleteRes.RowsAffected == 0 {
+		return ErrAbsenceNotFound
+	}
+
+	return nil
+}
+
+type dutyCandidate struct {
+	MemberID         int    `gorm:"column:id_member"`
+	FullName         string `gorm:"column:full_name"`
+	Phone            string `gorm:"column:phone_number"`
+	SpecializationID string `gorm:"column:id_specialization"`
+	Specialization   string `gorm:"column:specialization"`
+}
+
+// GetDutyCalendar lays out who of the working staff is on duty on each of the days starting at from, optionally
+// for one specialization. A shift touched by an absence is left out as a whole.
+func (repo *StaffRepoPostgres) GetDutyCalendar(from time.Time, days int, specializationID string) ([]*DutyDay, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if days <= 0 || days > maxCalendarDays {
+		days = 7
+	}
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	to := from.AddDate(0, 0, days)
+
+	staffTable := StaffMemberPg{}.TableName()
+	specAssocTable := StaffMemberSpecializationPg{}.TableName()
+	specTable := SpecializationPg{}.TableName()
+
+	query := repo.db.WithContext(ctx).
+		Table(staffTable+" AS staff").
+		Select("staff.id AS id_member, staff.full_name, staff.phone_number, spec.id AS id_specialization, spec.name AS specialization").
+		Joins("JOIN "+specAssocTable+" AS staffspec ON staffspec.id_member = staff.id AND staffspec.is_active = ?", true).
+		Joins("JOIN "+specTable+" AS spec ON spec.id = staffspec.id_specialization").
+		Where("staff.status = ?", StatusActive).
+		Order("spec.name, staff.full_name")
+
+	if specializationID != "" {
+		query = query.Where("spec.id = ?", specializationID)
+	}
+
+	var candidates []dutyCandidate
+	if err := query.Scan(&candidates).Error; err != nil {
+		repo.logger.Warnf("failed to get staff for the duty calendar: %v", err)
+		return nil, err
+	}
+
+	memberIDs := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		memberIDs = append(memberIDs, candidate.MemberID)
+	}
+
+	shiftsByMember := make(map[int]map[time.Weekday]WorkShift)
+	absencesByMember := make(map[int][]Absence)
+
+	if len(memberIDs) > 0 {
+		var shiftsPg []WorkShiftPg
+		if err := repo.db.WithContext(ctx).Where("id_member IN ?", memberIDs).Find(&shiftsPg).Error; err != nil {
+			repo.logger.Warnf("failed to get shifts for the duty calendar: %v", err)
+			return nil, err
+		}
+		for _, shiftPg := range shiftsPg {
+			if shiftsByMember[shiftPg.MemberID] == nil {
+				shiftsByMember[shiftPg.MemberID] = make(map[time.Weekday]WorkShift)
+			}
+			shiftsByMember[shiftPg.MemberID][shiftPg.Weekday] = WorkShift(shiftPg)
+		}
+
+		// a night shift of the last day ends the day after
+		var absencesPg []AbsencePg
+		if err := repo.db.WithContext(ctx).
+			Where("id_member IN ? AND starts_at < ? AND ends_at > ?", memberIDs, to.AddDate(0, 0, 1), from).
+			Find(&absencesPg).Error; err != nil {
+			repo.logger.Warnf("failed to get absences for the duty calendar: %v", err)
+			return nil, err
+		}
+		for _, absencePg := range absencesPg {
+			absencesByMember[absencePg.MemberID] = append(absencesByMember[absencePg.MemberID], Absence(absencePg))
+		}
+	}
+
+	calendar := make([]*DutyDay, 0, days)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		dutyDay := &DutyDay{Date: day, Shifts: []*DutyShift{}}
+
+		for _, candidate := range candidates {
+			shifts := shiftsByMember[candidate.MemberID]
+
+			var startsAt, endsAt time.Time
+			if len(shifts) == 0 {
+				startsAt, endsAt = day, day.AddDate(0, 0, 1)
+			} else if shift, ok := shifts[day.Weekday()]; ok {
+				startsAt, endsAt = shift.Window(day)
+			} else {
+				continue
+			}
+
+			absent := false
+			for _, absence := range absencesByMember[candidate.MemberID] {
+				if absence.Overlaps(startsAt, endsAt) {
+					absent = true
+					break
+				}
+			}
+			if absent {
+				continue
+			}
+
+			dutyDay.Shifts = append(dutyDay.Shifts, &DutyShift{
+				MemberID:         candidate.MemberID,
+				FullName:         candidate.FullName,
+				Phone:            candidate.Phone,
+				SpecializationID: candidate.SpecializationID,
+				Specialization:   candidate.Specialization,
+				StartsAt:         startsAt,
+				EndsAt:           endsAt,
+				Unscheduled:      len(shifts) == 0,
+			})
+		}
+
+		calendar = append(calendar, dutyDay)
+	}
+
+	return calendar, nil
+}
@@ -0,0 +1,284 @@
+package company
+
+import (
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("staff member status can not change this way")
+	ErrCreatingStatusChange    = errors.New("error recording status change")
+)
+
+type StaffStatusChangePg StaffStatusChange
+
+func (StaffStatusChangePg) TableName() string {
+	return "staff_status_history"
+}
+
+func (repo *StaffRepoPostgres) GetStaffMembers(pattern string, status *StaffMemberStatus, limit, offset int) ([]*StaffMember, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var membersPg []StaffMemberPg
+	query := repo.db.WithContext(ctx).Model(&StaffMemberPg{})
+
+	if pattern != "" {
+		likePattern := "%" + pattern + "%"
+		query = query.Where("full_name ILIKE ? OR phone_number LIKE ? OR CAST(id AS text) LIKE ?", likePattern, likePattern, likePattern)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count staff members: %v", err)
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []*StaffMember{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Order("full_name, id").Find(&membersPg).Error; err != nil {
+		repo.logger.Warnf("failed to query staff members: %v", err)
+		return nil, int(total), err
+	}
+
+	result := make([]*StaffMember, len(membersPg))
+	for i := range membersPg {
+		result[i] = (*StaffMember)(&membersPg[i])
+	}
+
+	return result, int(total), nil
+}
+
+func (repo *StaffRepoPostgres) GetStaffMemberByID(staffMemberID int) (*StaffMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var memberPg StaffMemberPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", staffMemberID).First(&memberPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStaffMemberNotFound
+		}
+
+		repo.logger.Errorf("failed to find staff member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	member := StaffMember(memberPg)
+	return &member, nil
+}
+
+func (repo *StaffRepoPostgres) UpdateStaffMemberName(staffMemberID int, fullName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateRes := repo.db.WithContext(ctx).
+		Model(&StaffMemberPg{}).
+		Where("id = ?", staffMemberID).
+		Update("full_name", fullName)
+
+	if updateRes.Error != nil {
+		repo.logger.Errorf("failed to update staff member %d: %v", staffMemberID, updateRes.Error)
+		return updateRes.Error
+	}
+
+	if updateRes.RowsAffected != 1 {
+		return ErrStaffMemberNotFound
+	}
+
+	return nil
+}
+
+// ChangeStaffMemberStatus moves the member to the new status and records it in the history. A member leaving work
+// hands the open requests over to the least busy colleague with one of the same specializations, requests nobody
+// can take lose the responsible. Dismissal also deactivates the specializations, suspension keeps them for the
+// return. Everything happens in one transaction.
+func (repo *StaffRepoPostgres) ChangeStaffMemberStatus(staffMemberID int, status StaffMemberStatus, changedBy string, reason *string) (*StatusChangeOutcome, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	outcome := &StatusChangeOutcome{
+		Reassigned: make(map[string]int),
+		Unassigned: []string{},
+	}
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var memberPg StaffMemberPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", staffMemberID).First(&memberPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrStaffMemberNotFound
+			}
+			return err
+		}
+
+		if !memberPg.Status.CanTransitionTo(status) {
+			return ErrInvalidStatusTransition
+		}
+
+		if err := tx.Model(&StaffMemberPg{}).Where("id = ?", staffMemberID).Update("status", status).Error; err != nil {
+			repo.logger.Errorf("failed to update status of staff member %d: %v", staffMemberID, err)
+			return err
+		}
+
+		changePg := StaffStatusChangePg{
+			MemberID:  staffMemberID,
+			From:      memberPg.Status,
+			To:        status,
+			ChangedBy: changedBy,
+			Reason:    reason,
+			CreatedAt: time.Now(),
+		}
+
+		createdFlag := false
+		for i := 0; i < retries && !createdFlag; i++ {
+			changeID, err := utils.GenerateID()
+			if err != nil {
+				repo.logger.Warnf("failed to generate status change ID, %v", err)
+				continue
+			}
+
+			changePg.ID = changeID
+
+			upsertRes := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&changePg)
+			if upsertRes.Error != nil {
+				return upsertRes.Error
+			}
+			createdFlag = upsertRes.RowsAffected == 1
+		}
+
+		if !createdFlag {
+			return ErrCreatingStatusChange
+		}
+
+		change := StaffStatusChange(changePg)
+		outcome.Change = &change
+
+		if status == StatusActive {
+			return nil
+		}
+
+		if err := repo.handOverOpenRequests(tx, staffMemberID, outcome); err != nil {
+			return err
+		}
+
+		if status == StatusInactive {
+			deactivateRes := tx.Model(&StaffMemberSpecializationPg{}).
+				Where("id_member = ? AND is_active = ?", staffMemberID, true).
+				Update("is_active", false)
+			if deactivateRes.Error != nil {
+				repo.logger.Errorf("failed to deactivate specializations of staff member %d: %v", staffMemberID, deactivateRes.Error)
+				return deactivateRes.Error
+			}
+			outcome.DeactivatedSpecializations = int(deactivateRes.RowsAffected)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return outcome, nil
+}
+
+func (repo *StaffRepoPostgres) handOverOpenRequests(tx *gorm.DB, staffMemberID int, outcome *StatusChangeOutcome) error {
+	var jobIDs []string
+	if err := tx.Model(&StaffMemberSpecializationPg{}).
+		Where("id_member = ? AND is_active = ?", staffMemberID, true).
+		Pluck("id_specialization", &jobIDs).Error; err != nil {
+		repo.logger.Errorf("failed to get specializations of staff member %d: %v", staffMemberID, err)
+		return err
+	}
+
+	// emergencies are handed over first, while the colleagues on shift are still free
+	var openRequests []requests.RequestPg
+	if err := tx.Where("id_responsible = ? AND status IN ?", staffMemberID,
+		[]requests.RequestStatus{requests.StatusAssigned, requests.StatusSuspended}).
+		Order(requests.PriorityOrder("priority") + ", created_at").
+		Find(&openRequests).Error; err != nil {
+		repo.logger.Errorf("failed to get open requests of staff member %d: %v", staffMemberID, err)
+		return err
+	}
+
+	now := time.Now()
+	for _, request := range openRequests {
+		var candidate StaffMemberPg
+		if len(jobIDs) > 0 {
+			if err := leastBusyQuery(tx, jobIDs, staffMemberID, now, request.Priority).Scan(&candidate).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"id_responsible": nil}
+		if candidate.ID != 0 {
+			updates["id_responsible"] = candidate.ID
+		} else if request.Status == requests.StatusAssigned {
+			updates["status"] = requests.StatusCreated
+		}
+
+		if err := tx.Model(&requests.RequestPg{}).Where("id = ?", request.ID).Updates(updates).Error; err != nil {
+			repo.logger.Errorf("failed to hand over request %s: %v", request.ID, err)
+			return err
+		}
+		if err := requests.SyncMergedStatus(tx, request.ID); err != nil {
+			repo.logger.Errorf("failed to pass the status of request %s to its duplicates: %v", request.ID, err)
+			return err
+		}
+
+		if candidate.ID != 0 {
+			outcome.Reassigned[request.ID] = candidate.ID
+		} else {
+			outcome.Unassigned = append(outcome.Unassigned, request.ID)
+		}
+	}
+
+	return nil
+}
+
+func (repo *StaffRepoPostgres) GetStatusHistory(staffMemberID int) ([]*StaffStatusChange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repo.memberExists(ctx, staffMemberID); err != nil {
+		return nil, err
+	}
+
+	var changesPg []StaffStatusChangePg
+	if err := repo.db.WithContext(ctx).Where("id_member = ?", staffMemberID).Order("created_at DESC").Find(&changesPg).Error; err != nil {
+		repo.logger.Warnf("failed to get status history of staff member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	changes := make([]*StaffStatusChange, len(changesPg))
+	for i := range changesPg {
+		changes[i] = (*StaffStatusChange)(&changesPg[i])
+	}
+
+	return changes, nil
+}
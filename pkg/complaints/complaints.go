@@ -0,0 +1,185 @@
+package complaints
+
+import "time"
+
+// Complaint is a resident's report about living together in the house, e.g. noise at night. Unlike a request it
+// is not a work order, staff review it and may warn the one it is about.
+type Complaint struct {
+	ID         string        `gorm:"type:char(40);primaryKey"`
+	ReporterID string        `gorm:"column:id_reporter;type:char(40);not null;index"`
+	HouseID    int           `gorm:"column:id_house;type:bigint;not null;index"`
+	Kind       ComplaintKind `gorm:"type:varchar(20);not null"`
+	Text       string        `gorm:"type:text;not null"`
+	// Apartment and AccusedResidentID point at the other party, staff may set the resident on review
+	Apartment         *string         `gorm:"type:varchar(10)"`
+	AccusedResidentID *string         `gorm:"column:id_accused;type:char(40);index"`
+	Status            ComplaintStatus `gorm:"type:varchar(30);not null;default:'подана';index"`
+	CreatedAt         time.Time       `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+
+	HandledBy   *string    `gorm:"column:handled_by;type:varchar(40)"`
+	ReviewedAt  *time.Time `gorm:"column:reviewed_at;type:timestamp"`
+	WarningAt   *time.Time `gorm:"column:warning_at;type:timestamp"`
+	WarningText *string    `gorm:"column:warning_text;type:varchar(1000)"`
+	ResolvedAt  *time.Time `gorm:"column:resolved_at;type:timestamp"`
+	Resolution  *string    `gorm:"type:varchar(1000)"`
+}
+
+// ComplaintView is the staff view with the names of both parties.
+type ComplaintView struct {
+	Complaint
+	Address      string  `gorm:"column:address"`
+	ReporterName string  `gorm:"column:reporter_name"`
+	AccusedName  *string `gorm:"column:accused_name"`
+}
+
+// Warning is what the resident a complaint is about sees: the staff's words, never the reporter or the complaint.
+type Warning struct {
+	ComplaintID string
+	HouseID     int
+	Kind        ComplaintKind
+	Text        string
+	IssuedAt    time.Time
+	Resolved    bool
+}
+
+type NewComplaint struct {
+	ReporterID string
+	HouseID    int
+	Kind       ComplaintKind
+	Text       string
+	Apartment  *string
+}
+
+type ComplaintFilter struct {
+	HouseID    *int
+	Kind       *ComplaintKind
+	Status     *ComplaintStatus
+	ReporterID *string
+	Limit      int
+	Offset     int
+}
+
+// Review moves a complaint on, the note is the warning text or the resolution depending on the status.
+type Review struct {
+	To                ComplaintStatus
+	StaffPhone        string
+	Note              string
+	AccusedResidentID *string
+}
+
+type ComplaintsRepo interface {
+	CreateComplaint(complaint NewComplaint) (*Complaint, error)
+	GetByFilter(filter ComplaintFilter) ([]*ComplaintView, int, error)
+	GetByID(id string) (*ComplaintView, error)
+	// Move checks the transition, a warning needs the text and the resident it is given to
+	Move(id string, review Review) (*Complaint, error)
+	GetWarningsFor(residentID string) ([]*Warning, error)
+}
+
+type ComplaintKind string
+
+const (
+	KindNoise       ComplaintKind = "шум"
+	KindParking     ComplaintKind = "парковка"
+	KindNeighbors   ComplaintKind = "соседи"
+	KindCleanliness ComplaintKind = "чистота"
+)
+
+func (k ComplaintKind) IsValid() bool {
+	switch k {
+	case KindNoise, KindParking, KindNeighbors, KindCleanliness:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the kind for clients and translations, the stored value stays Russian.
+func (k ComplaintKind) Code() string {
+	switch k {
+	case KindNoise:
+		return "noise"
+	case KindParking:
+		return "parking"
+	case KindNeighbors:
+		return "neighbors"
+	case KindCleanliness:
+		return "cleanliness"
+	default:
+		return ""
+	}
+}
+
+// ComplaintKinds lists every complaint kind, e.g. to translate them all.
+var ComplaintKinds = []ComplaintKind{KindNoise, KindParking, KindNeighbors, KindCleanliness}
+
+// ComplaintKindByCode finds the kind by its Code.
+func ComplaintKindByCode(code string) (ComplaintKind, bool) {
+	for _, k := range ComplaintKinds {
+		if k.Code() == code {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+type ComplaintStatus string
+
+const (
+	StatusSubmitted ComplaintStatus = "подана"
+	StatusReviewed  ComplaintStatus = "рассмотрена"
+	StatusWarning   ComplaintStatus = "вынесено_предупреждение"
+	StatusResolved  ComplaintStatus = "решена"
+)
+
+func (s ComplaintStatus) IsValid() bool {
+	switch s {
+	case StatusSubmitted, StatusReviewed, StatusWarning, StatusResolved:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the status for clients and translations, the stored value stays Russian.
+func (s ComplaintStatus) Code() string {
+	switch s {
+	case StatusSubmitted:
+		return "submitted"
+	case StatusReviewed:
+		return "reviewed"
+	case StatusWarning:
+		return "warning_issued"
+	case StatusResolved:
+		return "resolved"
+	default:
+		return ""
+	}
+}
+
+// ComplaintStatuses lists every complaint status, e.g. to translate them all.
+var ComplaintStatuses = []ComplaintStatus{StatusSubmitted, StatusReviewed, StatusWarning, StatusResolved}
+
+// ComplaintStatusByCode finds the status by its Code.
+func ComplaintStatusByCode(code string) (ComplaintStatus, bool) {
+	for _, s := range ComplaintStatuses {
+		if s.Code() == code {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// CanMoveTo follows the lifecycle: submitted, reviewed, then a warning or straight to resolved.
+func (s ComplaintStatus) CanMoveTo(to ComplaintStatus) bool {
+	switch s {
+	case StatusSubmitted:
+		return to == StatusReviewed
+	case StatusReviewed:
+		return to == StatusWarning || to == StatusResolved
+	case StatusWarning:
+		return to == StatusResolved
+	default:
+		return false
+	}
+}
@@ -0,0 +1,262 @@
+package complaints
+
+import (
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrComplaintNotFound  = errors.New("complaint not found")
+	ErrCreatingComplaint  = errors.New("error creating complaint")
+	ErrInvalidTransition  = errors.New("complaint can not move to this status")
+	ErrWarningNeedsTarget = errors.New("a warning needs its text and the resident it is given to")
+	ErrNotInHouse         = errors.New("resident does not live in the house of the complaint")
+	ErrSelfComplaint      = errors.New("resident can not be the one the own complaint is about")
+)
+
+type ComplaintPg Complaint
+
+func (ComplaintPg) TableName() string {
+	return "complaints"
+}
+
+type ComplaintsPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewComplaintsPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *ComplaintsPgRepo {
+	return &ComplaintsPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (repo *ComplaintsPgRepo) CreateComplaint(complaint NewComplaint) (*Complaint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	complaintPg := ComplaintPg{
+		ReporterID: complaint.ReporterID,
+		HouseID:    complaint.HouseID,
+		Kind:       complaint.Kind,
+		Text:       complaint.Text,
+		Apartment:  complaint.Apartment,
+		Status:     StatusSubmitted,
+		CreatedAt:  time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+		complaintID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate complaint ID, %v", err)
+			continue
+		}
+		complaintPg.ID = complaintID
+
+		res := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&complaintPg)
+		if res.Error != nil {
+			repo.logger.Warnf("failed to insert complaint: %v", res.Error)
+			return nil, res.Error
+		}
+		createdFlag = res.RowsAffected == 1
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingComplaint
+	}
+
+	created := Complaint(complaintPg)
+	return &created, nil
+}
+
+func (repo *ComplaintsPgRepo) viewQuery(db *gorm.DB) *gorm.DB {
+	residentsTable := residence.ResidentPg{}.TableName()
+
+	return db.Table(ComplaintPg{}.TableName() + " AS complaint").
+		Joins("JOIN " + residence.HousePg{}.TableName() + " AS house ON house.id = complaint.id_house").
+		Joins("JOIN " + residentsTable + " AS reporter ON reporter.id = complaint.id_reporter").
+		Joins("LEFT JOIN " + residentsTable + " AS accused ON accused.id = complaint.id_accused")
+}
+
+const viewColumns = "complaint.*, house.address, reporter.full_name AS reporter_name, accused.full_name AS accused_name"
+
+func (repo *ComplaintsPgRepo) GetByFilter(filter ComplaintFilter) ([]*ComplaintView, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.viewQuery(repo.db.WithContext(ctx))
+
+	if filter.HouseID != nil {
+		query = query.Where("complaint.id_house = ?", *filter.HouseID)
+	}
+	if filter.Kind != nil {
+		query = query.Where("complaint.kind = ?", *filter.Kind)
+	}
+	if filter.Status != nil {
+		query = query.Where("complaint.status = ?", *filter.Status)
+	}
+	if filter.ReporterID != nil {
+		query = query.Where("complaint.id_reporter = ?", *filter.ReporterID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count complaints: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*ComplaintView{}, 0, nil
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var complaints []*ComplaintView
+	if err := query.Select(viewColumns).Order("complaint.created_at DESC").Scan(&complaints).Error; err != nil {
+		repo.logger.Warnf("failed to query complaints: %v", err)
+		return nil, int(total), err
+	}
+
+	return complaints, int(total), nil
+}
+
+func (repo *ComplaintsPgRepo) GetByID(id string) (*ComplaintView, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var complaints []*ComplaintView
+	if err := repo.viewQuery(repo.db.WithContext(ctx)).Select(viewColumns).Where("complaint.id = ?", id).Scan(&complaints).Error; err != nil {
+		repo.logger.Warnf("failed to get complaint %s: %v", id, err)
+		return nil, err
+	}
+	if len(complaints) == 0 {
+		return nil, ErrComplaintNotFound
+	}
+
+	return complaints[0], nil
+}
+
+func (repo *ComplaintsPgRepo) Move(id string, review Review) (*Complaint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var complaintPg ComplaintPg
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&complaintPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrComplaintNotFound
+			}
+			return err
+		}
+
+		if !complaintPg.Status.CanMoveTo(review.To) {
+			return ErrInvalidTransition
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":     review.To,
+			"handled_by": review.StaffPhone,
+		}
+
+		// the resident the complaint is about may be named on any step before the warning
+		if review.AccusedResidentID != nil {
+			if *review.AccusedResidentID == complaintPg.ReporterID {
+				return ErrSelfComplaint
+			}
+
+			var count int64
+			if err := tx.Model(&residence.ResidentHousePg{}).
+				Where("id_resident = ? AND id_house = ?", *review.AccusedResidentID, complaintPg.HouseID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrNotInHouse
+			}
+
+			updates["id_accused"] = *review.AccusedResidentID
+			complaintPg.AccusedResidentID = review.AccusedResidentID
+		}
+
+		switch review.To {
+		case StatusReviewed:
+			updates["reviewed_at"] = now
+		case StatusWarning:
+			if review.Note == "" || complaintPg.AccusedResidentID == nil {
+				return ErrWarningNeedsTarget
+			}
+			updates["warning_at"] = now
+			updates["warning_text"] = review.Note
+		case StatusResolved:
+			updates["resolved_at"] = now
+			if review.Note != "" {
+				updates["resolution"] = review.Note
+			}
+		}
+
+		if err := tx.Model(&ComplaintPg{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).First(&complaintPg).Error
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to move complaint %s to %s: %v", id, review.To, err)
+		return nil, err
+	}
+
+	moved := Complaint(complaintPg)
+	return &moved, nil
+}
+
+// GetWarningsFor builds the warnings from the complaints about the resident, nothing of the reporter is selected.
+func (repo *ComplaintsPgRepo) GetWarningsFor(residentID string) ([]*Warning, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rows []struct {
+		ID          string
+		HouseID     int `gorm:"column:id_house"`
+		Kind        ComplaintKind
+		WarningText string    `gorm:"column:warning_text"`
+		WarningAt   time.Time `gorm:"column:warning_at"`
+		Status      ComplaintStatus
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&ComplaintPg{}).
+		Select("id, id_house, kind, warning_text, warning_at, status").
+		Where("id_accused = ? AND warning_at IS NOT NULL", residentID).
+		Order("warning_at DESC").
+		Scan(&rows).Error; err != nil {
+		repo.logger.Warnf("failed to get warnings of resident %s: %v", residentID, err)
+		return nil, err
+	}
+
+	warnings := make([]*Warning, len(rows))
+	for i, row := range rows {
+		warnings[i] = &Warning{
+			ComplaintID: row.ID,
+			HouseID:     row.HouseID,
+			Kind:        row.Kind,
+			Text:        row.WarningText,
+			IssuedAt:    row.WarningAt,
+			Resolved:    row.Status == StatusResolved,
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/announcements"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxAnnouncementTitle = 100
+	maxAnnouncementBody  = 1000
+	// announcementTimeLayout is what an <input type="datetime-local"> sends
+	announcementTimeLayout = "2006-01-02T15:04"
+)
+
+type AnnouncementsHandler struct {
+	AnnouncementsRepo announcements.AnnouncementsRepo
+	ResidentsRepo     residence.ResidentsController
+	Logger            *zap.SugaredLogger
+}
+
+func (h *AnnouncementsHandler) abortAnnouncementError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, announcements.ErrAnnouncementNotFound), errors.Is(err, residence.ErrNoHouseFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, announcements.ErrAlreadyFinished):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// parseHouseIDs accepts the houses as a comma separated list, duplicates are dropped.
+func parseHouseIDs(value string) ([]int, bool) {
+	seen := make(map[int]bool)
+	houseIDs := make([]int, 0)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		houseID, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		if !seen[houseID] {
+			seen[houseID] = true
+			houseIDs = append(houseIDs, houseID)
+		}
+	}
+
+	sort.Ints(houseIDs)
+
+	return houseIDs, len(houseIDs) > 0
+}
+
+// GetAnnouncements lists notices for staff, active=true keeps only the ones in force now.
+func (h *AnnouncementsHandler) GetAnnouncements() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		var houseFilter *int
+		if houseIDStr := c.Query("houseID"); houseIDStr != "" {
+			houseID, errConv := strconv.Atoi(houseIDStr)
+			if errConv != nil {
+				responseJSON["error"] = "invalid houseID"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			houseFilter = &houseID
+		}
+
+		var activeAt *time.Time
+		if c.Query("active") == "true" {
+			now := time.Now()
+			activeAt = &now
+		}
+
+		list, total, err := h.AnnouncementsRepo.GetAll(houseFilter, activeAt, limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get announcements: %v", err)
+			responseJSON["error"] = "failed to get announcements"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["announcements"] = list
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// CreateAnnouncement takes startsAt/endsAt as local date and time, an empty startsAt means now.
+func (h *AnnouncementsHandler) CreateAnnouncement() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		kind := announcements.Kind(c.PostForm("kind"))
+		title := strings.TrimSpace(c.PostForm("title"))
+		body := strings.TrimSpace(c.PostForm("body"))
+		houseIDs, okHouses := parseHouseIDs(c.PostForm("houseIDs"))
+
+		startsAt := time.Now()
+		if startsAtStr := c.PostForm("startsAt"); startsAtStr != "" {
+			parsed, err := time.ParseInLocation(announcementTimeLayout, startsAtStr, time.Local)
+			if err != nil {
+				responseJSON["error"] = "startsAt must be in YYYY-MM-DDTHH:MM format"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			startsAt = parsed
+		}
+
+		endsAt, errEnds := time.ParseInLocation(announcementTimeLayout, c.PostForm("endsAt"), time.Local)
+
+		if !kind.IsValid() || title == "" || utf8.RuneCountInString(title) > maxAnnouncementTitle ||
+			utf8.RuneCountInString(body) > maxAnnouncementBody || !okHouses || errEnds != nil || !endsAt.After(startsAt) {
+			responseJSON["error"] = "valid kind, title (up to 100 characters), house IDs and an end after the start are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		announcement, err := h.AnnouncementsRepo.Create(announcements.NewAnnouncement{
+			Kind:      kind,
+			Title:     title,
+			Body:      body,
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+			HouseIDs:  houseIDs,
+			CreatedBy: c.GetString("phoneNumber"),
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to create announcement: %v", err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("announcement %s (%s) posted for houses %v", announcement.ID, announcement.Kind, announcement.HouseIDs)
+		responseJSON["announcement"] = announcement
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *AnnouncementsHandler) FinishAnnouncement() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		if id == "" {
+			responseJSON["error"] = "id is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.AnnouncementsRepo.Finish(id, time.Now()); err != nil {
+			h.Logger.Errorf("failed to finish announcement %s: %v", id, err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "finished"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *AnnouncementsHandler) DeleteAnnouncement() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.Param("id")
+
+		if err := h.AnnouncementsRepo.Delete(id); err != nil {
+			h.Logger.Errorf("failed to delete announcement %s: %v", id, err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetMyAnnouncements returns the notices in force for the houses of the logged in resident,
+// accounts without a resident profile get an empty list so that every page can ask.
+func (h *AnnouncementsHandler) GetMyAnnouncements() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		responseJSON["announcements"] = []*announcements.Announcement{}
+
+		phone := c.GetString("phoneNumber")
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if errors.Is(err, residence.ErrResidentNotFound) {
+			c.JSON(http.StatusOK, responseJSON)
+			return
+		}
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		houses, err := h.ResidentsRepo.FindResidentHouses(resident.ID)
+		if err != nil {
+			h.Logger.Errorf("failed to get houses of resident %s: %v", resident.ID, err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		houseIDs := make([]int, len(houses))
+		for i, house := range houses {
+			houseIDs[i] = house.ID
+		}
+
+		list, err := h.AnnouncementsRepo.GetActiveForHouses(houseIDs, nil, time.Now())
+		if err != nil {
+			h.Logger.Errorf("failed to get announcements of resident %s: %v", resident.ID, err)
+			h.abortAnnouncementError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["announcements"] = list
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/userdata/apitoken"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/session"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenNameMaxLength matches the varchar(64) name column of api tokens.
+const tokenNameMaxLength = 64
+
+var (
+	ErrStaffUsePersonalTokens = errors.New("staff accounts cannot log in with a password here, create a personal access token instead")
+	ErrSessionRequired        = errors.New("this action requires a browser session")
+)
+
+func (h *UserHandler) roleResolver() *AccountRoleResolver {
+	return &AccountRoleResolver{
+		StaffRepo:     h.StaffRepo,
+		ResidentsRepo: h.ResidentsRepo,
+	}
+}
+
+// IssueTokens is the password grant for resident clients such as the mobile app.
+func (h *UserHandler) IssueTokens() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		password := c.PostForm("password")
+
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil || credentials.CheckPasswordInput(password) != nil {
+			responseJSON["error"] = ErrWrongFormat.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		user, wait, errAuthorize := h.authorizeThrottled(phoneNumber, password, c.ClientIP())
+		if errAuthorize != nil {
+			abortLoginError(c, responseJSON, errAuthorize, wait)
+			return
+		}
+
+		roles, errRoles := h.roleResolver().RolesForPhone(user.Phone)
+		if errRoles != nil {
+			h.Logger.Errorf("resolve roles for %s: %v", user.Phone, errRoles)
+			responseJSON["error"] = "failed to issue tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		// staff logins go through the second factor, a password alone must not be enough for a staff bearer token
+		if !slices.Contains(roles, session.ResidentRole) {
+			responseJSON["error"] = ErrStaffUsePersonalTokens.Error()
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		pair, err := h.APITokens.IssuePair(user.Phone, string(session.ResidentRole), apitoken.DefaultAccessTTL, apitoken.DefaultRefreshTTL)
+		if err != nil {
+			h.Logger.Errorf("issue tokens for %s: %v", user.Phone, err)
+			responseJSON["error"] = "failed to issue tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+func (h *UserHandler) RefreshTokens() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		refreshToken := c.PostForm("refreshToken")
+		if refreshToken == "" {
+			responseJSON["error"] = "refreshToken is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		pair, token, err := h.APITokens.Refresh(refreshToken, apitoken.DefaultAccessTTL, apitoken.DefaultRefreshTTL)
+		if err != nil {
+			responseJSON["error"] = "failed to refresh tokens"
+
+			if errors.Is(err, apitoken.ErrInvalidToken) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			} else {
+				h.Logger.Errorf("refresh tokens: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		roles, errRoles := h.roleResolver().RolesForPhone(token.Phone)
+		if errRoles != nil {
+			h.Logger.Errorf("resolve roles for %s: %v", token.Phone, errRoles)
+			responseJSON["error"] = "failed to refresh tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		if !slices.Contains(roles, session.Role(token.Role)) {
+			if _, errRevoke := h.APITokens.RevokeAllByPhone(token.Phone); errRevoke != nil {
+				h.Logger.Errorf("revoke tokens of %s: %v", token.Phone, errRevoke)
+			}
+
+			responseJSON["error"] = apitoken.ErrInvalidToken.Error()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+// RevokeRefreshToken is the logout of token clients, it ends the refresh token and its access token.
+func (h *UserHandler) RevokeRefreshToken() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		refreshToken := c.PostForm("refreshToken")
+		if refreshToken == "" {
+			responseJSON["error"] = "refreshToken is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.APITokens.RevokeRefreshToken(refreshToken); err != nil {
+			responseJSON["error"] = "failed to revoke token"
+
+			if errors.Is(err, apitoken.ErrInvalidToken) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				h.Logger.Errorf("revoke refresh token: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) CreatePersonalToken() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		// a leaked token must not be able to mint new ones
+		if c.GetString(session.AuthMethodContextKey) == session.AuthMethodToken {
+			responseJSON["error"] = ErrSessionRequired.Error()
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		name := strings.TrimSpace(c.PostForm("name"))
+		if length := utf8.RuneCountInString(name); length == 0 || length > tokenNameMaxLength {
+			responseJSON["error"] = "name is required and must be at most " + strconv.Itoa(tokenNameMaxLength) + " characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		ttl := apitoken.DefaultPersonalTTL
+		if daysStr := c.PostForm("expiresInDays"); daysStr != "" {
+			maxDays := int(apitoken.MaxPersonalTTL / (24 * time.Hour))
+
+			days, errConv := strconv.Atoi(daysStr)
+			if errConv != nil || days <= 0 || days > maxDays {
+				responseJSON["error"] = "expiresInDays must be between 1 and " + strconv.Itoa(maxDays)
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+
+			ttl = time.Duration(days) * 24 * time.Hour
+		}
+
+		token, created, err := h.APITokens.CreatePersonal(phone, string(session.StaffRole), name, ttl)
+		if err != nil {
+			h.Logger.Errorf("create personal access token for %s: %v", phone, err)
+			responseJSON["error"] = "failed to create token"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		h.Logger.Infof("personal access token %d created by %s", created.ID, phone)
+		responseJSON["token"] = token
+		responseJSON["info"] = created
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) GetMyTokens() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		tokens, err := h.APITokens.ListByPhone(phone)
+		if err != nil {
+			h.Logger.Errorf("list api tokens for %s: %v", phone, err)
+			responseJSON["error"] = "failed to get tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["tokens"] = tokens
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) RevokeMyToken() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		id, errConv := strconv.Atoi(c.Param("id"))
+		if errConv != nil {
+			responseJSON["error"] = "wrong token id"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.APITokens.Revoke(phone, id); err != nil {
+			responseJSON["error"] = "failed to revoke token"
+
+			if errors.Is(err, apitoken.ErrTokenNotFound) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				h.Logger.Errorf("revoke api token %d of %s: %v", id, phone, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) GetUserTokens() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		tokens, err := h.APITokens.ListByPhone(phoneNumber)
+		if err != nil {
+			h.Logger.Errorf("list api tokens for %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to get tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["tokens"] = tokens
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) RevokeUserTokens() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		revoked, err := h.APITokens.RevokeAllByPhone(phoneNumber)
+		if err != nil {
+			h.Logger.Errorf("revoke api tokens of %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to revoke tokens"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		h.Logger.Infof("%d api tokens of %s revoked by staff", revoked, phoneNumber)
+		responseJSON["revoked"] = revoked
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
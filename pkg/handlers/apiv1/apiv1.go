@@ -0,0 +1,128 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/categories"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/requests/intake"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/session"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	specTitle   = "HOA API"
+	specVersion = "1.0.0"
+)
+
+// Handler serves /api/v1, the versioned JSON API for the mobile app and integrations. Authentication is the
+// same as for the rest of the app: the session cookie or a bearer token.
+type Handler struct {
+	RequestsRepo   requests.RequestRepo
+	ResidentsRepo  residence.ResidentsController
+	StaffRepo      company.StaffRepo
+	UserRepo       userdata.UserRepo
+	CategoriesRepo categories.CategoriesRepo
+	Intake         *intake.Service
+	Logger         *zap.SugaredLogger
+}
+
+var pagingParams = []Param{
+	{Name: "page", In: "query", Type: "integer", Description: "page number, starts at 1"},
+	{Name: "limit", In: "query", Type: "integer", Description: "page size, at most 1000"},
+}
+
+func withPaging(params ...Param) []Param {
+	return append(params, pagingParams...)
+}
+
+func (h *Handler) Register(group *gin.RouterGroup) {
+	router := NewRouter(group)
+
+	staffOnly := []session.Role{session.StaffRole}
+	anyUser := []session.Role{session.ResidentRole, session.StaffRole}
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/me", Tag: "users", Roles: anyUser,
+		Summary: "Current user with the resident and staff profiles", Response: UserDTO{}, Handler: h.GetMe()})
+	router.Handle(Route{Method: http.MethodPut, Path: "/me/language", Tag: "users", Roles: anyUser,
+		Summary: "Set the language of labels in responses, empty to follow the Accept-Language header",
+		Body:    LanguageBody{}, Status: http.StatusNoContent, Handler: h.SetMyLanguage()})
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/requests", Tag: "requests", Roles: anyUser,
+		Summary: "Requests of the current resident, staff members see all requests and may filter them",
+		Params: withPaging(
+			Param{Name: "sort", In: "query", Type: "string", Description: "status_asc, status_desc, type_asc, type_desc, created_asc, created_desc, priority_asc (the most urgent first), priority_desc or due_asc (the nearest deadline first); priority and deadline sorting is staff only"},
+			Param{Name: "status", In: "query", Type: "string", Description: "staff only, a status code"},
+			Param{Name: "type", In: "query", Type: "string", Description: "staff only, a type code"},
+			Param{Name: "houseId", In: "query", Type: "integer", Description: "staff only"},
+			Param{Name: "responsibleId", In: "query", Type: "integer", Description: "staff only"},
+			Param{Name: "organizationId", In: "query", Type: "string", Description: "staff only"},
+			Param{Name: "priority", In: "query", Type: "string", Description: "staff only"},
+			Param{Name: "categoryId", In: "query", Type: "string", Description: "staff only, the category with its subcategories"},
+			Param{Name: "overdue", In: "query", Type: "boolean", Description: "staff only, open requests past their deadline"},
+		),
+		Response: RequestList{}, Handler: h.ListRequests()})
+	router.Handle(Route{Method: http.MethodPost, Path: "/requests", Tag: "requests", Roles: anyUser,
+		Summary: "Create a request for one of the resident's houses, an announced outage or similar open requests answer 409 until confirmed", Body: CreateRequestBody{},
+		Response: RequestDTO{}, Status: http.StatusCreated, Handler: h.CreateRequest()})
+	router.Handle(Route{Method: http.MethodGet, Path: "/categories", Tag: "requests", Roles: anyUser,
+		Summary: "Request categories in use, grouped by request type", Response: CategoryTree{}, Handler: h.ListCategories()})
+	router.Handle(Route{Method: http.MethodGet, Path: "/requests/:id", Tag: "requests", Roles: anyUser,
+		Summary: "Get a request, residents only see their own", Response: RequestDTO{}, Handler: h.GetRequest()})
+	router.Handle(Route{Method: http.MethodPatch, Path: "/requests/:id", Tag: "requests", Roles: staffOnly,
+		Summary: "Update a request partially", Body: UpdateRequestBody{}, Response: RequestDTO{}, Handler: h.UpdateRequest()})
+	router.Handle(Route{Method: http.MethodDelete, Path: "/requests/:id", Tag: "requests", Roles: staffOnly,
+		Summary: "Delete a request", Status: http.StatusNoContent, Handler: h.DeleteRequest()})
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/users", Tag: "users", Roles: staffOnly,
+		Summary: "List users", Params: withPaging(Param{Name: "phoneNumber", In: "query", Type: "string", Description: "part of the phone number"}),
+		Response: UserList{}, Handler: h.ListUsers()})
+	router.Handle(Route{Method: http.MethodGet, Path: "/users/:phoneNumber", Tag: "users", Roles: staffOnly,
+		Summary: "Get a user with the resident and staff profiles", Response: UserDTO{}, Handler: h.GetUser()})
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/houses", Tag: "houses", Roles: staffOnly,
+		Summary: "List houses", Params: withPaging(Param{Name: "pattern", In: "query", Type: "string", Description: "part of the address or ID"}),
+		Response: HouseList{}, Handler: h.ListHouses()})
+	router.Handle(Route{Method: http.MethodPost, Path: "/houses", Tag: "houses", Roles: staffOnly,
+		Summary: "Create a house", Body: HouseBody{}, Response: HouseDTO{}, Status: http.StatusCreated, Handler: h.CreateHouse()})
+	router.Handle(Route{Method: http.MethodPatch, Path: "/houses/:id", Tag: "houses", Roles: staffOnly,
+		Params:  []Param{{Name: "id", In: "path", Type: "integer"}},
+		Summary: "Change the address of a house", Body: HouseBody{}, Response: HouseDTO{}, Handler: h.UpdateHouse()})
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/specializations", Tag: "staff", Roles: staffOnly,
+		Summary: "List specializations", Params: withPaging(Param{Name: "pattern", In: "query", Type: "string"}),
+		Response: SpecializationList{}, Handler: h.ListSpecializations()})
+	router.Handle(Route{Method: http.MethodPost, Path: "/specializations", Tag: "staff", Roles: staffOnly,
+		Summary: "Create a specialization", Body: SpecializationBody{}, Response: SpecializationDTO{},
+		Status: http.StatusCreated, Handler: h.CreateSpecialization()})
+	router.Handle(Route{Method: http.MethodPost, Path: "/staff/:id/specializations", Tag: "staff", Roles: staffOnly,
+		Params:  []Param{{Name: "id", In: "path", Type: "integer"}},
+		Summary: "Give a staff member a specialization", Body: StaffSpecializationBody{}, Response: MessageResponse{},
+		Handler: h.AddStaffSpecialization()})
+	router.Handle(Route{Method: http.MethodDelete, Path: "/staff/:id/specializations/:specializationId", Tag: "staff", Roles: staffOnly,
+		Params:  []Param{{Name: "id", In: "path", Type: "integer"}},
+		Summary: "Deactivate a specialization of a staff member", Status: http.StatusNoContent,
+		Handler: h.DeactivateStaffSpecialization()})
+
+	router.Handle(Route{Method: http.MethodGet, Path: "/organizations", Tag: "organizations", Roles: staffOnly,
+		Summary: "List organizations", Params: withPaging(Param{Name: "pattern", In: "query", Type: "string"}),
+		Response: OrganizationList{}, Handler: h.ListOrganizations()})
+	router.Handle(Route{Method: http.MethodPost, Path: "/organizations", Tag: "organizations", Roles: staffOnly,
+		Summary: "Create an organization", Body: OrganizationBody{}, Response: OrganizationDTO{},
+		Status: http.StatusCreated, Handler: h.CreateOrganization()})
+	router.Handle(Route{Method: http.MethodPatch, Path: "/organizations/:id", Tag: "organizations", Roles: staffOnly,
+		Summary: "Rename an organization", Body: OrganizationBody{}, Response: OrganizationDTO{}, Handler: h.UpdateOrganization()})
+
+	spec := router.OpenAPI(specTitle, specVersion)
+	group.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+}
+
+func currentUser(c *gin.Context) (string, session.Role) {
+	return c.GetString("phoneNumber"), session.Role(c.GetString("role"))
+}
@@ -0,0 +1,175 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListSpecializations() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		specs, total, err := h.StaffRepo.GetSpecializations(c.Query("pattern"), limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("v1: list specializations: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, SpecializationList{
+			Items: specializationsToDTO(specs),
+			Meta:  newPageMeta(total, page, limit, utils.CountPages(total, limit)),
+		})
+	}
+}
+
+func (h *Handler) CreateSpecialization() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var body SpecializationBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		spec, err := h.StaffRepo.RegisterNewSpecialization(strings.TrimSpace(body.Title))
+		if err != nil {
+			if errors.Is(err, company.ErrCreatingSpecialization) {
+				abortWithError(c, http.StatusConflict, CodeConflict, err.Error())
+				return
+			}
+
+			h.Logger.Errorf("v1: create specialization: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusCreated, specializationToDTO(spec))
+	}
+}
+
+func staffIDParam(c *gin.Context) (int, bool) {
+	staffID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || staffID <= 0 {
+		abortWithError(c, http.StatusBadRequest, CodeBadRequest, "staff member id must be a positive integer")
+		return 0, false
+	}
+	return staffID, true
+}
+
+func (h *Handler) AddStaffSpecialization() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		staffID, ok := staffIDParam(c)
+		if !ok {
+			return
+		}
+
+		var body StaffSpecializationBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		if err := h.StaffRepo.AddStaffMemberSpecializationAssoc(staffID, body.SpecializationID); err != nil {
+			h.Logger.Errorf("v1: add specialization %s to staff member %d: %v", body.SpecializationID, staffID, err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, MessageResponse{Message: i18n.T(i18n.FromContext(c), "specialization added")})
+	}
+}
+
+func (h *Handler) DeactivateStaffSpecialization() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		staffID, ok := staffIDParam(c)
+		if !ok {
+			return
+		}
+
+		specID := c.Param("specializationId")
+		if err := h.StaffRepo.DeactivateStaffMemberSpecialization(staffID, specID); err != nil {
+			if errors.Is(err, company.ErrStaffMemberNotFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "no active specialization for this staff member")
+				return
+			}
+
+			h.Logger.Errorf("v1: deactivate specialization %s of staff member %d: %v", specID, staffID, err)
+			abortInternal(c)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) ListOrganizations() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		orgs, total, err := h.StaffRepo.GetOrganizationsByPattern(c.Query("pattern"), limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("v1: list organizations: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, OrganizationList{
+			Items: organizationsToDTO(orgs),
+			Meta:  newPageMeta(total, page, limit, utils.CountPages(total, limit)),
+		})
+	}
+}
+
+func (h *Handler) CreateOrganization() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var body OrganizationBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		org, err := h.StaffRepo.CreateOrganization(strings.TrimSpace(body.Name))
+		if err != nil {
+			if errors.Is(err, company.ErrCreatingOrganization) {
+				abortWithError(c, http.StatusConflict, CodeConflict, err.Error())
+				return
+			}
+
+			h.Logger.Errorf("v1: create organization: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusCreated, OrganizationDTO{ID: org.ID, Name: org.Name})
+	}
+}
+
+func (h *Handler) UpdateOrganization() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var body OrganizationBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		name := strings.TrimSpace(body.Name)
+		if err := h.StaffRepo.UpdateOrganizationByID(id, name); err != nil {
+			// the repo reports a missing organization with the creation error
+			if errors.Is(err, company.ErrCreatingOrganization) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "organization not found")
+				return
+			}
+
+			h.Logger.Errorf("v1: update organization %s: %v", id, err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, OrganizationDTO{ID: id, Name: name})
+	}
+}
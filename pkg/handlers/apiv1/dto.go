@@ -0,0 +1,364 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/categories"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"time"
+)
+
+// The DTOs below are the wire format of /api/v1, domain structs with their gorm tags never leave the package.
+// Enums go out as stable machine codes with a label in the language of the user, bodies and filters also take
+// the Russian values stored in the database for older clients.
+
+type PageMeta struct {
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Pages int `json:"pages"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type RequestDTO struct {
+	ID                string    `json:"id"`
+	ResidentID        string    `json:"residentId"`
+	HouseID           int       `json:"houseId"`
+	Type              string    `json:"type" enum:"apartment_internal,house_common"`
+	TypeLabel         string    `json:"typeLabel"`
+	Complaint         string    `json:"complaint"`
+	Cost              *float64  `json:"cost"`
+	Status            string    `json:"status" enum:"created,assigned,completed,cancelled,suspended,transferred"`
+	StatusLabel       string    `json:"statusLabel"`
+	ResponsibleID     *int      `json:"responsibleId"`
+	OrganizationID    *string   `json:"organizationId"`
+	CreatedAt         time.Time `json:"createdAt"`
+	Priority          string    `json:"priority" enum:"emergency,high,normal,low"`
+	PriorityLabel     string    `json:"priorityLabel"`
+	SuggestedPriority *string   `json:"suggestedPriority" enum:"emergency,high,normal,low"`
+	// SuggestedPriorityLabel is set together with SuggestedPriority
+	SuggestedPriorityLabel *string    `json:"suggestedPriorityLabel"`
+	CategoryID             *string    `json:"categoryId"`
+	DueAt                  *time.Time `json:"dueAt"`
+
+	TransferredAt        *time.Time `json:"transferredAt"`
+	ContractorAcceptedAt *time.Time `json:"contractorAcceptedAt"`
+	ContractorDoneAt     *time.Time `json:"contractorDoneAt"`
+	CompletionReport     *string    `json:"completionReport"`
+	InvoiceAmount        *float64   `json:"invoiceAmount"`
+}
+
+type RequestList struct {
+	Items []RequestDTO `json:"items"`
+	Meta  PageMeta     `json:"meta"`
+}
+
+type CreateRequestBody struct {
+	HouseID   int    `json:"houseId" binding:"required,gt=0"`
+	Type      string `json:"type" binding:"required,oneof=apartment_internal house_common ремонт_внутриквартирный ремонт_общедомового_имущества" enum:"apartment_internal,house_common"`
+	Complaint string `json:"complaint" binding:"required,min=1,max=4000"`
+	// Priority is the resident's suggestion, staff set the priority of the request
+	Priority *string `json:"priority" binding:"omitempty,oneof=emergency high normal low аварийная высокая обычная низкая" enum:"emergency,high,normal,low"`
+	// CategoryID must be a category of the type, see GET /categories
+	CategoryID *string `json:"categoryId" binding:"omitempty,max=40"`
+	// ConfirmOutage sends the request although an outage is announced in the house, otherwise that is a conflict
+	ConfirmOutage bool `json:"confirmOutage"`
+	// JoinRequestID makes the request a duplicate of an open request of the house, it then follows that request
+	JoinRequestID *string `json:"joinRequestId" binding:"omitempty,max=40"`
+	// ConfirmDuplicate sends a house_common request although similar ones are open, otherwise that is a conflict
+	// whose details list their IDs under joinRequestId
+	ConfirmDuplicate bool `json:"confirmDuplicate"`
+}
+
+// UpdateRequestBody is a partial update, omitted fields keep their values.
+type UpdateRequestBody struct {
+	Type           *string  `json:"type" binding:"omitempty,oneof=apartment_internal house_common ремонт_внутриквартирный ремонт_общедомового_имущества" enum:"apartment_internal,house_common"`
+	Complaint      *string  `json:"complaint" binding:"omitempty,min=1,max=4000"`
+	Cost           *float64 `json:"cost" binding:"omitempty,gte=0"`
+	Status         *string  `json:"status" binding:"omitempty,oneof=created assigned completed cancelled suspended transferred создана назначена_исполнителю выполнена отменена приостановлена передана_организации" enum:"created,assigned,completed,cancelled,suspended,transferred"`
+	ResponsibleID  *int     `json:"responsibleId" binding:"omitempty,gt=0"`
+	OrganizationID *string  `json:"organizationId" binding:"omitempty,max=40"`
+	Priority       *string  `json:"priority" binding:"omitempty,oneof=emergency high normal low аварийная высокая обычная низкая" enum:"emergency,high,normal,low"`
+}
+
+type HouseDTO struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+}
+
+type HouseList struct {
+	Items []HouseDTO `json:"items"`
+	Meta  PageMeta   `json:"meta"`
+}
+
+type HouseBody struct {
+	Address string `json:"address" binding:"required,min=1,max=100"`
+}
+
+type ResidentDTO struct {
+	ID       string     `json:"id"`
+	Phone    string     `json:"phoneNumber"`
+	FullName string     `json:"fullName"`
+	Houses   []HouseDTO `json:"houses"`
+}
+
+type SpecializationDTO struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type SpecializationList struct {
+	Items []SpecializationDTO `json:"items"`
+	Meta  PageMeta            `json:"meta"`
+}
+
+type SpecializationBody struct {
+	Title string `json:"title" binding:"required,min=1,max=40"`
+}
+
+type StaffMemberDTO struct {
+	ID              int                 `json:"id"`
+	Phone           string              `json:"phoneNumber"`
+	FullName        string              `json:"fullName"`
+	Status          string              `json:"status" enum:"active,dismissed,unavailable"`
+	StatusLabel     string              `json:"statusLabel"`
+	Specializations []SpecializationDTO `json:"specializations"`
+}
+
+type StaffSpecializationBody struct {
+	SpecializationID string `json:"specializationId" binding:"required,max=40"`
+}
+
+type OrganizationDTO struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	ContactPerson string `json:"contactPerson,omitempty"`
+	Phone         string `json:"phoneNumber,omitempty"`
+	Email         string `json:"email,omitempty"`
+	// Categories are the codes of the request types the organization takes
+	Categories []string `json:"categories,omitempty"`
+}
+
+type OrganizationList struct {
+	Items []OrganizationDTO `json:"items"`
+	Meta  PageMeta          `json:"meta"`
+}
+
+type OrganizationBody struct {
+	Name string `json:"name" binding:"required,min=1,max=40"`
+}
+
+// UserDTO joins the login with the resident and staff profiles of the same phone, either may be missing.
+type UserDTO struct {
+	Phone    string          `json:"phoneNumber"`
+	Role     string          `json:"role,omitempty" enum:"staff,resident"`
+	Resident *ResidentDTO    `json:"resident"`
+	Staff    *StaffMemberDTO `json:"staff"`
+	// Language is the preferred language of the current user, empty when the client's one is used
+	Language *string `json:"language,omitempty" enum:"ru,en"`
+}
+
+type LanguageBody struct {
+	// Language is the preferred language of labels, empty to use the Accept-Language header
+	Language string `json:"language" binding:"omitempty,oneof=ru en" enum:"ru,en"`
+}
+
+type UserList struct {
+	Items []UserDTO `json:"items"`
+	Meta  PageMeta  `json:"meta"`
+}
+
+func newPageMeta(total, page, limit, pages int) PageMeta {
+	return PageMeta{
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Pages: pages,
+	}
+}
+
+func requestToDTO(request *requests.Request, lang i18n.Lang) RequestDTO {
+	dto := RequestDTO{
+		ID:             request.ID,
+		ResidentID:     request.ResidentID,
+		HouseID:        request.HouseID,
+		Type:           request.RequestType.Code(),
+		TypeLabel:      i18n.Label(lang, i18n.GroupRequestType, request.RequestType.Code()),
+		Complaint:      request.Complaint,
+		Cost:           request.Cost,
+		Status:         request.Status.Code(),
+		StatusLabel:    i18n.Label(lang, i18n.GroupRequestStatus, request.Status.Code()),
+		ResponsibleID:  request.ResponsibleID,
+		OrganizationID: request.OrganizationID,
+		CreatedAt:      request.CreatedAt,
+		Priority:       request.Priority.Code(),
+		PriorityLabel:  i18n.Label(lang, i18n.GroupRequestPriority, request.Priority.Code()),
+		CategoryID:     request.CategoryID,
+		DueAt:          request.DueAt,
+
+		TransferredAt:        request.TransferredAt,
+		ContractorAcceptedAt: request.ContractorAcceptedAt,
+		ContractorDoneAt:     request.ContractorDoneAt,
+		CompletionReport:     request.CompletionReport,
+		InvoiceAmount:        request.InvoiceAmount,
+	}
+	if request.SuggestedPriority != nil {
+		suggested := request.SuggestedPriority.Code()
+		suggestedLabel := i18n.Label(lang, i18n.GroupRequestPriority, suggested)
+		dto.SuggestedPriority = &suggested
+		dto.SuggestedPriorityLabel = &suggestedLabel
+	}
+
+	return dto
+}
+
+func requestsToDTO(list []*requests.Request, lang i18n.Lang) []RequestDTO {
+	result := make([]RequestDTO, len(list))
+	for i, request := range list {
+		result[i] = requestToDTO(request, lang)
+	}
+	return result
+}
+
+func houseToDTO(house *residence.House) HouseDTO {
+	return HouseDTO{
+		ID:      house.ID,
+		Address: house.Address,
+	}
+}
+
+func housesToDTO(list []*residence.House) []HouseDTO {
+	result := make([]HouseDTO, len(list))
+	for i, house := range list {
+		result[i] = houseToDTO(house)
+	}
+	return result
+}
+
+func residentToDTO(resident *residence.Resident, houses []*residence.House) *ResidentDTO {
+	return &ResidentDTO{
+		ID:       resident.ID,
+		Phone:    resident.Phone,
+		FullName: resident.FullName,
+		Houses:   housesToDTO(houses),
+	}
+}
+
+func specializationToDTO(spec *company.Specialization) SpecializationDTO {
+	return SpecializationDTO{
+		ID:    spec.ID,
+		Title: spec.Title,
+	}
+}
+
+func specializationsToDTO(list []*company.Specialization) []SpecializationDTO {
+	result := make([]SpecializationDTO, len(list))
+	for i, spec := range list {
+		result[i] = specializationToDTO(spec)
+	}
+	return result
+}
+
+func staffMemberToDTO(member *company.StaffMember, specs []*company.Specialization, lang i18n.Lang) *StaffMemberDTO {
+	return &StaffMemberDTO{
+		ID:              member.ID,
+		Phone:           member.Phone,
+		FullName:        member.FullName,
+		Status:          member.Status.Code(),
+		StatusLabel:     i18n.Label(lang, i18n.GroupStaffStatus, member.Status.Code()),
+		Specializations: specializationsToDTO(specs),
+	}
+}
+
+func organizationsToDTO(list []*company.Organization) []OrganizationDTO {
+	result := make([]OrganizationDTO, len(list))
+	for i, org := range list {
+		categories := make([]string, len(org.Categories))
+		for j, category := range org.Categories {
+			categories[j] = category.Code()
+		}
+
+		result[i] = OrganizationDTO{
+			ID:            org.ID,
+			Name:          org.Name,
+			ContactPerson: org.ContactPerson,
+			Phone:         org.Phone,
+			Email:         org.Email,
+			Categories:    categories,
+		}
+	}
+	return result
+}
+
+type CategoryDTO struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	SLAHours *int          `json:"slaHours"`
+	Children []CategoryDTO `json:"children"`
+}
+
+type CategoryGroupDTO struct {
+	Type       string        `json:"type" enum:"apartment_internal,house_common"`
+	TypeLabel  string        `json:"typeLabel"`
+	Categories []CategoryDTO `json:"categories"`
+}
+
+type CategoryTree struct {
+	Items []CategoryGroupDTO `json:"items"`
+}
+
+func categoryNodesToDTO(nodes []*categories.Node) []CategoryDTO {
+	result := make([]CategoryDTO, len(nodes))
+	for i, node := range nodes {
+		result[i] = CategoryDTO{
+			ID:       node.ID,
+			Title:    node.Title,
+			SLAHours: node.SLAHours,
+			Children: categoryNodesToDTO(node.Children),
+		}
+	}
+	return result
+}
+
+func categoryTreeToDTO(groups []*categories.Group, lang i18n.Lang) CategoryTree {
+	tree := CategoryTree{Items: make([]CategoryGroupDTO, len(groups))}
+	for i, group := range groups {
+		tree.Items[i] = CategoryGroupDTO{
+			Type:       group.Type.Code(),
+			TypeLabel:  i18n.Label(lang, i18n.GroupRequestType, group.Type.Code()),
+			Categories: categoryNodesToDTO(group.Categories),
+		}
+	}
+	return tree
+}
+
+// parseRequestType takes a machine code or a stored value of the request type.
+func parseRequestType(value string) (requests.RequestType, bool) {
+	if requestType, ok := requests.RequestTypeByCode(value); ok {
+		return requestType, true
+	}
+	requestType := requests.RequestType(value)
+	return requestType, requestType.IsValid()
+}
+
+// parseRequestStatus takes a machine code or a stored value of the request status.
+func parseRequestStatus(value string) (requests.RequestStatus, bool) {
+	if status, ok := requests.RequestStatusByCode(value); ok {
+		return status, true
+	}
+	status := requests.RequestStatus(value)
+	return status, status.IsValid()
+}
+
+// parseRequestPriority takes a machine code or a stored value of the request priority.
+func parseRequestPriority(value string) (requests.RequestPriority, bool) {
+	if priority, ok := requests.RequestPriorityByCode(value); ok {
+		return priority, true
+	}
+	priority := requests.RequestPriority(value)
+	return priority, priority.IsValid()
+}
@@ -0,0 +1,130 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/i18n"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes are part of the API contract, clients switch on them instead of parsing messages.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeInternal         = "internal_error"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ErrorBody struct {
+	Code    string       `json:"code" enum:"bad_request,validation_failed,unauthorized,forbidden,not_found,conflict,internal_error"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// ErrorEnvelope is the only shape an /api/v1 error response has.
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error" binding:"required"`
+}
+
+var errInternal = errors.New("internal error, try again later")
+
+// abortWithError translates the message and the details into the language of the request, a text without a
+// translation is sent as it is.
+func abortWithError(c *gin.Context, status int, code, message string, details ...FieldError) {
+	lang := i18n.FromContext(c)
+
+	translated := make([]FieldError, len(details))
+	for i, detail := range details {
+		translated[i] = FieldError{Field: detail.Field, Message: i18n.T(lang, detail.Message)}
+	}
+
+	c.AbortWithStatusJSON(status, ErrorEnvelope{
+		Error: ErrorBody{
+			Code:    code,
+			Message: i18n.T(lang, message),
+			Details: translated,
+		},
+	})
+}
+
+func abortInternal(c *gin.Context) {
+	abortWithError(c, http.StatusInternalServerError, CodeInternal, errInternal.Error())
+}
+
+// jsonFieldName reports validation errors under the name clients send, not the Go field name.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func validationMessage(lang i18n.Lang, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return i18n.T(lang, "is required")
+	case "min":
+		return fmt.Sprintf(i18n.T(lang, "must be at least %s"), fieldErr.Param())
+	case "max":
+		return fmt.Sprintf(i18n.T(lang, "must be at most %s"), fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf(i18n.T(lang, "must be one of: %s"), fieldErr.Param())
+	case "gt":
+		return fmt.Sprintf(i18n.T(lang, "must be greater than %s"), fieldErr.Param())
+	case "gte":
+		return fmt.Sprintf(i18n.T(lang, "must be greater than or equal to %s"), fieldErr.Param())
+	default:
+		return fmt.Sprintf(i18n.T(lang, "is invalid (%s)"), fieldErr.Tag())
+	}
+}
+
+// bindJSON decodes and validates the body, on failure it has already answered with the envelope.
+func bindJSON(c *gin.Context, target any) bool {
+	if c.ContentType() != gin.MIMEJSON {
+		abortWithError(c, http.StatusUnsupportedMediaType, CodeBadRequest, "request body must be application/json")
+		return false
+	}
+
+	err := c.ShouldBindJSON(target)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		targetType := reflect.TypeOf(target).Elem()
+
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fieldName := fieldErr.Field()
+			if field, ok := targetType.FieldByName(fieldErr.StructField()); ok {
+				fieldName = jsonFieldName(field)
+			}
+
+			details = append(details, FieldError{
+				Field:   fieldName,
+				Message: validationMessage(i18n.FromContext(c), fieldErr),
+			})
+		}
+
+		abortWithError(c, http.StatusUnprocessableEntity, CodeValidationFailed, "request body failed validation", details...)
+		return false
+	}
+
+	abortWithError(c, http.StatusBadRequest, CodeBadRequest, i18n.T(i18n.FromContext(c), "malformed JSON body")+": "+err.Error())
+	return false
+}
@@ -0,0 +1,82 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListHouses() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		houses, total, err := h.ResidentsRepo.GetHouses(c.Query("pattern"), limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("v1: list houses: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, HouseList{
+			Items: housesToDTO(houses),
+			Meta:  newPageMeta(total, page, limit, utils.CountPages(total, limit)),
+		})
+	}
+}
+
+func (h *Handler) CreateHouse() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var body HouseBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		house, err := h.ResidentsRepo.RegisterNewHouse(strings.TrimSpace(body.Address))
+		if err != nil {
+			if errors.Is(err, residence.ErrHouseExists) {
+				abortWithError(c, http.StatusConflict, CodeConflict, err.Error())
+				return
+			}
+
+			h.Logger.Errorf("v1: create house: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusCreated, houseToDTO(house))
+	}
+}
+
+func (h *Handler) UpdateHouse() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		houseID, errConv := strconv.Atoi(c.Param("id"))
+		if errConv != nil {
+			abortWithError(c, http.StatusBadRequest, CodeBadRequest, "house id must be an integer")
+			return
+		}
+
+		var body HouseBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		address := strings.TrimSpace(body.Address)
+		if err := h.ResidentsRepo.UpdateHouseAddress(houseID, address); err != nil {
+			if errors.Is(err, residence.ErrNoHouseFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, err.Error())
+				return
+			}
+
+			h.Logger.Errorf("v1: update house %d: %v", houseID, err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, HouseDTO{ID: houseID, Address: address})
+	}
+}
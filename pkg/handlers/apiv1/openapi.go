@@ -0,0 +1,364 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/userdata/session"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Param documents a query or path parameter, path parameters that are not declared default to strings.
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+}
+
+// Route is registered on gin and described in the OpenAPI document at the same time, so the two cannot drift.
+type Route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Roles    []session.Role
+	Params   []Param
+	Body     any
+	Response any
+	Status   int
+	Handler  gin.HandlerFunc
+}
+
+type Router struct {
+	group  *gin.RouterGroup
+	routes []Route
+}
+
+func NewRouter(group *gin.RouterGroup) *Router {
+	return &Router{
+		group: group,
+	}
+}
+
+func (r *Router) Handle(route Route) {
+	if route.Status == 0 {
+		route.Status = http.StatusOK
+	}
+
+	handlers := make([]gin.HandlerFunc, 0, 2)
+	if len(route.Roles) > 0 {
+		handlers = append(handlers, requireRoles(route.Roles...))
+	}
+	handlers = append(handlers, route.Handler)
+
+	r.group.Handle(route.Method, route.Path, handlers...)
+	r.routes = append(r.routes, route)
+}
+
+func requireRoles(allowed ...session.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := session.Role(c.GetString("role"))
+		if role == "" {
+			abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "authentication required")
+			return
+		}
+
+		for _, allowedRole := range allowed {
+			if role == allowedRole {
+				c.Next()
+				return
+			}
+		}
+
+		abortWithError(c, http.StatusForbidden, CodeForbidden,
+			fmt.Sprintf(i18n.T(i18n.FromContext(c), "role %s is not allowed here"), role))
+	}
+}
+
+type schemaBuilder struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func bindingRules(field reflect.StructField) map[string]string {
+	rules := map[string]string{}
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		if name != "" {
+			rules[name] = value
+		}
+	}
+	return rules
+}
+
+func applyRules(schema map[string]any, rules map[string]string) {
+	isString := schema["type"] == "string"
+
+	for name, value := range rules {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case name == "min" && isString:
+			schema["minLength"] = int(number)
+		case name == "max" && isString:
+			schema["maxLength"] = int(number)
+		case name == "min" || name == "gte":
+			schema["minimum"] = number
+		case name == "max" || name == "lte":
+			schema["maximum"] = number
+		case name == "gt":
+			schema["minimum"] = number
+			schema["exclusiveMinimum"] = true
+		}
+	}
+}
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]any {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		inner := b.schemaFor(t.Elem())
+		if _, isRef := inner["$ref"]; isRef {
+			return map[string]any{"allOf": []any{inner}, "nullable": true}
+		}
+		inner["nullable"] = true
+		return inner
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		b.component(t)
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *schemaBuilder) component(t reflect.Type) {
+	if _, exists := b.components[t.Name()]; exists {
+		return
+	}
+	// placeholder first, so self-referencing types terminate
+	b.components[t.Name()] = map[string]any{}
+
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		schema := b.schemaFor(field.Type)
+
+		if enum := field.Tag.Get("enum"); enum != "" {
+			schema["enum"] = strings.Split(enum, ",")
+		}
+
+		rules := bindingRules(field)
+		applyRules(schema, rules)
+		if _, ok := rules["required"]; ok {
+			required = append(required, name)
+		}
+
+		properties[name] = schema
+	}
+
+	component := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		component["required"] = required
+	}
+
+	b.components[t.Name()] = component
+}
+
+func openAPIPath(path string) (string, []string) {
+	var names []string
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			names = append(names, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), names
+}
+
+func errorResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/ErrorEnvelope"},
+			},
+		},
+	}
+}
+
+// OpenAPI builds an OpenAPI 3.0 document from the registered routes.
+func (r *Router) OpenAPI(title, version string) map[string]any {
+	builder := &schemaBuilder{components: map[string]any{}}
+	builder.component(reflect.TypeOf(ErrorEnvelope{}))
+
+	basePath := strings.TrimSuffix(r.group.BasePath(), "/")
+	paths := map[string]any{}
+
+	for _, route := range r.routes {
+		path, pathParams := openAPIPath(basePath + route.Path)
+
+		declared := map[string]Param{}
+		for _, param := range route.Params {
+			declared[param.In+":"+param.Name] = param
+		}
+
+		var parameters []any
+		for _, name := range pathParams {
+			param, ok := declared["path:"+name]
+			if !ok {
+				param = Param{Name: name, In: "path", Type: "string"}
+			}
+
+			parameters = append(parameters, map[string]any{
+				"name":        name,
+				"in":          "path",
+				"required":    true,
+				"description": param.Description,
+				"schema":      map[string]any{"type": param.Type},
+			})
+		}
+		for _, param := range route.Params {
+			if param.In != "query" {
+				continue
+			}
+
+			parameters = append(parameters, map[string]any{
+				"name":        param.Name,
+				"in":          "query",
+				"description": param.Description,
+				"schema":      map[string]any{"type": param.Type},
+			})
+		}
+
+		successResponse := map[string]any{"description": http.StatusText(route.Status)}
+		if route.Response != nil {
+			successResponse["content"] = map[string]any{
+				"application/json": map[string]any{
+					"schema": builder.schemaFor(reflect.TypeOf(route.Response)),
+				},
+			}
+		}
+
+		responses := map[string]any{
+			strconv.Itoa(route.Status): successResponse,
+			"400":                      errorResponse("Malformed request"),
+			"401":                      errorResponse("Authentication required"),
+			"500":                      errorResponse("Internal error"),
+		}
+		if len(route.Roles) > 0 {
+			responses["403"] = errorResponse("Role not allowed")
+		}
+		if len(pathParams) > 0 {
+			responses["404"] = errorResponse("Not found")
+		}
+
+		operation := map[string]any{
+			"summary":     route.Summary,
+			"tags":        []string{route.Tag},
+			"operationId": strings.ToLower(route.Method) + strings.NewReplacer("/", "_", "{", "", "}", "").Replace(path),
+			"responses":   responses,
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if len(route.Roles) > 0 {
+			roles := make([]string, len(route.Roles))
+			for i, role := range route.Roles {
+				roles[i] = string(role)
+			}
+			operation["x-roles"] = roles
+		}
+		if route.Body != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": builder.schemaFor(reflect.TypeOf(route.Body)),
+					},
+				},
+			}
+			responses["422"] = errorResponse("Validation failed")
+		}
+
+		pathItem, ok := paths[path].(map[string]any)
+		if !ok {
+			pathItem = map[string]any{}
+			paths[path] = pathItem
+		}
+		pathItem[strings.ToLower(route.Method)] = operation
+	}
+
+	tagSet := map[string]struct{}{}
+	for _, route := range r.routes {
+		tagSet[route.Tag] = struct{}{}
+	}
+	tags := make([]any, 0, len(tagSet))
+	for _, name := range sortedKeys(tagSet) {
+		tags = append(tags, map[string]any{"name": name})
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": builder.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+				"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": "hoa_project"},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []string{}},
+			map[string]any{"cookieAuth": []string{}},
+		},
+	}
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
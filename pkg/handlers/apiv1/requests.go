@@ -0,0 +1,382 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/categories"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/requests/intake"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// residentForCaller resolves the resident profile of the caller, a caller without one gets a 403.
+func (h *Handler) residentForCaller(c *gin.Context, phone string) (*residence.Resident, bool) {
+	resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+	if err != nil {
+		if errors.Is(err, residence.ErrResidentNotFound) {
+			abortWithError(c, http.StatusForbidden, CodeForbidden, "only residents can do this")
+			return nil, false
+		}
+
+		h.Logger.Errorf("v1: get resident by phone %s: %v", phone, err)
+		abortInternal(c)
+		return nil, false
+	}
+
+	return resident, true
+}
+
+func (h *Handler) ListRequests() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, role := currentUser(c)
+		page, limit := utils.GetPageAndLimitFromContext(c)
+		offset := (page - 1) * limit
+
+		var (
+			list  []*requests.Request
+			total int
+			err   error
+		)
+
+		if role == session.StaffRole {
+			filter := requests.RequestFilter{
+				Limit:  limit,
+				Offset: offset,
+				Sort:   c.Query("sort"),
+			}
+
+			var details []FieldError
+			if statusStr := c.Query("status"); statusStr != "" {
+				if status, ok := parseRequestStatus(statusStr); ok {
+					filter.Status = &status
+				} else {
+					details = append(details, FieldError{Field: "status", Message: "is not a known status"})
+				}
+			}
+			if typeStr := c.Query("type"); typeStr != "" {
+				if requestType, ok := parseRequestType(typeStr); ok {
+					filter.RequestType = &requestType
+				} else {
+					details = append(details, FieldError{Field: "type", Message: "is not a known request type"})
+				}
+			}
+			if houseStr := c.Query("houseId"); houseStr != "" {
+				if houseID, errConv := strconv.Atoi(houseStr); errConv == nil {
+					filter.HouseID = &houseID
+				} else {
+					details = append(details, FieldError{Field: "houseId", Message: "must be an integer"})
+				}
+			}
+			if responsibleStr := c.Query("responsibleId"); responsibleStr != "" {
+				if responsibleID, errConv := strconv.Atoi(responsibleStr); errConv == nil {
+					filter.ResponsibleID = &responsibleID
+				} else {
+					details = append(details, FieldError{Field: "responsibleId", Message: "must be an integer"})
+				}
+			}
+			if organizationID := c.Query("organizationId"); organizationID != "" {
+				filter.OrganizationID = &organizationID
+			}
+			if priorityStr := c.Query("priority"); priorityStr != "" {
+				if priority, ok := parseRequestPriority(priorityStr); ok {
+					filter.Priority = &priority
+				} else {
+					details = append(details, FieldError{Field: "priority", Message: "is not a known priority"})
+				}
+			}
+			if overdueStr := c.Query("overdue"); overdueStr != "" {
+				if overdue, errConv := strconv.ParseBool(overdueStr); errConv == nil {
+					filter.Overdue = overdue
+				} else {
+					details = append(details, FieldError{Field: "overdue", Message: "must be a boolean"})
+				}
+			}
+			if categoryID := c.Query("categoryId"); categoryID != "" {
+				categoryIDs, errCategory := h.CategoriesRepo.GetSubtreeIDs(categoryID)
+				switch {
+				case errors.Is(errCategory, categories.ErrCategoryNotFound):
+					details = append(details, FieldError{Field: "categoryId", Message: "is not a known category"})
+				case errCategory != nil:
+					h.Logger.Errorf("v1: expand category %s: %v", categoryID, errCategory)
+					abortInternal(c)
+					return
+				default:
+					filter.CategoryIDs = categoryIDs
+				}
+			}
+
+			if len(details) > 0 {
+				abortWithError(c, http.StatusBadRequest, CodeBadRequest, "invalid query parameters", details...)
+				return
+			}
+
+			list, total, err = h.RequestsRepo.GetByFilter(filter)
+		} else {
+			list, total, err = h.RequestsRepo.GetResidentRequestsByPhone(phone, limit, offset, c.Query("sort"))
+		}
+
+		if err != nil {
+			h.Logger.Errorf("v1: list requests for %s: %v", phone, err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, RequestList{
+			Items: requestsToDTO(list, i18n.FromContext(c)),
+			Meta:  newPageMeta(total, page, limit, utils.CountPages(total, limit)),
+		})
+	}
+}
+
+func (h *Handler) CreateRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, _ := currentUser(c)
+
+		var body CreateRequestBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		resident, ok := h.residentForCaller(c, phone)
+		if !ok {
+			return
+		}
+
+		isValid, err := h.ResidentsRepo.ValidateResidentHouse(resident.ID, body.HouseID)
+		if err != nil {
+			h.Logger.Errorf("v1: validate house %d of resident %s: %v", body.HouseID, resident.ID, err)
+			abortInternal(c)
+			return
+		}
+		if !isValid {
+			abortWithError(c, http.StatusForbidden, CodeForbidden, "the house is not linked to your account",
+				FieldError{Field: "houseId", Message: "is not one of your houses"})
+			return
+		}
+
+		joining := body.JoinRequestID != nil && *body.JoinRequestID != ""
+
+		if !joining && !body.ConfirmOutage {
+			if outages := h.Intake.ActiveOutages(body.HouseID); len(outages) > 0 {
+				abortWithError(c, http.StatusConflict, CodeConflict, intake.OutageWarning(i18n.FromContext(c), outages[0]),
+					FieldError{Field: "confirmOutage", Message: "set to true to send the request anyway"})
+				return
+			}
+		}
+
+		// the binding has already checked the value, a code or a stored one
+		requestType, _ := parseRequestType(body.Type)
+
+		if !joining && !body.ConfirmDuplicate {
+			if similar := h.Intake.SimilarOpen(body.HouseID, requestType, body.Complaint); len(similar) > 0 {
+				details := make([]FieldError, len(similar))
+				for i, s := range similar {
+					details[i] = FieldError{Field: "joinRequestId", Message: s.ID}
+				}
+				abortWithError(c, http.StatusConflict, CodeConflict,
+					"similar requests are already open in this house, join one of them or send yours anyway", details...)
+				return
+			}
+		}
+
+		requestData := requests.InitialRequestData{
+			ResidentID:  resident.ID,
+			HouseID:     body.HouseID,
+			RequestType: requestType,
+			Complaint:   body.Complaint,
+		}
+		if body.Priority != nil {
+			suggested, _ := parseRequestPriority(*body.Priority)
+			requestData.SuggestedPriority = &suggested
+		}
+		if joining {
+			requestData.ParentID = body.JoinRequestID
+		}
+
+		var defaults *categories.Defaults
+		if body.CategoryID != nil {
+			defaults, err = h.CategoriesRepo.GetDefaults(*body.CategoryID)
+			if err != nil {
+				if errors.Is(err, categories.ErrCategoryNotFound) || errors.Is(err, categories.ErrCategoryInactive) {
+					abortWithError(c, http.StatusUnprocessableEntity, CodeValidationFailed, "request body failed validation",
+						FieldError{Field: "categoryId", Message: "is not a category in use"})
+					return
+				}
+				h.Logger.Errorf("v1: get category %s: %v", *body.CategoryID, err)
+				abortInternal(c)
+				return
+			}
+			if defaults.Category.Group != requestData.RequestType {
+				abortWithError(c, http.StatusUnprocessableEntity, CodeValidationFailed, "request body failed validation",
+					FieldError{Field: "categoryId", Message: "belongs to the other request type"})
+				return
+			}
+			requestData.CategoryID = body.CategoryID
+			requestData.SLAHours = defaults.SLAHours
+		}
+
+		request, err := h.RequestsRepo.CreateRequest(requestData)
+		if err != nil {
+			switch {
+			case errors.Is(err, requests.ErrNoRequestsFound):
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request to join not found")
+			case errors.Is(err, requests.ErrRequestClosed), errors.Is(err, requests.ErrMergeMismatch):
+				lang := i18n.FromContext(c)
+				abortWithError(c, http.StatusConflict, CodeConflict, i18n.T(lang, "can not join this request")+": "+i18n.T(lang, err.Error()))
+			default:
+				h.Logger.Errorf("v1: create request: %v", err)
+				abortInternal(c)
+			}
+			return
+		}
+
+		// a duplicate follows its parent, only a request of its own goes to the staff of the category
+		if defaults != nil && request.ParentID == nil {
+			h.Intake.AssignByCategory(request, defaults.SpecializationIDs)
+		}
+
+		c.JSON(http.StatusCreated, requestToDTO(request, i18n.FromContext(c)))
+	}
+}
+
+func (h *Handler) GetRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, role := currentUser(c)
+
+		request, err := h.RequestsRepo.GetByID(c.Param("id"))
+		if err != nil {
+			if errors.Is(err, requests.ErrNoRequestsFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request not found")
+				return
+			}
+
+			h.Logger.Errorf("v1: get request %s: %v", c.Param("id"), err)
+			abortInternal(c)
+			return
+		}
+
+		if role != session.StaffRole {
+			resident, ok := h.residentForCaller(c, phone)
+			if !ok {
+				return
+			}
+			// someone else's request is reported as missing, not as forbidden
+			if request.ResidentID != resident.ID {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request not found")
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, requestToDTO(request, i18n.FromContext(c)))
+	}
+}
+
+func (h *Handler) UpdateRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var body UpdateRequestBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		updates := requests.Request{
+			ID:             id,
+			Cost:           body.Cost,
+			ResponsibleID:  body.ResponsibleID,
+			OrganizationID: body.OrganizationID,
+		}
+		if body.Type != nil {
+			updates.RequestType, _ = parseRequestType(*body.Type)
+		}
+		if body.Complaint != nil {
+			updates.Complaint = *body.Complaint
+		}
+		if body.Status != nil {
+			updates.Status, _ = parseRequestStatus(*body.Status)
+		}
+		if body.Priority != nil {
+			updates.Priority, _ = parseRequestPriority(*body.Priority)
+		}
+
+		current, err := h.RequestsRepo.GetByID(id)
+		if err != nil {
+			if errors.Is(err, requests.ErrNoRequestsFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request not found")
+				return
+			}
+
+			h.Logger.Errorf("v1: get request %s to update: %v", id, err)
+			abortInternal(c)
+			return
+		}
+		if err := requests.CheckNoTransfer(current, updates.Status, updates.OrganizationID); err != nil {
+			lang := i18n.FromContext(c)
+			abortWithError(c, http.StatusConflict, CodeConflict,
+				i18n.T(lang, err.Error())+", "+i18n.T(lang, "it checks the contract of the organization"))
+			return
+		}
+
+		if err := h.RequestsRepo.UpdateRequest(&updates); err != nil {
+			if errors.Is(err, requests.ErrNoRequestsFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request not found")
+				return
+			}
+			if errors.Is(err, requests.ErrMergedRequest) {
+				abortWithError(c, http.StatusConflict, CodeConflict, err.Error())
+				return
+			}
+
+			h.Logger.Errorf("v1: update request %s: %v", id, err)
+			abortInternal(c)
+			return
+		}
+
+		request, err := h.RequestsRepo.GetByID(id)
+		if err != nil {
+			h.Logger.Errorf("v1: reload request %s: %v", id, err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, requestToDTO(request, i18n.FromContext(c)))
+	}
+}
+
+func (h *Handler) DeleteRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if err := h.RequestsRepo.DeleteByID(id); err != nil {
+			if errors.Is(err, requests.ErrNoRequestsFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "request not found")
+				return
+			}
+
+			h.Logger.Errorf("v1: delete request %s: %v", id, err)
+			abortInternal(c)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) ListCategories() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		groups, err := h.CategoriesRepo.GetTree(false)
+		if err != nil {
+			h.Logger.Errorf("v1: list categories: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, categoryTreeToDTO(groups, i18n.FromContext(c)))
+	}
+}
@@ -0,0 +1,145 @@
+package apiv1
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadUser collects both profiles of a phone, a phone with neither is reported as not found.
+func (h *Handler) loadUser(phone string, lang i18n.Lang) (*UserDTO, error) {
+	user := &UserDTO{Phone: phone}
+
+	staffMember, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+	if err != nil && !errors.Is(err, company.ErrStaffMemberNotFound) {
+		return nil, err
+	}
+	if staffMember != nil {
+		specs, errSpecs := h.StaffRepo.FindCurrentSpecializations(staffMember.ID)
+		if errSpecs != nil {
+			return nil, errSpecs
+		}
+		user.Staff = staffMemberToDTO(staffMember, specs, lang)
+	}
+
+	resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+	if err != nil && !errors.Is(err, residence.ErrResidentNotFound) {
+		return nil, err
+	}
+	if resident != nil {
+		houses, errHouses := h.ResidentsRepo.FindResidentHouses(resident.ID)
+		if errHouses != nil {
+			return nil, errHouses
+		}
+		user.Resident = residentToDTO(resident, houses)
+	}
+
+	return user, nil
+}
+
+func (h *Handler) GetMe() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, role := currentUser(c)
+
+		user, err := h.loadUser(phone, i18n.FromContext(c))
+		if err != nil {
+			h.Logger.Errorf("v1: load user %s: %v", phone, err)
+			abortInternal(c)
+			return
+		}
+
+		language, err := h.UserRepo.GetLanguage(phone)
+		if err != nil {
+			h.Logger.Errorf("v1: get language of %s: %v", phone, err)
+			abortInternal(c)
+			return
+		}
+
+		user.Role = string(role)
+		user.Language = &language
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func (h *Handler) SetMyLanguage() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, _ := currentUser(c)
+
+		var body LanguageBody
+		if !bindJSON(c, &body) {
+			return
+		}
+
+		if err := h.UserRepo.SetLanguage(phone, body.Language); err != nil {
+			if errors.Is(err, userdata.ErrUserNotFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, "user not found")
+				return
+			}
+			h.Logger.Errorf("v1: set language of %s: %v", phone, err)
+			abortInternal(c)
+			return
+		}
+
+		if err := session.CacheLanguage(c, body.Language); err != nil {
+			h.Logger.Warnf("v1: cache language of %s: %v", phone, err)
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) GetUser() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone, err := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeBadRequest, err.Error(),
+				FieldError{Field: "phoneNumber", Message: err.Error()})
+			return
+		}
+
+		user, err := h.loadUser(phone, i18n.FromContext(c))
+		if err != nil {
+			h.Logger.Errorf("v1: load user %s: %v", phone, err)
+			abortInternal(c)
+			return
+		}
+		if user.Staff == nil && user.Resident == nil {
+			abortWithError(c, http.StatusNotFound, CodeNotFound, "user not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// ListUsers returns only the phones, like the admin panel list, profiles are fetched with GetUser.
+func (h *Handler) ListUsers() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		users, total, err := h.UserRepo.GetAll(credentials.PhoneSearchPattern(c.Query("phoneNumber")), limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("v1: list users: %v", err)
+			abortInternal(c)
+			return
+		}
+
+		items := make([]UserDTO, len(users))
+		for i, user := range users {
+			items[i] = UserDTO{Phone: user.Phone}
+		}
+
+		c.JSON(http.StatusOK, UserList{
+			Items: items,
+			Meta:  newPageMeta(total, page, limit, utils.CountPages(total, limit)),
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/appointments"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxSlotsPerProposal = 10
+	maxSlotCancelReason = 200
+	// pastAppointmentsInFeed keeps recent visits in the calendars for a while after they are over
+	pastAppointmentsInFeed = 30 * 24 * time.Hour
+)
+
+// AppointmentsHandler serves the visit slots of apartment repairs and the calendar feeds of the agreed visits.
+type AppointmentsHandler struct {
+	AppointmentsRepo appointments.AppointmentsRepo
+	RequestsRepo     requests.RequestRepo
+	StaffRepo        company.StaffRepo
+	ResidentsRepo    residence.ResidentsController
+	Logger           *zap.SugaredLogger
+}
+
+func (h *AppointmentsHandler) abortAppointmentError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, appointments.ErrSlotNotFound), errors.Is(err, appointments.ErrFeedNotFound),
+		errors.Is(err, requests.ErrNoRequestsFound), errors.Is(err, residence.ErrResidentNotFound),
+		errors.Is(err, company.ErrStaffMemberNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, appointments.ErrNotResponsible):
+		c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+	case errors.Is(err, appointments.ErrSlotOverlap), errors.Is(err, appointments.ErrSlotNotAvailable),
+		errors.Is(err, requests.ErrRequestClosed), errors.Is(err, requests.ErrMergedRequest):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, appointments.ErrNotApartmentRequest), errors.Is(err, appointments.ErrInvalidWindow):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// feedURL builds the absolute link a calendar app subscribes to.
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/calendar/" + token + ".ics"
+}
+
+func optionalReason(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (h *AppointmentsHandler) GetRequestSlots() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		requestID := c.Query("requestID")
+		if requestID == "" {
+			responseJSON["error"] = "requestID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		slots, err := h.AppointmentsRepo.GetSlots(requestID)
+		if err != nil {
+			h.Logger.Errorf("failed to get slots of request %s: %v", requestID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["slots"] = slots
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// ProposeSlots takes the windows as paired "start" and "end" fields in the datetime-local format.
+func (h *AppointmentsHandler) ProposeSlots() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		requestID := strings.TrimSpace(c.PostForm("requestID"))
+		starts := c.PostFormArray("start")
+		ends := c.PostFormArray("end")
+
+		if requestID == "" || len(starts) == 0 || len(starts) != len(ends) || len(starts) > maxSlotsPerProposal {
+			responseJSON["error"] = "requestID and from 1 to 10 start/end pairs are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		windows := make([]appointments.Window, len(starts))
+		for i := range starts {
+			startsAt, errStart := time.ParseInLocation(announcementTimeLayout, starts[i], time.Local)
+			endsAt, errEnd := time.ParseInLocation(announcementTimeLayout, ends[i], time.Local)
+			if errStart != nil || errEnd != nil {
+				responseJSON["error"] = "start and end must be in YYYY-MM-DDTHH:MM format"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			windows[i] = appointments.Window{StartsAt: startsAt, EndsAt: endsAt}
+		}
+
+		member, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		slots, err := h.AppointmentsRepo.ProposeSlots(requestID, member.ID, windows)
+		if err != nil {
+			h.Logger.Errorf("failed to propose slots for request %s: %v", requestID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["slots"] = slots
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// CancelSlotByStaff withdraws a proposal or calls off the agreed visit, the resident then picks another time.
+// Only the staff member the slot was proposed by may do it.
+func (h *AppointmentsHandler) CancelSlotByStaff() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		slotID := strings.TrimSpace(c.PostForm("slotID"))
+		reason := strings.TrimSpace(c.PostForm("reason"))
+		if slotID == "" || len([]rune(reason)) > maxSlotCancelReason {
+			responseJSON["error"] = "slotID is required, the reason is up to 200 characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		member, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		slot, err := h.AppointmentsRepo.GetSlotByID(slotID)
+		if err != nil {
+			h.Logger.Errorf("failed to get slot %s: %v", slotID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+		if slot.StaffMemberID != member.ID {
+			h.Logger.Infof("staff member %d refused to cancel slot %s of %d", member.ID, slotID, slot.StaffMemberID)
+			h.abortAppointmentError(c, responseJSON, appointments.ErrNotResponsible)
+			return
+		}
+
+		slot, err = h.AppointmentsRepo.CancelSlot(slotID, phone, optionalReason(reason))
+		if err != nil {
+			h.Logger.Errorf("failed to cancel slot %s: %v", slotID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["slot"] = slot
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// PickSlot lets the resident choose the visit time, picking another proposed slot reschedules the visit.
+func (h *AppointmentsHandler) PickSlot() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		slotID := strings.TrimSpace(c.PostForm("slotID"))
+		if slotID == "" {
+			responseJSON["error"] = "slotID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		slot, err := h.AppointmentsRepo.PickSlot(slotID, resident.ID)
+		if err != nil {
+			h.Logger.Errorf("failed to pick slot %s by resident %s: %v", slotID, resident.ID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["slot"] = slot
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// CancelSlotByResident calls off the agreed visit of the resident's request so that the staff proposes another time.
+func (h *AppointmentsHandler) CancelSlotByResident() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		slotID := strings.TrimSpace(c.PostForm("slotID"))
+		reason := strings.TrimSpace(c.PostForm("reason"))
+		if slotID == "" || len([]rune(reason)) > maxSlotCancelReason {
+			responseJSON["error"] = "slotID is required, the reason is up to 200 characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		slot, err := h.AppointmentsRepo.GetSlotByID(slotID)
+		if err != nil {
+			h.Logger.Errorf("failed to get slot %s: %v", slotID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		request, err := h.RequestsRepo.GetByID(slot.RequestID)
+		if err != nil && !errors.Is(err, requests.ErrNoRequestsFound) {
+			h.Logger.Errorf("failed to get request %s: %v", slot.RequestID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+		if err != nil || request.ResidentID != resident.ID {
+			h.abortAppointmentError(c, responseJSON, appointments.ErrSlotNotFound)
+			return
+		}
+		// proposals belong to the staff, the resident only calls off the visit they agreed to
+		if slot.Status != appointments.SlotChosen {
+			h.abortAppointmentError(c, responseJSON, appointments.ErrSlotNotAvailable)
+			return
+		}
+
+		slot, err = h.AppointmentsRepo.CancelSlot(slotID, phone, optionalReason(reason))
+		if err != nil {
+			h.Logger.Errorf("failed to cancel slot %s: %v", slotID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["slot"] = slot
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *AppointmentsHandler) GetMyStaffAppointments() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		member, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		list, err := h.AppointmentsRepo.GetStaffAppointments(member.ID, time.Now())
+		if err != nil {
+			h.Logger.Errorf("failed to get appointments of staff member %d: %v", member.ID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["appointments"] = list
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *AppointmentsHandler) GetMyResidentAppointments() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		list, err := h.AppointmentsRepo.GetResidentAppointments(resident.ID, time.Now())
+		if err != nil {
+			h.Logger.Errorf("failed to get appointments of resident %s: %v", resident.ID, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["appointments"] = list
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// RotateStaffFeed gives the staff member a new calendar link, the previous one stops working.
+func (h *AppointmentsHandler) RotateStaffFeed() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		member, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		h.rotateFeed(c, responseJSON, appointments.OwnerStaff, strconv.Itoa(member.ID))
+	}
+}
+
+// RotateResidentFeed gives the resident a new calendar link, the previous one stops working.
+func (h *AppointmentsHandler) RotateResidentFeed() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortAppointmentError(c, responseJSON, err)
+			return
+		}
+
+		h.rotateFeed(c, responseJSON, appointments.OwnerResident, resident.ID)
+	}
+}
+
+func (h *AppointmentsHandler) rotateFeed(c *gin.Context, responseJSON gin.H, kind appointments.OwnerKind, ownerID string) {
+	token, err := h.AppointmentsRepo.RotateFeedToken(kind, ownerID)
+	if err != nil {
+		h.Logger.Errorf("failed to rotate feed of %s %s: %v", kind, ownerID, err)
+		h.abortAppointmentError(c, responseJSON, err)
+		return
+	}
+
+	responseJSON["url"] = feedURL(c, token)
+	responseJSON["message"] = "keep the link private, anyone with it sees your visits"
+	c.JSON(http.StatusOK, responseJSON)
+}
+
+// GetCalendarFeed serves the .ics feed, calendar apps can not log in, so the secret link is the only credential.
+func (h *AppointmentsHandler) GetCalendarFeed() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+		feed, err := h.AppointmentsRepo.GetFeedOwner(token)
+		if err != nil {
+			if !errors.Is(err, appointments.ErrFeedNotFound) {
+				h.Logger.Errorf("failed to find calendar feed: %v", err)
+			}
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		since := time.Now().Add(-pastAppointmentsInFeed)
+
+		var list []*appointments.Appointment
+		switch feed.OwnerKind {
+		case appointments.OwnerStaff:
+			staffMemberID, errConv := strconv.Atoi(feed.OwnerID)
+			if errConv != nil {
+				c.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+			list, err = h.AppointmentsRepo.GetStaffAppointments(staffMemberID, since)
+		default:
+			list, err = h.AppointmentsRepo.GetResidentAppointments(feed.OwnerID, since)
+		}
+		if err != nil {
+			h.Logger.Errorf("failed to get appointments of %s %s: %v", feed.OwnerKind, feed.OwnerID, err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Header("Content-Type", "text/calendar; charset=utf-8")
+		c.Header("Content-Disposition", `inline; filename="visits.ics"`)
+		c.Status(http.StatusOK)
+		if err := appointments.WriteCalendar(c.Writer, "HOA visits", list); err != nil {
+			h.Logger.Errorf("failed to write calendar feed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+	"go.uber.org/zap"
+)
+
+const (
+	maxLineItemDescription = 200
+	statementFontFamily    = "statement"
+
+	// accountantContextKey holds the *company.StaffMember RequireAccountant let through
+	accountantContextKey = "accountant"
+)
+
+// BillingHandler serves request line items, their approval by accountants and the residents' statements.
+// FontBytes is a TTF with Cyrillic glyphs for the PDF, without it the statement falls back to a core font.
+type BillingHandler struct {
+	BillingRepo   billing.BillingRepo
+	StaffRepo     company.StaffRepo
+	ResidentsRepo residence.ResidentsController
+	RequestsRepo  requests.RequestRepo
+	FontBytes     []byte
+	Logger        *zap.SugaredLogger
+}
+
+// callerStaffMember is the staff member making the request, any failure is already answered.
+func (h *BillingHandler) callerStaffMember(c *gin.Context, responseJSON gin.H) (*company.StaffMember, bool) {
+	phone := c.GetString("phoneNumber")
+
+	member, err := h.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+	if err != nil {
+		h.Logger.Errorf("failed to get staff member %s: %v", phone, err)
+
+		if errors.Is(err, company.ErrStaffMemberNotFound) {
+			responseJSON["error"] = "no permission"
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+		} else {
+			responseJSON["error"] = "failed to get staff member"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+		}
+		return nil, false
+	}
+
+	return member, true
+}
+
+// RequireAccountant lets through only staff members granted the accountant role.
+func (h *BillingHandler) RequireAccountant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		member, ok := h.callerStaffMember(c, responseJSON)
+		if !ok {
+			return
+		}
+
+		isAccountant, err := h.BillingRepo.IsAccountant(member.ID)
+		if err != nil {
+			responseJSON["error"] = "failed to check accountant role"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+		if !isAccountant {
+			responseJSON["error"] = "accountant role required"
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		c.Set(accountantContextKey, member)
+		c.Next()
+	}
+}
+
+func (h *BillingHandler) abortBillingError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, billing.ErrLineItemNotFound), errors.Is(err, requests.ErrNoRequestsFound),
+		errors.Is(err, billing.ErrAccountantNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, billing.ErrAlreadyReviewed):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, billing.ErrSelfReview):
+		c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+func (h *BillingHandler) GetRequestCosts() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		requestID := c.Query("requestID")
+		if requestID == "" {
+			responseJSON["error"] = "requestID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		items, err := h.BillingRepo.GetLineItems(requestID)
+		if err != nil {
+			h.Logger.Errorf("failed to get line items of request %s: %v", requestID, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["items"] = items
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// AddRequestCost records a line item waiting for an accountant, the payer defaults to the one usual for the request type.
+func (h *BillingHandler) AddRequestCost() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		requestID := c.PostForm("requestID")
+		kind := billing.ItemKind(c.PostForm("kind"))
+		description := strings.TrimSpace(c.PostForm("description"))
+		quantity, errQty := utils.ParseFinite(c.PostForm("quantity"))
+		unitPrice, errPrice := utils.ParseFinite(c.PostForm("unitPrice"))
+
+		if requestID == "" || !kind.IsValid() || description == "" || len(description) > maxLineItemDescription ||
+			errQty != nil || quantity <= 0 || errPrice != nil || unitPrice < 0 {
+			responseJSON["error"] = "requestID, valid kind, description, positive quantity and non-negative unitPrice are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		request, err := h.RequestsRepo.GetByID(requestID)
+		if err != nil {
+			h.Logger.Errorf("failed to get request %s: %v", requestID, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		payer := billing.DefaultPayer(request.RequestType)
+		if payerStr := c.PostForm("payer"); payerStr != "" {
+			payer = billing.Payer(payerStr)
+			if !payer.IsValid() {
+				responseJSON["error"] = "invalid payer"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
+		item, err := h.BillingRepo.AddLineItem(billing.NewLineItem{
+			RequestID:   request.ID,
+			Kind:        kind,
+			Description: description,
+			Quantity:    quantity,
+			UnitPrice:   unitPrice,
+			Payer:       payer,
+			CreatedBy:   c.GetString("phoneNumber"),
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to add line item to request %s: %v", request.ID, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["item"] = item
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *BillingHandler) DeleteRequestCost() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.Param("id")
+
+		if err := h.BillingRepo.DeleteLineItem(id); err != nil {
+			h.Logger.Errorf("failed to delete line item %s: %v", id, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *BillingHandler) GetPendingCosts() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		items, total, err := h.BillingRepo.GetPendingLineItems(limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get pending line items: %v", err)
+			responseJSON["error"] = "failed to get pending line items"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["items"] = items
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *BillingHandler) ReviewCost() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		decision := c.PostForm("decision")
+		comment := strings.TrimSpace(c.PostForm("comment"))
+
+		if id == "" || (decision != "approve" && decision != "reject") || len(comment) > maxLineItemDescription {
+			responseJSON["error"] = "id and decision (approve or reject) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		reviewer := c.GetString("phoneNumber")
+
+		if err := h.BillingRepo.ReviewLineItem(id, reviewer, decision == "approve", comment); err != nil {
+			h.Logger.Errorf("failed to review line item %s by %s: %v", id, reviewer, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("line item %s reviewed by %s: %s", id, reviewer, decision)
+		responseJSON["message"] = "reviewed"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GrantAccountant runs behind RequireAccountant, the first accountant is seeded at startup, see
+// billing.SeedAccountant.
+func (h *BillingHandler) GrantAccountant() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.PostForm("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		caller := c.MustGet(accountantContextKey).(*company.StaffMember)
+		if caller.ID == staffMemberID {
+			responseJSON["error"] = "the accountant role can not be granted to yourself"
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		member, err := h.StaffRepo.GetStaffMemberByID(staffMemberID)
+		if err != nil {
+			h.Logger.Infof("grant accountant to %d: %v", staffMemberID, err)
+			if errors.Is(err, company.ErrStaffMemberNotFound) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+				return
+			}
+			responseJSON["error"] = "failed to get staff member"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+		if member.Status == company.StatusInactive {
+			responseJSON["error"] = "the staff member is dismissed"
+			c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+			return
+		}
+
+		if err := h.BillingRepo.GrantAccountant(staffMemberID); err != nil {
+			h.Logger.Errorf("failed to grant accountant to %d: %v", staffMemberID, err)
+			responseJSON["error"] = "failed to grant accountant role"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		h.Logger.Infof("accountant role granted to staff member %d by %d", staffMemberID, caller.ID)
+		responseJSON["message"] = "granted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *BillingHandler) RevokeAccountant() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.BillingRepo.RevokeAccountant(staffMemberID); err != nil {
+			h.Logger.Errorf("failed to revoke accountant from %d: %v", staffMemberID, err)
+			h.abortBillingError(c, responseJSON, err)
+			return
+		}
+
+		caller := c.MustGet(accountantContextKey).(*company.StaffMember)
+		h.Logger.Infof("accountant role revoked from staff member %d by %d", staffMemberID, caller.ID)
+
+		responseJSON["message"] = "revoked"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetResidentStatement builds the statement of any resident by residentID.
+func (h *BillingHandler) GetResidentStatement() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		residentID := c.Query("residentID")
+		if residentID == "" {
+			responseJSON["error"] = "residentID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		h.respondWithStatement(c, responseJSON, residentID)
+	}
+}
+
+// GetMyStatement builds the statement of the logged in resident.
+func (h *BillingHandler) GetMyStatement() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+
+			if errors.Is(err, residence.ErrResidentNotFound) {
+				responseJSON["error"] = "resident not found"
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				responseJSON["error"] = "failed to get resident"
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		h.respondWithStatement(c, responseJSON, resident.ID)
+	}
+}
+
+// statementPeriod reads "from" and "to" as inclusive dates, by default the current month is taken.
+func statementPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, fromStr, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, toStr, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !to.After(from) {
+		return from, to, errors.New("to is before from")
+	}
+
+	return from, to, nil
+}
+
+func (h *BillingHandler) respondWithStatement(c *gin.Context, responseJSON gin.H, residentID string) {
+	from, to, err := statementPeriod(c)
+	if err != nil {
+		responseJSON["error"] = "from and to must be dates in YYYY-MM-DD format, from not after to"
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+		return
+	}
+
+	statement, err := h.BillingRepo.GetResidentStatement(residentID, from, to)
+	if err != nil {
+		h.Logger.Errorf("failed to get statement of resident %s: %v", residentID, err)
+		responseJSON["error"] = "failed to get statement"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+		return
+	}
+
+	if c.Query("format") != "pdf" {
+		responseJSON["statement"] = statement
+		c.JSON(http.StatusOK, responseJSON)
+		return
+	}
+
+	pdf := h.renderStatement(statement)
+
+	fileName := fmt.Sprintf("statement_%s_%s.pdf", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly))
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+
+	if err = pdf.Output(c.Writer); err != nil {
+		h.Logger.Errorf("failed to render statement of resident %s: %v", residentID, err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
+
+func (h *BillingHandler) renderStatement(statement *billing.Statement) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+
+	family := "Helvetica"
+	if len(h.FontBytes) > 0 {
+		pdf.AddUTF8FontFromBytes(statementFontFamily, "", h.FontBytes)
+		family = statementFontFamily
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont(family, "", 14)
+	pdf.CellFormat(0, 8, "Statement of billable repairs", "", 1, "L", false, 0, "")
+
+	pdf.SetFont(family, "", 10)
+	pdf.CellFormat(0, 6, "Resident: "+statement.ResidentID, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Period: %s — %s",
+		statement.From.Format(time.DateOnly), statement.To.AddDate(0, 0, -1).Format(time.DateOnly)), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	widths := []float64{24, 30, 64, 18, 24, 30}
+	headers := []string{"Date", "Kind", "Description", "Qty", "Price", "Amount"}
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for _, line := range statement.Lines {
+		description := line.Description
+		if len([]rune(description)) > 38 {
+			description = string([]rune(description)[:37]) + "…"
+		}
+
+		pdf.CellFormat(widths[0], 6, line.CreatedAt.Format(time.DateOnly), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, string(line.Kind), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 6, description, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], 6, strconv.FormatFloat(line.Quantity, 'f', 2, 64), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, strconv.FormatFloat(line.UnitPrice, 'f', 2, 64), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[5], 6, strconv.FormatFloat(line.Amount, 'f', 2, 64), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(4)
+	for _, kind := range []billing.ItemKind{billing.KindMaterials, billing.KindLabor, billing.KindContractorInvoice} {
+		if total, ok := statement.TotalsByKind[kind]; ok {
+			pdf.CellFormat(0, 6, fmt.Sprintf("%s: %.2f", kind, total), "", 1, "R", false, 0, "")
+		}
+	}
+	pdf.CellFormat(0, 7, fmt.Sprintf("Total: %.2f", statement.Total), "", 1, "R", false, 0, "")
+
+	return pdf
+}
+
+// recordContractorInvoice puts the contractor's invoice in line for an accountant, a failure here does not undo
+// the completion, the item can still be added by hand.
+func recordContractorInvoice(repo billing.BillingRepo, logger *zap.SugaredLogger, request *requests.Request, amount float64, author string) {
+	if repo == nil || amount <= 0 {
+		return
+	}
+
+	_, err := repo.AddLineItem(billing.NewLineItem{
+		RequestID:   request.ID,
+		Kind:        billing.KindContractorInvoice,
+		Description: "contractor invoice",
+		Quantity:    1,
+		UnitPrice:   amount,
+		Payer:       billing.DefaultPayer(request.RequestType),
+		CreatedBy:   author,
+	})
+	if err != nil {
+		logger.Errorf("failed to add contractor invoice to request %s: %v", request.ID, err)
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/categories"
+	"DBPrototyping/pkg/requests"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxCategoryTitle = 100
+
+// CategoriesHandler manages the tree of request categories, residents pick a category when reporting a problem.
+type CategoriesHandler struct {
+	CategoriesRepo categories.CategoriesRepo
+	Logger         *zap.SugaredLogger
+}
+
+func abortCategoryError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, categories.ErrCategoryNotFound), errors.Is(err, categories.ErrSpecializationNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, categories.ErrCategoryInactive), errors.Is(err, categories.ErrNonPositiveSLA):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	case errors.Is(err, categories.ErrDuplicateTitle):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// categoryForm reads the fields shared by creating and editing a category, specializations come comma separated.
+func categoryForm(c *gin.Context) (title string, slaHours *int, specializationIDs []string, ok bool) {
+	title = strings.TrimSpace(c.PostForm("title"))
+	if title == "" || len([]rune(title)) > maxCategoryTitle {
+		return "", nil, nil, false
+	}
+
+	if slaStr := strings.TrimSpace(c.PostForm("slaHours")); slaStr != "" {
+		sla, err := strconv.Atoi(slaStr)
+		if err != nil || sla <= 0 {
+			return "", nil, nil, false
+		}
+		slaHours = &sla
+	}
+
+	for _, id := range strings.Split(c.PostForm("specializationIDs"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			specializationIDs = append(specializationIDs, id)
+		}
+	}
+
+	return title, slaHours, specializationIDs, true
+}
+
+// GetTree gives staff the whole tree, hidden categories included with inactive=true.
+func (h *CategoriesHandler) GetTree() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		includeInactive, _ := strconv.ParseBool(c.Query("inactive"))
+
+		groups, err := h.CategoriesRepo.GetTree(includeInactive)
+		if err != nil {
+			h.Logger.Errorf("failed to get categories: %v", err)
+			abortCategoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["groups"] = groups
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetActiveTree is the tree residents choose from.
+func (h *CategoriesHandler) GetActiveTree() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		groups, err := h.CategoriesRepo.GetTree(false)
+		if err != nil {
+			h.Logger.Errorf("failed to get categories: %v", err)
+			abortCategoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["groups"] = groups
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *CategoriesHandler) CreateCategory() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		title, slaHours, specializationIDs, ok := categoryForm(c)
+		parentID := strings.TrimSpace(c.PostForm("parentID"))
+		group := requests.RequestType(c.PostForm("type"))
+
+		// a subcategory takes the type of its parent, a top category needs one
+		if !ok || (parentID == "" && !group.IsValid()) {
+			responseJSON["error"] = "title (up to 100 characters), a positive slaHours or none, and a type or parentID are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		newCategory := categories.NewCategory{
+			Group:             group,
+			Title:             title,
+			SLAHours:          slaHours,
+			SpecializationIDs: specializationIDs,
+		}
+		if parentID != "" {
+			newCategory.ParentID = &parentID
+		}
+
+		category, err := h.CategoriesRepo.CreateCategory(newCategory)
+		if err != nil {
+			h.Logger.Errorf("failed to create category %s: %v", title, err)
+			abortCategoryError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("category %s %s created by %s", category.ID, category.Title, c.GetString("phoneNumber"))
+
+		responseJSON["category"] = category
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *CategoriesHandler) UpdateCategory() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		title, slaHours, specializationIDs, ok := categoryForm(c)
+		if id == "" || !ok {
+			responseJSON["error"] = "id, title (up to 100 characters) and a positive slaHours or none are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		err := h.CategoriesRepo.UpdateCategory(id, categories.CategoryUpdate{
+			Title:             title,
+			SLAHours:          slaHours,
+			SpecializationIDs: specializationIDs,
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to update category %s: %v", id, err)
+			abortCategoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "category updated"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *CategoriesHandler) SetCategoryActive() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		active, errActive := strconv.ParseBool(c.PostForm("active"))
+		if id == "" || errActive != nil {
+			responseJSON["error"] = "id and active are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.CategoriesRepo.SetActive(id, active); err != nil {
+			h.Logger.Errorf("failed to set category %s active %t: %v", id, active, err)
+			abortCategoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "category updated"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -2,27 +2,45 @@ package handlers
 
 import (
 	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/ratings"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/session"
 	"DBPrototyping/pkg/utils"
 	"errors"
 	"net/http"
+	"net/mail"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type StaffHandler struct {
-	StaffRepo company.StaffRepo
-	Logger    *zap.SugaredLogger
+	StaffRepo      company.StaffRepo
+	RatingsRepo    ratings.RatingsRepo
+	PhoneRenamer   credentials.PhoneRenamer
+	SessionManager session.GinSessionManagerRepo
+	Logger         *zap.SugaredLogger
 }
 
 func (h *StaffHandler) GetLeastBusyByJobID() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		jobIDStr := c.Query("jobID")
+		jobIDStr := c.PostForm("jobID")
 
 		responseJSON := gin.H{}
 
-		staffMember, errFindMember := h.StaffRepo.FindLeastBusyByJobID(jobIDStr)
+		// an emergency is given to whoever has the fewest emergencies, the ordinary queue does not hold it back
+		priority := requests.RequestPriority(c.DefaultPostForm("priority", string(requests.PriorityNormal)))
+		if !priority.IsValid() {
+			responseJSON["error"] = "invalid priority"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		staffMember, errFindMember := h.StaffRepo.FindLeastBusyByJobID(jobIDStr, priority)
 
 		if errFindMember != nil {
 			h.Logger.Errorf("failed to find least busy by jobID: %v", errFindMember)
@@ -298,3 +316,220 @@ func (h *StaffHandler) UpdateOrganizationName() func(c *gin.Context) {
 		c.JSON(http.StatusOK, responseJSON)
 	}
 }
+
+func (h *StaffHandler) GetOrganizationInfo() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.Query("organizationID")
+		if orgID == "" {
+			responseJSON["error"] = "organizationID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		org, err := h.StaffRepo.GetOrganizationByID(orgID)
+		if err != nil {
+			h.Logger.Errorf("failed to get organization %s: %v", orgID, err)
+			responseJSON["error"] = "failed to get organization"
+
+			if errors.Is(err, company.ErrOrganizationNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["organization"] = org
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) UpdateOrganizationContacts() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.PostForm("organizationID")
+		if orgID == "" {
+			responseJSON["error"] = "organizationID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		contacts := company.OrganizationContacts{
+			ContactPerson: strings.TrimSpace(c.PostForm("contactPerson")),
+			Email:         strings.TrimSpace(c.PostForm("email")),
+		}
+
+		if phone := strings.TrimSpace(c.PostForm("phoneNumber")); phone != "" {
+			normalized, errPhone := credentials.NormalizePhone(phone)
+			if errPhone != nil {
+				responseJSON["error"] = errPhone.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			contacts.Phone = normalized
+		}
+
+		if contacts.Email != "" {
+			if _, errMail := mail.ParseAddress(contacts.Email); errMail != nil {
+				responseJSON["error"] = "invalid email"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
+		if err := h.StaffRepo.UpdateOrganizationContacts(orgID, contacts); err != nil {
+			h.Logger.Errorf("failed to update contacts of organization %s: %v", orgID, err)
+			responseJSON["error"] = "failed to update contacts"
+
+			if errors.Is(err, company.ErrOrganizationNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// SetOrganizationCategories replaces the served request types with the repeated "category" form values.
+func (h *StaffHandler) SetOrganizationCategories() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.PostForm("organizationID")
+		if orgID == "" {
+			responseJSON["error"] = "organizationID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		values := c.PostFormArray("category")
+		categories := make([]requests.RequestType, 0, len(values))
+		for _, value := range values {
+			category := requests.RequestType(value)
+			if !category.IsValid() {
+				responseJSON["error"] = "unknown category: " + value
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			categories = append(categories, category)
+		}
+
+		if err := h.StaffRepo.SetOrganizationCategories(orgID, categories); err != nil {
+			h.Logger.Errorf("failed to set categories of organization %s: %v", orgID, err)
+			responseJSON["error"] = "failed to set categories"
+
+			if errors.Is(err, company.ErrOrganizationNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) AddOrganizationContract() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.PostForm("organizationID")
+		number := strings.TrimSpace(c.PostForm("number"))
+		startsAt, errStart := time.Parse(time.DateOnly, c.PostForm("startsAt"))
+
+		if orgID == "" || number == "" || errStart != nil {
+			responseJSON["error"] = "organizationID, number and startsAt (YYYY-MM-DD) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var endsAt *time.Time
+		if endsStr := c.PostForm("endsAt"); endsStr != "" {
+			parsed, errEnd := time.Parse(time.DateOnly, endsStr)
+			if errEnd != nil || parsed.Before(startsAt) {
+				responseJSON["error"] = "endsAt must be a date not before startsAt"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			endsAt = &parsed
+		}
+
+		contract, err := h.StaffRepo.AddOrganizationContract(orgID, number, startsAt, endsAt)
+		if err != nil {
+			h.Logger.Errorf("failed to add contract to organization %s: %v", orgID, err)
+			responseJSON["error"] = "failed to add contract"
+
+			if errors.Is(err, company.ErrOrganizationNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["contract"] = contract
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) DeactivateOrganizationContract() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.Query("organizationID")
+		contractID := c.Param("id")
+		if orgID == "" || contractID == "" {
+			responseJSON["error"] = "organizationID and contract id are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.StaffRepo.DeactivateOrganizationContract(orgID, contractID); err != nil {
+			h.Logger.Errorf("failed to deactivate contract %s: %v", contractID, err)
+			responseJSON["error"] = "failed to deactivate contract"
+
+			if errors.Is(err, company.ErrContractNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) GetRepresentatives() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		orgID := c.Query("organizationID")
+		if orgID == "" {
+			responseJSON["error"] = "organizationID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		reps, err := h.StaffRepo.GetRepresentatives(orgID)
+		if err != nil {
+			h.Logger.Errorf("failed to get representatives of organization %s: %v", orgID, err)
+			responseJSON["error"] = "failed to get representatives"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["representatives"] = reps
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/complaints"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxComplaintText = 2000
+	maxComplaintNote = 1000
+)
+
+// ComplaintsHandler serves the residents' complaints about living together, noise or parking, and their review
+// by staff. The reporter is known only to staff, the resident a complaint is about only sees the warnings.
+type ComplaintsHandler struct {
+	ComplaintsRepo complaints.ComplaintsRepo
+	ResidentsRepo  residence.ResidentsController
+	Logger         *zap.SugaredLogger
+}
+
+func (h *ComplaintsHandler) abortComplaintError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, complaints.ErrComplaintNotFound), errors.Is(err, residence.ErrResidentNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, complaints.ErrInvalidTransition):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, complaints.ErrWarningNeedsTarget), errors.Is(err, complaints.ErrNotInHouse),
+		errors.Is(err, complaints.ErrSelfComplaint):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// reporterView is the reporter's own complaint, the resident it is about and the warning text stay with staff.
+func reporterView(complaint *complaints.ComplaintView) gin.H {
+	return gin.H{
+		"ID":         complaint.ID,
+		"HouseID":    complaint.HouseID,
+		"Address":    complaint.Address,
+		"Kind":       complaint.Kind.Code(),
+		"Text":       complaint.Text,
+		"Apartment":  complaint.Apartment,
+		"Status":     complaint.Status.Code(),
+		"CreatedAt":  complaint.CreatedAt,
+		"ReviewedAt": complaint.ReviewedAt,
+		"WarningAt":  complaint.WarningAt,
+		"ResolvedAt": complaint.ResolvedAt,
+		"Resolution": complaint.Resolution,
+	}
+}
+
+// staffView is the whole complaint as staff see it, the kind and the status go by their machine codes.
+func staffView(complaint *complaints.Complaint) gin.H {
+	return gin.H{
+		"ID":                complaint.ID,
+		"ReporterID":        complaint.ReporterID,
+		"HouseID":           complaint.HouseID,
+		"Kind":              complaint.Kind.Code(),
+		"Text":              complaint.Text,
+		"Apartment":         complaint.Apartment,
+		"AccusedResidentID": complaint.AccusedResidentID,
+		"Status":            complaint.Status.Code(),
+		"CreatedAt":         complaint.CreatedAt,
+		"HandledBy":         complaint.HandledBy,
+		"ReviewedAt":        complaint.ReviewedAt,
+		"WarningAt":         complaint.WarningAt,
+		"WarningText":       complaint.WarningText,
+		"ResolvedAt":        complaint.ResolvedAt,
+		"Resolution":        complaint.Resolution,
+	}
+}
+
+// parseComplaintKind takes a machine code or a stored value of the complaint kind.
+func parseComplaintKind(value string) (complaints.ComplaintKind, bool) {
+	if kind, ok := complaints.ComplaintKindByCode(value); ok {
+		return kind, true
+	}
+	kind := complaints.ComplaintKind(value)
+	return kind, kind.IsValid()
+}
+
+// parseComplaintStatus takes a machine code or a stored value of the complaint status.
+func parseComplaintStatus(value string) (complaints.ComplaintStatus, bool) {
+	if status, ok := complaints.ComplaintStatusByCode(value); ok {
+		return status, true
+	}
+	status := complaints.ComplaintStatus(value)
+	return status, status.IsValid()
+}
+
+func (h *ComplaintsHandler) CreateComplaint() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		houseID, errHouse := strconv.Atoi(c.PostForm("houseID"))
+		kind, kindValid := parseComplaintKind(c.PostForm("kind"))
+		text := strings.TrimSpace(c.PostForm("text"))
+		apartment := strings.TrimSpace(c.PostForm("apartment"))
+
+		if errHouse != nil || !kindValid || text == "" || len([]rune(text)) > maxComplaintText ||
+			len([]rune(apartment)) > maxApartmentLength {
+			responseJSON["error"] = "houseID, kind and text up to 2000 characters are required, the apartment is up to 10 characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		isValid, errValidating := h.ResidentsRepo.ValidateResidentHouse(resident.ID, houseID)
+		if !isValid || errValidating != nil {
+			h.Logger.Errorf("failed to validate house %d of resident %s: %v", houseID, resident.ID, errValidating)
+			responseJSON["error"] = "no permission to complain in house " + strconv.Itoa(houseID)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		newComplaint := complaints.NewComplaint{
+			ReporterID: resident.ID,
+			HouseID:    houseID,
+			Kind:       kind,
+			Text:       text,
+		}
+		if apartment != "" {
+			newComplaint.Apartment = &apartment
+		}
+
+		complaint, err := h.ComplaintsRepo.CreateComplaint(newComplaint)
+		if err != nil {
+			h.Logger.Errorf("failed to create complaint of resident %s: %v", resident.ID, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["complaint"] = reporterView(&complaints.ComplaintView{Complaint: *complaint})
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ComplaintsHandler) GetMyComplaints() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		list, total, err := h.ComplaintsRepo.GetByFilter(complaints.ComplaintFilter{
+			ReporterID: &resident.ID,
+			Limit:      limit,
+			Offset:     (page - 1) * limit,
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to get complaints of resident %s: %v", resident.ID, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		views := make([]gin.H, len(list))
+		for i, complaint := range list {
+			views[i] = reporterView(complaint)
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		responseJSON["complaints"] = views
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetMyWarnings lists the warnings staff gave the resident after complaints about them.
+func (h *ComplaintsHandler) GetMyWarnings() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		warnings, err := h.ComplaintsRepo.GetWarningsFor(resident.ID)
+		if err != nil {
+			h.Logger.Errorf("failed to get warnings of resident %s: %v", resident.ID, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		views := make([]gin.H, len(warnings))
+		for i, warning := range warnings {
+			views[i] = gin.H{
+				"ComplaintID": warning.ComplaintID,
+				"HouseID":     warning.HouseID,
+				"Kind":        warning.Kind.Code(),
+				"Text":        warning.Text,
+				"IssuedAt":    warning.IssuedAt,
+				"Resolved":    warning.Resolved,
+			}
+		}
+
+		responseJSON["warnings"] = views
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ComplaintsHandler) GetComplaints() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		filter := complaints.ComplaintFilter{
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+		}
+
+		if houseStr := c.Query("houseID"); houseStr != "" {
+			if houseID, err := strconv.Atoi(houseStr); err == nil {
+				filter.HouseID = &houseID
+			} else {
+				h.Logger.Debugf("ignore invalid houseID filter: %s", houseStr)
+			}
+		}
+		if kindStr := c.Query("kind"); kindStr != "" {
+			if kind, ok := parseComplaintKind(kindStr); ok {
+				filter.Kind = &kind
+			} else {
+				h.Logger.Debugf("ignore invalid complaint kind filter: %s", kindStr)
+			}
+		}
+		if statusStr := c.Query("status"); statusStr != "" {
+			if status, ok := parseComplaintStatus(statusStr); ok {
+				filter.Status = &status
+			} else {
+				h.Logger.Debugf("ignore invalid complaint status filter: %s", statusStr)
+			}
+		}
+
+		list, total, err := h.ComplaintsRepo.GetByFilter(filter)
+		if err != nil {
+			h.Logger.Errorf("failed to get complaints: %v", err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		views := make([]gin.H, len(list))
+		for i, complaint := range list {
+			view := staffView(&complaint.Complaint)
+			view["Address"] = complaint.Address
+			view["ReporterName"] = complaint.ReporterName
+			view["AccusedName"] = complaint.AccusedName
+			views[i] = view
+		}
+
+		responseJSON["complaints"] = views
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// MoveComplaint takes the complaint a step on, the note is the warning text or the resolution.
+func (h *ComplaintsHandler) MoveComplaint() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		status, statusValid := parseComplaintStatus(c.PostForm("status"))
+		note := strings.TrimSpace(c.PostForm("note"))
+		accusedID := strings.TrimSpace(c.PostForm("accusedResidentID"))
+
+		if id == "" || !statusValid || len([]rune(note)) > maxComplaintNote {
+			responseJSON["error"] = "id, status and a note up to 1000 characters are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		review := complaints.Review{
+			To:         status,
+			StaffPhone: c.GetString("phoneNumber"),
+			Note:       note,
+		}
+		if accusedID != "" {
+			review.AccusedResidentID = &accusedID
+		}
+
+		complaint, err := h.ComplaintsRepo.Move(id, review)
+		if err != nil {
+			h.Logger.Errorf("failed to move complaint %s to %s: %v", id, status, err)
+			h.abortComplaintError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("complaint %s moved to %s by %s", id, status, review.StaffPhone)
+
+		responseJSON["complaint"] = staffView(complaint)
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
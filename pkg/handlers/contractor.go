@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxUpdateLength = 2000
+
+// ContractorHandler serves the portal of organization representatives. Every request they touch is checked
+// against their organization, a foreign request is reported as missing.
+type ContractorHandler struct {
+	RequestsRepo requests.RequestRepo
+	StaffRepo    company.StaffRepo
+	BillingRepo  billing.BillingRepo
+	Logger       *zap.SugaredLogger
+}
+
+func (h *ContractorHandler) representative(c *gin.Context, responseJSON gin.H) (*company.OrganizationRepresentative, bool) {
+	phone := c.GetString("phoneNumber")
+
+	rep, err := h.StaffRepo.GetRepresentativeByPhone(phone)
+	if err != nil {
+		h.Logger.Errorf("failed to get representative %s: %v", phone, err)
+
+		if errors.Is(err, company.ErrRepresentativeNotFound) {
+			responseJSON["error"] = "no permission"
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+		} else {
+			responseJSON["error"] = "failed to get representative"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+		}
+		return nil, false
+	}
+
+	return rep, true
+}
+
+// ownRequest loads the request from the "id" form or query value if it is transferred to the caller's organization.
+func (h *ContractorHandler) ownRequest(c *gin.Context, responseJSON gin.H, id string) (*company.OrganizationRepresentative, *requests.Request, bool) {
+	rep, ok := h.representative(c, responseJSON)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if id == "" {
+		responseJSON["error"] = "id is required"
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+		return nil, nil, false
+	}
+
+	request, err := h.RequestsRepo.GetByID(id)
+	if err != nil && !errors.Is(err, requests.ErrNoRequestsFound) {
+		h.Logger.Errorf("failed to get request %s: %v", id, err)
+		responseJSON["error"] = "failed to get request"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+		return nil, nil, false
+	}
+
+	if request == nil || request.OrganizationID == nil || *request.OrganizationID != rep.OrganizationID {
+		responseJSON["error"] = requests.ErrNoRequestsFound.Error()
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+		return nil, nil, false
+	}
+
+	return rep, request, true
+}
+
+func (h *ContractorHandler) abortFlowError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, requests.ErrNoRequestsFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, requests.ErrRequestClosed), errors.Is(err, requests.ErrNotTransferred),
+		errors.Is(err, requests.ErrAlreadyAccepted), errors.Is(err, requests.ErrNotAccepted):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+func (h *ContractorHandler) GetRequests() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		rep, ok := h.representative(c, responseJSON)
+		if !ok {
+			return
+		}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		filter := requests.RequestFilter{
+			OrganizationScope: &rep.OrganizationID,
+			Limit:             limit,
+			Offset:            (page - 1) * limit,
+			Sort:              c.Query("sort"),
+		}
+
+		if statusStr := c.Query("status"); statusStr != "" {
+			reqStatus := requests.RequestStatus(statusStr)
+			if reqStatus.IsValid() {
+				filter.Status = &reqStatus
+			} else {
+				h.Logger.Debugf("ignore invalid request status filter: %s", statusStr)
+			}
+		}
+
+		requestsList, total, err := h.RequestsRepo.GetByFilter(filter)
+		if err != nil {
+			h.Logger.Errorf("failed to get requests of organization %s: %v", rep.OrganizationID, err)
+			responseJSON["error"] = "failed to get requests"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["requests"] = requestsList
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ContractorHandler) GetRequestUpdates() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		_, request, ok := h.ownRequest(c, responseJSON, c.Query("id"))
+		if !ok {
+			return
+		}
+
+		updates, err := h.RequestsRepo.GetUpdates(request.ID)
+		if err != nil {
+			h.Logger.Errorf("failed to get updates of request %s: %v", request.ID, err)
+			responseJSON["error"] = "failed to get updates"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["updates"] = updates
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ContractorHandler) AcceptRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		rep, request, ok := h.ownRequest(c, responseJSON, c.PostForm("id"))
+		if !ok {
+			return
+		}
+
+		if err := h.RequestsRepo.RecordContractorAcceptance(request.ID, rep.OrganizationID); err != nil {
+			h.Logger.Errorf("failed to accept request %s by %s: %v", request.ID, rep.Phone, err)
+			h.abortFlowError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("request %s accepted by %s of organization %s", request.ID, rep.Phone, rep.OrganizationID)
+		responseJSON["message"] = "accepted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// DeclineRequest hands the request back to staff, the reason is kept as an update so staff see why.
+func (h *ContractorHandler) DeclineRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		reason := strings.TrimSpace(c.PostForm("reason"))
+		if reason == "" || len(reason) > maxUpdateLength {
+			responseJSON["error"] = "reason is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		rep, request, ok := h.ownRequest(c, responseJSON, c.PostForm("id"))
+		if !ok {
+			return
+		}
+
+		if err := h.RequestsRepo.DeclineTransfer(request.ID, rep.OrganizationID); err != nil {
+			h.Logger.Errorf("failed to decline request %s by %s: %v", request.ID, rep.Phone, err)
+			h.abortFlowError(c, responseJSON, err)
+			return
+		}
+
+		if _, err := h.RequestsRepo.AddUpdate(request.ID, rep.Phone, string(session.ContractorRole), "declined: "+reason); err != nil {
+			h.Logger.Errorf("failed to save decline reason of request %s: %v", request.ID, err)
+		}
+
+		responseJSON["message"] = "declined"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ContractorHandler) PostUpdate() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		text := strings.TrimSpace(c.PostForm("text"))
+		if text == "" || len(text) > maxUpdateLength {
+			responseJSON["error"] = "text is required and must be at most 2000 bytes"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		rep, request, ok := h.ownRequest(c, responseJSON, c.PostForm("id"))
+		if !ok {
+			return
+		}
+
+		if request.Status != requests.StatusTransferred {
+			responseJSON["error"] = requests.ErrNotTransferred.Error()
+			c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+			return
+		}
+
+		update, err := h.RequestsRepo.AddUpdate(request.ID, rep.Phone, string(session.ContractorRole), text)
+		if err != nil {
+			h.Logger.Errorf("failed to add update to request %s: %v", request.ID, err)
+			responseJSON["error"] = "failed to add update"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["update"] = update
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ContractorHandler) CompleteRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		report := strings.TrimSpace(c.PostForm("report"))
+		cost, errCost := utils.ParseFinite(c.PostForm("cost"))
+
+		if report == "" || len(report) > maxUpdateLength || errCost != nil || cost < 0 {
+			responseJSON["error"] = "report and a non-negative cost are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		rep, request, ok := h.ownRequest(c, responseJSON, c.PostForm("id"))
+		if !ok {
+			return
+		}
+
+		if err := h.RequestsRepo.RecordContractorCompletion(request.ID, rep.OrganizationID, report, cost); err != nil {
+			h.Logger.Errorf("failed to complete request %s by %s: %v", request.ID, rep.Phone, err)
+			h.abortFlowError(c, responseJSON, err)
+			return
+		}
+
+		recordContractorInvoice(h.BillingRepo, h.Logger, request, cost, rep.Phone)
+
+		h.Logger.Infof("request %s completed by %s with cost %.2f", request.ID, rep.Phone, cost)
+		responseJSON["message"] = "completed"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
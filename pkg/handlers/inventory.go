@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/inventory"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxInventoryName    = 100
+	maxInventoryUnit    = 20
+	maxMovementComment  = 200
+	maxRequestMaterials = 100
+)
+
+// InventoryHandler keeps the storeroom: items, their stock at the storage locations, the materials used
+// for requests and the movement report.
+type InventoryHandler struct {
+	InventoryRepo inventory.InventoryRepo
+	Logger        *zap.SugaredLogger
+}
+
+func (h *InventoryHandler) abortInventoryError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, inventory.ErrItemNotFound), errors.Is(err, inventory.ErrLocationNotFound),
+		errors.Is(err, inventory.ErrMovementNotFound), errors.Is(err, requests.ErrNoRequestsFound),
+		errors.Is(err, residence.ErrNoHouseFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, inventory.ErrInsufficientStock), errors.Is(err, inventory.ErrLocationNotEmpty),
+		errors.Is(err, inventory.ErrItemInactive), errors.Is(err, inventory.ErrAlreadyReturned),
+		errors.Is(err, inventory.ErrDuplicateName), errors.Is(err, inventory.ErrRequestCancelled),
+		errors.Is(err, requests.ErrMergedRequest), errors.Is(err, billing.ErrAlreadyReviewed):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, inventory.ErrNonPositiveQuantity), errors.Is(err, inventory.ErrSameLocation),
+		errors.Is(err, inventory.ErrNotUsage):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// alertLowStock reports an item that fell to its minimum, the staff see it on the inventory page as well.
+func (h *InventoryHandler) alertLowStock(stock *inventory.ItemStock) {
+	if stock != nil && stock.Low {
+		h.Logger.Warnf("inventory item %s (%s) is low on stock: %.2f %s left, minimum %.2f",
+			stock.ID, stock.Name, stock.Total, stock.Unit, stock.MinStock)
+	}
+}
+
+func (h *InventoryHandler) GetLocations() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		locations, err := h.InventoryRepo.GetLocations()
+		if err != nil {
+			h.Logger.Errorf("failed to get storage locations: %v", err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["locations"] = locations
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *InventoryHandler) CreateLocation() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		name := strings.TrimSpace(c.PostForm("name"))
+		if name == "" || len([]rune(name)) > maxInventoryName {
+			responseJSON["error"] = "name up to 100 characters is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var houseID *int
+		if houseStr := c.PostForm("houseID"); houseStr != "" {
+			parsed, err := strconv.Atoi(houseStr)
+			if err != nil {
+				responseJSON["error"] = "invalid houseID"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			houseID = &parsed
+		}
+
+		location, err := h.InventoryRepo.CreateLocation(name, houseID)
+		if err != nil {
+			h.Logger.Errorf("failed to create storage location %q: %v", name, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["location"] = location
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *InventoryHandler) DeleteLocation() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.Param("id")
+		if err := h.InventoryRepo.DeleteLocation(id); err != nil {
+			h.Logger.Errorf("failed to delete storage location %s: %v", id, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetStock lists the items with their stock, "low=true" keeps only the ones running low.
+func (h *InventoryHandler) GetStock() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		lowOnly, _ := strconv.ParseBool(c.Query("low"))
+		includeInactive, _ := strconv.ParseBool(c.Query("inactive"))
+		filter := inventory.StockFilter{
+			Search:          strings.TrimSpace(c.Query("search")),
+			LowOnly:         lowOnly,
+			IncludeInactive: includeInactive,
+			Limit:           limit,
+			Offset:          (page - 1) * limit,
+		}
+		if locationID := c.Query("locationID"); locationID != "" {
+			filter.LocationID = &locationID
+		}
+
+		items, total, err := h.InventoryRepo.GetStock(filter)
+		if err != nil {
+			h.Logger.Errorf("failed to get inventory stock: %v", err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		responseJSON["items"] = items
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// itemFromForm reads the fields shared by the creation and the update of an item.
+func itemFromForm(c *gin.Context) (inventory.NewItem, bool) {
+	name := strings.TrimSpace(c.PostForm("name"))
+	unit := strings.TrimSpace(c.PostForm("unit"))
+	unitPrice, errPrice := utils.ParseFinite(c.PostForm("unitPrice"))
+
+	minStock := 0.0
+	var errMin error
+	if minStr := c.PostForm("minStock"); minStr != "" {
+		minStock, errMin = utils.ParseFinite(minStr)
+	}
+
+	item := inventory.NewItem{
+		Name:      name,
+		Unit:      unit,
+		UnitPrice: unitPrice,
+		MinStock:  minStock,
+	}
+
+	valid := name != "" && len([]rune(name)) <= maxInventoryName && unit != "" && len([]rune(unit)) <= maxInventoryUnit &&
+		errPrice == nil && unitPrice >= 0 && errMin == nil && minStock >= 0
+
+	return item, valid
+}
+
+func (h *InventoryHandler) CreateItem() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		newItem, ok := itemFromForm(c)
+		if !ok {
+			responseJSON["error"] = "name, unit, non-negative unitPrice and minStock are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		item, err := h.InventoryRepo.CreateItem(newItem)
+		if err != nil {
+			h.Logger.Errorf("failed to create inventory item %q: %v", newItem.Name, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["item"] = item
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// UpdateItem also archives an item with "active=false", an archived item keeps its stock and history
+// but can not be received or used any more.
+func (h *InventoryHandler) UpdateItem() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		newItem, ok := itemFromForm(c)
+		active, errActive := strconv.ParseBool(c.DefaultPostForm("active", "true"))
+		if id == "" || !ok || errActive != nil {
+			responseJSON["error"] = "id, name, unit, non-negative unitPrice and minStock are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		item := &inventory.Item{
+			ID:        id,
+			Name:      newItem.Name,
+			Unit:      newItem.Unit,
+			UnitPrice: newItem.UnitPrice,
+			MinStock:  newItem.MinStock,
+			IsActive:  active,
+		}
+
+		if err := h.InventoryRepo.UpdateItem(item); err != nil {
+			h.Logger.Errorf("failed to update inventory item %s: %v", id, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "updated"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// movementForm reads the item, the location and the quantity every stock operation takes.
+func movementForm(c *gin.Context) (string, string, float64, string, bool) {
+	itemID := c.PostForm("itemID")
+	locationID := c.PostForm("locationID")
+	quantity, errQty := utils.ParseFinite(c.PostForm("quantity"))
+	comment := strings.TrimSpace(c.PostForm("comment"))
+
+	valid := itemID != "" && locationID != "" && errQty == nil && quantity > 0 && len([]rune(comment)) <= maxMovementComment
+
+	return itemID, locationID, quantity, comment, valid
+}
+
+func (h *InventoryHandler) Receive() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		itemID, locationID, quantity, comment, ok := movementForm(c)
+		if !ok {
+			responseJSON["error"] = "itemID, locationID and positive quantity are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		movement, err := h.InventoryRepo.Receive(itemID, locationID, quantity, c.GetString("phoneNumber"), comment)
+		if err != nil {
+			h.Logger.Errorf("failed to receive item %s at %s: %v", itemID, locationID, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["movement"] = movement
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// WriteOff takes off the stock what was lost or broken, the comment tells why.
+func (h *InventoryHandler) WriteOff() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		itemID, locationID, quantity, comment, ok := movementForm(c)
+		if !ok || comment == "" {
+			responseJSON["error"] = "itemID, locationID, positive quantity and comment are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		movement, stock, err := h.InventoryRepo.WriteOff(itemID, locationID, quantity, c.GetString("phoneNumber"), comment)
+		if err != nil {
+			h.Logger.Errorf("failed to write off item %s at %s: %v", itemID, locationID, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		h.alertLowStock(stock)
+
+		responseJSON["movement"] = movement
+		responseJSON["stock"] = stock
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *InventoryHandler) Transfer() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		itemID, fromLocationID, quantity, _, ok := movementForm(c)
+		toLocationID := c.PostForm("toLocationID")
+		if !ok || toLocationID == "" {
+			responseJSON["error"] = "itemID, locationID, toLocationID and positive quantity are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.InventoryRepo.Transfer(itemID, fromLocationID, toLocationID, quantity, c.GetString("phoneNumber")); err != nil {
+			h.Logger.Errorf("failed to move item %s from %s to %s: %v", itemID, fromLocationID, toLocationID, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "moved"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetRequestMaterials lists the materials taken for a request, the returned ones included.
+func (h *InventoryHandler) GetRequestMaterials() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		requestID := c.Query("requestID")
+		if requestID == "" {
+			responseJSON["error"] = "requestID is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		kind := inventory.MovementUsage
+		materials, _, err := h.InventoryRepo.GetMovements(inventory.MovementFilter{
+			RequestID: &requestID,
+			Kind:      &kind,
+			Limit:     maxRequestMaterials,
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to get materials of request %s: %v", requestID, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["materials"] = materials
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// UseMaterials records the materials the executor took for a request, their cost goes to the request's
+// line items for an accountant to approve. The payer defaults to the one usual for the request type.
+func (h *InventoryHandler) UseMaterials() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		requestID := c.PostForm("requestID")
+		itemID, locationID, quantity, _, ok := movementForm(c)
+		payer := billing.Payer(c.PostForm("payer"))
+		if requestID == "" || !ok || (payer != "" && !payer.IsValid()) {
+			responseJSON["error"] = "requestID, itemID, locationID and positive quantity are required, payer is optional"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		usage, err := h.InventoryRepo.UseForRequest(inventory.MaterialUsage{
+			RequestID:  requestID,
+			ItemID:     itemID,
+			LocationID: locationID,
+			Quantity:   quantity,
+			Payer:      payer,
+			Author:     c.GetString("phoneNumber"),
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to use item %s for request %s: %v", itemID, requestID, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		h.alertLowStock(usage.Stock)
+
+		responseJSON["movement"] = usage.Movement
+		responseJSON["item"] = usage.LineItem
+		responseJSON["stock"] = usage.Stock
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *InventoryHandler) ReturnMaterials() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		if id == "" {
+			responseJSON["error"] = "id is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.InventoryRepo.ReturnFromRequest(id, c.GetString("phoneNumber")); err != nil {
+			h.Logger.Errorf("failed to return materials of movement %s: %v", id, err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "returned"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *InventoryHandler) GetMovements() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		filter := inventory.MovementFilter{
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+		}
+		if itemID := c.Query("itemID"); itemID != "" {
+			filter.ItemID = &itemID
+		}
+		if locationID := c.Query("locationID"); locationID != "" {
+			filter.LocationID = &locationID
+		}
+		if requestID := c.Query("requestID"); requestID != "" {
+			filter.RequestID = &requestID
+		}
+		if kindStr := c.Query("kind"); kindStr != "" {
+			kind := inventory.MovementKind(kindStr)
+			if !kind.IsValid() {
+				responseJSON["error"] = "invalid kind"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			filter.Kind = &kind
+		}
+		if c.Query("from") != "" || c.Query("to") != "" {
+			from, to, err := statementPeriod(c)
+			if err != nil {
+				responseJSON["error"] = "from and to must be dates in YYYY-MM-DD format, from not after to"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			filter.From = &from
+			filter.To = &to
+		}
+
+		movements, total, err := h.InventoryRepo.GetMovements(filter)
+		if err != nil {
+			h.Logger.Errorf("failed to get stock movements: %v", err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		responseJSON["movements"] = movements
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetMovementReport sums up the movements per item for a period, the current month by default.
+func (h *InventoryHandler) GetMovementReport() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		from, to, err := statementPeriod(c)
+		if err != nil {
+			responseJSON["error"] = "from and to must be dates in YYYY-MM-DD format, from not after to"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var locationID *string
+		if locationStr := c.Query("locationID"); locationStr != "" {
+			locationID = &locationStr
+		}
+
+		rows, err := h.InventoryRepo.GetMovementReport(from, to, locationID)
+		if err != nil {
+			h.Logger.Errorf("failed to build stock movement report: %v", err)
+			h.abortInventoryError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["from"] = from
+		responseJSON["to"] = to
+		responseJSON["rows"] = rows
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/announcements"
+	"DBPrototyping/pkg/appointments"
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/complaints"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/inventory"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/userdata/signup"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResolveLanguage chooses the interface language of the request: the one the user picked, otherwise the browser's.
+// It has to run after the session and token middlewares, which tell who the user is. The pick is read from the
+// database once per session, see session.CachedLanguage.
+func (h *UserHandler) ResolveLanguage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/static/") {
+			c.Next()
+			return
+		}
+
+		lang := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+
+		if phone := c.GetString("phoneNumber"); phone != "" {
+			preferred, cached := session.CachedLanguage(c)
+			if !cached {
+				var err error
+				preferred, err = h.UserRepo.GetLanguage(phone)
+				if err != nil {
+					h.Logger.Warnf("get language of %s: %v", phone, err)
+				} else if errCache := session.CacheLanguage(c, preferred); errCache != nil {
+					h.Logger.Warnf("cache language of %s: %v", phone, errCache)
+				}
+			}
+			if i18n.Lang(preferred).IsValid() {
+				lang = i18n.Lang(preferred)
+			}
+		}
+
+		c.Set(i18n.ContextKey, string(lang))
+		c.Next()
+	}
+}
+
+// SetMyLanguage stores the interface language of the current user, an empty one goes back to the browser's.
+func (h *UserHandler) SetMyLanguage() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phone := c.GetString("phoneNumber")
+		language := strings.TrimSpace(c.PostForm("language"))
+
+		responseJSON := gin.H{}
+
+		if language != "" && !i18n.Lang(language).IsValid() {
+			responseJSON["error"] = "unsupported language"
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.UserRepo.SetLanguage(phone, language); err != nil {
+			if errors.Is(err, userdata.ErrUserNotFound) {
+				responseJSON["error"] = "user not found"
+
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+				return
+			}
+			h.Logger.Errorf("set language of %s: %v", phone, err)
+			responseJSON["error"] = "internal error"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		if err := session.CacheLanguage(c, language); err != nil {
+			h.Logger.Warnf("cache language of %s: %v", phone, err)
+		}
+
+		responseJSON["language"] = language
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// enumLabels translates the values of every enum the pages show, grouped the same way as the i18n keys, so that
+// scripts can label values they get from the web API. Complaints are served by their machine codes, so their groups
+// are keyed by code, the others by the stored value. The weekday names and the texts of the scripts come along.
+func enumLabels(lang i18n.Lang) map[string]map[string]string {
+	return map[string]map[string]string{
+		i18n.GroupRequestStatus:   labelsByValue(lang, i18n.GroupRequestStatus, requests.RequestStatuses),
+		i18n.GroupRequestType:     labelsByValue(lang, i18n.GroupRequestType, requests.RequestTypes),
+		i18n.GroupRequestPriority: labelsByValue(lang, i18n.GroupRequestPriority, requests.RequestPriorities),
+		i18n.GroupStaffStatus:     labelsByValue(lang, i18n.GroupStaffStatus, company.StaffMemberStatuses),
+		i18n.GroupAbsenceKind:     labelsByValue(lang, i18n.GroupAbsenceKind, company.AbsenceKinds),
+		i18n.GroupSignupStatus:    labelsByValue(lang, i18n.GroupSignupStatus, signup.Statuses),
+		i18n.GroupContactChannel:  labelsByValue(lang, i18n.GroupContactChannel, residence.ContactChannels),
+		i18n.GroupLinkStatus:      labelsByValue(lang, i18n.GroupLinkStatus, residence.LinkRequestStatuses),
+		i18n.GroupAnnouncement:    labelsByValue(lang, i18n.GroupAnnouncement, announcements.Kinds),
+		i18n.GroupSlotStatus:      labelsByValue(lang, i18n.GroupSlotStatus, appointments.SlotStatuses),
+		i18n.GroupBillingItem:     labelsByValue(lang, i18n.GroupBillingItem, billing.ItemKinds),
+		i18n.GroupBillingPayer:    labelsByValue(lang, i18n.GroupBillingPayer, billing.Payers),
+		i18n.GroupBillingApproval: labelsByValue(lang, i18n.GroupBillingApproval, billing.ApprovalStatuses),
+		i18n.GroupMovementKind:    labelsByValue(lang, i18n.GroupMovementKind, inventory.MovementKinds),
+		i18n.GroupComplaintKind:   labelsByCode(lang, i18n.GroupComplaintKind, complaints.ComplaintKinds),
+		i18n.GroupComplaintStatus: labelsByCode(lang, i18n.GroupComplaintStatus, complaints.ComplaintStatuses),
+		i18n.GroupWeekday:         i18n.Group(lang, i18n.GroupWeekday),
+		i18n.GroupScript:          i18n.Group(lang, i18n.GroupScript),
+	}
+}
+
+// enumValue is a stored enum value with a machine code.
+type enumValue interface {
+	~string
+	Code() string
+}
+
+// labelsByValue translates the values of an enum group keyed by the stored value.
+func labelsByValue[T enumValue](lang i18n.Lang, group string, values []T) map[string]string {
+	labels := make(map[string]string, len(values))
+	for _, v := range values {
+		labels[string(v)] = i18n.Label(lang, group, v.Code())
+	}
+	return labels
+}
+
+// labelsByCode translates the values of an enum group keyed by the machine code.
+func labelsByCode[T enumValue](lang i18n.Lang, group string, values []T) map[string]string {
+	labels := make(map[string]string, len(values))
+	for _, v := range values {
+		labels[v.Code()] = i18n.Label(lang, group, v.Code())
+	}
+	return labels
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/maintenance"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxPlanTitle       = 100
+	maxPlanDescription = 1000
+	maxRuleLength      = 200
+	previewOccurrences = 5
+)
+
+// MaintenanceHandler manages the recurring maintenance plans of the houses.
+type MaintenanceHandler struct {
+	MaintenanceRepo maintenance.MaintenanceRepo
+	Scheduler       *maintenance.Scheduler
+	Logger          *zap.SugaredLogger
+}
+
+func (h *MaintenanceHandler) abortMaintenanceError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, maintenance.ErrPlanNotFound), errors.Is(err, maintenance.ErrSpecializationNotFound),
+		errors.Is(err, residence.ErrNoHouseFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, maintenance.ErrInvalidRule), errors.Is(err, maintenance.ErrRuleOver):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+func (h *MaintenanceHandler) GetPlans() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		var houseID *int
+		if houseStr := c.Query("houseID"); houseStr != "" {
+			parsed, err := strconv.Atoi(houseStr)
+			if err != nil {
+				responseJSON["error"] = "invalid houseID"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			houseID = &parsed
+		}
+
+		plans, total, err := h.MaintenanceRepo.GetPlans(houseID, limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get maintenance plans: %v", err)
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		responseJSON["plans"] = plans
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// CreatePlan takes the rule in the RRULE syntax, e.g. "FREQ=MONTHLY;BYMONTHDAY=1", and the start in the
+// datetime-local format, the start also gives the time of day of the generated requests.
+func (h *MaintenanceHandler) CreatePlan() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		houseID, errHouse := strconv.Atoi(c.PostForm("houseID"))
+		specializationID := strings.TrimSpace(c.PostForm("specializationID"))
+		title := strings.TrimSpace(c.PostForm("title"))
+		description := strings.TrimSpace(c.PostForm("description"))
+		rule := strings.TrimSpace(c.PostForm("rule"))
+		startsAt, errStart := time.ParseInLocation(announcementTimeLayout, c.PostForm("startsAt"), time.Local)
+
+		if errHouse != nil || specializationID == "" || title == "" || rule == "" || errStart != nil ||
+			len([]rune(title)) > maxPlanTitle || len([]rune(description)) > maxPlanDescription || len(rule) > maxRuleLength {
+			responseJSON["error"] = "houseID, specializationID, title (up to 100 characters), rule and startsAt in YYYY-MM-DDTHH:MM format are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		plan, err := h.MaintenanceRepo.CreatePlan(maintenance.NewPlan{
+			HouseID:          houseID,
+			SpecializationID: specializationID,
+			Title:            title,
+			Description:      description,
+			Rule:             rule,
+			StartsAt:         startsAt,
+			CreatedBy:        c.GetString("phoneNumber"),
+		})
+		if err != nil {
+			h.Logger.Errorf("failed to create maintenance plan for house %d: %v", houseID, err)
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["plan"] = plan
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// PreviewRule shows the next occurrences of a rule, so that staff can check it before saving the plan.
+func (h *MaintenanceHandler) PreviewRule() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		startsAt, errStart := time.ParseInLocation(announcementTimeLayout, c.Query("startsAt"), time.Local)
+		if errStart != nil {
+			responseJSON["error"] = "startsAt in YYYY-MM-DDTHH:MM format is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		rule, err := maintenance.ParseRule(c.Query("rule"))
+		if err != nil {
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		occurrences := make([]time.Time, 0, previewOccurrences)
+		after := startsAt.Add(-time.Second)
+		for len(occurrences) < previewOccurrences {
+			next, ok := rule.Next(startsAt, after)
+			if !ok {
+				break
+			}
+			occurrences = append(occurrences, next)
+			after = next
+		}
+
+		responseJSON["rule"] = rule.String()
+		responseJSON["occurrences"] = occurrences
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *MaintenanceHandler) SetPlanActive() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := strings.TrimSpace(c.PostForm("id"))
+		active, errActive := strconv.ParseBool(c.PostForm("active"))
+		if id == "" || errActive != nil {
+			responseJSON["error"] = "id and active (true or false) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.MaintenanceRepo.SetPlanActive(id, active); err != nil {
+			h.Logger.Errorf("failed to change maintenance plan %s: %v", id, err)
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "updated"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *MaintenanceHandler) DeletePlan() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.Param("id")
+		if err := h.MaintenanceRepo.DeletePlan(id); err != nil {
+			h.Logger.Errorf("failed to delete maintenance plan %s: %v", id, err)
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// RunScheduler generates the due requests now instead of waiting for the next scheduler tick.
+func (h *MaintenanceHandler) RunScheduler() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		generated, err := h.Scheduler.RunOnce(time.Now())
+		if err != nil {
+			h.Logger.Errorf("failed to run maintenance scheduler: %v", err)
+			h.abortMaintenanceError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["generated"] = generated
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/userdata/session"
+	"fmt"
 	"html/template"
 	"net/http"
 
@@ -29,12 +32,39 @@ func (h *PageHandler) InitHTML() {
 		"specializations.tmpl",
 		"admin_houses.tmpl",
 		"admin_organizations.tmpl",
+		"password_reset.tmpl",
+		"change_password.tmpl",
+		"lockouts.tmpl",
+		"two_factor_verify.tmpl",
+		"two_factor_setup.tmpl",
+		"sessions.tmpl",
+		"api_tokens.tmpl",
+		"contractor_requests.tmpl",
+		"billing.tmpl",
+		"calendar.tmpl",
+		"profile.tmpl",
+		"signup.tmpl",
+		"signups.tmpl",
+		"announcements.tmpl",
+		"ratings.tmpl",
+		"maintenance.tmpl",
+		"inventory.tmpl",
+		"categories.tmpl",
+		"complaints.tmpl",
+		"admin_complaints.tmpl",
+	}
+
+	// templates translate with {{t .lang "key"}}, the language comes with the data of every page
+	funcs := template.FuncMap{
+		"t": func(lang any, key string) string {
+			return i18n.T(i18n.Lang(fmt.Sprint(lang)), key)
+		},
 	}
 
 	h.Templates = make(map[string]*template.Template)
 
 	for _, page := range pagesExtendingBase {
-		tmpl := template.Must(template.ParseFiles(
+		tmpl := template.Must(template.New(baseTemplate).Funcs(funcs).ParseFiles(
 			baseTemplatesPath+baseTemplate,
 			baseTemplatesPath+page,
 		))
@@ -43,10 +73,23 @@ func (h *PageHandler) InitHTML() {
 }
 
 func (h *PageHandler) respondWithHTML(c *gin.Context, templateName string, data gin.H) {
+	csrfToken, err := session.EnsureCSRFToken(c)
+	if err != nil {
+		h.Logger.Errorf("start session for page %q, err %s", templateName, err)
+
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	data["csrfToken"] = csrfToken
+	lang := i18n.FromContext(c)
+	data["lang"] = lang
+	data["langs"] = i18n.Langs
+	data["labels"] = enumLabels(lang)
+
 	c.Status(http.StatusOK)
 
 	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := h.Templates[templateName].ExecuteTemplate(c.Writer, templateName, data)
+	err = h.Templates[templateName].ExecuteTemplate(c.Writer, templateName, data)
 
 	if err != nil {
 		h.Logger.Errorf("error rendering page %q, err %s", templateName, err)
@@ -67,7 +110,7 @@ func (h *PageHandler) MainPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "main",
+			"title":       "page.main",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -87,7 +130,7 @@ func (h *PageHandler) AdminRequestsPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "admin requests",
+			"title":       "page.admin_requests",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -107,7 +150,7 @@ func (h *PageHandler) SpecializationsPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "user management",
+			"title":       "page.user_management",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -127,7 +170,7 @@ func (h *PageHandler) HousesPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "houses management",
+			"title":       "page.houses_management",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -147,7 +190,7 @@ func (h *PageHandler) OrganizationsPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "orgs management",
+			"title":       "page.orgs_management",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -167,7 +210,7 @@ func (h *PageHandler) UsersManagerPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "user management",
+			"title":       "page.user_management",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -187,7 +230,7 @@ func (h *PageHandler) LoginPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "main",
+			"title":       "page.main",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -202,7 +245,7 @@ func (h *PageHandler) RegisterPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "Register",
+			"title":       "page.register",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -217,7 +260,7 @@ func (h *PageHandler) CreateRequestPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "main",
+			"title":       "page.main",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -232,7 +275,7 @@ func (h *PageHandler) UserRequestsPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "my requests",
+			"title":       "page.my_requests",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -247,7 +290,7 @@ func (h *PageHandler) AdminPage() gin.HandlerFunc {
 		roleVal, _ := c.Get("role")
 
 		data := gin.H{
-			"title":       "admin panel",
+			"title":       "page.admin_panel",
 			"role":        roleVal,
 			"phoneNumber": phoneVal,
 		}
@@ -255,3 +298,354 @@ func (h *PageHandler) AdminPage() gin.HandlerFunc {
 		h.respondWithHTML(c, "admin.tmpl", data)
 	}
 }
+
+func (h *PageHandler) PasswordResetPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.password_reset",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+			"token":       c.Query("token"),
+		}
+
+		h.respondWithHTML(c, "password_reset.tmpl", data)
+	}
+}
+
+func (h *PageHandler) ChangePasswordPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.change_password",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "change_password.tmpl", data)
+	}
+}
+
+func (h *PageHandler) LockoutsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.login_lockouts",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "lockouts.tmpl", data)
+	}
+}
+
+func (h *PageHandler) TwoFactorVerifyPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.two_factor_authentication",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "two_factor_verify.tmpl", data)
+	}
+}
+
+func (h *PageHandler) TwoFactorSetupPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.two_factor_setup",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "two_factor_setup.tmpl", data)
+	}
+}
+
+func (h *PageHandler) SessionsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.active_sessions",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "sessions.tmpl", data)
+	}
+}
+
+func (h *PageHandler) APITokensPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.api_tokens",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "api_tokens.tmpl", data)
+	}
+}
+
+func (h *PageHandler) ContractorRequestsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.contractor_requests",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "contractor_requests.tmpl", data)
+	}
+}
+
+func (h *PageHandler) BillingPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.billing",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "billing.tmpl", data)
+	}
+}
+
+func (h *PageHandler) CalendarPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.duty_calendar",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "calendar.tmpl", data)
+	}
+}
+
+func (h *PageHandler) ProfilePage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.my_profile",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "profile.tmpl", data)
+	}
+}
+
+func (h *PageHandler) SignupPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.sign_up",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "signup.tmpl", data)
+	}
+}
+
+func (h *PageHandler) SignupsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.sign_ups",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "signups.tmpl", data)
+	}
+}
+
+func (h *PageHandler) AnnouncementsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.announcements",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "announcements.tmpl", data)
+	}
+}
+
+func (h *PageHandler) RatingsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.ratings",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "ratings.tmpl", data)
+	}
+}
+
+func (h *PageHandler) MaintenancePage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.maintenance",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "maintenance.tmpl", data)
+	}
+}
+
+func (h *PageHandler) InventoryPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.inventory",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "inventory.tmpl", data)
+	}
+}
+
+func (h *PageHandler) CategoriesPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.categories",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "categories.tmpl", data)
+	}
+}
+
+func (h *PageHandler) ComplaintsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.my_complaints",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "complaints.tmpl", data)
+	}
+}
+
+func (h *PageHandler) AdminComplaintsPage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+
+		if !exists {
+			c.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		roleVal, _ := c.Get("role")
+
+		data := gin.H{
+			"title":       "page.complaints",
+			"role":        roleVal,
+			"phoneNumber": phoneVal,
+		}
+
+		h.respondWithHTML(c, "admin_complaints.tmpl", data)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/credentials"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultResetTokenTTL = 30 * time.Minute
+
+var ErrPasswordsMismatch = errors.New("new password and its confirmation do not match")
+
+func (h *UserHandler) resetTokenTTL() time.Duration {
+	if h.ResetTokenTTL <= 0 {
+		return defaultResetTokenTTL
+	}
+	return h.ResetTokenTTL
+}
+
+func (h *UserHandler) ChangePassword() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, exists := c.Get("phoneNumber")
+		phoneString, ok := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		if !exists || !ok {
+			responseJSON["error"] = "session error, try to re-login"
+			h.Logger.Errorf("change password phone conversion fail for phone value %v", phoneVal)
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		oldPassword := c.PostForm("oldPassword")
+		newPassword := c.PostForm("newPassword")
+		confirmPassword := c.PostForm("confirmPassword")
+
+		if oldPassword == "" {
+			responseJSON["error"] = "current password is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if errPolicy := h.PasswordPolicy.Validate(newPassword, phoneString); errPolicy != nil {
+			h.Logger.Infof("change password for %s rejected by policy: %v", phoneString, errPolicy)
+			responseJSON["error"] = errPolicy.Error()
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if newPassword != confirmPassword {
+			responseJSON["error"] = ErrPasswordsMismatch.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.UserRepo.ChangePassword(phoneString, oldPassword, newPassword); err != nil {
+			h.Logger.Errorf("change password error for %s: %v", phoneString, err)
+			responseJSON["error"] = "failed to change password"
+
+			if errors.Is(err, userdata.ErrWrongPassword) {
+				responseJSON["error"] = "current password is incorrect"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "password changed"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// RequestPasswordReset is public, so it answers the same way whether the phone number is registered or not.
+func (h *UserHandler) RequestPasswordReset() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil {
+			h.Logger.Infof("request password reset: wrong phone format %s", c.PostForm("phoneNumber"))
+			responseJSON["error"] = errPhone.Error()
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		// every request sends a code, so each one is counted by the login limiter as an attempt on the phone
+		wait, errCheck := h.LoginLimiter.Check(phoneNumber, c.ClientIP())
+		if errCheck != nil {
+			h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+		}
+		if wait > 0 {
+			h.Logger.Infof("password reset for %s from %s rejected, blocked for %s", phoneNumber, c.ClientIP(), wait)
+			abortLoginError(c, responseJSON, ErrTooManyAttempts, wait)
+			return
+		}
+		if _, errFailure := h.LoginLimiter.RegisterFailure(phoneNumber, c.ClientIP()); errFailure != nil {
+			h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+		}
+
+		if err := h.issueResetToken(phoneNumber); err != nil && !errors.Is(err, userdata.ErrUserNotFound) {
+			h.Logger.Errorf("request password reset for %s: %v", phoneNumber, err)
+		}
+
+		responseJSON["message"] = "if the phone number is registered, a reset code has been sent"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) StaffResetPassword() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.issueResetToken(phoneNumber); err != nil {
+			h.Logger.Errorf("staff password reset for %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to issue reset token: " + err.Error()
+
+			if errors.Is(err, userdata.ErrUserNotFound) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "reset token sent to " + phoneNumber
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) ResetPassword() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		token := c.PostForm("token")
+		newPassword := c.PostForm("newPassword")
+		confirmPassword := c.PostForm("confirmPassword")
+
+		responseJSON := gin.H{}
+
+		if token == "" {
+			responseJSON["error"] = "reset code is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if errPolicy := h.PasswordPolicy.Validate(newPassword, ""); errPolicy != nil {
+			h.Logger.Infof("reset password rejected by policy: %v", errPolicy)
+			responseJSON["error"] = errPolicy.Error()
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if newPassword != confirmPassword {
+			responseJSON["error"] = ErrPasswordsMismatch.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		user, err := h.UserRepo.ResetPasswordByToken(token, newPassword)
+		if err != nil {
+			h.Logger.Errorf("reset password error: %v", err)
+			responseJSON["error"] = "failed to reset password"
+
+			if errors.Is(err, userdata.ErrInvalidToken) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		h.Logger.Infof("password reset by token for %s", user.Phone)
+		responseJSON["message"] = "password has been reset, you can log in now"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) issueResetToken(phoneNumber string) error {
+	token, resetToken, err := h.UserRepo.CreateResetToken(phoneNumber, h.resetTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	return h.ResetSender.SendResetToken(resetToken.Phone, token, resetToken.ExpiresAt)
+}
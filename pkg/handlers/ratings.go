@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/ratings"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxRatingComment = 1000
+
+// RatingsHandler serves the residents' ratings of completed requests and their aggregates for the staff.
+type RatingsHandler struct {
+	RatingsRepo   ratings.RatingsRepo
+	ResidentsRepo residence.ResidentsController
+	Logger        *zap.SugaredLogger
+}
+
+func (h *RatingsHandler) abortRatingError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, requests.ErrNoRequestsFound), errors.Is(err, residence.ErrResidentNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, ratings.ErrRequestNotCompleted):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, ratings.ErrInvalidScore):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// ratingPeriod reads optional "from" and "to" as inclusive dates, a missing bound leaves the period open.
+func ratingPeriod(c *gin.Context) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, fromStr, time.Local)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, toStr, time.Local)
+		if err != nil {
+			return nil, nil, err
+		}
+		next := parsed.AddDate(0, 0, 1)
+		to = &next
+	}
+
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, nil, errors.New("to is before from")
+	}
+
+	return from, to, nil
+}
+
+// RateRequest leaves or changes the rating of the resident's own completed request.
+func (h *RatingsHandler) RateRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		phone := c.GetString("phoneNumber")
+
+		requestID := strings.TrimSpace(c.PostForm("requestID"))
+		score, errScore := strconv.Atoi(c.PostForm("score"))
+		commentStr := strings.TrimSpace(c.PostForm("comment"))
+
+		if requestID == "" || errScore != nil || score < ratings.MinScore || score > ratings.MaxScore || len([]rune(commentStr)) > maxRatingComment {
+			responseJSON["error"] = "requestID and a score from 1 to 5 are required, the comment is up to 1000 characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+		if err != nil {
+			h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+			h.abortRatingError(c, responseJSON, err)
+			return
+		}
+
+		var comment *string
+		if commentStr != "" {
+			comment = &commentStr
+		}
+
+		rating, err := h.RatingsRepo.Rate(requestID, resident.ID, score, comment)
+		if err != nil {
+			h.Logger.Errorf("failed to rate request %s by resident %s: %v", requestID, resident.ID, err)
+			h.abortRatingError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["rating"] = rating
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetRatingAnalytics groups the ratings by staff member, specialization or organization, staff by default.
+func (h *RatingsHandler) GetRatingAnalytics() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		groupBy := ratings.ByStaffMember
+		if groupStr := c.Query("groupBy"); groupStr != "" {
+			groupBy = ratings.GroupBy(groupStr)
+			if !groupBy.IsValid() {
+				responseJSON["error"] = "groupBy must be staff, specialization or organization"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
+		from, to, err := ratingPeriod(c)
+		if err != nil {
+			responseJSON["error"] = "from and to must be dates in YYYY-MM-DD format, from not after to"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		total, err := h.RatingsRepo.GetTotal(from, to)
+		if err != nil {
+			h.Logger.Errorf("failed to summarize ratings: %v", err)
+			h.abortRatingError(c, responseJSON, err)
+			return
+		}
+
+		groups, err := h.RatingsRepo.Summarize(groupBy, from, to)
+		if err != nil {
+			h.Logger.Errorf("failed to summarize ratings by %s: %v", groupBy, err)
+			h.abortRatingError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["groupBy"] = groupBy
+		responseJSON["total"] = total
+		responseJSON["groups"] = groups
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetRatings lists single ratings newest first, maxScore helps to find the unhappy residents.
+func (h *RatingsHandler) GetRatings() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		from, to, err := ratingPeriod(c)
+		if err != nil {
+			responseJSON["error"] = "from and to must be dates in YYYY-MM-DD format, from not after to"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		filter := ratings.RatingFilter{
+			From:   from,
+			To:     to,
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+		}
+
+		if staffStr := c.Query("staffMemberID"); staffStr != "" {
+			staffMemberID, errConv := strconv.Atoi(staffStr)
+			if errConv != nil {
+				responseJSON["error"] = "invalid staffMemberID"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			filter.StaffMemberID = &staffMemberID
+		}
+		if organizationID := c.Query("organizationID"); organizationID != "" {
+			filter.OrganizationID = &organizationID
+		}
+		if maxStr := c.Query("maxScore"); maxStr != "" {
+			maxScore, errConv := strconv.Atoi(maxStr)
+			if errConv != nil {
+				responseJSON["error"] = "invalid maxScore"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			filter.MaxScore = &maxScore
+		}
+
+		list, total, err := h.RatingsRepo.GetRatings(filter)
+		if err != nil {
+			h.Logger.Errorf("failed to get ratings: %v", err)
+			h.abortRatingError(c, responseJSON, err)
+			return
+		}
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": utils.CountPages(total, limit),
+		}
+
+		responseJSON["ratings"] = list
+		responseJSON["meta"] = meta
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
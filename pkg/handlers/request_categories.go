@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/categories"
+	"DBPrototyping/pkg/requests"
+	"time"
+)
+
+// moveToCategory changes the category of the request, the deadline is counted anew from the creation of the
+// request with the SLA of the new category.
+func (h *RequestsHandler) moveToCategory(request *requests.Request, defaults *categories.Defaults) error {
+	var dueAt *time.Time
+	if defaults.SLAHours != nil {
+		due := request.CreatedAt.Add(time.Duration(*defaults.SLAHours) * time.Hour)
+		dueAt = &due
+	}
+
+	categoryID := defaults.Category.ID
+	return h.RequestsRepo.SetCategory(request.ID, &categoryID, dueAt)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/requests"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *RequestsHandler) abortMergeError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, requests.ErrNoRequestsFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, requests.ErrMergedRequest), errors.Is(err, requests.ErrMergeMismatch),
+		errors.Is(err, requests.ErrRequestClosed), errors.Is(err, requests.ErrNotMerged):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, requests.ErrSelfMerge):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// MergeRequests takes childIDs as a comma separated list of the duplicates of parentID.
+func (h *RequestsHandler) MergeRequests() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		parentID := strings.TrimSpace(c.PostForm("parentID"))
+
+		childIDs := make([]string, 0)
+		for _, childID := range strings.Split(c.PostForm("childIDs"), ",") {
+			if childID = strings.TrimSpace(childID); childID != "" {
+				childIDs = append(childIDs, childID)
+			}
+		}
+
+		if parentID == "" || len(childIDs) == 0 {
+			responseJSON["error"] = "parentID and childIDs are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.RequestsRepo.Merge(parentID, childIDs); err != nil {
+			h.Logger.Errorf("failed to merge requests %v into %s: %v", childIDs, parentID, err)
+			h.abortMergeError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("requests %v merged into %s by %s", childIDs, parentID, c.GetString("phoneNumber"))
+		responseJSON["message"] = "merged"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *RequestsHandler) UnmergeRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		if id == "" {
+			responseJSON["error"] = "id is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.RequestsRepo.Unmerge(id); err != nil {
+			h.Logger.Errorf("failed to unmerge request %s: %v", id, err)
+			h.abortMergeError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "unmerged"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
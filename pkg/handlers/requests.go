@@ -1,24 +1,39 @@
 package handlers
 
 import (
+	"DBPrototyping/pkg/announcements"
+	"DBPrototyping/pkg/appointments"
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/categories"
 	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/ratings"
 	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/requests/intake"
 	"DBPrototyping/pkg/residence"
 	"DBPrototyping/pkg/userdata"
 	"DBPrototyping/pkg/utils"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type RequestsHandler struct {
-	RequestsRepo  requests.RequestRepo
-	ResidentsRepo residence.ResidentsController
-	StaffRepo     company.StaffRepo
-	UserRepo      userdata.UserRepo
-	Logger        *zap.SugaredLogger
+	RequestsRepo      requests.RequestRepo
+	ResidentsRepo     residence.ResidentsController
+	StaffRepo         company.StaffRepo
+	UserRepo          userdata.UserRepo
+	BillingRepo       billing.BillingRepo
+	AnnouncementsRepo announcements.AnnouncementsRepo
+	RatingsRepo       ratings.RatingsRepo
+	AppointmentsRepo  appointments.AppointmentsRepo
+	CategoriesRepo    categories.CategoriesRepo
+	Intake            *intake.Service
+	Logger            *zap.SugaredLogger
 }
 
 func (h *RequestsHandler) CreateRequest() func(c *gin.Context) {
@@ -41,8 +56,32 @@ func (h *RequestsHandler) CreateRequest() func(c *gin.Context) {
 
 		requestType := requests.RequestType(c.PostForm("requestType"))
 		complaint := c.PostForm("complaint")
+		joinRequestID := c.PostForm("joinRequestID")
+		// the resident only suggests how urgent the problem is, the priority itself is set by staff
+		suggestedPriority := requests.RequestPriority(c.PostForm("priority"))
+
+		// the category gives the type of the request, a type sent along has to be the same
+		categoryID := c.PostForm("categoryID")
+		var defaults *categories.Defaults
+		if categoryID != "" {
+			var errCategory error
+			defaults, errCategory = h.CategoriesRepo.GetDefaults(categoryID)
+			if errCategory != nil {
+				h.Logger.Infof("create request with category %s: %v", categoryID, errCategory)
+				abortCategoryError(c, responseJSON, errCategory)
+				return
+			}
+			if requestType == "" {
+				requestType = defaults.Category.Group
+			} else if requestType != defaults.Category.Group {
+				responseJSON["error"] = "the category belongs to the other request type"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
 
-		if houseIDString == "" || errHouseIDConversion != nil || !requestType.IsValid() || complaint == "" {
+		if houseIDString == "" || errHouseIDConversion != nil || !requestType.IsValid() || complaint == "" ||
+			(suggestedPriority != "" && !suggestedPriority.IsValid()) {
 			responseJSON["error"] = "proper request type and complaint are required"
 			h.Logger.Infof("create request type and complaint are required, but wrong info provided, %s %s %s %s", houseIDString, requestType, complaint, responseJSON)
 
@@ -70,23 +109,81 @@ func (h *RequestsHandler) CreateRequest() func(c *gin.Context) {
 			return
 		}
 
+		if joinRequestID == "" && c.PostForm("confirmOutage") != "on" {
+			if outages := h.Intake.ActiveOutages(houseID); len(outages) > 0 {
+				responseJSON["error"] = intake.OutageWarning(i18n.FromContext(c), outages[0])
+				responseJSON["outages"] = outages
+				responseJSON["confirm"] = "confirmOutage"
+
+				c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+				return
+			}
+		}
+
+		if joinRequestID == "" && c.PostForm("confirmDuplicate") != "on" {
+			if similar := h.Intake.SimilarOpen(houseID, requestType, complaint); len(similar) > 0 {
+				duplicates := make([]gin.H, len(similar))
+				for i, s := range similar {
+					duplicates[i] = gin.H{
+						"ID":         s.ID,
+						"Complaint":  s.Complaint,
+						"Status":     s.Status,
+						"CreatedAt":  s.CreatedAt,
+						"Similarity": s.Similarity,
+						"Reporters":  s.Reporters,
+					}
+				}
+
+				responseJSON["error"] = i18n.T(i18n.FromContext(c), "similar requests are already open in this house, join one of them or send yours anyway")
+				responseJSON["duplicates"] = duplicates
+				responseJSON["confirm"] = "confirmDuplicate"
+
+				c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+				return
+			}
+		}
+
 		requestData := requests.InitialRequestData{
 			ResidentID:  resident.ID,
 			HouseID:     houseID,
 			RequestType: requestType,
 			Complaint:   complaint,
 		}
+		if joinRequestID != "" {
+			requestData.ParentID = &joinRequestID
+		}
+		if suggestedPriority != "" {
+			requestData.SuggestedPriority = &suggestedPriority
+		}
+		if defaults != nil {
+			requestData.CategoryID = &categoryID
+			requestData.SLAHours = defaults.SLAHours
+		}
 
 		request, errCreatingRequest := h.RequestsRepo.CreateRequest(requestData)
 
 		if errCreatingRequest != nil {
 			h.Logger.Errorf("failed to create request: %v", errCreatingRequest)
-			responseJSON["error"] = "failed to create request"
 
-			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			switch {
+			case errors.Is(errCreatingRequest, requests.ErrNoRequestsFound):
+				responseJSON["error"] = "request to join not found"
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			case errors.Is(errCreatingRequest, requests.ErrRequestClosed), errors.Is(errCreatingRequest, requests.ErrMergeMismatch):
+				responseJSON["error"] = "can not join this request: " + errCreatingRequest.Error()
+				c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+			default:
+				responseJSON["error"] = "failed to create request"
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
 			return
 		}
 
+		// a duplicate follows its parent, only a request of its own goes to the staff of the category
+		if defaults != nil && request.ParentID == nil {
+			h.Intake.AssignByCategory(request, defaults.SpecializationIDs)
+		}
+
 		h.Logger.Infof("created request: %v", request)
 
 		c.JSON(http.StatusOK, request)
@@ -124,6 +221,29 @@ func (h *RequestsHandler) GetRequestsForUser() func(c *gin.Context) {
 			return
 		}
 
+		requestIDs := make([]string, len(userRequests))
+		for i, request := range userRequests {
+			requestIDs[i] = request.ID
+		}
+
+		requestRatings, errRatings := h.RatingsRepo.GetByRequestIDs(requestIDs)
+		if errRatings != nil {
+			h.Logger.Errorf("failed to get ratings of user requests: %v", errRatings)
+			responseJSON["error"] = "failed to get userRequests"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		requestSlots, errSlots := h.AppointmentsRepo.GetSlotsByRequestIDs(requestIDs)
+		if errSlots != nil {
+			h.Logger.Errorf("failed to get visit slots of user requests: %v", errSlots)
+			responseJSON["error"] = "failed to get userRequests"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
 		pages := utils.CountPages(total, limit)
 
 		meta := gin.H{
@@ -134,6 +254,8 @@ func (h *RequestsHandler) GetRequestsForUser() func(c *gin.Context) {
 		}
 
 		responseJSON["requests"] = userRequests
+		responseJSON["ratings"] = requestRatings
+		responseJSON["slots"] = requestSlots
 		responseJSON["meta"] = meta
 
 		c.JSON(http.StatusOK, responseJSON)
@@ -161,9 +283,36 @@ func (h *RequestsHandler) GetRequestsForAdmin() func(c *gin.Context) {
 		if organizationID := c.Query("organizationID"); organizationID != "" {
 			filter.OrganizationID = &organizationID
 		}
+		if parentID := c.Query("parentID"); parentID != "" {
+			filter.ParentID = &parentID
+		}
+		if planned, err := strconv.ParseBool(c.Query("planned")); err == nil {
+			filter.Planned = &planned
+		}
 		if complaint := c.Query("complaint"); complaint != "" {
 			filter.Complaint = &complaint
 		}
+		if overdue, err := strconv.ParseBool(c.Query("overdue")); err == nil {
+			filter.Overdue = overdue
+		}
+		// a category stands for its whole branch
+		if categoryID := c.Query("categoryID"); categoryID != "" {
+			categoryIDs, err := h.CategoriesRepo.GetSubtreeIDs(categoryID)
+			if err != nil {
+				h.Logger.Debugf("failed to expand category filter %s: %v", categoryID, err)
+				abortCategoryError(c, gin.H{}, err)
+				return
+			}
+			filter.CategoryIDs = categoryIDs
+		}
+		if priorityStr := c.Query("priority"); priorityStr != "" {
+			priority := requests.RequestPriority(priorityStr)
+			if priority.IsValid() {
+				filter.Priority = &priority
+			} else {
+				h.Logger.Debugf("ignore invalid priority filter: %s", priorityStr)
+			}
+		}
 
 		if reqTypeString := c.Query("type"); reqTypeString != "" {
 			reqType := requests.RequestType(reqTypeString)
@@ -238,8 +387,12 @@ func (h *RequestsHandler) UpdateRequest() func(c *gin.Context) {
 		reqStatus := requests.RequestStatus(c.PostForm("status"))
 		respIDStr := c.PostForm("respID")
 		organizationIDStr := c.PostForm("organizationID")
+		priority := requests.RequestPriority(c.PostForm("priority"))
+		categoryID := c.PostForm("categoryID")
 
-		if id == "" || residentID == "" || errConvertHouse != nil || !reqType.IsValid() || !reqStatus.IsValid() {
+		// residentID stays empty for planned requests, an empty value leaves the column as it is
+		if id == "" || errConvertHouse != nil || !reqType.IsValid() || !reqStatus.IsValid() ||
+			(priority != "" && !priority.IsValid()) {
 			responseJSON["error"] = "invalid request"
 			h.Logger.Debugf("ignore invalid request")
 			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
@@ -253,10 +406,11 @@ func (h *RequestsHandler) UpdateRequest() func(c *gin.Context) {
 			HouseID:     houseID,
 			RequestType: reqType,
 			Status:      reqStatus,
+			Priority:    priority,
 		}
 
 		if costStr != "" {
-			if costVal, err := strconv.ParseFloat(costStr, 64); err == nil {
+			if costVal, err := utils.ParseFinite(costStr); err == nil && costVal >= 0 {
 				requestUpdates.Cost = &costVal
 			} else {
 				responseJSON["error"] = "invalid cost"
@@ -282,17 +436,71 @@ func (h *RequestsHandler) UpdateRequest() func(c *gin.Context) {
 			requestUpdates.OrganizationID = &organizationIDStr
 		}
 
+		current, errCurrent := h.RequestsRepo.GetByID(id)
+		if errCurrent != nil {
+			h.Logger.Errorf("failed to get request %s to update: %v", id, errCurrent)
+			responseJSON["error"] = "failed to update request"
+			if errors.Is(errCurrent, requests.ErrNoRequestsFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		if err := requests.CheckNoTransfer(current, reqStatus, requestUpdates.OrganizationID); err != nil {
+			responseJSON["error"] = err.Error() + ", use the Transfer action"
+			c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+			return
+		}
+
+		// an empty category or the one the request already has is left as it is, even when hidden since
+		var defaults *categories.Defaults
+		if current.CategoryID != nil && *current.CategoryID == categoryID {
+			categoryID = ""
+		}
+		if categoryID != "" {
+			var errCategory error
+			defaults, errCategory = h.CategoriesRepo.GetDefaults(categoryID)
+			if errCategory != nil {
+				h.Logger.Infof("update request %s with category %s: %v", id, categoryID, errCategory)
+				abortCategoryError(c, responseJSON, errCategory)
+				return
+			}
+			if defaults.Category.Group != reqType {
+				responseJSON["error"] = "the category belongs to the other request type"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
 		h.Logger.Infof("update request payload: %v", requestUpdates)
 
 		errUpdating := h.RequestsRepo.UpdateRequest(&requestUpdates)
 
 		if errUpdating != nil {
 			h.Logger.Errorf("failed to update request: %v", errUpdating)
+
+			if errors.Is(errUpdating, requests.ErrMergedRequest) {
+				responseJSON["error"] = errUpdating.Error()
+				c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+				return
+			}
+
 			responseJSON["error"] = "failed to update request"
 			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
 			return
 		}
 
+		if defaults != nil {
+			if errCategory := h.moveToCategory(current, defaults); errCategory != nil {
+				h.Logger.Errorf("failed to move request %s to category %s: %v", id, categoryID, errCategory)
+				responseJSON["error"] = "request updated, but the category is not changed"
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				return
+			}
+		}
+
 		responseJSON["message"] = requestUpdates.ResponsibleID
 		c.JSON(http.StatusOK, responseJSON)
 	}
@@ -322,3 +530,162 @@ func (h *RequestsHandler) DeleteRequest() func(c *gin.Context) {
 		c.JSON(http.StatusOK, responseJSON)
 	}
 }
+
+// abortContractorFlowError maps the repo errors of the transfer workflow to statuses.
+func (h *RequestsHandler) abortContractorFlowError(c *gin.Context, responseJSON gin.H, err error) {
+	switch {
+	case errors.Is(err, requests.ErrNoRequestsFound), errors.Is(err, company.ErrOrganizationNotFound):
+		responseJSON["error"] = err.Error()
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, requests.ErrRequestClosed), errors.Is(err, requests.ErrNotTransferred),
+		errors.Is(err, requests.ErrAlreadyAccepted), errors.Is(err, requests.ErrNotAccepted),
+		errors.Is(err, requests.ErrMergedRequest):
+		responseJSON["error"] = err.Error()
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, company.ErrTypeNotCovered), errors.Is(err, company.ErrNoActiveContract):
+		responseJSON["error"] = err.Error()
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+func (h *RequestsHandler) TransferRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		orgID := c.PostForm("organizationID")
+		if id == "" || orgID == "" {
+			responseJSON["error"] = "id and organizationID are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		request, err := h.RequestsRepo.GetByID(id)
+		if err != nil {
+			h.Logger.Errorf("failed to get request %s for transfer: %v", id, err)
+			h.abortContractorFlowError(c, responseJSON, err)
+			return
+		}
+
+		if err = h.StaffRepo.ValidateOrganizationCoverage(orgID, request.RequestType); err != nil {
+			h.Logger.Infof("organization %s can not take request %s: %v", orgID, id, err)
+			h.abortContractorFlowError(c, responseJSON, err)
+			return
+		}
+
+		if err = h.RequestsRepo.TransferToOrganization(id, orgID); err != nil {
+			h.Logger.Errorf("failed to transfer request %s to organization %s: %v", id, orgID, err)
+			h.abortContractorFlowError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("request %s transferred to organization %s", id, orgID)
+		responseJSON["message"] = "transferred"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// currentContractor is the organization a request is transferred to now, staff record the contractor's steps
+// on its behalf and the repo refuses them once the request has moved on.
+func (h *RequestsHandler) currentContractor(c *gin.Context, responseJSON gin.H, id string) (string, bool) {
+	request, err := h.RequestsRepo.GetByID(id)
+	if err != nil {
+		h.Logger.Errorf("failed to get request %s: %v", id, err)
+		h.abortContractorFlowError(c, responseJSON, err)
+		return "", false
+	}
+	if request.OrganizationID == nil {
+		h.abortContractorFlowError(c, responseJSON, requests.ErrNotTransferred)
+		return "", false
+	}
+
+	return *request.OrganizationID, true
+}
+
+func (h *RequestsHandler) RecordContractorAcceptance() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		if id == "" {
+			responseJSON["error"] = "id is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		organizationID, ok := h.currentContractor(c, responseJSON, id)
+		if !ok {
+			return
+		}
+
+		if err := h.RequestsRepo.RecordContractorAcceptance(id, organizationID); err != nil {
+			h.Logger.Errorf("failed to record contractor acceptance of request %s: %v", id, err)
+			h.abortContractorFlowError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "accepted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *RequestsHandler) RecordContractorCompletion() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		report := strings.TrimSpace(c.PostForm("report"))
+		invoice, errInvoice := utils.ParseFinite(c.PostForm("invoiceAmount"))
+
+		if id == "" || report == "" || errInvoice != nil || invoice < 0 {
+			responseJSON["error"] = "id, report and a non-negative invoiceAmount are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		organizationID, ok := h.currentContractor(c, responseJSON, id)
+		if !ok {
+			return
+		}
+
+		if err := h.RequestsRepo.RecordContractorCompletion(id, organizationID, report, invoice); err != nil {
+			h.Logger.Errorf("failed to record contractor completion of request %s: %v", id, err)
+			h.abortContractorFlowError(c, responseJSON, err)
+			return
+		}
+
+		if request, err := h.RequestsRepo.GetByID(id); err == nil {
+			recordContractorInvoice(h.BillingRepo, h.Logger, request, invoice, c.GetString("phoneNumber"))
+		}
+
+		responseJSON["message"] = "completed"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *RequestsHandler) GetRequestUpdates() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.Query("id")
+		if id == "" {
+			responseJSON["error"] = "id is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		updates, err := h.RequestsRepo.GetUpdates(id)
+		if err != nil {
+			h.Logger.Errorf("failed to get updates of request %s: %v", id, err)
+			responseJSON["error"] = "failed to get updates"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["updates"] = updates
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxResidentNameLength = 40
+	maxLinkCommentLength  = 200
+	ownLinkRequestsLimit  = 20
+)
+
+func (h *ResidentsHandler) abortProfileError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, residence.ErrResidentNotFound), errors.Is(err, residence.ErrNoHouseFound),
+		errors.Is(err, residence.ErrLinkRequestNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, residence.ErrAlreadyLinked), errors.Is(err, residence.ErrLinkRequestPending),
+		errors.Is(err, residence.ErrLinkRequestReviewed):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// currentResident finds the resident behind the logged in phone, staff and contractors without a resident
+// profile get 404.
+func (h *ResidentsHandler) currentResident(c *gin.Context, responseJSON gin.H) (*residence.Resident, bool) {
+	phone := c.GetString("phoneNumber")
+
+	resident, err := h.ResidentsRepo.GetResidentByPhoneNumber(phone)
+	if err != nil {
+		h.Logger.Errorf("failed to get resident %s: %v", phone, err)
+		h.abortProfileError(c, responseJSON, err)
+		return nil, false
+	}
+
+	return resident, true
+}
+
+func (h *ResidentsHandler) GetMyProfile() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		resident, ok := h.currentResident(c, responseJSON)
+		if !ok {
+			return
+		}
+
+		houses, err := h.ResidentsRepo.FindResidentHouses(resident.ID)
+		if err != nil {
+			h.Logger.Errorf("failed to get houses of resident %s: %v", resident.ID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		linkRequests, _, err := h.ResidentsRepo.GetHouseLinkRequests(resident.ID, nil, ownLinkRequestsLimit, 0)
+		if err != nil {
+			h.Logger.Errorf("failed to get house link requests of resident %s: %v", resident.ID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["resident"] = resident
+		responseJSON["houses"] = houses
+		responseJSON["linkRequests"] = linkRequests
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// UpdateMyProfile changes the name and contact preferences, the phone number is the login and stays with staff.
+func (h *ResidentsHandler) UpdateMyProfile() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		update := residence.ResidentProfileUpdate{
+			FullName:         strings.TrimSpace(c.PostForm("fullName")),
+			Email:            strings.TrimSpace(c.PostForm("email")),
+			PreferredContact: residence.ContactChannel(c.PostForm("preferredContact")),
+		}
+
+		if update.FullName == "" || len([]rune(update.FullName)) > maxResidentNameLength || !update.PreferredContact.IsValid() {
+			responseJSON["error"] = "full name (up to 40 characters) and a valid preferred contact are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if update.Email != "" {
+			if _, errMail := mail.ParseAddress(update.Email); errMail != nil {
+				responseJSON["error"] = "invalid email"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		} else if update.PreferredContact == residence.ContactEmail {
+			responseJSON["error"] = "email is required to be contacted by email"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		resident, ok := h.currentResident(c, responseJSON)
+		if !ok {
+			return
+		}
+
+		if err := h.ResidentsRepo.UpdateResidentProfile(resident.ID, update); err != nil {
+			h.Logger.Errorf("failed to update profile of resident %s: %v", resident.ID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "updated"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ResidentsHandler) RequestHouseLink() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		houseID, errConv := strconv.Atoi(c.PostForm("houseID"))
+		commentStr := strings.TrimSpace(c.PostForm("comment"))
+
+		if errConv != nil || len(commentStr) > maxLinkCommentLength {
+			responseJSON["error"] = "houseID is required, comment must be at most 200 bytes"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var comment *string
+		if commentStr != "" {
+			comment = &commentStr
+		}
+
+		resident, ok := h.currentResident(c, responseJSON)
+		if !ok {
+			return
+		}
+
+		request, err := h.ResidentsRepo.RequestHouseLink(resident.ID, houseID, comment)
+		if err != nil {
+			h.Logger.Errorf("failed to request link of resident %s to house %d: %v", resident.ID, houseID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["linkRequest"] = request
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetHouseLinkRequests is the staff queue, by default only pending requests are shown.
+func (h *ResidentsHandler) GetHouseLinkRequests() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		status := residence.LinkPending
+		statusFilter := &status
+		if statusStr := c.Query("status"); statusStr == "any" {
+			statusFilter = nil
+		} else if statusStr != "" {
+			status = residence.LinkRequestStatus(statusStr)
+			if !status.IsValid() {
+				responseJSON["error"] = "invalid status"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
+		linkRequests, total, err := h.ResidentsRepo.GetHouseLinkRequests(c.Query("residentID"), statusFilter, limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get house link requests: %v", err)
+			responseJSON["error"] = "failed to get house link requests"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["linkRequests"] = linkRequests
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *ResidentsHandler) ReviewHouseLinkRequest() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		decision := c.PostForm("decision")
+		commentStr := strings.TrimSpace(c.PostForm("comment"))
+
+		if id == "" || (decision != "approve" && decision != "reject") || len(commentStr) > maxLinkCommentLength {
+			responseJSON["error"] = "id and decision (approve or reject) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var comment *string
+		if commentStr != "" {
+			comment = &commentStr
+		}
+
+		reviewer := c.GetString("phoneNumber")
+
+		if err := h.ResidentsRepo.ReviewHouseLinkRequest(id, reviewer, decision == "approve", comment); err != nil {
+			h.Logger.Errorf("failed to review house link request %s: %v", id, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("house link request %s reviewed by %s: %s", id, reviewer, decision)
+		responseJSON["message"] = "reviewed"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
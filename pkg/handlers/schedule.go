@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/company"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAbsenceComment = 200
+
+// parseDayMinute turns "HH:MM" into minutes from midnight.
+func parseDayMinute(value string) (int, bool) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+func (h *StaffHandler) abortScheduleError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, company.ErrStaffMemberNotFound), errors.Is(err, company.ErrShiftNotFound),
+		errors.Is(err, company.ErrAbsenceNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// GetStaffSchedule returns the weekly shifts of a staff member and the absences that are not over yet.
+func (h *StaffHandler) GetStaffSchedule() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		shifts, err := h.StaffRepo.GetWorkSchedule(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get schedule of staff member %d: %v", staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		absences, err := h.StaffRepo.GetAbsences(staffMemberID, time.Now())
+		if err != nil {
+			h.Logger.Errorf("failed to get absences of staff member %d: %v", staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["shifts"] = shifts
+		responseJSON["absences"] = absences
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// SetStaffShift takes weekday as 0 (Sunday) to 6 and start/end as "HH:MM", an end not after the start means a night shift.
+func (h *StaffHandler) SetStaffShift() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.PostForm("staffMemberID"))
+		weekday, errDay := strconv.Atoi(c.PostForm("weekday"))
+		startMinute, okStart := parseDayMinute(c.PostForm("start"))
+		endMinute, okEnd := parseDayMinute(c.PostForm("end"))
+
+		if errConv != nil || errDay != nil || weekday < 0 || weekday > 6 || !okStart || !okEnd {
+			responseJSON["error"] = "staffMemberID, weekday (0-6) and start/end in HH:MM format are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		shift := company.WorkShift{
+			MemberID:    staffMemberID,
+			Weekday:     time.Weekday(weekday),
+			StartMinute: startMinute,
+			EndMinute:   endMinute,
+		}
+
+		if err := h.StaffRepo.SetWorkShift(shift); err != nil {
+			h.Logger.Errorf("failed to set shift of staff member %d: %v", staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["shift"] = shift
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) DeleteStaffShift() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		weekday, errDay := strconv.Atoi(c.Query("weekday"))
+
+		if errConv != nil || errDay != nil || weekday < 0 || weekday > 6 {
+			responseJSON["error"] = "staffMemberID and weekday (0-6) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.StaffRepo.DeleteWorkShift(staffMemberID, time.Weekday(weekday)); err != nil {
+			h.Logger.Errorf("failed to delete shift of staff member %d: %v", staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// AddStaffAbsence takes from and to as inclusive dates in YYYY-MM-DD format.
+func (h *StaffHandler) AddStaffAbsence() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.PostForm("staffMemberID"))
+		kind := company.AbsenceKind(c.PostForm("kind"))
+		from, errFrom := time.ParseInLocation(time.DateOnly, c.PostForm("from"), time.Local)
+		to, errTo := time.ParseInLocation(time.DateOnly, c.PostForm("to"), time.Local)
+		commentStr := strings.TrimSpace(c.PostForm("comment"))
+
+		if errConv != nil || !kind.IsValid() || errFrom != nil || errTo != nil || to.Before(from) || len(commentStr) > maxAbsenceComment {
+			responseJSON["error"] = "staffMemberID, valid kind and from/to dates (from not after to) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var comment *string
+		if commentStr != "" {
+			comment = &commentStr
+		}
+
+		absence, err := h.StaffRepo.AddAbsence(staffMemberID, kind, from, to.AddDate(0, 0, 1), comment)
+		if err != nil {
+			h.Logger.Errorf("failed to add absence of staff member %d: %v", staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["absence"] = absence
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) DeleteStaffAbsence() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		absenceID := c.Param("id")
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.StaffRepo.DeleteAbsence(staffMemberID, absenceID); err != nil {
+			h.Logger.Errorf("failed to delete absence %s of staff member %d: %v", absenceID, staffMemberID, err)
+			h.abortScheduleError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["message"] = "deleted"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetDutyCalendar shows who is on duty per day, "from" defaults to today and "days" to a week.
+func (h *StaffHandler) GetDutyCalendar() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		from := time.Now()
+		if fromStr := c.Query("from"); fromStr != "" {
+			parsed, err := time.ParseInLocation(time.DateOnly, fromStr, time.Local)
+			if err != nil {
+				responseJSON["error"] = "from must be a date in YYYY-MM-DD format"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+			from = parsed
+		}
+
+		days, _ := strconv.Atoi(c.Query("days"))
+
+		calendar, err := h.StaffRepo.GetDutyCalendar(from, days, c.Query("specializationID"))
+		if err != nil {
+			h.Logger.Errorf("failed to get duty calendar: %v", err)
+			responseJSON["error"] = "failed to get duty calendar"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["days"] = calendar
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
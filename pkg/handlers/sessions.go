@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/session"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountRoleResolver answers which roles a phone holds using the same tables Login checks.
+type AccountRoleResolver struct {
+	StaffRepo     company.StaffRepo
+	ResidentsRepo residence.ResidentsController
+}
+
+func (r *AccountRoleResolver) RolesForPhone(phone string) ([]session.Role, error) {
+	var roles []session.Role
+
+	staffMember, errStaff := r.StaffRepo.GetStaffMemberByPhoneNumber(phone)
+	if errStaff != nil && !errors.Is(errStaff, company.ErrStaffMemberNotFound) {
+		return nil, errStaff
+	}
+	if staffMember != nil && staffMember.Status != company.StatusInactive {
+		roles = append(roles, session.StaffRole)
+	}
+
+	resident, errResident := r.ResidentsRepo.GetResidentByPhoneNumber(phone)
+	if errResident != nil && !errors.Is(errResident, residence.ErrResidentNotFound) {
+		return nil, errResident
+	}
+	if resident != nil {
+		roles = append(roles, session.ResidentRole)
+	}
+
+	rep, errRep := r.StaffRepo.GetRepresentativeByPhone(phone)
+	if errRep != nil && !errors.Is(errRep, company.ErrRepresentativeNotFound) {
+		return nil, errRep
+	}
+	if rep != nil {
+		roles = append(roles, session.ContractorRole)
+	}
+
+	return roles, nil
+}
+
+func sortSessionsByLastSeen(list []*session.Info) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+}
+
+func (h *UserHandler) GetMySessions() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		list, err := h.SessionManager.ListUserSessions(phone)
+		if err != nil {
+			h.Logger.Errorf("list sessions for %s: %v", phone, err)
+			responseJSON["error"] = "failed to get sessions"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		sortSessionsByLastSeen(list)
+
+		responseJSON["sessions"] = list
+		responseJSON["current"] = h.SessionManager.CurrentSessionID(c)
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) RevokeMySession() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		id := c.Param("id")
+		if id == h.SessionManager.CurrentSessionID(c) {
+			responseJSON["error"] = "use logout to end the current session"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.SessionManager.RevokeUserSession(phone, id); err != nil {
+			h.Logger.Errorf("revoke session %s of %s: %v", id, phone, err)
+			responseJSON["error"] = "failed to revoke session"
+
+			if errors.Is(err, session.ErrSessionNotFound) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// RevokeMyOtherSessions logs the user out everywhere except the browser that sent the request.
+func (h *UserHandler) RevokeMyOtherSessions() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		list, err := h.SessionManager.ListUserSessions(phone)
+		if err != nil {
+			h.Logger.Errorf("list sessions for %s: %v", phone, err)
+			responseJSON["error"] = "failed to get sessions"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		current := h.SessionManager.CurrentSessionID(c)
+		revoked := 0
+
+		for _, info := range list {
+			if info.ID == current {
+				continue
+			}
+
+			if err := h.SessionManager.RevokeUserSession(phone, info.ID); err != nil && !errors.Is(err, session.ErrSessionNotFound) {
+				h.Logger.Errorf("revoke session %s of %s: %v", info.ID, phone, err)
+				responseJSON["error"] = "failed to revoke sessions"
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				return
+			}
+			revoked++
+		}
+
+		responseJSON["revoked"] = revoked
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) GetUserSessions() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		list, err := h.SessionManager.ListUserSessions(phoneNumber)
+		if err != nil {
+			h.Logger.Errorf("list sessions for %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to get sessions"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		sortSessionsByLastSeen(list)
+
+		responseJSON["sessions"] = list
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// RevokeUserSessions revokes a single session when the id query parameter is given, all sessions of the user otherwise.
+func (h *UserHandler) RevokeUserSessions() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if id := c.Query("id"); id != "" {
+			if err := h.SessionManager.RevokeUserSession(phoneNumber, id); err != nil {
+				h.Logger.Errorf("revoke session %s of %s: %v", id, phoneNumber, err)
+				responseJSON["error"] = "failed to revoke session"
+
+				if errors.Is(err, session.ErrSessionNotFound) {
+					responseJSON["error"] = err.Error()
+					c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+				} else {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				}
+				return
+			}
+
+			h.Logger.Infof("session %s of %s revoked by staff", id, phoneNumber)
+			responseJSON["revoked"] = 1
+			responseJSON["message"] = "success"
+			c.JSON(http.StatusOK, responseJSON)
+			return
+		}
+
+		revoked, err := h.SessionManager.RevokeAllUserSessions(phoneNumber)
+		if err != nil {
+			h.Logger.Errorf("revoke sessions of %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to revoke sessions"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		h.Logger.Infof("%d sessions of %s revoked by staff", revoked, phoneNumber)
+		responseJSON["revoked"] = revoked
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/signup"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxClaimedAddressLength = 100
+	maxApartmentLength      = 10
+	maxSignupReviewComment  = 200
+	// signupStatusLookback is how many recent sign-ups of a phone the status check compares the password with
+	signupStatusLookback = 5
+)
+
+// signupSubmittedMessage is the answer to every well-formed sign-up, it must not reveal whether the phone
+// already has an account or a pending sign-up.
+const signupSubmittedMessage = "sign-up submitted, the staff will check the address and activate the account; " +
+	"check the status with your phone number and password"
+
+func abortSignupError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, signup.ErrSignupNotFound), errors.Is(err, residence.ErrNoHouseFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, signup.ErrSignupReviewed), errors.Is(err, userdata.ErrUserExists):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	case errors.Is(err, signup.ErrApprovalNeedsHouse):
+		c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+// SignUp is the public resident sign-up, the account stays pending until staff approve the claimed address.
+func (h *UserHandler) SignUp() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		password := c.PostForm("password")
+		fullName := strings.TrimSpace(c.PostForm("fullName"))
+		address := strings.TrimSpace(c.PostForm("address"))
+		apartment := strings.TrimSpace(c.PostForm("apartment"))
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil || !isValidFullName(fullName) {
+			h.Logger.Info("sign-up: phone number or full name are in the wrong format")
+			responseJSON["error"] = ErrWrongFormat.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if address == "" || utf8.RuneCountInString(address) > maxClaimedAddressLength || utf8.RuneCountInString(apartment) > maxApartmentLength {
+			responseJSON["error"] = "address (up to 100 characters) is required, apartment must be at most 10 characters"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if errPolicy := h.PasswordPolicy.Validate(password, phoneNumber); errPolicy != nil {
+			h.Logger.Infof("sign-up password for %s rejected by policy: %v", phoneNumber, errPolicy)
+			responseJSON["error"] = errPolicy.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		passwordHash, errHash := utils.HashPassword(password)
+		if errHash != nil {
+			h.Logger.Errorf("sign-up: failed to hash password: %v", errHash)
+			responseJSON["error"] = "internal error"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		created, err := h.SignupRepo.Create(signup.NewSignup{
+			Phone:          phoneNumber,
+			FullName:       fullName,
+			PasswordHash:   passwordHash,
+			ClaimedAddress: address,
+			Apartment:      apartment,
+		})
+		switch {
+		case errors.Is(err, signup.ErrPhoneRegistered), errors.Is(err, signup.ErrSignupPending):
+			h.Logger.Infof("sign-up for %s ignored: %v", phoneNumber, err)
+		case err != nil:
+			h.Logger.Errorf("sign-up for %s failed: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to submit sign-up, try again later"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		default:
+			h.Logger.Infof("sign-up %s submitted for %s", created.ID, phoneNumber)
+		}
+
+		responseJSON["message"] = signupSubmittedMessage
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// GetSignupStatus lets an applicant without an account see the decision, the password proves the sign-up is theirs.
+// An approved sign-up shares its password with the account, so guesses are counted by the login limiter.
+func (h *UserHandler) GetSignupStatus() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		password := c.PostForm("password")
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil || credentials.CheckPasswordInput(password) != nil {
+			responseJSON["error"] = ErrWrongFormat.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		wait, errCheck := h.LoginLimiter.Check(phoneNumber, c.ClientIP())
+		if errCheck != nil {
+			h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+		}
+		if wait > 0 {
+			h.Logger.Infof("sign-up status for %s from %s rejected, blocked for %s", phoneNumber, c.ClientIP(), wait)
+			abortLoginError(c, responseJSON, ErrTooManyAttempts, wait)
+			return
+		}
+
+		signups, err := h.SignupRepo.GetLatestByPhone(phoneNumber, signupStatusLookback)
+		if err != nil {
+			h.Logger.Errorf("failed to get sign-ups of %s: %v", phoneNumber, err)
+			responseJSON["error"] = "internal error"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		for _, s := range signups {
+			if !utils.CheckPassword(s.PasswordHash, password) {
+				continue
+			}
+
+			lang := i18n.FromContext(c)
+			statusLabel := i18n.Label(lang, i18n.GroupSignupStatus, s.Status.Code())
+
+			message := fmt.Sprintf(i18n.T(lang, "sign-up is %s"), statusLabel)
+			switch s.Status {
+			case signup.StatusApproved:
+				message += ", " + i18n.T(lang, "you can log in now")
+			case signup.StatusRejected:
+				if s.ReviewComment != nil {
+					message += ": " + *s.ReviewComment
+				}
+			}
+
+			responseJSON["status"] = s.Status.Code()
+			responseJSON["statusLabel"] = statusLabel
+			responseJSON["reason"] = s.ReviewComment
+			responseJSON["message"] = message
+			c.JSON(http.StatusOK, responseJSON)
+			return
+		}
+
+		if _, errFailure := h.LoginLimiter.RegisterFailure(phoneNumber, c.ClientIP()); errFailure != nil {
+			h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+		}
+
+		responseJSON["error"] = "no sign-up found for this phone number and password"
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	}
+}
+
+// GetSignups is the staff approval queue, by default only pending sign-ups are shown.
+func (h *UserHandler) GetSignups() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		status := signup.StatusPending
+		statusFilter := &status
+		if statusStr := c.Query("status"); statusStr == "any" {
+			statusFilter = nil
+		} else if statusStr != "" {
+			status = signup.Status(statusStr)
+			if !status.IsValid() {
+				responseJSON["error"] = "invalid status"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+		}
+
+		signups, total, err := h.SignupRepo.GetAll(c.Query("pattern"), statusFilter, limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get sign-ups: %v", err)
+			responseJSON["error"] = "failed to get sign-ups"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["signups"] = signups
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// undoSignupApproval removes what an interrupted approval has already created, so the sign-up can be reviewed again.
+func (h *UserHandler) undoSignupApproval(phone string) {
+	if err := h.ResidentsRepo.DeleteResidentByPhone(phone); err != nil && !errors.Is(err, residence.ErrResidentNotFound) {
+		h.Logger.Errorf("failed to undo resident of sign-up %s: %v", phone, err)
+	}
+	if err := h.UserRepo.DeleteByPhone(phone); err != nil && !errors.Is(err, userdata.ErrUserNotFound) {
+		h.Logger.Errorf("failed to undo login of sign-up %s: %v", phone, err)
+	}
+}
+
+// ReviewSignup approves a sign-up against a house chosen by staff or rejects it with a reason.
+func (h *UserHandler) ReviewSignup() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		id := c.PostForm("id")
+		decision := c.PostForm("decision")
+		commentStr := strings.TrimSpace(c.PostForm("comment"))
+
+		if id == "" || (decision != "approve" && decision != "reject") || len(commentStr) > maxSignupReviewComment {
+			responseJSON["error"] = "id and decision (approve or reject) are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var comment *string
+		if commentStr != "" {
+			comment = &commentStr
+		}
+
+		reviewer := c.GetString("phoneNumber")
+
+		if decision == "reject" {
+			if comment == nil {
+				responseJSON["error"] = "a rejection needs a reason"
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+
+			if err := h.SignupRepo.Review(id, reviewer, false, nil, comment); err != nil {
+				h.Logger.Errorf("failed to reject sign-up %s: %v", id, err)
+				abortSignupError(c, responseJSON, err)
+				return
+			}
+
+			h.Logger.Infof("sign-up %s rejected by %s", id, reviewer)
+			responseJSON["message"] = "rejected"
+			c.JSON(http.StatusOK, responseJSON)
+			return
+		}
+
+		houseID, errConv := strconv.Atoi(c.PostForm("houseID"))
+		if errConv != nil {
+			responseJSON["error"] = signup.ErrApprovalNeedsHouse.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		pending, err := h.SignupRepo.GetByID(id)
+		if err != nil {
+			h.Logger.Errorf("failed to get sign-up %s: %v", id, err)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+		if pending.Status != signup.StatusPending {
+			abortSignupError(c, responseJSON, signup.ErrSignupReviewed)
+			return
+		}
+
+		if _, err = h.ResidentsRepo.GetHouseByID(houseID); err != nil {
+			h.Logger.Errorf("sign-up %s: failed to get house %d: %v", id, houseID, err)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+
+		// the login goes first, its primary key stops a second approval of the same phone
+		if _, err = h.UserRepo.RegisterHashed(pending.Phone, pending.PasswordHash); err != nil {
+			h.Logger.Errorf("sign-up %s: failed to create login %s: %v", id, pending.Phone, err)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+
+		resident, err := h.ResidentsRepo.RegisterNewResident(pending.Phone, pending.FullName)
+		if err != nil {
+			h.Logger.Errorf("sign-up %s: failed to register resident %s: %v", id, pending.Phone, err)
+			h.undoSignupApproval(pending.Phone)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+
+		if err = h.ResidentsRepo.AddResidentAddressAssoc(resident.ID, houseID); err != nil {
+			h.Logger.Errorf("sign-up %s: failed to link resident %s to house %d: %v", id, resident.ID, houseID, err)
+			h.undoSignupApproval(pending.Phone)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+
+		if err = h.SignupRepo.Review(id, reviewer, true, &houseID, comment); err != nil {
+			h.Logger.Errorf("failed to approve sign-up %s: %v", id, err)
+			h.undoSignupApproval(pending.Phone)
+			abortSignupError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("sign-up %s approved by %s, resident %s linked to house %d", id, reviewer, resident.ID, houseID)
+		responseJSON["message"] = "approved"
+		responseJSON["resident"] = resident
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxStaffNameLength    = 40
+	maxStatusReasonLength = 200
+)
+
+func (h *StaffHandler) abortProfileError(c *gin.Context, responseJSON gin.H, err error) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, company.ErrStaffMemberNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, responseJSON)
+	case errors.Is(err, company.ErrInvalidStatusTransition), errors.Is(err, credentials.ErrPhoneTaken):
+		c.AbortWithStatusJSON(http.StatusConflict, responseJSON)
+	default:
+		responseJSON["error"] = "internal error"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
+func (h *StaffHandler) GetStaffMembers() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		page, limit := utils.GetPageAndLimitFromContext(c)
+
+		var status *company.StaffMemberStatus
+		if statusStr := c.Query("status"); statusStr != "" {
+			memberStatus := company.StaffMemberStatus(statusStr)
+			if memberStatus.IsValid() {
+				status = &memberStatus
+			} else {
+				h.Logger.Debugf("ignore invalid staff status filter: %s", statusStr)
+			}
+		}
+
+		members, total, err := h.StaffRepo.GetStaffMembers(c.Query("pattern"), status, limit, (page-1)*limit)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff members: %v", err)
+			responseJSON["error"] = "failed to get staff members"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		pages := utils.CountPages(total, limit)
+
+		meta := gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"pages": pages,
+		}
+
+		responseJSON["staff"] = members
+		responseJSON["meta"] = meta
+
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) GetStaffProfile() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		member, err := h.StaffRepo.GetStaffMemberByID(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %d: %v", staffMemberID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		specs, err := h.StaffRepo.FindCurrentSpecializations(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get specializations of staff member %d: %v", staffMemberID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		rating, err := h.RatingsRepo.GetStaffMemberSummary(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get rating of staff member %d: %v", staffMemberID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["staff"] = member
+		responseJSON["specializations"] = specs
+		responseJSON["rating"] = rating
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// UpdateStaffProfile changes the full name and/or the phone number, empty fields stay as they are. The phone number
+// is the login, so it moves together with the account and the member's sessions are closed.
+func (h *StaffHandler) UpdateStaffProfile() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.PostForm("staffMemberID"))
+		fullName := strings.TrimSpace(c.PostForm("fullName"))
+		phoneStr := strings.TrimSpace(c.PostForm("phone"))
+
+		if errConv != nil || (fullName == "" && phoneStr == "") || len([]rune(fullName)) > maxStaffNameLength {
+			responseJSON["error"] = "staffMemberID and a full name (up to 40 characters) or a phone are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		member, err := h.StaffRepo.GetStaffMemberByID(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get staff member %d: %v", staffMemberID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		if phoneStr != "" {
+			phone, errPhone := credentials.NormalizePhone(phoneStr)
+			if errPhone != nil {
+				responseJSON["error"] = errPhone.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+				return
+			}
+
+			if phone != member.Phone {
+				if err = h.PhoneRenamer.RenamePhone(member.Phone, phone); err != nil {
+					h.Logger.Errorf("failed to change phone of staff member %d: %v", staffMemberID, err)
+					h.abortProfileError(c, responseJSON, err)
+					return
+				}
+
+				if _, errRevoke := h.SessionManager.RevokeAllUserSessions(member.Phone); errRevoke != nil {
+					h.Logger.Errorf("failed to revoke sessions of %s after phone change: %v", member.Phone, errRevoke)
+				}
+
+				h.Logger.Infof("staff member %d phone changed from %s to %s", staffMemberID, member.Phone, phone)
+				member.Phone = phone
+			}
+		}
+
+		if fullName != "" && fullName != member.FullName {
+			if err = h.StaffRepo.UpdateStaffMemberName(staffMemberID, fullName); err != nil {
+				h.Logger.Errorf("failed to rename staff member %d: %v", staffMemberID, err)
+				h.abortProfileError(c, responseJSON, err)
+				return
+			}
+			member.FullName = fullName
+		}
+
+		responseJSON["staff"] = member
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// ChangeStaffStatus moves a member between работает, недоступен and уволился, the outcome lists where the
+// member's open requests went.
+func (h *StaffHandler) ChangeStaffStatus() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.PostForm("staffMemberID"))
+		status := company.StaffMemberStatus(c.PostForm("status"))
+		reasonStr := strings.TrimSpace(c.PostForm("reason"))
+
+		if errConv != nil || !status.IsValid() || len(reasonStr) > maxStatusReasonLength {
+			responseJSON["error"] = "staffMemberID and a valid status are required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		var reason *string
+		if reasonStr != "" {
+			reason = &reasonStr
+		}
+
+		changedBy := c.GetString("phoneNumber")
+
+		outcome, err := h.StaffRepo.ChangeStaffMemberStatus(staffMemberID, status, changedBy, reason)
+		if err != nil {
+			h.Logger.Errorf("failed to change status of staff member %d to %s: %v", staffMemberID, status, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		h.Logger.Infof("staff member %d status %s -> %s by %s, reassigned %d, unassigned %d",
+			staffMemberID, outcome.Change.From, outcome.Change.To, changedBy, len(outcome.Reassigned), len(outcome.Unassigned))
+
+		// role re-validation drops the staff role of a dismissed member anyway, revoking makes it immediate
+		if status == company.StatusInactive {
+			if member, errMember := h.StaffRepo.GetStaffMemberByID(staffMemberID); errMember == nil {
+				if _, errRevoke := h.SessionManager.RevokeAllUserSessions(member.Phone); errRevoke != nil {
+					h.Logger.Errorf("failed to revoke sessions of dismissed %s: %v", member.Phone, errRevoke)
+				}
+			}
+		}
+
+		responseJSON["outcome"] = outcome
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *StaffHandler) GetStaffStatusHistory() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		staffMemberID, errConv := strconv.Atoi(c.Query("staffMemberID"))
+		if errConv != nil {
+			responseJSON["error"] = "invalid staffMemberID"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		history, err := h.StaffRepo.GetStatusHistory(staffMemberID)
+		if err != nil {
+			h.Logger.Errorf("failed to get status history of staff member %d: %v", staffMemberID, err)
+			h.abortProfileError(c, responseJSON, err)
+			return
+		}
+
+		responseJSON["history"] = history
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"DBPrototyping/pkg/userdata/credentials"
+	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/userdata/twofactor"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrTwoFactorSession  = errors.New("no login is waiting for two-factor authentication, log in again")
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required for staff accounts")
+)
+
+// staffTwoFactorStep tells which page a staff member has to visit after the password check, empty means none.
+func (h *UserHandler) staffTwoFactorStep(phone string) (string, error) {
+	enabled, err := h.TwoFactorRepo.IsEnabled(phone)
+	if err != nil {
+		return "", err
+	}
+
+	if enabled {
+		return "/2fa/verify", nil
+	}
+	if h.RequireStaff2FA {
+		return "/2fa/setup", nil
+	}
+
+	return "", nil
+}
+
+// twoFactorSubject returns the phone whose second factor is being managed: either a logged in staff member
+// or a login that is waiting for its second factor.
+func (h *UserHandler) twoFactorSubject(c *gin.Context) (string, bool, bool) {
+	roleVal, _ := c.Get("role")
+	if role, ok := roleVal.(string); ok && session.Role(role) == session.StaffRole {
+		if phone, ok := c.Get("phoneNumber"); ok {
+			if phoneString, ok := phone.(string); ok && phoneString != "" {
+				return phoneString, false, true
+			}
+		}
+	}
+
+	if phone, ok := h.SessionManager.GetPendingTwoFactor(c); ok {
+		return phone, true, true
+	}
+
+	return "", false, false
+}
+
+func (h *UserHandler) completeTwoFactorLogin(c *gin.Context, phone string) error {
+	h.SessionManager.ClearPendingTwoFactor(c)
+
+	return h.SessionManager.StartUserSession(c, phone, session.StaffRole)
+}
+
+func (h *UserHandler) VerifyTwoFactor() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+		responseJSON["type"] = "login"
+
+		phone, ok := h.SessionManager.GetPendingTwoFactor(c)
+		if !ok {
+			responseJSON["error"] = ErrTwoFactorSession.Error()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		code := c.PostForm("code")
+		if code == "" {
+			responseJSON["error"] = "code is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		clientIP := c.ClientIP()
+
+		wait, errCheck := h.LoginLimiter.Check(phone, clientIP)
+		if errCheck != nil {
+			h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+		}
+		if wait > 0 {
+			responseJSON["error"] = ErrTooManyAttempts.Error()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, responseJSON)
+			return
+		}
+
+		if err := h.TwoFactorRepo.Verify(phone, code); err != nil {
+			h.Logger.Infof("two-factor verification failed for %s: %v", phone, err)
+
+			if errors.Is(err, twofactor.ErrInvalidCode) {
+				if _, errFailure := h.LoginLimiter.RegisterFailure(phone, clientIP); errFailure != nil {
+					h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+				}
+
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+				return
+			}
+
+			responseJSON["error"] = "failed to verify code"
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		if err := h.completeTwoFactorLogin(c, phone); err != nil {
+			h.Logger.Errorf("save session error: %s", err.Error())
+			responseJSON["error"] = err.Error()
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["message"] = phone
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) BeginTwoFactorEnrollment() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phone, _, ok := h.twoFactorSubject(c)
+		if !ok {
+			responseJSON["error"] = ErrTwoFactorSession.Error()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		enrollment, err := h.TwoFactorRepo.BeginEnrollment(phone)
+		if err != nil {
+			h.Logger.Errorf("begin two-factor enrollment for %s: %v", phone, err)
+			responseJSON["error"] = "failed to start enrollment"
+
+			if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		responseJSON["secret"] = enrollment.Secret
+		responseJSON["uri"] = enrollment.URI
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) ConfirmTwoFactorEnrollment() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phone, pending, ok := h.twoFactorSubject(c)
+		if !ok {
+			responseJSON["error"] = ErrTwoFactorSession.Error()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		code := c.PostForm("code")
+		if code == "" {
+			responseJSON["error"] = "code is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		recoveryCodes, err := h.TwoFactorRepo.ConfirmEnrollment(phone, code)
+		if err != nil {
+			h.Logger.Infof("confirm two-factor enrollment for %s: %v", phone, err)
+			responseJSON["error"] = "failed to confirm enrollment"
+
+			if errors.Is(err, twofactor.ErrInvalidCode) || errors.Is(err, twofactor.ErrNoPendingEnrollment) ||
+				errors.Is(err, twofactor.ErrAlreadyEnabled) {
+				responseJSON["error"] = err.Error()
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		// the code has just been checked against the new secret, so an enrollment during login completes it
+		if pending {
+			if err := h.completeTwoFactorLogin(c, phone); err != nil {
+				h.Logger.Errorf("save session error: %s", err.Error())
+				responseJSON["error"] = err.Error()
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				return
+			}
+			responseJSON["next"] = "/"
+		}
+
+		responseJSON["recoveryCodes"] = recoveryCodes
+		responseJSON["message"] = "two-factor authentication enabled"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) GetTwoFactorStatus() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		phone, _, ok := h.twoFactorSubject(c)
+		if !ok {
+			responseJSON["error"] = ErrTwoFactorSession.Error()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+			return
+		}
+
+		enabled, err := h.TwoFactorRepo.IsEnabled(phone)
+		if err != nil {
+			h.Logger.Errorf("two-factor status for %s: %v", phone, err)
+			responseJSON["error"] = "failed to get two-factor status"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["enabled"] = enabled
+		responseJSON["required"] = h.RequireStaff2FA
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// verifyTwoFactorThrottled checks a code of the phone through the login limiter like authorizeThrottled does for
+// passwords. It returns ErrTooManyAttempts together with the remaining wait, otherwise the error of Verify.
+func (h *UserHandler) verifyTwoFactorThrottled(phone, code, clientIP string) (time.Duration, error) {
+	wait, errCheck := h.LoginLimiter.Check(phone, clientIP)
+	if errCheck != nil {
+		h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+	}
+	if wait > 0 {
+		h.Logger.Infof("two-factor code for %s from %s rejected, blocked for %s", phone, clientIP, wait)
+		return wait, ErrTooManyAttempts
+	}
+
+	err := h.TwoFactorRepo.Verify(phone, code)
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		if _, errFailure := h.LoginLimiter.RegisterFailure(phone, clientIP); errFailure != nil {
+			h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+		}
+	}
+	return 0, err
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		if wait, err := h.verifyTwoFactorThrottled(phone, c.PostForm("code"), c.ClientIP()); err != nil {
+			h.Logger.Infof("regenerate recovery codes for %s: %v", phone, err)
+			responseJSON["error"] = err.Error()
+
+			if errors.Is(err, ErrTooManyAttempts) {
+				abortLoginError(c, responseJSON, err, wait)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		recoveryCodes, err := h.TwoFactorRepo.RegenerateRecoveryCodes(phone)
+		if err != nil {
+			h.Logger.Errorf("regenerate recovery codes for %s: %v", phone, err)
+			responseJSON["error"] = "failed to regenerate recovery codes"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["recoveryCodes"] = recoveryCodes
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) DisableTwoFactor() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		phoneVal, _ := c.Get("phoneNumber")
+		phone, _ := phoneVal.(string)
+
+		responseJSON := gin.H{}
+
+		if h.RequireStaff2FA {
+			responseJSON["error"] = ErrTwoFactorRequired.Error()
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		if wait, err := h.verifyTwoFactorThrottled(phone, c.PostForm("code"), c.ClientIP()); err != nil {
+			h.Logger.Infof("disable two-factor for %s: %v", phone, err)
+			responseJSON["error"] = err.Error()
+
+			if errors.Is(err, ErrTooManyAttempts) {
+				abortLoginError(c, responseJSON, err, wait)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.TwoFactorRepo.Disable(phone); err != nil {
+			h.Logger.Errorf("disable two-factor for %s: %v", phone, err)
+			responseJSON["error"] = "failed to disable two-factor authentication"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["message"] = "two-factor authentication disabled"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+// ResetStaffTwoFactor lets another staff member drop the second factor of someone who lost both the device and
+// the recovery codes, the owner will have to enroll again on the next login. The caller proves the own second
+// factor first, so a stolen password-only session cannot strip it from a colleague.
+func (h *UserHandler) ResetStaffTwoFactor() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		callerVal, _ := c.Get("phoneNumber")
+		caller, _ := callerVal.(string)
+
+		responseJSON := gin.H{}
+
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+		if phoneNumber == caller {
+			responseJSON["error"] = "use the two-factor settings to manage your own second factor"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		clientIP := c.ClientIP()
+
+		wait, errCheck := h.LoginLimiter.Check(caller, clientIP)
+		if errCheck != nil {
+			h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+		}
+		if wait > 0 {
+			responseJSON["error"] = ErrTooManyAttempts.Error()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, responseJSON)
+			return
+		}
+
+		if err := h.TwoFactorRepo.Verify(caller, c.PostForm("code")); err != nil {
+			h.Logger.Warnf("two-factor reset of %s by %s refused: %v", phoneNumber, caller, err)
+
+			if errors.Is(err, twofactor.ErrInvalidCode) {
+				if _, errFailure := h.LoginLimiter.RegisterFailure(caller, clientIP); errFailure != nil {
+					h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+				}
+			}
+
+			responseJSON["error"] = "confirm the reset with your own two-factor code: " + err.Error()
+			c.AbortWithStatusJSON(http.StatusForbidden, responseJSON)
+			return
+		}
+
+		if err := h.TwoFactorRepo.Disable(phoneNumber); err != nil {
+			h.Logger.Errorf("reset two-factor for %s: %v", phoneNumber, err)
+			responseJSON["error"] = "failed to reset two-factor authentication: " + err.Error()
+
+			if errors.Is(err, twofactor.ErrTwoFactorNotEnabled) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			}
+			return
+		}
+
+		h.Logger.Warnf("two-factor authentication of %s reset by %s from %s", phoneNumber, caller, clientIP)
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
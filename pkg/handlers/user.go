@@ -4,52 +4,109 @@ import (
 	"DBPrototyping/pkg/company"
 	"DBPrototyping/pkg/residence"
 	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/userdata/apitoken"
+	"DBPrototyping/pkg/userdata/credentials"
 	"DBPrototyping/pkg/userdata/session"
+	"DBPrototyping/pkg/userdata/signup"
+	"DBPrototyping/pkg/userdata/throttle"
+	"DBPrototyping/pkg/userdata/twofactor"
 	"DBPrototyping/pkg/utils"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 var (
-	ErrWrongFormat     = errors.New("wrong format: the data provided is <5 or >30 symbols or some symbols are not ascii")
+	ErrWrongFormat     = errors.New("wrong format: check the phone number, full name and password")
 	ErrRegisteringRole = errors.New("error registering a user: no role specified")
+	// ErrInvalidCredentials is the only error a failed login reports, it must not reveal whether the phone exists
+	ErrInvalidCredentials = errors.New("invalid phone number or password")
+	ErrTooManyAttempts    = errors.New("too many login attempts, try again later")
 )
 
+// fullNameMaxLength matches the varchar(40) columns of residents and staff members.
+const fullNameMaxLength = 40
+
+func isValidFullName(fullName string) bool {
+	length := utf8.RuneCountInString(fullName)
+	return length > 0 && length <= fullNameMaxLength && utf8.ValidString(fullName)
+}
+
 type UserHandler struct {
 	SessionManager session.GinSessionManagerRepo
 	ResidentsRepo  residence.ResidentsController
 	StaffRepo      company.StaffRepo
 	UserRepo       userdata.UserRepo
-	Logger         *zap.SugaredLogger
+	SignupRepo     signup.SignupRepo
+	ResetSender    userdata.ResetTokenSender
+	ResetTokenTTL  time.Duration
+	LoginLimiter   throttle.LoginLimiter
+	PasswordPolicy credentials.PasswordPolicy
+	TwoFactorRepo  twofactor.TwoFactorRepo
+	APITokens      apitoken.TokenRepo
+	// RequireStaff2FA forces every staff member to enroll into two-factor authentication before the first login
+	RequireStaff2FA bool
+	Logger          *zap.SugaredLogger
 }
 
 func (h *UserHandler) Register() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		phoneNumber := c.PostForm("phoneNumber")
 		password := c.PostForm("password")
 		isResident := c.PostForm("isResident") == "on"
 		isStaffMember := c.PostForm("isStaffMember") == "on"
-		fullName := c.PostForm("fullName")
+		isContractor := c.PostForm("isContractor") == "on"
+		organizationID := strings.TrimSpace(c.PostForm("organizationID"))
+		fullName := strings.TrimSpace(c.PostForm("fullName"))
 
-		if phoneNumber == "" || password == "" || fullName == "" || len(password) < 5 || len(phoneNumber) < 5 ||
-			len(phoneNumber) > 40 || len(password) > 30 || !utils.IsNumbers(phoneNumber) || !utils.IsNumbersAndLetters(password) {
-
-			h.Logger.Error("phone number, password or full name are in the wrong format")
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil || !isValidFullName(fullName) {
+			h.Logger.Info("phone number or full name are in the wrong format")
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrWrongFormat.Error()})
 
 			return
 		}
 
-		if !isResident && !isStaffMember {
+		if errPolicy := h.PasswordPolicy.Validate(password, phoneNumber); errPolicy != nil {
+			h.Logger.Infof("password for %s rejected by policy: %v", phoneNumber, errPolicy)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errPolicy.Error()})
+
+			return
+		}
+
+		if !isResident && !isStaffMember && !isContractor {
 			h.Logger.Error("At least one role should be specified in order to be registered")
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrRegisteringRole.Error()})
 
 			return
 		}
 
+		if isContractor && (isStaffMember || organizationID == "") {
+			h.Logger.Info("contractor registration needs an organization and excludes the staff role")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "a contractor needs an organization ID and can not be staff"})
+
+			return
+		}
+
+		if isContractor {
+			if _, errOrg := h.StaffRepo.GetOrganizationByID(organizationID); errOrg != nil {
+				h.Logger.Infof("contractor registration for unknown organization %s: %v", organizationID, errOrg)
+
+				if errors.Is(errOrg, company.ErrOrganizationNotFound) {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errOrg.Error()})
+				} else {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check organization"})
+				}
+				return
+			}
+		}
+
 		responseJSON := gin.H{}
 		responseJSON["type"] = "login"
 
@@ -66,7 +123,7 @@ func (h *UserHandler) Register() func(c *gin.Context) {
 			return
 		}
 
-		if isResident || isStaffMember {
+		if isResident || isStaffMember || isContractor {
 			if isResident {
 				_, errResidentReg := h.ResidentsRepo.RegisterNewResident(phoneNumber, fullName)
 				if errResidentReg != nil {
@@ -86,6 +143,16 @@ func (h *UserHandler) Register() func(c *gin.Context) {
 					responseJSON["error"] = finalErr.Error()
 				}
 			}
+
+			if isContractor {
+				_, errRepReg := h.StaffRepo.RegisterRepresentative(phoneNumber, fullName, organizationID)
+				if errRepReg != nil {
+					h.Logger.Errorf("register representative error: %s", errRepReg.Error())
+
+					finalErr = errors.Join(finalErr, errRepReg)
+					responseJSON["error"] = finalErr.Error()
+				}
+			}
 		}
 
 		responseJSON["message"] = user.Phone
@@ -94,15 +161,61 @@ func (h *UserHandler) Register() func(c *gin.Context) {
 	}
 }
 
+// authorizeThrottled checks the password through the login limiter. It returns ErrTooManyAttempts together with
+// the remaining wait or ErrInvalidCredentials, other errors are internal.
+func (h *UserHandler) authorizeThrottled(phoneNumber, password, clientIP string) (*userdata.User, time.Duration, error) {
+	wait, errCheck := h.LoginLimiter.Check(phoneNumber, clientIP)
+	if errCheck != nil {
+		h.Logger.Errorf("login limiter check error: %s", errCheck.Error())
+	}
+	if wait > 0 {
+		h.Logger.Infof("login for %s from %s rejected, blocked for %s", phoneNumber, clientIP, wait)
+		return nil, wait, ErrTooManyAttempts
+	}
+
+	user, errAuthorize := h.UserRepo.Authorize(phoneNumber, password)
+	if errAuthorize != nil {
+		h.Logger.Errorf("authorize phone number error: %s", errAuthorize.Error())
+
+		if errors.Is(errAuthorize, userdata.ErrUserNotFound) || errors.Is(errAuthorize, userdata.ErrWrongPassword) {
+			if _, errFailure := h.LoginLimiter.RegisterFailure(phoneNumber, clientIP); errFailure != nil {
+				h.Logger.Errorf("login limiter register failure error: %s", errFailure.Error())
+			}
+			return nil, 0, ErrInvalidCredentials
+		}
+
+		return nil, 0, errAuthorize
+	}
+
+	if errSuccess := h.LoginLimiter.RegisterSuccess(user.Phone); errSuccess != nil {
+		h.Logger.Errorf("login limiter register success error: %s", errSuccess.Error())
+	}
+
+	return user, 0, nil
+}
+
+func abortLoginError(c *gin.Context, responseJSON gin.H, err error, wait time.Duration) {
+	responseJSON["error"] = err.Error()
+
+	switch {
+	case errors.Is(err, ErrTooManyAttempts):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, responseJSON)
+	case errors.Is(err, ErrInvalidCredentials):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
+	default:
+		responseJSON["error"] = "failed to log in, try again later"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+	}
+}
+
 func (h *UserHandler) Login() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		phoneNumber := c.PostForm("phoneNumber")
 		password := c.PostForm("password")
 
-		if phoneNumber == "" || password == "" || len(password) < 5 || len(phoneNumber) < 5 ||
-			len(phoneNumber) > 40 || len(password) > 30 || !utils.IsNumbers(phoneNumber) || !utils.IsNumbersAndLetters(password) {
-
-			h.Logger.Error("phone number or password are in the wrong format")
+		phoneNumber, errPhone := credentials.NormalizePhone(c.PostForm("phoneNumber"))
+		if errPhone != nil || credentials.CheckPasswordInput(password) != nil {
+			h.Logger.Info("phone number or password are in the wrong format")
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ErrWrongFormat.Error()})
 
 			return
@@ -113,23 +226,14 @@ func (h *UserHandler) Login() func(c *gin.Context) {
 
 		var finalErr error
 
-		userToLogin, errUserLogin := h.UserRepo.Authorize(phoneNumber, password)
+		userToLogin, wait, errUserLogin := h.authorizeThrottled(phoneNumber, password, c.ClientIP())
 		if errUserLogin != nil {
-			finalErr = errors.Join(finalErr, errUserLogin)
-			responseJSON["error"] = finalErr.Error()
-
-			h.Logger.Errorf("authorize phone number error: %s", errUserLogin.Error())
-			c.AbortWithStatusJSON(http.StatusUnauthorized, responseJSON)
-
+			abortLoginError(c, responseJSON, errUserLogin, wait)
 			return
 		}
 
-		h.SessionManager.SetUserSessionPhone(c, userToLogin.Phone)
-
 		saveRole := func(role session.Role) error {
-			h.SessionManager.SetUserSessionRole(c, role)
-
-			if err := h.SessionManager.SaveSession(c); err != nil {
+			if err := h.SessionManager.StartUserSession(c, userToLogin.Phone, role); err != nil {
 				finalErr = errors.Join(finalErr, err)
 				h.Logger.Errorf("save session error: %s", err.Error())
 
@@ -150,7 +254,42 @@ func (h *UserHandler) Login() func(c *gin.Context) {
 			return
 		}
 
+		// a dismissed member keeps the account only for the other roles it may hold
+		if staffMember != nil && staffMember.Status == company.StatusInactive {
+			staffMember = nil
+		}
+
 		if staffMember != nil {
+			next, errTwoFactor := h.staffTwoFactorStep(userToLogin.Phone)
+			if errTwoFactor != nil {
+				finalErr = errors.Join(finalErr, errTwoFactor)
+				responseJSON["error"] = finalErr.Error()
+
+				h.Logger.Errorf("two-factor check error: %s", errTwoFactor.Error())
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				return
+			}
+
+			if next != "" {
+				h.SessionManager.SetPendingTwoFactor(c, userToLogin.Phone)
+				if err := h.SessionManager.SaveSession(c); err != nil {
+					responseJSON["error"] = err.Error()
+
+					h.Logger.Errorf("save pending two-factor session error: %s", err.Error())
+
+					c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+					return
+				}
+
+				responseJSON["type"] = "2fa"
+				responseJSON["next"] = next
+				responseJSON["message"] = "second factor required"
+
+				c.JSON(http.StatusOK, responseJSON)
+				return
+			}
+
 			if err := saveRole(session.StaffRole); err != nil {
 				finalErr = errors.Join(finalErr, err)
 				responseJSON["error"] = finalErr.Error()
@@ -167,6 +306,35 @@ func (h *UserHandler) Login() func(c *gin.Context) {
 			return
 		}
 
+		rep, errRep := h.StaffRepo.GetRepresentativeByPhone(userToLogin.Phone)
+		if errRep != nil && !errors.Is(errRep, company.ErrRepresentativeNotFound) {
+			finalErr = errors.Join(finalErr, errRep)
+			responseJSON["error"] = finalErr.Error()
+
+			h.Logger.Errorf("representative error: %s", errRep.Error())
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		if rep != nil {
+			if err := saveRole(session.ContractorRole); err != nil {
+				finalErr = errors.Join(finalErr, err)
+				responseJSON["error"] = finalErr.Error()
+
+				h.Logger.Errorf("save contractor role error: %s", err.Error())
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+				return
+			}
+
+			responseJSON["message"] = userToLogin.Phone
+			responseJSON["next"] = "/contractor/requests"
+
+			c.JSON(http.StatusOK, responseJSON)
+			return
+		}
+
 		resident, errResident := h.ResidentsRepo.GetResidentByPhoneNumber(userToLogin.Phone)
 		if errResident != nil && !errors.Is(errResident, residence.ErrResidentNotFound) {
 			finalErr = errors.Join(finalErr, errResident)
@@ -198,11 +366,16 @@ func (h *UserHandler) Login() func(c *gin.Context) {
 
 func (h *UserHandler) DeleteUser() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		phoneNumber := c.Param("phoneNumber")
-
 		var finalErr error
 		responseJSON := gin.H{}
 
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
 		residentDeleteErr := h.ResidentsRepo.DeleteResidentByPhone(phoneNumber)
 		if residentDeleteErr != nil && !errors.Is(residentDeleteErr, residence.ErrResidentNotFound) {
 			h.Logger.Errorf("delete resident error: %s", residentDeleteErr.Error())
@@ -224,6 +397,16 @@ func (h *UserHandler) DeleteUser() func(c *gin.Context) {
 			return
 		}
 
+		repDeleteErr := h.StaffRepo.DeleteRepresentativeByPhone(phoneNumber)
+		if repDeleteErr != nil && !errors.Is(repDeleteErr, company.ErrRepresentativeNotFound) {
+			h.Logger.Errorf("delete representative error: %s", repDeleteErr.Error())
+			finalErr = errors.Join(finalErr, repDeleteErr)
+
+			responseJSON["error"] = finalErr.Error()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
 		userDeleteErr := h.UserRepo.DeleteByPhone(phoneNumber)
 		if userDeleteErr != nil {
 			h.Logger.Errorf("delete user error: %s", userDeleteErr.Error())
@@ -234,6 +417,18 @@ func (h *UserHandler) DeleteUser() func(c *gin.Context) {
 			return
 		}
 
+		// role re-validation would catch the deleted account on the next request anyway, revoking makes it immediate
+		// and keeps the lists clean
+		if _, errRevoke := h.SessionManager.RevokeAllUserSessions(phoneNumber); errRevoke != nil {
+			h.Logger.Errorf("revoke sessions of deleted user error: %s", errRevoke.Error())
+			finalErr = errors.Join(finalErr, errRevoke)
+		}
+
+		if _, errRevoke := h.APITokens.RevokeAllByPhone(phoneNumber); errRevoke != nil {
+			h.Logger.Errorf("revoke api tokens of deleted user error: %s", errRevoke.Error())
+			finalErr = errors.Join(finalErr, errRevoke)
+		}
+
 		if finalErr != nil {
 			h.Logger.Errorf("user delete error: %s", finalErr.Error())
 			responseJSON["error"] = finalErr.Error()
@@ -253,7 +448,7 @@ func (h *UserHandler) GetAllUsersFiltered() func(c *gin.Context) {
 
 		page, limit := utils.GetPageAndLimitFromContext(c)
 
-		phoneToMatch := c.Query("phoneNumber")
+		phoneToMatch := credentials.PhoneSearchPattern(c.Query("phoneNumber"))
 
 		offset := (page - 1) * limit
 
@@ -289,10 +484,15 @@ func (h *UserHandler) GetAllUsersFiltered() func(c *gin.Context) {
 
 func (h *UserHandler) GetUserDetails() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		phoneNumber := c.Param("phoneNumber")
-
 		responseJSON := gin.H{}
 
+		phoneNumber, errPhone := credentials.NormalizePhone(c.Param("phoneNumber"))
+		if errPhone != nil {
+			responseJSON["error"] = errPhone.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
 		staffMember, errStaff := h.StaffRepo.GetStaffMemberByPhoneNumber(phoneNumber)
 		if errStaff != nil && !errors.Is(errStaff, company.ErrStaffMemberNotFound) {
 			responseJSON["error"] = errStaff.Error()
@@ -332,7 +532,51 @@ func (h *UserHandler) Logout() gin.HandlerFunc {
 		if err != nil {
 			h.Logger.Errorf("save session error: %s", err.Error())
 			c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+			return
 		}
 		c.Redirect(http.StatusSeeOther, "/login")
 	}
 }
+
+func (h *UserHandler) GetLoginLockouts() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		lockouts, err := h.LoginLimiter.ListBlocked()
+		if err != nil {
+			h.Logger.Errorf("get login lockouts error: %v", err)
+			responseJSON["error"] = "failed to get lockouts"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		responseJSON["lockouts"] = lockouts
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
+
+func (h *UserHandler) ClearLoginLockout() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		responseJSON := gin.H{}
+
+		key := c.Query("key")
+		if key == "" {
+			responseJSON["error"] = "key is required"
+			c.AbortWithStatusJSON(http.StatusBadRequest, responseJSON)
+			return
+		}
+
+		if err := h.LoginLimiter.Clear(key); err != nil {
+			h.Logger.Errorf("clear login lockout %s error: %v", key, err)
+			responseJSON["error"] = "failed to clear lockout"
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, responseJSON)
+			return
+		}
+
+		h.Logger.Infof("login lockout cleared for %s", key)
+		responseJSON["message"] = "success"
+		c.JSON(http.StatusOK, responseJSON)
+	}
+}
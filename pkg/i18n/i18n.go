@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lang is a language the interface is translated to.
+type Lang string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+)
+
+// Default is used when neither the user nor the browser asked for a known language.
+const Default = LangEN
+
+// ContextKey holds the language of the current request in the gin context.
+const ContextKey = "lang"
+
+var Langs = []Lang{LangRU, LangEN}
+
+func (l Lang) IsValid() bool {
+	switch l {
+	case LangRU, LangEN:
+		return true
+	default:
+		return false
+	}
+}
+
+// FromAcceptLanguage picks the first known language of the header, weights are ignored since browsers list the
+// languages in the order of preference anyway.
+func FromAcceptLanguage(header string) Lang {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if lang := Lang(primary); lang.IsValid() {
+			return lang
+		}
+	}
+	return Default
+}
+
+// FromContext is the language the middleware chose for the request.
+func FromContext(c *gin.Context) Lang {
+	if lang := Lang(c.GetString(ContextKey)); lang.IsValid() {
+		return lang
+	}
+	return Default
+}
+
+// T translates the key, a key missing in the language falls back to the default one and then to the key itself.
+func T(lang Lang, key string) string {
+	if message, ok := messages[lang][key]; ok {
+		return message
+	}
+	if message, ok := messages[Default][key]; ok {
+		return message
+	}
+	return key
+}
+
+// Group translates every key of the group, e.g. the texts of the scripts, keyed without the group prefix. The keys
+// are those of the default language, which has them all.
+func Group(lang Lang, group string) map[string]string {
+	prefix := group + "."
+	labels := make(map[string]string)
+	for key := range messages[Default] {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			labels[name] = T(lang, key)
+		}
+	}
+	return labels
+}
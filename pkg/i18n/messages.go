@@ -0,0 +1,1762 @@
+package i18n
+
+// Keys of the enum labels are the group and the machine code, e.g. "request_status.created".
+const (
+	GroupRequestStatus   = "request_status"
+	GroupRequestType     = "request_type"
+	GroupRequestPriority = "request_priority"
+	GroupStaffStatus     = "staff_status"
+	GroupSignupStatus    = "signup_status"
+	GroupComplaintKind   = "complaint_kind"
+	GroupComplaintStatus = "complaint_status"
+	GroupAbsenceKind     = "absence_kind"
+	GroupContactChannel  = "contact_channel"
+	GroupLinkStatus      = "link_status"
+	GroupAnnouncement    = "announcement_kind"
+	GroupSlotStatus      = "slot_status"
+	GroupBillingItem     = "billing_item"
+	GroupBillingPayer    = "billing_payer"
+	GroupBillingApproval = "billing_approval"
+	GroupMovementKind    = "movement_kind"
+	// GroupWeekday names the days of the week by their number, Sunday is 0
+	GroupWeekday = "weekday"
+	// GroupScript holds the texts scripts put on the pages, the pages hand them over with the enum labels
+	GroupScript = "script"
+)
+
+// Label translates a value of an enum group by its machine code.
+func Label(lang Lang, group, code string) string {
+	return T(lang, group+"."+code)
+}
+
+var messages = map[Lang]map[string]string{
+	LangEN: {
+		"request_status.created":     "Created",
+		"request_status.assigned":    "Assigned",
+		"request_status.completed":   "Completed",
+		"request_status.cancelled":   "Cancelled",
+		"request_status.suspended":   "Suspended",
+		"request_status.transferred": "Transferred to a contractor",
+
+		"request_type.apartment_internal": "Apartment repair",
+		"request_type.house_common":       "Common property repair",
+
+		"request_priority.emergency": "Emergency",
+		"request_priority.high":      "High",
+		"request_priority.normal":    "Normal",
+		"request_priority.low":       "Low",
+
+		"staff_status.active":      "Working",
+		"staff_status.unavailable": "Unavailable",
+		"staff_status.dismissed":   "Dismissed",
+
+		"signup_status.pending":  "Under review",
+		"signup_status.approved": "Approved",
+		"signup_status.rejected": "Rejected",
+
+		"complaint_kind.noise":       "Noise",
+		"complaint_kind.parking":     "Parking",
+		"complaint_kind.neighbors":   "Neighbours",
+		"complaint_kind.cleanliness": "Cleanliness",
+
+		"complaint_status.submitted":      "Submitted",
+		"complaint_status.reviewed":       "Reviewed",
+		"complaint_status.warning_issued": "Warning issued",
+		"complaint_status.resolved":       "Resolved",
+
+		"absence_kind.vacation":   "Vacation",
+		"absence_kind.sick_leave": "Sick leave",
+		"absence_kind.day_off":    "Day off",
+
+		"contact_channel.call":  "Phone call",
+		"contact_channel.sms":   "SMS",
+		"contact_channel.email": "Email",
+
+		"link_status.pending":  "Under review",
+		"link_status.approved": "Approved",
+		"link_status.rejected": "Rejected",
+
+		"announcement_kind.notice": "Notice",
+		"announcement_kind.outage": "Outage",
+
+		"slot_status.proposed":  "Proposed",
+		"slot_status.chosen":    "Chosen",
+		"slot_status.cancelled": "Cancelled",
+
+		"billing_item.materials":          "Materials",
+		"billing_item.labor":              "Labour",
+		"billing_item.contractor_invoice": "Contractor invoice",
+
+		"billing_payer.hoa_fund": "HOA fund",
+		"billing_payer.resident": "Resident",
+
+		"billing_approval.pending":  "Awaiting review",
+		"billing_approval.approved": "Approved",
+		"billing_approval.rejected": "Rejected",
+
+		"movement_kind.receipt":   "Receipt",
+		"movement_kind.usage":     "Used on a request",
+		"movement_kind.return":    "Return",
+		"movement_kind.write_off": "Write-off",
+		"movement_kind.transfer":  "Transfer",
+
+		"lang.ru": "Русский",
+		"lang.en": "English",
+
+		"nav.logged_in_as":      "Logged in as",
+		"nav.admin_panel":       "Admin panel",
+		"nav.contractor_portal": "Contractor portal",
+		"nav.create_request":    "Create request",
+		"nav.my_requests":       "My requests",
+		"nav.complaints":        "Complaints",
+		"nav.profile":           "My profile",
+		"nav.change_password":   "Change password",
+		"nav.sessions":          "Active sessions",
+		"nav.api_tokens":        "API tokens",
+		"nav.logout":            "Logout",
+		"nav.login":             "Login",
+		"nav.language":          "Language",
+
+		"script.submitting":              "Submitting...",
+		"script.success":                 "Success: ",
+		"script.network_error":           "Network error",
+		"script.request_created":         "Request %s created",
+		"script.request_joined":          "You joined request %s, its progress is shown in My requests",
+		"script.join":                    "Join",
+		"script.reported_by":             "reported by",
+		"script.no_requests":             "No requests found",
+		"script.type":                    "Type:",
+		"script.status":                  "Status:",
+		"script.priority":                "Priority:",
+		"script.request_failed":          "Request failed",
+		"script.visit_agreed":            "Visit agreed:",
+		"script.visit_choose":            "Choose a time for the visit:",
+		"script.visit_cancel":            "Cancel visit",
+		"script.visit_cancel_reason":     "Why does this time not suit you? The staff will propose another one.",
+		"script.visit_move":              "Move here",
+		"script.visit_pick":              "Pick",
+		"script.your_rating":             "Your rating: ",
+		"script.rate_prompt":             "How satisfied are you with the result?",
+		"script.comment_optional":        "Comment (optional)",
+		"script.rate_change":             "Change rating",
+		"script.rate":                    "Rate",
+		"script.rate_failed":             "Failed to save the rating",
+		"script.loading":                 "Loading...",
+		"script.feed_confirm":            "A new link replaces the previous one. Continue?",
+		"script.feed_failed":             "Failed to create the link",
+		"script.no_houses_linked":        "No houses linked yet",
+		"script.no_link_requests":        "No link requests",
+		"script.error":                   "Error",
+		"script.link_comment":            "Comment for the staff (apartment number, etc.):",
+		"script.link_sent":               "Request sent, the staff will review it",
+		"script.nothing_found":           "Nothing found",
+		"script.link_request":            "Request link",
+		"script.saving":                  "Saving...",
+		"script.saved":                   "Saved",
+		"script.no_complaints":           "No complaints",
+		"script.apartment_short":         ", apt. ",
+		"script.complaint_sent_at":       "sent ",
+		"script.complaint_reviewed_at":   "reviewed ",
+		"script.complaint_warning_at":    "warning issued ",
+		"script.complaint_resolved_at":   "resolved ",
+		"script.resolution":              "Resolution: ",
+		"script.house_sep":               " • house ",
+		"script.warning_resolved":        " • resolved",
+		"script.complaint_sent":          "Complaint sent, staff will review it",
+		"script.houses_sep":              " • houses ",
+		"script.tfa_status_failed":       "Failed to load status",
+		"script.tfa_enabled":             "enabled",
+		"script.tfa_disabled":            "disabled",
+		"script.tfa_required":            " (required)",
+		"script.tfa_enroll_failed":       "Failed to start enrollment",
+		"script.tfa_enabled_done":        "Enabled",
+		"script.tfa_enable_failed":       "Failed to enable",
+		"script.tfa_codes_regenerated":   "New recovery codes generated",
+		"script.tfa_codes_failed":        "Failed to regenerate codes",
+		"script.tfa_disable_confirm":     "Disable two-factor authentication?",
+		"script.tfa_disabled_done":       "Disabled",
+		"script.tfa_disable_failed":      "Failed to disable",
+		"script.endpoint_missing":        "Endpoint is not set or there is no endpoint.",
+		"script.hidden_suffix":           " (hidden)",
+		"script.resident_suggested":      "Resident suggested: ",
+		"script.organization_sep":        " • organization: ",
+		"script.resident":                "Resident:",
+		"script.house":                   "House:",
+		"script.resident_suggests":       "(resident suggests %s)",
+		"script.responsible_sep":         " • responsible: ",
+		"script.category_prefix":         "category: ",
+		"script.due_prefix":              "due ",
+		"script.overdue_suffix":          " • overdue",
+		"script.planned_maintenance":     "planned maintenance, plan %s",
+		"script.duplicate_of":            "duplicate of %s, the status follows it",
+		"script.transferred_prefix":      "transferred ",
+		"script.accepted_prefix":         "accepted ",
+		"script.not_accepted":            "not accepted yet",
+		"script.done_prefix":             "done ",
+		"script.invoice_prefix":          "invoice: ",
+		"script.report_prefix":           "Report: ",
+		"script.edit":                    "Edit",
+		"script.get_phone":               "Get phone",
+		"script.no_resident_id":          "No resident ID",
+		"script.phone_failed":            "Failed to get phone, HTTP %s",
+		"script.phone_prefix":            "Phone: ",
+		"script.phone_not_found":         "Phone not found",
+		"script.delete":                  "Delete",
+		"script.delete_request_confirm":  "Delete request %s?",
+		"script.delete_failed":           "Delete failed: %s",
+		"script.retransfer":              "Re-transfer",
+		"script.transfer":                "Transfer",
+		"script.transfer_prompt":         "Organization ID to transfer request %s to:",
+		"script.contractor_accepted":     "Contractor accepted",
+		"script.contractor_done":         "Contractor done",
+		"script.completion_report":       "Completion report:",
+		"script.invoice_amount":          "Invoice amount:",
+		"script.invoice_not_number":      "Invoice amount must be a number",
+		"script.updates":                 "Updates",
+		"script.no_updates":              "No updates",
+		"script.costs":                   "Costs",
+		"script.no_line_items":           "No line items",
+		"script.add_line_item":           "Add a line item?",
+		"script.line_kind_prompt":        "Kind (материалы, работа, счет_подрядчика):",
+		"script.description_prompt":      "Description:",
+		"script.line_quantity_prompt":    "Quantity (hours for работа):",
+		"script.unit_price_prompt":       "Unit price:",
+		"script.payer_prompt":            "Payer (фонд_тсж or житель), empty for the default:",
+		"script.materials":               "Materials",
+		"script.from_location":           " from %s",
+		"script.deleted_location":        "deleted location",
+		"script.no_materials":            "No materials used",
+		"script.materials_prompt":        "Enter \"new\" to take materials or a number to return them to the storeroom:",
+		"script.no_such_entry":           "No such entry",
+		"script.return_confirm":          "Return %s of %s? The cost line is removed too.",
+		"script.item_search_prompt":      "Item name (part of it):",
+		"script.no_items_in_stock":       "No such items in stock",
+		"script.quantity_unit_prompt":    "Quantity (%s):",
+		"script.visits":                  "Visits",
+		"script.no_visit_times":          "No visit times proposed",
+		"script.visits_prompt":           "Enter \"new\" to propose a time or a number to cancel that slot:",
+		"script.start_prompt":            "Start (YYYY-MM-DDTHH:MM):",
+		"script.duration_prompt":         "Duration in minutes:",
+		"script.invalid_start_duration":  "Invalid start or duration",
+		"script.no_such_slot":            "No such slot",
+		"script.reason_prompt":           "Reason (shown to the resident):",
+		"script.unmerge":                 "Unmerge",
+		"script.unmerge_confirm":         "Make request %s independent of %s?",
+		"script.duplicates":              "Duplicates",
+		"script.merge_duplicates":        "Merge duplicates",
+		"script.merge_prompt":            "IDs of the duplicates to merge into %s (comma separated):",
+		"script.enter_job_id":            "Enter jobID",
+		"script.looking_up":              "Looking up...",
+		"script.lookup_failed":           "Lookup failed",
+		"script.found_responsible":       "Found responsible ID: ",
+		"script.no_responsible":          "No responsible found",
+		"script.reporter_prefix":         "reporter: ",
+		"script.about_sep":               " • about: ",
+		"script.sent_sep":                " • sent ",
+		"script.handled_by_sep":          " • handled by ",
+		"script.warning_at":              "Warning (%s): ",
+		"script.complaint_reviewed":      "Reviewed",
+		"script.accused_prompt":          "ID of the resident it is about, empty if unknown:",
+		"script.issue_warning":           "Issue warning",
+		"script.warning_resident_prompt": "ID of the resident the warning is for:",
+		"script.warning_text_prompt":     "Warning text, the resident sees it without the complaint or the reporter:",
+		"script.warning_issued":          "Warning issued",
+		"script.resolve":                 "Resolve",
+		"script.resolution_prompt":       "Resolution, the reporter sees it:",
+		"script.complaint_resolved":      "Resolved",
+		"script.finish_confirm":          "End \"%s\" now?",
+		"script.finished":                "Finished",
+		"script.delete_confirm":          "Delete \"%s\"?",
+		"script.deleted":                 "Deleted",
+		"script.no_announcements":        "No announcements",
+		"script.by_sep":                  " • by ",
+		"script.finish_now":              "End now",
+		"script.posting":                 "Posting...",
+		"script.posted":                  "Posted",
+		"script.token_personal":          "Personal access token",
+		"script.token_access":            "App access token",
+		"script.token_refresh":           "App refresh token",
+		"script.no_tokens":               "No active tokens",
+		"script.never":                   "never",
+		"script.kind":                    "Kind:",
+		"script.token_dates":             "created %s • expires %s • last used %s",
+		"script.revoke":                  "Revoke",
+		"script.revoke_confirm":          "Revoke this token?",
+		"script.revoke_failed":           "Revoke failed: %s",
+		"script.create_failed":           "Create failed: %s",
+		"script.comment_optional_prompt": "Comment (optional):",
+		"script.rejection_reason_prompt": "Reason of rejection:",
+		"script.nothing_to_review":       "Nothing to review",
+		"script.payer_sep":               " • payer ",
+		"script.request_prefix":          "request ",
+		"script.approve":                 "Approve",
+		"script.reject":                  "Reject",
+		"script.nobody_on_duty":          "Nobody on duty",
+		"script.all_day":                 "all day",
+		"script.weekly_shifts":           "Weekly shifts",
+		"script.no_weekly_shifts":        "No weekly shifts, on duty at any time",
+		"script.remove":                  "Remove",
+		"script.absences":                "Absences",
+		"script.no_absences":             "No upcoming absences",
+		"script.enter_member_id":         "Enter the staff member ID first",
+		"script.no_visits":               "No upcoming visits",
+		"script.title_prompt":            "Title:",
+		"script.sla_prompt":              "SLA in hours, empty to take the parent's:",
+		"script.specs_prompt":            "Default specialization IDs, comma separated, empty to take the parent's:",
+		"script.sla_prefix":              "SLA: ",
+		"script.hours_suffix":            " h",
+		"script.inherited":               "inherited",
+		"script.specializations_sep":     " • specializations: ",
+		"script.add_subcategory":         "Add subcategory",
+		"script.hide":                    "Hide",
+		"script.show":                    "Show",
+		"script.hidden_done":             "Hidden",
+		"script.shown_done":              "Shown",
+		"script.no_categories":           "No categories yet",
+		"script.added":                   "Added %s",
+		"script.no_lockouts":             "No active lockouts",
+		"script.key":                     "Key:",
+		"script.failures":                "Failures:",
+		"script.lockout_dates":           "blocked until %s • last failure %s",
+		"script.clear":                   "Clear",
+		"script.clear_confirm":           "Clear lockout for %s?",
+		"script.clear_failed":            "Clear failed: %s",
+		"script.no_updates_yet":          "No updates yet",
+		"script.cost_prefix":             "cost: ",
+		"script.accept":                  "Accept",
+		"script.decline":                 "Decline",
+		"script.decline_prompt":          "Why do you decline the request?",
+		"script.post_update":             "Post update",
+		"script.update_prompt":           "Update for request %s:",
+		"script.complete":                "Complete",
+		"script.ratings_average":         "%s from %s ratings",
+		"script.no_ratings":              "no ratings",
+		"script.no_ratings_period":       "No ratings for the period",
+		"script.no_low_ratings":          "No low ratings",
+		"script.request_dash":            " — request ",
+		"script.resumed":                 "Resumed",
+		"script.paused":                  "Paused",
+		"script.plan_delete_confirm":     "Delete \"%s\"? Requests it created stay.",
+		"script.no_plans":                "No plans",
+		"script.house_dash":              " — house ",
+		"script.paused_suffix":           " (paused)",
+		"script.specialization_sep":      " • specialization ",
+		"script.next_sep":                " • next: ",
+		"script.schedule_over":           "none, the schedule is over",
+		"script.last_sep":                " • last: ",
+		"script.pause":                   "Pause",
+		"script.resume":                  "Resume",
+		"script.planned_requests":        "Planned requests",
+		"script.no_occurrences":          "no occurrences",
+		"script.plan_created":            "Plan created, first run ",
+		"script.done":                    "Done",
+		"script.generated":               "Generated %s requests",
+		"script.no_sessions":             "No active sessions",
+		"script.unknown_browser":         "Unknown browser",
+		"script.this_session":            "(this session)",
+		"script.session_dates":           "logged in %s • last seen %s",
+		"script.logout_session_confirm":  "Log out this session?",
+		"script.logout_others_confirm":   "Log out all other sessions?",
+		"script.house_for_prompt":        "House ID for \"%s\":",
+		"script.signup_approved":         "Approved, the account is active",
+		"script.signup_rejected":         "Rejected",
+		"script.no_signups":              "No sign-ups",
+		"script.claims_prefix":           "Claims: ",
+		"script.reviewed_by":             "Reviewed by %s at %s",
+		"script.no_specializations":      "No specializations found",
+		"script.copy_id":                 "Copy ID",
+		"script.copy":                    "Copy",
+		"script.copying":                 "Copying...",
+		"script.copied":                  "Copied",
+		"script.id_copied":               "ID copied to clipboard",
+		"script.failed":                  "Failed",
+		"script.copy_failed":             "Copy failed",
+		"script.name_prefix":             "Name: ",
+		"script.creating":                "Creating...",
+		"script.created":                 "Created",
+		"script.no_locations":            "No storage locations",
+		"script.no_such_location":        "No such location",
+		"script.quantity_positive":       "Quantity must be a positive number",
+		"script.receive_at":              "Receive \"%s\" at:",
+		"script.receive_comment":         "Comment (supplier, invoice):",
+		"script.received":                "Received",
+		"script.write_off_from":          "Write off \"%s\" from:",
+		"script.reason":                  "Reason:",
+		"script.written_off":             "Written off",
+		"script.move_from":               "Move \"%s\" from:",
+		"script.move_to":                 "To:",
+		"script.moved":                   "Moved",
+		"script.unit_price_of":           "Unit price of \"%s\":",
+		"script.low_stock_at":            "Low stock at:",
+		"script.archive_confirm":         "Archive \"%s\"? It can not be received or used afterwards.",
+		"script.updated":                 "Updated",
+		"script.no_items":                "No items",
+		"script.low_suffix":              " • LOW",
+		"script.archived_suffix":         " • archived",
+		"script.item_price":              "price %s per %s • low at %s",
+		"script.out_of_stock":            " • out of stock",
+		"script.receive":                 "Receive",
+		"script.write_off":               "Write off",
+		"script.move":                    "Move",
+		"script.archive":                 "Archive",
+		"script.restore":                 "Restore",
+		"script.restored":                "Restored",
+		"script.running_low":             "Running low: ",
+		"script.left":                    "%s left",
+		"script.any":                     "any",
+		"script.all":                     "all",
+		"script.delete_location_confirm": "Delete location \"%s\"?",
+		"script.stock_report_header":     "Item: opening + received − used − written off ± moved = closing",
+		"script.showing_of":              "Showing %s of %s",
+		"script.no_movements":            "No movements",
+		"script.request_sep":             " • request ",
+		"script.returned_suffix":         " • returned",
+		"script.location_added":          "Location added",
+		"script.item_added":              "Item added",
+		"script.no_houses":               "No houses found",
+		"script.address_prefix":          "Address: ",
+		"script.edit_address":            "Edit address",
+		"script.review_sep":              " • review: ",
+		"script.org_id_empty":            "Organization ID is empty",
+		"script.name_required":           "Name is required",
+		"script.no_contracts":            "No contracts",
+		"script.open_ended":              "open-ended",
+		"script.inactive_suffix":         " (inactive)",
+		"script.deactivate":              "Deactivate",
+		"script.deactivate_confirm":      "Deactivate contract %s?",
+		"script.no_representatives":      "No representatives",
+		"script.contacts_saved":          "Contacts saved",
+		"script.categories_saved":        "Categories saved",
+		"script.contract_added":          "Contract added",
+		"script.no_organizations":        "No organizations found",
+		"script.serves_prefix":           "Serves: ",
+		"script.nothing":                 "nothing",
+		"script.active_contracts_sep":    " • active contracts: ",
+		"script.contact_sep":             " • contact: ",
+		"script.edit_name":               "Edit name",
+		"script.contacts_contracts":      "Contacts & contracts",
+		"script.no_users":                "No users found",
+		"script.phone":                   "Phone:",
+		"script.details":                 "Details",
+		"script.delete_user_confirm":     "Delete user %s?",
+		"script.reset_password":          "Reset password",
+		"script.reset_password_confirm":  "Send a password reset token to %s?",
+		"script.reset_tfa":               "Reset 2FA",
+		"script.reset_tfa_confirm":       "Remove two-factor authentication of %s?",
+		"script.reset_tfa_code":          "Your own two-factor code to confirm the reset:",
+		"script.logout_everywhere":       "Log out everywhere",
+		"script.revoke_sessions_confirm": "%s has %s active session(s). Revoke all of them?",
+		"script.revoked_sessions":        "Revoked sessions: %s",
+		"script.details_failed":          "Failed to fetch details",
+		"script.no_ratings_yet":          "no ratings yet",
+		"script.full_name_prompt":        "Full name:",
+		"script.phone_prompt":            "Phone (changing it moves the login and closes the sessions):",
+		"script.staff_status_prompt":     "New status (работает, недоступен, уволился):",
+		"script.reason_optional":         "Reason (optional):",
+		"script.reassigned":              "Reassigned requests: %s",
+		"script.unassigned":              "Unassigned requests: %s",
+		"script.deactivated_specs":       "Deactivated specializations: %s",
+		"script.changed_by":              " by ",
+		"script.no_status_changes":       "No status changes",
+		"script.no_houses_linked_short":  "No houses",
+		"script.remove_house_confirm":    "Remove house %s from resident?",
+		"script.remove_failed":           "Remove failed",
+		"script.no_specs":                "No specializations",
+		"script.deactivate_spec_confirm": "Deactivate specialization %s?",
+		"script.deactivate_failed":       "Deactivate failed",
+		"script.add_house":               "Add house",
+		"script.house_id":                "House ID",
+		"script.enter_house_id":          "Enter house ID",
+		"script.add_house_hint":          "Assign a house by its numeric ID to this resident.",
+		"script.add_spec":                "Add specialization",
+		"script.spec_id":                 "Specialization ID",
+		"script.enter_spec_id":           "Enter specialization ID",
+		"script.add_spec_hint":           "Assign a specialization by its string ID to this staff member.",
+		"script.positive_number":         "Please enter a valid positive number.",
+		"script.add_house_failed":        "Add house failed",
+		"script.house_added":             "House added.",
+		"script.enter_spec_id_please":    "Please enter a specialization ID.",
+		"script.add_spec_failed":         "Add specialization failed",
+		"script.spec_added":              "Specialization added.",
+		"script.network_or_server":       "Network or server error.",
+
+		"login.title":                "Login",
+		"login.phone":                "Phone number",
+		"login.password":             "Password",
+		"login.password_placeholder": "Your password",
+		"login.submit":               "Sign in",
+		"login.forgot":               "Forgot your password?",
+		"login.no_account":           "No account yet?",
+		"login.signup":               "Sign up as a resident",
+
+		"create_request.notices":            "Notices for your houses",
+		"create_request.title":              "New request",
+		"create_request.house_id":           "House ID",
+		"create_request.type":               "Request type",
+		"create_request.choose":             "Choose an option:",
+		"create_request.category":           "Category",
+		"create_request.dont_know":          "I don't know",
+		"create_request.category_hint":      "The category sends the request straight to the right staff.",
+		"create_request.complaint":          "Complaint",
+		"create_request.complaint_hint":     "Describe your problem",
+		"create_request.urgency":            "How urgent is it?",
+		"create_request.priority_emergency": "Emergency: flooding, no electricity or gas, danger to life",
+		"create_request.priority_high":      "Urgent",
+		"create_request.priority_normal":    "Normal",
+		"create_request.priority_low":       "Not urgent",
+		"create_request.urgency_hint":       "Staff review the urgency and set the priority of the request.",
+		"create_request.send":               "Send request",
+		"create_request.duplicates_title":   "Already reported in this house",
+		"create_request.duplicates_hint":    "Join one of these requests to follow its progress instead of creating another one.",
+		"create_request.send_anyway":        "My problem is different, send it",
+
+		"common.total":           "Total:",
+		"common.prev":            "Prev",
+		"common.next":            "Next",
+		"common.page":            "Page",
+		"common.refresh":         "Refresh",
+		"common.apply":           "Apply",
+		"common.any":             "any",
+		"common.save":            "Save",
+		"common.search":          "Search",
+		"common.sort":            "Sort:",
+		"common.per_page":        "Per page:",
+		"common.house_id":        "House ID",
+		"common.optional":        "optional",
+		"common.kind":            "Kind:",
+		"common.status":          "Status:",
+		"common.comma_separated": "comma separated",
+		"common.create":          "Create",
+		"common.cancel":          "Cancel",
+		"common.close":           "Close",
+
+		"main.about": "HOA complaints service by Vladislav Severov aka lein3000",
+
+		"my_requests.title":             "My requests",
+		"my_requests.total":             "Total requests:",
+		"my_requests.sort_created_desc": "newest first",
+		"my_requests.sort_type_asc":     "by type",
+		"my_requests.sort_status_asc":   "by status",
+		"my_requests.calendar_title":    "Visits calendar",
+		"my_requests.calendar_hint":     "Subscribe to the link in your calendar app to see the agreed repair visits.",
+		"my_requests.calendar_create":   "Create calendar link",
+		"my_requests.statement_title":   "Billing statement",
+		"my_requests.statement_hint":    "Approved charges for repairs inside your apartment. Leave the dates empty for the current month.",
+		"my_requests.from":              "From:",
+		"my_requests.to":                "To:",
+		"my_requests.download_pdf":      "Download PDF",
+
+		"profile.title":              "My profile",
+		"profile.phone":              "Phone number:",
+		"profile.phone_hint":         "To change it, contact the staff.",
+		"profile.full_name":          "Full name:",
+		"profile.email":              "Email:",
+		"profile.contact":            "Preferred contact:",
+		"profile.contact_call":       "phone call",
+		"profile.contact_sms":        "SMS",
+		"profile.contact_email":      "email",
+		"profile.houses":             "My houses",
+		"profile.link_title":         "Request a link to a house",
+		"profile.search_address":     "Search by address:",
+		"profile.search_placeholder": "street, building",
+		"profile.link_requests":      "My link requests",
+
+		"signup.hint":                        "The account becomes active after the staff check that you live at the address.",
+		"signup.full_name":                   "Full name",
+		"signup.full_name_placeholder":       "John Doe",
+		"signup.address":                     "Address of the house",
+		"signup.apartment":                   "Apartment",
+		"signup.password_placeholder":        "Create a password",
+		"signup.submit":                      "Sign up",
+		"signup.status_title":                "Check the sign-up status",
+		"signup.status_password_placeholder": "Password of the sign-up",
+		"signup.check":                       "Check",
+
+		"password.rules":                  "At least 8 characters mixing letters, digits or symbols, or a passphrase of 16+ characters",
+		"password.current":                "Current password",
+		"password.new":                    "New password",
+		"password.confirm":                "Confirm new password",
+		"password.repeat":                 "Repeat new password",
+		"password.forgot_title":           "Forgot password",
+		"password.send_code":              "Send reset code",
+		"password.reset_title":            "Set a new password",
+		"password.reset_code":             "Reset code",
+		"password.reset_code_placeholder": "Code you have received",
+		"password.reset_submit":           "Reset password",
+
+		"two_factor.title":       "Two-factor authentication",
+		"two_factor.code":        "Code from your authenticator app or a recovery code",
+		"two_factor.verify":      "Verify",
+		"two_factor.status":      "Status:",
+		"two_factor.enroll_hint": "Add the account to an authenticator app by scanning a QR code generated from the URI below or by entering the secret manually.",
+		"two_factor.generate":    "Generate secret",
+		"two_factor.secret":      "Secret:",
+		"two_factor.app_code":    "Code from the app",
+		"two_factor.enable":      "Enable",
+		"two_factor.manage_code": "Current code or a recovery code",
+		"two_factor.regenerate":  "New recovery codes",
+		"two_factor.disable":     "Disable",
+		"two_factor.codes_hint":  "Recovery codes, each works once. Store them somewhere safe, they will not be shown again.",
+		"two_factor.continue":    "Continue",
+
+		"calendar.title":             "Duty calendar",
+		"calendar.hint":              "Working staff on shift per specialization. Staff without a schedule are on duty all day.",
+		"calendar.days":              "Days:",
+		"calendar.specialization_id": "Specialization ID:",
+		"calendar.show":              "Show",
+		"calendar.member_title":      "Schedule of a staff member",
+		"calendar.member_id":         "Staff member ID:",
+		"calendar.load":              "Load",
+		"calendar.weekday":           "Weekday:",
+		"calendar.start":             "Start:",
+		"calendar.end":               "End:",
+		"calendar.set_shift":         "Set shift",
+		"calendar.comment":           "Comment:",
+		"calendar.add_absence":       "Add absence",
+		"calendar.visits_title":      "My visits",
+		"calendar.visits_hint":       "Apartment visits residents agreed to. The calendar link shows them in your calendar app.",
+
+		"weekday.0": "Sunday",
+		"weekday.1": "Monday",
+		"weekday.2": "Tuesday",
+		"weekday.3": "Wednesday",
+		"weekday.4": "Thursday",
+		"weekday.5": "Friday",
+		"weekday.6": "Saturday",
+
+		"complaints.new":              "New complaint",
+		"complaints.hint":             "For noise, parking, neighbours or cleanliness. Only staff see who complained, the neighbour is never told your name.",
+		"complaints.kind":             "What about",
+		"complaints.apartment":        "Apartment it comes from",
+		"complaints.text":             "Complaint",
+		"complaints.text_placeholder": "What happens and when",
+		"complaints.send":             "Send complaint",
+		"complaints.warnings":         "Warnings to you",
+		"complaints.mine":             "My complaints",
+
+		"sessions.hint":          "Browsers where you are logged in. Revoke any session you do not recognise.",
+		"sessions.revoke_others": "Log out other sessions",
+
+		"api_tokens.hint":             "Tokens used by the mobile app and integrations on your behalf. Revoke any token you no longer use.",
+		"api_tokens.name":             "Token name",
+		"api_tokens.name_placeholder": "Intercom integration",
+		"api_tokens.expires_days":     "Expires in (days)",
+		"api_tokens.create":           "Create personal access token",
+		"api_tokens.copy_now":         "Copy the token now, it will not be shown again:",
+
+		"register.title":                    "Register",
+		"register.roles":                    "Roles",
+		"register.resident":                 "Resident",
+		"register.staff":                    "Staff member",
+		"register.contractor":               "Contractor representative",
+		"register.organization_id":          "Organization ID",
+		"register.organization_placeholder": "only for contractor representatives",
+		"register.organization_hint":        "The representative sees only requests transferred to this organization",
+		"register.submit":                   "Create account",
+
+		"admin.hint":            "Quick navigator.",
+		"admin.register":        "User registration",
+		"admin.signups":         "Sign-up requests",
+		"admin.specializations": "Manage specializations",
+		"admin.houses":          "Manage houses",
+		"admin.announcements":   "Announcements",
+		"admin.maintenance":     "Maintenance plans",
+		"admin.inventory":       "Storeroom inventory",
+		"admin.categories":      "Request categories",
+		"admin.organizations":   "Manage organizations",
+		"admin.requests":        "Manage requests",
+		"admin.users":           "Manage users",
+		"admin.billing":         "Billing",
+		"admin.ratings":         "Resident ratings",
+		"admin.lockouts":        "Login lockouts",
+
+		"lockouts.hint": "Phone numbers and addresses currently blocked after failed login attempts.",
+
+		"signups.hint":   "Check that the applicant lives at the claimed address, then approve the sign-up with the matching house or reject it with a reason.",
+		"signups.search": "Search (phone, name or address):",
+
+		"categories.hint":               "Residents pick a category when reporting a problem. A request of a category with default specializations goes straight to the least busy of that staff, the SLA sets its deadline. A subcategory without its own SLA or specializations takes the parent's.",
+		"categories.title":              "Title:",
+		"categories.title_placeholder":  "Plumbing",
+		"categories.under":              "Under:",
+		"categories.sla_hours":          "SLA, hours:",
+		"categories.inherit":            "inherit",
+		"categories.specialization_ids": "Specialization IDs:",
+		"categories.add":                "Add category",
+		"categories.show_hidden":        "hidden too",
+
+		"announcements.hint":              "Residents see the notices in force on the main and the new request pages. An active outage asks the resident to confirm before a new request for the house is sent.",
+		"announcements.title_placeholder": "No cold water",
+		"announcements.text":              "Text:",
+		"announcements.house_ids":         "House IDs (comma separated):",
+		"announcements.starts_at":         "Starts at:",
+		"announcements.ends_at":           "Ends at:",
+		"announcements.post":              "Post",
+		"announcements.only_active":       "Only active",
+
+		"billing.pending_title":   "Line items waiting for approval",
+		"billing.hint":            "Only accountants can approve or reject, and never their own items.",
+		"billing.pending":         "Pending:",
+		"billing.statement_title": "Resident statement",
+		"billing.resident_id":     "Resident ID:",
+		"billing.accountants":     "Accountants",
+		"billing.grant":           "Grant",
+		"billing.revoke":          "Revoke",
+
+		"ratings.hint":              "Residents rate their completed requests from 1 to 5. Leave the dates empty for the whole time.",
+		"ratings.group_by":          "Group by:",
+		"ratings.by_staff":          "staff member",
+		"ratings.by_specialization": "specialization",
+		"ratings.by_organization":   "organization",
+		"ratings.overall":           "Overall:",
+		"ratings.low_title":         "Low ratings",
+		"ratings.low_hint":          "Ratings of 2 and below, newest first.",
+
+		"maintenance.hint":              "Each plan creates a planned request for the house's common property on schedule and gives it to the least busy staff member of the specialization.",
+		"maintenance.title_placeholder": "Elevator inspection",
+		"maintenance.description":       "Description:",
+		"maintenance.rule":              "Schedule (RRULE):",
+		"maintenance.rule_hint":         "Supported: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY (MO..SU), BYMONTHDAY (-1 is the last day), BYMONTH, UNTIL (YYYYMMDD) or COUNT.",
+		"maintenance.first_run":         "First run:",
+		"maintenance.preview":           "Preview",
+		"maintenance.create":            "Create plan",
+		"maintenance.run":               "Generate due requests now",
+
+		"specializations.create":             "Create specialization",
+		"specializations.search":             "Search:",
+		"specializations.search_placeholder": "search by id or name",
+		"specializations.job_name":           "Job name:",
+
+		"inventory.hint":                 "Materials taken for a request are added to its costs from the \"Materials\" button on the requests panel.",
+		"inventory.locations":            "Storage locations",
+		"inventory.location_placeholder": "Main storeroom",
+		"inventory.none":                 "none",
+		"inventory.add_location":         "Add location",
+		"inventory.items":                "Items",
+		"inventory.name":                 "Name:",
+		"inventory.item_placeholder":     "LED bulb E27",
+		"inventory.unit":                 "Unit:",
+		"inventory.unit_placeholder":     "pcs",
+		"inventory.unit_price":           "Unit price:",
+		"inventory.min_stock":            "Low stock at:",
+		"inventory.add_item":             "Add item",
+		"inventory.name_placeholder":     "name",
+		"inventory.location":             "Location:",
+		"inventory.low_only":             "low stock only",
+		"inventory.archived_too":         "archived too",
+		"inventory.movements_title":      "Stock movements",
+		"inventory.all":                  "all",
+		"inventory.report":               "Report",
+		"inventory.movements":            "Movements",
+
+		"houses.search":             "Search (ID or address):",
+		"houses.search_placeholder": "enter id or address",
+		"houses.add":                "Add house",
+		"houses.address":            "Address:",
+		"houses.update":             "Update house",
+		"houses.link_requests":      "House link requests",
+
+		"organizations.search":               "Search (ID or name):",
+		"organizations.search_placeholder":   "enter id or name",
+		"organizations.add":                  "Add organization",
+		"organizations.update":               "Update organization",
+		"organizations.details":              "Organization details",
+		"organizations.contacts":             "Contacts",
+		"organizations.contact_person":       "Contact person:",
+		"organizations.phone":                "Phone:",
+		"organizations.save_contacts":        "Save contacts",
+		"organizations.types":                "Served request types",
+		"organizations.save_types":           "Save categories",
+		"organizations.contracts":            "Contracts",
+		"organizations.representatives":      "Representatives",
+		"organizations.representatives_hint": "Representatives are registered on the user registration page with the contractor role",
+		"organizations.number":               "Number:",
+		"organizations.starts":               "Starts:",
+		"organizations.ends":                 "Ends:",
+		"organizations.add_contract":         "Add contract",
+
+		"requests.sort_created_asc":      "oldest first",
+		"requests.sort_type_desc":        "by type, reversed",
+		"requests.sort_status_desc":      "by status, reversed",
+		"requests.sort_priority_asc":     "most urgent first",
+		"requests.sort_priority_desc":    "least urgent first",
+		"requests.sort_due_asc":          "nearest deadline first",
+		"requests.request_id":            "request id",
+		"requests.resident_id":           "resident id",
+		"requests.id_example":            "e.g. 123",
+		"requests.house_hint":            "Filter by house identifier",
+		"requests.responsible_id":        "Responsible ID",
+		"requests.responsible_hint":      "Staff member numeric id (optional)",
+		"requests.organization_id":       "organization id",
+		"requests.merged_into":           "Merged into:",
+		"requests.parent_id":             "parent request id",
+		"requests.origin":                "Origin:",
+		"requests.origin_residents":      "reported by residents",
+		"requests.origin_plans":          "maintenance plans",
+		"requests.type":                  "Type:",
+		"requests.priority":              "Priority:",
+		"requests.category":              "Category:",
+		"requests.overdue_only":          "overdue only",
+		"requests.complaint_contains":    "Complaint contains:",
+		"requests.complaint_placeholder": "search in complaint",
+		"requests.edit":                  "Edit request",
+		"requests.id_readonly":           "ID (readonly):",
+		"requests.house_numeric":         "Numeric house identifier",
+		"requests.cost":                  "Cost:",
+		"requests.cost_hint":             "Enter cost in your currency (optional)",
+		"requests.numeric_id":            "numeric id",
+		"requests.keep":                  "keep",
+		"requests.category_hint":         "Another category sets the deadline anew by its SLA",
+		"requests.complaint":             "Complaint:",
+		"requests.organization_hint":     "Set by the Transfer action, which checks the contract",
+		"requests.add_job_lookup":        "Add job lookup",
+		"requests.job_id":                "Job ID",
+		"requests.job_id_placeholder":    "job id",
+		"requests.find":                  "Find",
+		"requests.job_lookup_hint":       "Find least busy staff by job id",
+
+		"contractor.title":          "Transferred requests",
+		"contractor.complete_title": "Mark request completed",
+		"contractor.request_id":     "Request ID:",
+		"contractor.report":         "Completion report:",
+		"contractor.complete":       "Complete",
+
+		"users.search":              "Search phone:",
+		"users.search_placeholder":  "enter phone or part of it",
+		"users.details":             "User details",
+		"users.get_houses":          "Get houses",
+		"users.rating":              "Resident rating:",
+		"users.get_specializations": "Get specializations",
+		"users.add_specialization":  "Add specialization",
+		"users.edit_profile":        "Edit profile",
+		"users.change_status":       "Change status",
+		"users.status_history":      "Status history",
+		"users.add":                 "Add",
+		"users.enter_id":            "Enter ID",
+
+		"admin_complaints.hint": "Complaints about noise, parking, neighbours and cleanliness go through review, a warning and resolution. The resident a warning is given to sees only its text, never the reporter or the complaint.",
+
+		"page.main":                      "Home",
+		"page.admin_requests":            "Manage requests",
+		"page.user_management":           "Manage users",
+		"page.houses_management":         "Manage houses",
+		"page.orgs_management":           "Manage organizations",
+		"page.register":                  "Register",
+		"page.my_requests":               "My requests",
+		"page.admin_panel":               "Admin panel",
+		"page.password_reset":            "Password reset",
+		"page.change_password":           "Change password",
+		"page.login_lockouts":            "Login lockouts",
+		"page.two_factor_authentication": "Two-factor authentication",
+		"page.two_factor_setup":          "Two-factor setup",
+		"page.active_sessions":           "Active sessions",
+		"page.api_tokens":                "API tokens",
+		"page.contractor_requests":       "Contractor requests",
+		"page.billing":                   "Billing",
+		"page.duty_calendar":             "Duty calendar",
+		"page.my_profile":                "My profile",
+		"page.sign_up":                   "Sign up",
+		"page.sign_ups":                  "Sign-up requests",
+		"page.announcements":             "Announcements",
+		"page.ratings":                   "Resident ratings",
+		"page.maintenance":               "Maintenance plans",
+		"page.inventory":                 "Storeroom inventory",
+		"page.categories":                "Request categories",
+		"page.my_complaints":             "My complaints",
+		"page.complaints":                "Complaints",
+
+		"brand": "HOA",
+
+		"footer": "HOA complaints service, built with golang + gin",
+	},
+	LangRU: {
+		"request_status.created":     "Создана",
+		"request_status.assigned":    "Назначена исполнителю",
+		"request_status.completed":   "Выполнена",
+		"request_status.cancelled":   "Отменена",
+		"request_status.suspended":   "Приостановлена",
+		"request_status.transferred": "Передана организации",
+
+		"request_type.apartment_internal": "Ремонт внутриквартирный",
+		"request_type.house_common":       "Ремонт общедомового имущества",
+
+		"request_priority.emergency": "Аварийная",
+		"request_priority.high":      "Высокая",
+		"request_priority.normal":    "Обычная",
+		"request_priority.low":       "Низкая",
+
+		"staff_status.active":      "Работает",
+		"staff_status.unavailable": "Недоступен",
+		"staff_status.dismissed":   "Уволился",
+
+		"signup_status.pending":  "На рассмотрении",
+		"signup_status.approved": "Одобрена",
+		"signup_status.rejected": "Отклонена",
+
+		"complaint_kind.noise":       "Шум",
+		"complaint_kind.parking":     "Парковка",
+		"complaint_kind.neighbors":   "Соседи",
+		"complaint_kind.cleanliness": "Чистота",
+
+		"complaint_status.submitted":      "Подана",
+		"complaint_status.reviewed":       "Рассмотрена",
+		"complaint_status.warning_issued": "Вынесено предупреждение",
+		"complaint_status.resolved":       "Решена",
+
+		"absence_kind.vacation":   "Отпуск",
+		"absence_kind.sick_leave": "Больничный",
+		"absence_kind.day_off":    "Отгул",
+
+		"contact_channel.call":  "Звонок",
+		"contact_channel.sms":   "SMS",
+		"contact_channel.email": "Эл. почта",
+
+		"link_status.pending":  "На рассмотрении",
+		"link_status.approved": "Одобрена",
+		"link_status.rejected": "Отклонена",
+
+		"announcement_kind.notice": "Объявление",
+		"announcement_kind.outage": "Отключение",
+
+		"slot_status.proposed":  "Предложено",
+		"slot_status.chosen":    "Выбрано",
+		"slot_status.cancelled": "Отменено",
+
+		"billing_item.materials":          "Материалы",
+		"billing_item.labor":              "Работа",
+		"billing_item.contractor_invoice": "Счёт подрядчика",
+
+		"billing_payer.hoa_fund": "Фонд ТСЖ",
+		"billing_payer.resident": "Житель",
+
+		"billing_approval.pending":  "На проверке",
+		"billing_approval.approved": "Одобрено",
+		"billing_approval.rejected": "Отклонено",
+
+		"movement_kind.receipt":   "Поступление",
+		"movement_kind.usage":     "Расход по заявке",
+		"movement_kind.return":    "Возврат",
+		"movement_kind.write_off": "Списание",
+		"movement_kind.transfer":  "Перемещение",
+
+		"lang.ru": "Русский",
+		"lang.en": "English",
+
+		"nav.logged_in_as":      "Вы вошли как",
+		"nav.admin_panel":       "Панель администратора",
+		"nav.contractor_portal": "Портал подрядчика",
+		"nav.create_request":    "Создать заявку",
+		"nav.my_requests":       "Мои заявки",
+		"nav.complaints":        "Жалобы",
+		"nav.profile":           "Мой профиль",
+		"nav.change_password":   "Сменить пароль",
+		"nav.sessions":          "Активные сеансы",
+		"nav.api_tokens":        "API-токены",
+		"nav.logout":            "Выйти",
+		"nav.login":             "Войти",
+		"nav.language":          "Язык",
+
+		"script.submitting":              "Отправка...",
+		"script.success":                 "Готово: ",
+		"script.network_error":           "Ошибка сети",
+		"script.request_created":         "Заявка %s создана",
+		"script.request_joined":          "Вы присоединились к заявке %s, её ход виден в разделе «Мои заявки»",
+		"script.join":                    "Присоединиться",
+		"script.reported_by":             "сообщили",
+		"script.no_requests":             "Заявок не найдено",
+		"script.type":                    "Тип:",
+		"script.status":                  "Статус:",
+		"script.priority":                "Приоритет:",
+		"script.request_failed":          "Запрос не выполнен",
+		"script.visit_agreed":            "Визит согласован:",
+		"script.visit_choose":            "Выберите время визита:",
+		"script.visit_cancel":            "Отменить визит",
+		"script.visit_cancel_reason":     "Почему это время вам не подходит? Сотрудники предложат другое.",
+		"script.visit_move":              "Перенести сюда",
+		"script.visit_pick":              "Выбрать",
+		"script.your_rating":             "Ваша оценка: ",
+		"script.rate_prompt":             "Насколько вы довольны результатом?",
+		"script.comment_optional":        "Комментарий (необязательно)",
+		"script.rate_change":             "Изменить оценку",
+		"script.rate":                    "Оценить",
+		"script.rate_failed":             "Не удалось сохранить оценку",
+		"script.loading":                 "Загрузка...",
+		"script.feed_confirm":            "Новая ссылка заменит прежнюю. Продолжить?",
+		"script.feed_failed":             "Не удалось создать ссылку",
+		"script.no_houses_linked":        "Дома ещё не привязаны",
+		"script.no_link_requests":        "Запросов на привязку нет",
+		"script.error":                   "Ошибка",
+		"script.link_comment":            "Комментарий для сотрудников (номер квартиры и т. п.):",
+		"script.link_sent":               "Запрос отправлен, сотрудники его рассмотрят",
+		"script.nothing_found":           "Ничего не найдено",
+		"script.link_request":            "Запросить привязку",
+		"script.saving":                  "Сохранение...",
+		"script.saved":                   "Сохранено",
+		"script.no_complaints":           "Жалоб нет",
+		"script.apartment_short":         ", кв. ",
+		"script.complaint_sent_at":       "отправлена ",
+		"script.complaint_reviewed_at":   "рассмотрена ",
+		"script.complaint_warning_at":    "вынесено предупреждение ",
+		"script.complaint_resolved_at":   "закрыта ",
+		"script.resolution":              "Решение: ",
+		"script.house_sep":               " • дом ",
+		"script.warning_resolved":        " • снято",
+		"script.complaint_sent":          "Жалоба отправлена, сотрудники её рассмотрят",
+		"script.houses_sep":              " • дома ",
+		"script.tfa_status_failed":       "Не удалось загрузить состояние",
+		"script.tfa_enabled":             "включена",
+		"script.tfa_disabled":            "выключена",
+		"script.tfa_required":            " (обязательна)",
+		"script.tfa_enroll_failed":       "Не удалось начать подключение",
+		"script.tfa_enabled_done":        "Включено",
+		"script.tfa_enable_failed":       "Не удалось включить",
+		"script.tfa_codes_regenerated":   "Созданы новые коды восстановления",
+		"script.tfa_codes_failed":        "Не удалось создать новые коды",
+		"script.tfa_disable_confirm":     "Отключить двухфакторную аутентификацию?",
+		"script.tfa_disabled_done":       "Выключено",
+		"script.tfa_disable_failed":      "Не удалось отключить",
+		"script.endpoint_missing":        "Адрес отправки формы не задан.",
+		"script.hidden_suffix":           " (скрыта)",
+		"script.resident_suggested":      "Житель предложил: ",
+		"script.organization_sep":        " • организация: ",
+		"script.resident":                "Житель:",
+		"script.house":                   "Дом:",
+		"script.resident_suggests":       "(житель предлагает: %s)",
+		"script.responsible_sep":         " • ответственный: ",
+		"script.category_prefix":         "категория: ",
+		"script.due_prefix":              "срок: ",
+		"script.overdue_suffix":          " • просрочена",
+		"script.planned_maintenance":     "плановое обслуживание, план %s",
+		"script.duplicate_of":            "дубликат заявки %s, статус следует за ней",
+		"script.transferred_prefix":      "передана ",
+		"script.accepted_prefix":         "принята ",
+		"script.not_accepted":            "ещё не принята",
+		"script.done_prefix":             "выполнена ",
+		"script.invoice_prefix":          "счёт: ",
+		"script.report_prefix":           "Отчёт: ",
+		"script.edit":                    "Изменить",
+		"script.get_phone":               "Узнать телефон",
+		"script.no_resident_id":          "Нет ID жителя",
+		"script.phone_failed":            "Не удалось получить телефон, HTTP %s",
+		"script.phone_prefix":            "Телефон: ",
+		"script.phone_not_found":         "Телефон не найден",
+		"script.delete":                  "Удалить",
+		"script.delete_request_confirm":  "Удалить заявку %s?",
+		"script.delete_failed":           "Не удалось удалить: %s",
+		"script.retransfer":              "Передать заново",
+		"script.transfer":                "Передать",
+		"script.transfer_prompt":         "ID организации, которой передать заявку %s:",
+		"script.contractor_accepted":     "Подрядчик принял",
+		"script.contractor_done":         "Подрядчик выполнил",
+		"script.completion_report":       "Отчёт о выполнении:",
+		"script.invoice_amount":          "Сумма счёта:",
+		"script.invoice_not_number":      "Сумма счёта должна быть числом",
+		"script.updates":                 "Обновления",
+		"script.no_updates":              "Обновлений нет",
+		"script.costs":                   "Затраты",
+		"script.no_line_items":           "Позиций нет",
+		"script.add_line_item":           "Добавить позицию?",
+		"script.line_kind_prompt":        "Вид (материалы, работа, счет_подрядчика):",
+		"script.description_prompt":      "Описание:",
+		"script.line_quantity_prompt":    "Количество (часы для вида «работа»):",
+		"script.unit_price_prompt":       "Цена за единицу:",
+		"script.payer_prompt":            "Плательщик (фонд_тсж или житель), пусто — по умолчанию:",
+		"script.materials":               "Материалы",
+		"script.from_location":           " со склада %s",
+		"script.deleted_location":        "удалённое место",
+		"script.no_materials":            "Материалы не использовались",
+		"script.materials_prompt":        "Введите «new», чтобы взять материалы, или номер, чтобы вернуть их на склад:",
+		"script.no_such_entry":           "Нет такой записи",
+		"script.return_confirm":          "Вернуть %s позиции «%s»? Строка затрат тоже будет удалена.",
+		"script.item_search_prompt":      "Название позиции (или его часть):",
+		"script.no_items_in_stock":       "Таких позиций на складе нет",
+		"script.quantity_unit_prompt":    "Количество (%s):",
+		"script.visits":                  "Визиты",
+		"script.no_visit_times":          "Время визита не предложено",
+		"script.visits_prompt":           "Введите «new», чтобы предложить время, или номер, чтобы отменить это время:",
+		"script.start_prompt":            "Начало (ГГГГ-ММ-ДДTЧЧ:ММ):",
+		"script.duration_prompt":         "Длительность в минутах:",
+		"script.invalid_start_duration":  "Неверное начало или длительность",
+		"script.no_such_slot":            "Нет такого времени",
+		"script.reason_prompt":           "Причина (её увидит житель):",
+		"script.unmerge":                 "Отделить",
+		"script.unmerge_confirm":         "Сделать заявку %s независимой от %s?",
+		"script.duplicates":              "Дубликаты",
+		"script.merge_duplicates":        "Объединить дубликаты",
+		"script.merge_prompt":            "ID дубликатов для объединения с заявкой %s (через запятую):",
+		"script.enter_job_id":            "Введите jobID",
+		"script.looking_up":              "Поиск...",
+		"script.lookup_failed":           "Поиск не удался",
+		"script.found_responsible":       "Найден ответственный, ID: ",
+		"script.no_responsible":          "Ответственный не найден",
+		"script.reporter_prefix":         "заявитель: ",
+		"script.about_sep":               " • на кого: ",
+		"script.sent_sep":                " • отправлена ",
+		"script.handled_by_sep":          " • обработал ",
+		"script.warning_at":              "Предупреждение (%s): ",
+		"script.complaint_reviewed":      "Рассмотрена",
+		"script.accused_prompt":          "ID жителя, на которого жалоба, пусто — если неизвестно:",
+		"script.issue_warning":           "Вынести предупреждение",
+		"script.warning_resident_prompt": "ID жителя, которому выносится предупреждение:",
+		"script.warning_text_prompt":     "Текст предупреждения, житель увидит его без жалобы и без заявителя:",
+		"script.warning_issued":          "Предупреждение вынесено",
+		"script.resolve":                 "Закрыть",
+		"script.resolution_prompt":       "Решение, его увидит заявитель:",
+		"script.complaint_resolved":      "Закрыта",
+		"script.finish_confirm":          "Завершить «%s» сейчас?",
+		"script.finished":                "Завершено",
+		"script.delete_confirm":          "Удалить «%s»?",
+		"script.deleted":                 "Удалено",
+		"script.no_announcements":        "Объявлений нет",
+		"script.by_sep":                  " • автор: ",
+		"script.finish_now":              "Завершить сейчас",
+		"script.posting":                 "Публикация...",
+		"script.posted":                  "Опубликовано",
+		"script.token_personal":          "Личный токен доступа",
+		"script.token_access":            "Токен доступа приложения",
+		"script.token_refresh":           "Токен обновления приложения",
+		"script.no_tokens":               "Активных токенов нет",
+		"script.never":                   "никогда",
+		"script.kind":                    "Вид:",
+		"script.token_dates":             "создан %s • истекает %s • использован %s",
+		"script.revoke":                  "Отозвать",
+		"script.revoke_confirm":          "Отозвать этот токен?",
+		"script.revoke_failed":           "Не удалось отозвать: %s",
+		"script.create_failed":           "Не удалось создать: %s",
+		"script.comment_optional_prompt": "Комментарий (необязательно):",
+		"script.rejection_reason_prompt": "Причина отказа:",
+		"script.nothing_to_review":       "Проверять нечего",
+		"script.payer_sep":               " • плательщик: ",
+		"script.request_prefix":          "заявка ",
+		"script.approve":                 "Одобрить",
+		"script.reject":                  "Отклонить",
+		"script.nobody_on_duty":          "Никто не дежурит",
+		"script.all_day":                 "весь день",
+		"script.weekly_shifts":           "Смены по неделе",
+		"script.no_weekly_shifts":        "Смен по неделе нет, дежурит в любое время",
+		"script.remove":                  "Убрать",
+		"script.absences":                "Отсутствия",
+		"script.no_absences":             "Предстоящих отсутствий нет",
+		"script.enter_member_id":         "Сначала введите ID сотрудника",
+		"script.no_visits":               "Предстоящих визитов нет",
+		"script.title_prompt":            "Название:",
+		"script.sla_prompt":              "SLA в часах, пусто — как у родительской:",
+		"script.specs_prompt":            "ID специализаций по умолчанию через запятую, пусто — как у родительской:",
+		"script.sla_prefix":              "SLA: ",
+		"script.hours_suffix":            " ч",
+		"script.inherited":               "наследуется",
+		"script.specializations_sep":     " • специализации: ",
+		"script.add_subcategory":         "Добавить подкатегорию",
+		"script.hide":                    "Скрыть",
+		"script.show":                    "Показать",
+		"script.hidden_done":             "Скрыта",
+		"script.shown_done":              "Показана",
+		"script.no_categories":           "Категорий пока нет",
+		"script.added":                   "Добавлено: %s",
+		"script.no_lockouts":             "Активных блокировок нет",
+		"script.key":                     "Ключ:",
+		"script.failures":                "Неудачных попыток:",
+		"script.lockout_dates":           "заблокировано до %s • последняя неудача %s",
+		"script.clear":                   "Снять",
+		"script.clear_confirm":           "Снять блокировку для %s?",
+		"script.clear_failed":            "Не удалось снять: %s",
+		"script.no_updates_yet":          "Обновлений пока нет",
+		"script.cost_prefix":             "стоимость: ",
+		"script.accept":                  "Принять",
+		"script.decline":                 "Отказаться",
+		"script.decline_prompt":          "Почему вы отказываетесь от заявки?",
+		"script.post_update":             "Добавить обновление",
+		"script.update_prompt":           "Обновление по заявке %s:",
+		"script.complete":                "Завершить",
+		"script.ratings_average":         "%s по %s оценкам",
+		"script.no_ratings":              "оценок нет",
+		"script.no_ratings_period":       "Оценок за период нет",
+		"script.no_low_ratings":          "Низких оценок нет",
+		"script.request_dash":            " — заявка ",
+		"script.resumed":                 "Возобновлён",
+		"script.paused":                  "Приостановлен",
+		"script.plan_delete_confirm":     "Удалить «%s»? Созданные им заявки останутся.",
+		"script.no_plans":                "Планов нет",
+		"script.house_dash":              " — дом ",
+		"script.paused_suffix":           " (приостановлен)",
+		"script.specialization_sep":      " • специализация ",
+		"script.next_sep":                " • следующий: ",
+		"script.schedule_over":           "нет, расписание закончилось",
+		"script.last_sep":                " • последний: ",
+		"script.pause":                   "Приостановить",
+		"script.resume":                  "Возобновить",
+		"script.planned_requests":        "Плановые заявки",
+		"script.no_occurrences":          "нет запусков",
+		"script.plan_created":            "План создан, первый запуск ",
+		"script.done":                    "Готово",
+		"script.generated":               "Создано заявок: %s",
+		"script.no_sessions":             "Активных сессий нет",
+		"script.unknown_browser":         "Неизвестный браузер",
+		"script.this_session":            "(эта сессия)",
+		"script.session_dates":           "вход %s • активность %s",
+		"script.logout_session_confirm":  "Завершить эту сессию?",
+		"script.logout_others_confirm":   "Завершить все остальные сессии?",
+		"script.house_for_prompt":        "ID дома для «%s»:",
+		"script.signup_approved":         "Одобрено, учётная запись активна",
+		"script.signup_rejected":         "Отклонено",
+		"script.no_signups":              "Заявок на регистрацию нет",
+		"script.claims_prefix":           "Указал: ",
+		"script.reviewed_by":             "Рассмотрел %s, %s",
+		"script.no_specializations":      "Специализаций не найдено",
+		"script.copy_id":                 "Скопировать ID",
+		"script.copy":                    "Копировать",
+		"script.copying":                 "Копирование...",
+		"script.copied":                  "Скопировано",
+		"script.id_copied":               "ID скопирован в буфер обмена",
+		"script.failed":                  "Ошибка",
+		"script.copy_failed":             "Не удалось скопировать",
+		"script.name_prefix":             "Название: ",
+		"script.creating":                "Создание...",
+		"script.created":                 "Создано",
+		"script.no_locations":            "Мест хранения нет",
+		"script.no_such_location":        "Нет такого места",
+		"script.quantity_positive":       "Количество должно быть положительным числом",
+		"script.receive_at":              "Принять «%s» на:",
+		"script.receive_comment":         "Комментарий (поставщик, накладная):",
+		"script.received":                "Принято",
+		"script.write_off_from":          "Списать «%s» с:",
+		"script.reason":                  "Причина:",
+		"script.written_off":             "Списано",
+		"script.move_from":               "Переместить «%s» с:",
+		"script.move_to":                 "На:",
+		"script.moved":                   "Перемещено",
+		"script.unit_price_of":           "Цена за единицу «%s»:",
+		"script.low_stock_at":            "Мало на складе при:",
+		"script.archive_confirm":         "Отправить «%s» в архив? Её больше нельзя будет принять или использовать.",
+		"script.updated":                 "Обновлено",
+		"script.no_items":                "Позиций нет",
+		"script.low_suffix":              " • МАЛО",
+		"script.archived_suffix":         " • в архиве",
+		"script.item_price":              "цена %s за %s • мало при %s",
+		"script.out_of_stock":            " • нет в наличии",
+		"script.receive":                 "Принять",
+		"script.write_off":               "Списать",
+		"script.move":                    "Переместить",
+		"script.archive":                 "В архив",
+		"script.restore":                 "Восстановить",
+		"script.restored":                "Восстановлено",
+		"script.running_low":             "Заканчивается: ",
+		"script.left":                    "осталось %s",
+		"script.any":                     "любое",
+		"script.all":                     "все",
+		"script.delete_location_confirm": "Удалить место «%s»?",
+		"script.stock_report_header":     "Позиция: на начало + принято − использовано − списано ± перемещено = на конец",
+		"script.showing_of":              "Показано %s из %s",
+		"script.no_movements":            "Движений нет",
+		"script.request_sep":             " • заявка ",
+		"script.returned_suffix":         " • возвращено",
+		"script.location_added":          "Место добавлено",
+		"script.item_added":              "Позиция добавлена",
+		"script.no_houses":               "Домов не найдено",
+		"script.address_prefix":          "Адрес: ",
+		"script.edit_address":            "Изменить адрес",
+		"script.review_sep":              " • решение: ",
+		"script.org_id_empty":            "ID организации не указан",
+		"script.name_required":           "Название обязательно",
+		"script.no_contracts":            "Договоров нет",
+		"script.open_ended":              "бессрочно",
+		"script.inactive_suffix":         " (не действует)",
+		"script.deactivate":              "Прекратить",
+		"script.deactivate_confirm":      "Прекратить договор %s?",
+		"script.no_representatives":      "Представителей нет",
+		"script.contacts_saved":          "Контакты сохранены",
+		"script.categories_saved":        "Категории сохранены",
+		"script.contract_added":          "Договор добавлен",
+		"script.no_organizations":        "Организаций не найдено",
+		"script.serves_prefix":           "Обслуживает: ",
+		"script.nothing":                 "ничего",
+		"script.active_contracts_sep":    " • действующих договоров: ",
+		"script.contact_sep":             " • контакт: ",
+		"script.edit_name":               "Изменить название",
+		"script.contacts_contracts":      "Контакты и договоры",
+		"script.no_users":                "Пользователей не найдено",
+		"script.phone":                   "Телефон:",
+		"script.details":                 "Подробнее",
+		"script.delete_user_confirm":     "Удалить пользователя %s?",
+		"script.reset_password":          "Сбросить пароль",
+		"script.reset_password_confirm":  "Отправить код сброса пароля на %s?",
+		"script.reset_tfa":               "Сбросить 2FA",
+		"script.reset_tfa_confirm":       "Отключить двухфакторную аутентификацию у %s?",
+		"script.reset_tfa_code":          "Ваш собственный код двухфакторной аутентификации для подтверждения:",
+		"script.logout_everywhere":       "Завершить все сессии",
+		"script.revoke_sessions_confirm": "У %s активных сессий: %s. Завершить все?",
+		"script.revoked_sessions":        "Завершено сессий: %s",
+		"script.details_failed":          "Не удалось загрузить подробности",
+		"script.no_ratings_yet":          "оценок пока нет",
+		"script.full_name_prompt":        "ФИО:",
+		"script.phone_prompt":            "Телефон (его смена переносит вход и завершает сессии):",
+		"script.staff_status_prompt":     "Новый статус (работает, недоступен, уволился):",
+		"script.reason_optional":         "Причина (необязательно):",
+		"script.reassigned":              "Переназначено заявок: %s",
+		"script.unassigned":              "Снято заявок: %s",
+		"script.deactivated_specs":       "Отключено специализаций: %s",
+		"script.changed_by":              ", изменил: ",
+		"script.no_status_changes":       "Смен статуса нет",
+		"script.no_houses_linked_short":  "Домов нет",
+		"script.remove_house_confirm":    "Отвязать дом %s от жителя?",
+		"script.remove_failed":           "Не удалось отвязать",
+		"script.no_specs":                "Специализаций нет",
+		"script.deactivate_spec_confirm": "Отключить специализацию %s?",
+		"script.deactivate_failed":       "Не удалось отключить",
+		"script.add_house":               "Добавить дом",
+		"script.house_id":                "ID дома",
+		"script.enter_house_id":          "Введите ID дома",
+		"script.add_house_hint":          "Привяжите дом к жителю по его числовому ID.",
+		"script.add_spec":                "Добавить специализацию",
+		"script.spec_id":                 "ID специализации",
+		"script.enter_spec_id":           "Введите ID специализации",
+		"script.add_spec_hint":           "Назначьте сотруднику специализацию по её строковому ID.",
+		"script.positive_number":         "Введите положительное число.",
+		"script.add_house_failed":        "Не удалось добавить дом",
+		"script.house_added":             "Дом добавлен.",
+		"script.enter_spec_id_please":    "Введите ID специализации.",
+		"script.add_spec_failed":         "Не удалось добавить специализацию",
+		"script.spec_added":              "Специализация добавлена.",
+		"script.network_or_server":       "Ошибка сети или сервера.",
+
+		"login.title":                "Вход",
+		"login.phone":                "Номер телефона",
+		"login.password":             "Пароль",
+		"login.password_placeholder": "Ваш пароль",
+		"login.submit":               "Войти",
+		"login.forgot":               "Забыли пароль?",
+		"login.no_account":           "Ещё нет аккаунта?",
+		"login.signup":               "Зарегистрироваться как житель",
+
+		"create_request.notices":            "Объявления по вашим домам",
+		"create_request.title":              "Новая заявка",
+		"create_request.house_id":           "Номер дома",
+		"create_request.type":               "Тип заявки",
+		"create_request.choose":             "Выберите вариант:",
+		"create_request.category":           "Категория",
+		"create_request.dont_know":          "Не знаю",
+		"create_request.category_hint":      "Категория сразу направляет заявку нужным сотрудникам.",
+		"create_request.complaint":          "Описание проблемы",
+		"create_request.complaint_hint":     "Опишите вашу проблему",
+		"create_request.urgency":            "Насколько это срочно?",
+		"create_request.priority_emergency": "Авария: затопление, нет света или газа, угроза жизни",
+		"create_request.priority_high":      "Срочно",
+		"create_request.priority_normal":    "Обычная",
+		"create_request.priority_low":       "Не срочно",
+		"create_request.urgency_hint":       "Сотрудники оценят срочность и назначат приоритет заявки.",
+		"create_request.send":               "Отправить заявку",
+		"create_request.duplicates_title":   "Уже сообщили в этом доме",
+		"create_request.duplicates_hint":    "Присоединитесь к одной из этих заявок, чтобы следить за её ходом, вместо создания новой.",
+		"create_request.send_anyway":        "У меня другая проблема, отправить",
+
+		"common.total":           "Всего:",
+		"common.prev":            "Назад",
+		"common.next":            "Вперёд",
+		"common.page":            "Страница",
+		"common.refresh":         "Обновить",
+		"common.apply":           "Применить",
+		"common.any":             "любой",
+		"common.save":            "Сохранить",
+		"common.search":          "Найти",
+		"common.sort":            "Сортировка:",
+		"common.per_page":        "На странице:",
+		"common.house_id":        "Номер дома",
+		"common.optional":        "необязательно",
+		"common.kind":            "Вид:",
+		"common.status":          "Статус:",
+		"common.comma_separated": "через запятую",
+		"common.create":          "Создать",
+		"common.cancel":          "Отмена",
+		"common.close":           "Закрыть",
+
+		"main.about": "Сервис заявок ТСЖ, автор Владислав Северов aka lein3000",
+
+		"my_requests.title":             "Мои заявки",
+		"my_requests.total":             "Всего заявок:",
+		"my_requests.sort_created_desc": "сначала новые",
+		"my_requests.sort_type_asc":     "по типу",
+		"my_requests.sort_status_asc":   "по статусу",
+		"my_requests.calendar_title":    "Календарь визитов",
+		"my_requests.calendar_hint":     "Подпишитесь на ссылку в приложении календаря, чтобы видеть согласованные визиты мастеров.",
+		"my_requests.calendar_create":   "Создать ссылку на календарь",
+		"my_requests.statement_title":   "Выписка по начислениям",
+		"my_requests.statement_hint":    "Утверждённые начисления за ремонт в вашей квартире. Оставьте даты пустыми для текущего месяца.",
+		"my_requests.from":              "С:",
+		"my_requests.to":                "По:",
+		"my_requests.download_pdf":      "Скачать PDF",
+
+		"profile.title":              "Мой профиль",
+		"profile.phone":              "Номер телефона:",
+		"profile.phone_hint":         "Чтобы изменить его, обратитесь к сотрудникам.",
+		"profile.full_name":          "ФИО:",
+		"profile.email":              "Эл. почта:",
+		"profile.contact":            "Предпочтительная связь:",
+		"profile.contact_call":       "звонок",
+		"profile.contact_sms":        "SMS",
+		"profile.contact_email":      "эл. почта",
+		"profile.houses":             "Мои дома",
+		"profile.link_title":         "Запросить привязку к дому",
+		"profile.search_address":     "Поиск по адресу:",
+		"profile.search_placeholder": "улица, дом",
+		"profile.link_requests":      "Мои запросы на привязку",
+
+		"signup.hint":                        "Аккаунт станет активным, когда сотрудники проверят, что вы живёте по этому адресу.",
+		"signup.full_name":                   "ФИО",
+		"signup.full_name_placeholder":       "Иванов Иван Иванович",
+		"signup.address":                     "Адрес дома",
+		"signup.apartment":                   "Квартира",
+		"signup.password_placeholder":        "Придумайте пароль",
+		"signup.submit":                      "Зарегистрироваться",
+		"signup.status_title":                "Проверить статус заявки на регистрацию",
+		"signup.status_password_placeholder": "Пароль, указанный при регистрации",
+		"signup.check":                       "Проверить",
+
+		"password.rules":                  "Не меньше 8 символов из букв, цифр или знаков, либо фраза от 16 символов",
+		"password.current":                "Текущий пароль",
+		"password.new":                    "Новый пароль",
+		"password.confirm":                "Подтвердите новый пароль",
+		"password.repeat":                 "Повторите новый пароль",
+		"password.forgot_title":           "Восстановление пароля",
+		"password.send_code":              "Отправить код сброса",
+		"password.reset_title":            "Новый пароль",
+		"password.reset_code":             "Код сброса",
+		"password.reset_code_placeholder": "Полученный код",
+		"password.reset_submit":           "Сбросить пароль",
+
+		"two_factor.title":       "Двухфакторная аутентификация",
+		"two_factor.code":        "Код из приложения-аутентификатора или код восстановления",
+		"two_factor.verify":      "Подтвердить",
+		"two_factor.status":      "Статус:",
+		"two_factor.enroll_hint": "Добавьте аккаунт в приложение-аутентификатор, отсканировав QR-код из ссылки ниже или введя секрет вручную.",
+		"two_factor.generate":    "Создать секрет",
+		"two_factor.secret":      "Секрет:",
+		"two_factor.app_code":    "Код из приложения",
+		"two_factor.enable":      "Включить",
+		"two_factor.manage_code": "Текущий код или код восстановления",
+		"two_factor.regenerate":  "Новые коды восстановления",
+		"two_factor.disable":     "Отключить",
+		"two_factor.codes_hint":  "Коды восстановления, каждый действует один раз. Сохраните их в надёжном месте, больше они показаны не будут.",
+		"two_factor.continue":    "Продолжить",
+
+		"calendar.title":             "Календарь дежурств",
+		"calendar.hint":              "Работающие сотрудники на смене по специализациям. Сотрудники без графика дежурят весь день.",
+		"calendar.days":              "Дней:",
+		"calendar.specialization_id": "Номер специализации:",
+		"calendar.show":              "Показать",
+		"calendar.member_title":      "График сотрудника",
+		"calendar.member_id":         "Номер сотрудника:",
+		"calendar.load":              "Загрузить",
+		"calendar.weekday":           "День недели:",
+		"calendar.start":             "Начало:",
+		"calendar.end":               "Конец:",
+		"calendar.set_shift":         "Задать смену",
+		"calendar.comment":           "Комментарий:",
+		"calendar.add_absence":       "Добавить отсутствие",
+		"calendar.visits_title":      "Мои визиты",
+		"calendar.visits_hint":       "Визиты в квартиры, согласованные с жителями. Ссылка на календарь показывает их в приложении календаря.",
+
+		"weekday.0": "Воскресенье",
+		"weekday.1": "Понедельник",
+		"weekday.2": "Вторник",
+		"weekday.3": "Среда",
+		"weekday.4": "Четверг",
+		"weekday.5": "Пятница",
+		"weekday.6": "Суббота",
+
+		"complaints.new":              "Новая жалоба",
+		"complaints.hint":             "О шуме, парковке, соседях или чистоте. Кто пожаловался, видят только сотрудники, соседу ваше имя не сообщают.",
+		"complaints.kind":             "О чём",
+		"complaints.apartment":        "Из какой квартиры",
+		"complaints.text":             "Жалоба",
+		"complaints.text_placeholder": "Что происходит и когда",
+		"complaints.send":             "Отправить жалобу",
+		"complaints.warnings":         "Предупреждения вам",
+		"complaints.mine":             "Мои жалобы",
+
+		"sessions.hint":          "Браузеры, в которых выполнен вход. Завершите сеанс, который вы не узнаёте.",
+		"sessions.revoke_others": "Выйти из остальных сеансов",
+
+		"api_tokens.hint":             "Токены, которыми мобильное приложение и интеграции действуют от вашего имени. Отзовите токены, которыми больше не пользуетесь.",
+		"api_tokens.name":             "Название токена",
+		"api_tokens.name_placeholder": "Интеграция с домофоном",
+		"api_tokens.expires_days":     "Срок действия (дней)",
+		"api_tokens.create":           "Создать личный токен доступа",
+		"api_tokens.copy_now":         "Скопируйте токен сейчас, больше он показан не будет:",
+
+		"register.title":                    "Регистрация",
+		"register.roles":                    "Роли",
+		"register.resident":                 "Житель",
+		"register.staff":                    "Сотрудник",
+		"register.contractor":               "Представитель подрядчика",
+		"register.organization_id":          "Номер организации",
+		"register.organization_placeholder": "только для представителей подрядчиков",
+		"register.organization_hint":        "Представитель видит только заявки, переданные этой организации",
+		"register.submit":                   "Создать аккаунт",
+
+		"admin.hint":            "Быстрый переход.",
+		"admin.register":        "Регистрация пользователей",
+		"admin.signups":         "Заявки на регистрацию",
+		"admin.specializations": "Специализации",
+		"admin.houses":          "Дома",
+		"admin.announcements":   "Объявления",
+		"admin.maintenance":     "Планы обслуживания",
+		"admin.inventory":       "Склад",
+		"admin.categories":      "Категории заявок",
+		"admin.organizations":   "Организации",
+		"admin.requests":        "Заявки",
+		"admin.users":           "Пользователи",
+		"admin.billing":         "Начисления",
+		"admin.ratings":         "Оценки жителей",
+		"admin.lockouts":        "Блокировки входа",
+
+		"lockouts.hint": "Номера телефонов и адреса, заблокированные после неудачных попыток входа.",
+
+		"signups.hint":   "Проверьте, что заявитель живёт по указанному адресу, затем одобрите заявку с подходящим домом или отклоните её с причиной.",
+		"signups.search": "Поиск (телефон, имя или адрес):",
+
+		"categories.hint":               "Жители выбирают категорию, сообщая о проблеме. Заявка категории со специализациями по умолчанию сразу уходит наименее загруженному из этих сотрудников, срок задаёт SLA. Подкатегория без своего SLA или специализаций берёт их у родительской.",
+		"categories.title":              "Название:",
+		"categories.title_placeholder":  "Сантехника",
+		"categories.under":              "Внутри:",
+		"categories.sla_hours":          "SLA, часов:",
+		"categories.inherit":            "как у родительской",
+		"categories.specialization_ids": "Номера специализаций:",
+		"categories.add":                "Добавить категорию",
+		"categories.show_hidden":        "и скрытые",
+
+		"announcements.hint":              "Жители видят действующие объявления на главной странице и на странице новой заявки. При идущем отключении жителя просят подтвердить отправку новой заявки по дому.",
+		"announcements.title_placeholder": "Нет холодной воды",
+		"announcements.text":              "Текст:",
+		"announcements.house_ids":         "Номера домов (через запятую):",
+		"announcements.starts_at":         "Начало:",
+		"announcements.ends_at":           "Окончание:",
+		"announcements.post":              "Опубликовать",
+		"announcements.only_active":       "Только действующие",
+
+		"billing.pending_title":   "Позиции, ожидающие утверждения",
+		"billing.hint":            "Утверждать и отклонять могут только бухгалтеры и никогда — собственные позиции.",
+		"billing.pending":         "Ожидают:",
+		"billing.statement_title": "Выписка жителя",
+		"billing.resident_id":     "Номер жителя:",
+		"billing.accountants":     "Бухгалтеры",
+		"billing.grant":           "Назначить",
+		"billing.revoke":          "Снять",
+
+		"ratings.hint":              "Жители оценивают выполненные заявки от 1 до 5. Оставьте даты пустыми, чтобы взять всё время.",
+		"ratings.group_by":          "Группировать по:",
+		"ratings.by_staff":          "сотруднику",
+		"ratings.by_specialization": "специализации",
+		"ratings.by_organization":   "организации",
+		"ratings.overall":           "В целом:",
+		"ratings.low_title":         "Низкие оценки",
+		"ratings.low_hint":          "Оценки 2 и ниже, сначала новые.",
+
+		"maintenance.hint":              "Каждый план по расписанию создаёт плановую заявку на общедомовое имущество и поручает её наименее загруженному сотруднику специализации.",
+		"maintenance.title_placeholder": "Осмотр лифта",
+		"maintenance.description":       "Описание:",
+		"maintenance.rule":              "Расписание (RRULE):",
+		"maintenance.rule_hint":         "Поддерживаются: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY (MO..SU), BYMONTHDAY (-1 — последний день), BYMONTH, UNTIL (ГГГГММДД) или COUNT.",
+		"maintenance.first_run":         "Первый запуск:",
+		"maintenance.preview":           "Предпросмотр",
+		"maintenance.create":            "Создать план",
+		"maintenance.run":               "Создать назревшие заявки сейчас",
+
+		"specializations.create":             "Создать специализацию",
+		"specializations.search":             "Поиск:",
+		"specializations.search_placeholder": "поиск по номеру или названию",
+		"specializations.job_name":           "Название работы:",
+
+		"inventory.hint":                 "Материалы, взятые для заявки, добавляются к её затратам кнопкой «Материалы» на панели заявок.",
+		"inventory.locations":            "Места хранения",
+		"inventory.location_placeholder": "Основной склад",
+		"inventory.none":                 "нет",
+		"inventory.add_location":         "Добавить место",
+		"inventory.items":                "Позиции",
+		"inventory.name":                 "Название:",
+		"inventory.item_placeholder":     "Лампа светодиодная E27",
+		"inventory.unit":                 "Единица:",
+		"inventory.unit_placeholder":     "шт",
+		"inventory.unit_price":           "Цена за единицу:",
+		"inventory.min_stock":            "Мало на складе при:",
+		"inventory.add_item":             "Добавить позицию",
+		"inventory.name_placeholder":     "название",
+		"inventory.location":             "Место:",
+		"inventory.low_only":             "только заканчивающиеся",
+		"inventory.archived_too":         "и архивные",
+		"inventory.movements_title":      "Движение запасов",
+		"inventory.all":                  "все",
+		"inventory.report":               "Отчёт",
+		"inventory.movements":            "Движения",
+
+		"houses.search":             "Поиск (номер или адрес):",
+		"houses.search_placeholder": "введите номер или адрес",
+		"houses.add":                "Добавить дом",
+		"houses.address":            "Адрес:",
+		"houses.update":             "Изменить дом",
+		"houses.link_requests":      "Запросы на привязку к дому",
+
+		"organizations.search":               "Поиск (номер или название):",
+		"organizations.search_placeholder":   "введите номер или название",
+		"organizations.add":                  "Добавить организацию",
+		"organizations.update":               "Изменить организацию",
+		"organizations.details":              "Сведения об организации",
+		"organizations.contacts":             "Контакты",
+		"organizations.contact_person":       "Контактное лицо:",
+		"organizations.phone":                "Телефон:",
+		"organizations.save_contacts":        "Сохранить контакты",
+		"organizations.types":                "Обслуживаемые типы заявок",
+		"organizations.save_types":           "Сохранить типы",
+		"organizations.contracts":            "Договоры",
+		"organizations.representatives":      "Представители",
+		"organizations.representatives_hint": "Представители регистрируются на странице регистрации пользователей с ролью подрядчика",
+		"organizations.number":               "Номер:",
+		"organizations.starts":               "Начало:",
+		"organizations.ends":                 "Окончание:",
+		"organizations.add_contract":         "Добавить договор",
+
+		"requests.sort_created_asc":      "сначала старые",
+		"requests.sort_type_desc":        "по типу, в обратном порядке",
+		"requests.sort_status_desc":      "по статусу, в обратном порядке",
+		"requests.sort_priority_asc":     "сначала срочные",
+		"requests.sort_priority_desc":    "сначала несрочные",
+		"requests.sort_due_asc":          "сначала с ближайшим сроком",
+		"requests.request_id":            "номер заявки",
+		"requests.resident_id":           "номер жителя",
+		"requests.id_example":            "например, 123",
+		"requests.house_hint":            "Отбор по номеру дома",
+		"requests.responsible_id":        "Номер ответственного",
+		"requests.responsible_hint":      "Числовой номер сотрудника (необязательно)",
+		"requests.organization_id":       "номер организации",
+		"requests.merged_into":           "Объединена с:",
+		"requests.parent_id":             "номер основной заявки",
+		"requests.origin":                "Источник:",
+		"requests.origin_residents":      "от жителей",
+		"requests.origin_plans":          "планы обслуживания",
+		"requests.type":                  "Тип:",
+		"requests.priority":              "Приоритет:",
+		"requests.category":              "Категория:",
+		"requests.overdue_only":          "только просроченные",
+		"requests.complaint_contains":    "Описание содержит:",
+		"requests.complaint_placeholder": "поиск по описанию",
+		"requests.edit":                  "Изменить заявку",
+		"requests.id_readonly":           "Номер (только чтение):",
+		"requests.house_numeric":         "Числовой номер дома",
+		"requests.cost":                  "Стоимость:",
+		"requests.cost_hint":             "Стоимость в вашей валюте (необязательно)",
+		"requests.numeric_id":            "числовой номер",
+		"requests.keep":                  "не менять",
+		"requests.category_hint":         "Другая категория заново задаёт срок по своему SLA",
+		"requests.complaint":             "Описание:",
+		"requests.organization_hint":     "Задаётся действием передачи, которое проверяет договор",
+		"requests.add_job_lookup":        "Подбор по работе",
+		"requests.job_id":                "Номер работы",
+		"requests.job_id_placeholder":    "номер работы",
+		"requests.find":                  "Найти",
+		"requests.job_lookup_hint":       "Найти наименее загруженного сотрудника по номеру работы",
+
+		"contractor.title":          "Переданные заявки",
+		"contractor.complete_title": "Отметить заявку выполненной",
+		"contractor.request_id":     "Номер заявки:",
+		"contractor.report":         "Отчёт о выполнении:",
+		"contractor.complete":       "Выполнено",
+
+		"users.search":              "Поиск по телефону:",
+		"users.search_placeholder":  "введите телефон или его часть",
+		"users.details":             "Сведения о пользователе",
+		"users.get_houses":          "Показать дома",
+		"users.rating":              "Оценка жителей:",
+		"users.get_specializations": "Показать специализации",
+		"users.add_specialization":  "Добавить специализацию",
+		"users.edit_profile":        "Изменить профиль",
+		"users.change_status":       "Изменить статус",
+		"users.status_history":      "История статусов",
+		"users.add":                 "Добавить",
+		"users.enter_id":            "Введите номер",
+
+		"admin_complaints.hint": "Жалобы на шум, парковку, соседей и чистоту проходят рассмотрение, предупреждение и решение. Житель, получивший предупреждение, видит только его текст, но не заявителя и не жалобу.",
+
+		"page.main":                      "Главная",
+		"page.admin_requests":            "Заявки",
+		"page.user_management":           "Пользователи",
+		"page.houses_management":         "Дома",
+		"page.orgs_management":           "Организации",
+		"page.register":                  "Регистрация",
+		"page.my_requests":               "Мои заявки",
+		"page.admin_panel":               "Панель администратора",
+		"page.password_reset":            "Сброс пароля",
+		"page.change_password":           "Смена пароля",
+		"page.login_lockouts":            "Блокировки входа",
+		"page.two_factor_authentication": "Двухфакторная аутентификация",
+		"page.two_factor_setup":          "Настройка двухфакторной аутентификации",
+		"page.active_sessions":           "Активные сеансы",
+		"page.api_tokens":                "API-токены",
+		"page.contractor_requests":       "Заявки подрядчика",
+		"page.billing":                   "Начисления",
+		"page.duty_calendar":             "Календарь дежурств",
+		"page.my_profile":                "Мой профиль",
+		"page.sign_up":                   "Регистрация жителя",
+		"page.sign_ups":                  "Заявки на регистрацию",
+		"page.announcements":             "Объявления",
+		"page.ratings":                   "Оценки жителей",
+		"page.maintenance":               "Планы обслуживания",
+		"page.inventory":                 "Склад",
+		"page.categories":                "Категории заявок",
+		"page.my_complaints":             "Мои жалобы",
+		"page.complaints":                "Жалобы",
+
+		"brand": "ТСЖ",
+
+		"footer": "Сервис заявок ТСЖ на golang + gin",
+
+		// API messages are keyed by their English text, which is what an untranslated one stays
+		"internal error, try again later":                "внутренняя ошибка, попробуйте позже",
+		"authentication required":                        "требуется вход",
+		"role %s is not allowed here":                    "роли %s это недоступно",
+		"request body must be application/json":          "тело запроса должно быть в формате application/json",
+		"malformed JSON body":                            "некорректный JSON в теле запроса",
+		"request body failed validation":                 "тело запроса не прошло проверку",
+		"invalid query parameters":                       "некорректные параметры запроса",
+		"is required":                                    "обязательно",
+		"must be at least %s":                            "должно быть не меньше %s",
+		"must be at most %s":                             "должно быть не больше %s",
+		"must be one of: %s":                             "должно быть одним из: %s",
+		"must be greater than %s":                        "должно быть больше %s",
+		"must be greater than or equal to %s":            "должно быть не меньше %s",
+		"is invalid (%s)":                                "некорректно (%s)",
+		"must be a boolean":                              "должно быть true или false",
+		"must be an integer":                             "должно быть целым числом",
+		"is not a known status":                          "неизвестный статус",
+		"is not a known request type":                    "неизвестный тип заявки",
+		"is not a known priority":                        "неизвестный приоритет",
+		"is not a known category":                        "неизвестная категория",
+		"is not a category in use":                       "категория не используется",
+		"belongs to the other request type":              "относится к другому типу заявок",
+		"is not one of your houses":                      "не ваш дом",
+		"the house is not linked to your account":        "дом не привязан к вашему аккаунту",
+		"only residents can do this":                     "это доступно только жителям",
+		"request not found":                              "заявка не найдена",
+		"request to join not found":                      "заявка для присоединения не найдена",
+		"can not join this request":                      "нельзя присоединиться к этой заявке",
+		"user not found":                                 "пользователь не найден",
+		"organization not found":                         "организация не найдена",
+		"house not found":                                "дом не найден",
+		"house already exists":                           "такой дом уже есть",
+		"house id must be an integer":                    "номер дома должен быть целым числом",
+		"staff member id must be a positive integer":     "номер сотрудника должен быть положительным целым числом",
+		"no active specialization for this staff member": "у сотрудника нет такой действующей специализации",
+		"error creating specialization":                  "не удалось создать специализацию",
+		"error creating organization":                    "не удалось создать организацию",
+		"specialization added":                           "специализация добавлена",
+		"invalid phone number":                           "некорректный номер телефона",
+		"request is already completed or cancelled":      "заявка уже выполнена или отменена",
+		"set to true to send the request anyway":         "передайте true, чтобы всё равно отправить заявку",
+		"request is merged into another one, change the parent request instead":        "заявка объединена с другой, изменяйте основную заявку",
+		"merged requests must be open, not transferred and of the same house and type": "объединяемые заявки должны быть открыты, не переданы организации и относиться к одному дому и типу",
+		"a request is handed to an organization only by the transfer":                  "заявка передаётся организации только через передачу",
+		"it checks the contract of the organization":                                   "при ней проверяется договор организации",
+		"sign-up is %s":      "заявка на регистрацию: %s",
+		"you can log in now": "теперь можно войти",
+		"an outage is in progress in this house: %s (until %s). Send the request anyway?":        "в доме идёт отключение: %s (до %s). Всё равно отправить заявку?",
+		"similar requests are already open in this house, join one of them or send yours anyway": "в доме уже открыты похожие заявки, присоединитесь к одной из них или всё равно отправьте свою",
+	},
+}
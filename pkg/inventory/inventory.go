@@ -0,0 +1,194 @@
+package inventory
+
+import (
+	"DBPrototyping/pkg/billing"
+	"time"
+)
+
+// Location is a place where materials are kept, the HOA storeroom or a closet in one of the houses.
+type Location struct {
+	ID        string    `gorm:"type:char(40);primaryKey"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex"`
+	HouseID   *int      `gorm:"column:id_house;type:bigint"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// Item is a kind of material, UnitPrice is what a unit costs the request it is used for and MinStock
+// is the total quantity at which the item is reported as running low, zero turns the alert off.
+type Item struct {
+	ID        string    `gorm:"type:char(40);primaryKey"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex"`
+	Unit      string    `gorm:"type:varchar(20);not null"`
+	UnitPrice float64   `gorm:"column:unit_price;type:numeric(10,2);not null"`
+	MinStock  float64   `gorm:"column:min_stock;type:numeric(12,2);not null;default:0"`
+	IsActive  bool      `gorm:"column:is_active;not null;default:true"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+// Stock is the quantity of an item at a location, it changes only together with a movement.
+type Stock struct {
+	ItemID     string  `gorm:"column:id_item;type:char(40);primaryKey"`
+	LocationID string  `gorm:"column:id_location;type:char(40);primaryKey"`
+	Quantity   float64 `gorm:"type:numeric(12,2);not null"`
+}
+
+// Movement records one change of stock, Quantity is positive when the materials come to the location
+// and negative when they leave it. Materials used for a request keep the request and its line item.
+type Movement struct {
+	ID         string       `gorm:"type:char(40);primaryKey"`
+	ItemID     string       `gorm:"column:id_item;type:char(40);not null;index"`
+	LocationID string       `gorm:"column:id_location;type:char(40);not null;index"`
+	Kind       MovementKind `gorm:"type:varchar(30);not null"`
+	Quantity   float64      `gorm:"type:numeric(12,2);not null"`
+	RequestID  *string      `gorm:"column:id_request;type:char(40);index"`
+	LineItemID *string      `gorm:"column:id_line_item;type:char(40)"`
+	Author     string       `gorm:"type:varchar(40);not null"`
+	Comment    *string      `gorm:"type:varchar(200)"`
+	CreatedAt  time.Time    `gorm:"column:created_at;type:timestamp;not null;default:now();index"`
+	// ReturnedAt is set on a usage whose materials went back to the storeroom
+	ReturnedAt *time.Time `gorm:"column:returned_at;type:timestamp"`
+}
+
+// MovementView is a movement with the names of its item and location, the location may be deleted since.
+type MovementView struct {
+	Movement
+	ItemName     string
+	Unit         string
+	LocationName *string
+}
+
+type NewItem struct {
+	Name      string
+	Unit      string
+	UnitPrice float64
+	MinStock  float64
+}
+
+// LocationStock is the part of the item's stock kept at one location.
+type LocationStock struct {
+	LocationID   string
+	LocationName string
+	Quantity     float64
+}
+
+// ItemStock is an item with its stock over all locations, Low tells that the total fell to MinStock.
+type ItemStock struct {
+	Item
+	Total     float64
+	Low       bool
+	Locations []*LocationStock `gorm:"-"`
+}
+
+type StockFilter struct {
+	Search          string
+	LocationID      *string
+	LowOnly         bool
+	IncludeInactive bool
+
+	Limit  int
+	Offset int
+}
+
+// MovementFilter narrows the movements, From is inclusive and To is exclusive.
+type MovementFilter struct {
+	ItemID     *string
+	LocationID *string
+	RequestID  *string
+	Kind       *MovementKind
+	From       *time.Time
+	To         *time.Time
+
+	Limit  int
+	Offset int
+}
+
+// MaterialUsage takes materials from a location for the work on a request.
+type MaterialUsage struct {
+	RequestID  string
+	ItemID     string
+	LocationID string
+	Quantity   float64
+	Payer      billing.Payer
+	Author     string
+}
+
+// Usage is the outcome of MaterialUsage: the movement, the line item put in line for an accountant
+// and what is left of the item.
+type Usage struct {
+	Movement *Movement
+	LineItem *billing.LineItem
+	Stock    *ItemStock
+}
+
+// ReportRow sums up the movements of an item over a period. Used and WrittenOff are positive, Used is net of
+// the returns, Transferred is the net inflow and is zero when the report covers all locations.
+type ReportRow struct {
+	ItemID      string
+	Name        string
+	Unit        string
+	Opening     float64
+	Received    float64
+	Used        float64
+	WrittenOff  float64
+	Transferred float64
+	Closing     float64
+}
+
+type InventoryRepo interface {
+	CreateLocation(name string, houseID *int) (*Location, error)
+	GetLocations() ([]*Location, error)
+	// DeleteLocation removes only an empty location
+	DeleteLocation(id string) error
+	CreateItem(item NewItem) (*Item, error)
+	UpdateItem(item *Item) error
+	GetStock(filter StockFilter) ([]*ItemStock, int, error)
+	Receive(itemID, locationID string, quantity float64, author, comment string) (*Movement, error)
+	WriteOff(itemID, locationID string, quantity float64, author, comment string) (*Movement, *ItemStock, error)
+	Transfer(itemID, fromLocationID, toLocationID string, quantity float64, author string) error
+	UseForRequest(usage MaterialUsage) (*Usage, error)
+	// ReturnFromRequest puts the materials of a usage back, its line item must still wait for review
+	ReturnFromRequest(movementID, author string) error
+	GetMovements(filter MovementFilter) ([]*MovementView, int, error)
+	// GetMovementReport sums up the movements in [from, to), for one location when locationID is given
+	GetMovementReport(from, to time.Time, locationID *string) ([]*ReportRow, error)
+}
+
+type MovementKind string
+
+const (
+	MovementReceipt  MovementKind = "поступление"
+	MovementUsage    MovementKind = "расход_по_заявке"
+	MovementReturn   MovementKind = "возврат"
+	MovementWriteOff MovementKind = "списание"
+	MovementTransfer MovementKind = "перемещение"
+)
+
+func (k MovementKind) IsValid() bool {
+	switch k {
+	case MovementReceipt, MovementUsage, MovementReturn, MovementWriteOff, MovementTransfer:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the movement kind for clients and translations, the stored value stays Russian.
+func (k MovementKind) Code() string {
+	switch k {
+	case MovementReceipt:
+		return "receipt"
+	case MovementUsage:
+		return "usage"
+	case MovementReturn:
+		return "return"
+	case MovementWriteOff:
+		return "write_off"
+	case MovementTransfer:
+		return "transfer"
+	default:
+		return ""
+	}
+}
+
+// MovementKinds lists every movement kind, e.g. to translate them all.
+var MovementKinds = []MovementKind{MovementReceipt, MovementUsage, MovementReturn, MovementWriteOff, MovementTransfer}
@@ -0,0 +1,840 @@
+package inventory
+
+import (
+	"DBPrototyping/pkg/billing"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrItemNotFound        = errors.New("inventory item not found")
+	ErrItemInactive        = errors.New("inventory item is archived")
+	ErrLocationNotFound    = errors.New("storage location not found")
+	ErrLocationNotEmpty    = errors.New("storage location still keeps materials")
+	ErrSameLocation        = errors.New("materials can not be moved to the same location")
+	ErrInsufficientStock   = errors.New("not enough materials at the location")
+	ErrMovementNotFound    = errors.New("stock movement not found")
+	ErrNotUsage            = errors.New("only materials used for a request can be returned")
+	ErrAlreadyReturned     = errors.New("materials are already returned")
+	ErrRequestCancelled    = errors.New("request is cancelled")
+	ErrCreatingItem        = errors.New("error creating inventory item")
+	ErrCreatingLocation    = errors.New("error creating storage location")
+	ErrCreatingMovement    = errors.New("error creating stock movement")
+	ErrDuplicateName       = errors.New("name is already taken")
+	ErrNonPositiveQuantity = errors.New("quantity must be positive")
+)
+
+type LocationPg Location
+
+func (LocationPg) TableName() string {
+	return "inventory_locations"
+}
+
+type ItemPg Item
+
+func (ItemPg) TableName() string {
+	return "inventory_items"
+}
+
+type StockPg Stock
+
+func (StockPg) TableName() string {
+	return "inventory_stock"
+}
+
+type MovementPg Movement
+
+func (MovementPg) TableName() string {
+	return "inventory_movements"
+}
+
+type InventoryPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewInventoryPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *InventoryPgRepo {
+	return &InventoryPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// roundQuantity keeps the two decimals the stock columns hold, so the checks in Go agree with the database.
+func roundQuantity(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func (repo *InventoryPgRepo) CreateLocation(name string, houseID *int) (*Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if houseID != nil {
+		var count int64
+		if err := repo.db.WithContext(ctx).Model(&residence.HousePg{}).Where("id = ?", *houseID).Count(&count).Error; err != nil {
+			repo.logger.Warnf("failed to check house %d: %v", *houseID, err)
+			return nil, err
+		}
+		if count == 0 {
+			return nil, residence.ErrNoHouseFound
+		}
+	}
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&LocationPg{}).Where("LOWER(name) = LOWER(?)", name).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check location name %q: %v", name, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrDuplicateName
+	}
+
+	locationPg := LocationPg{
+		Name:      name,
+		HouseID:   houseID,
+		CreatedAt: time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+		locationID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate location ID, %v", err)
+			continue
+		}
+
+		locationPg.ID = locationID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&locationPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingLocation
+	}
+
+	location := Location(locationPg)
+	return &location, nil
+}
+
+func (repo *InventoryPgRepo) GetLocations() ([]*Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var locationsPg []LocationPg
+	if err := repo.db.WithContext(ctx).Order("name").Find(&locationsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get storage locations: %v", err)
+		return nil, err
+	}
+
+	locations := make([]*Location, len(locationsPg))
+	for i := range locationsPg {
+		locations[i] = (*Location)(&locationsPg[i])
+	}
+
+	return locations, nil
+}
+
+// DeleteLocation keeps the movements of the location for the report, only its empty stock rows go.
+func (repo *InventoryPgRepo) DeleteLocation(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locationPg LocationPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&locationPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrLocationNotFound
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&StockPg{}).Where("id_location = ? AND quantity > 0", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrLocationNotEmpty
+		}
+
+		if err := tx.Where("id_location = ?", id).Delete(&StockPg{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&LocationPg{}).Error; err != nil {
+			repo.logger.Warnf("failed to delete storage location %s: %v", id, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (repo *InventoryPgRepo) CreateItem(item NewItem) (*Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&ItemPg{}).Where("LOWER(name) = LOWER(?)", item.Name).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check item name %q: %v", item.Name, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrDuplicateName
+	}
+
+	itemPg := ItemPg{
+		Name:      item.Name,
+		Unit:      item.Unit,
+		UnitPrice: roundQuantity(item.UnitPrice),
+		MinStock:  roundQuantity(item.MinStock),
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+		itemID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate item ID, %v", err)
+			continue
+		}
+
+		itemPg.ID = itemID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&itemPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingItem
+	}
+
+	created := Item(itemPg)
+	return &created, nil
+}
+
+// UpdateItem changes the description of the item, the new price applies only to the materials used afterwards.
+func (repo *InventoryPgRepo) UpdateItem(item *Item) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&ItemPg{}).Where("LOWER(name) = LOWER(?) AND id <> ?", item.Name, item.ID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check item name %q: %v", item.Name, err)
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateName
+	}
+
+	res := repo.db.WithContext(ctx).Model(&ItemPg{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+		"name":       item.Name,
+		"unit":       item.Unit,
+		"unit_price": roundQuantity(item.UnitPrice),
+		"min_stock":  roundQuantity(item.MinStock),
+		"is_active":  item.IsActive,
+	})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to update item %s: %v", item.ID, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+type locationStockRow struct {
+	ItemID       string `gorm:"column:id_item"`
+	LocationID   string
+	LocationName string
+	Quantity     float64
+}
+
+// fillLocations adds the non-empty locations to the items and marks the ones running low.
+func fillLocations(db *gorm.DB, items []*ItemStock) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*ItemStock, len(items))
+	ids := make([]string, len(items))
+	for i, item := range items {
+		item.Low = item.MinStock > 0 && item.Total <= item.MinStock
+		item.Locations = []*LocationStock{}
+		byID[item.ID] = item
+		ids[i] = item.ID
+	}
+
+	var rows []locationStockRow
+	err := db.Table(StockPg{}.TableName()+" AS stock").
+		Select("stock.id_item, stock.id_location AS location_id, loc.name AS location_name, stock.quantity").
+		Joins("JOIN "+LocationPg{}.TableName()+" AS loc ON loc.id = stock.id_location").
+		Where("stock.id_item IN ? AND stock.quantity > 0", ids).
+		Order("loc.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		byID[row.ItemID].Locations = append(byID[row.ItemID].Locations, &LocationStock{
+			LocationID:   row.LocationID,
+			LocationName: row.LocationName,
+			Quantity:     row.Quantity,
+		})
+	}
+
+	return nil
+}
+
+func stockQuery(db *gorm.DB) *gorm.DB {
+	return db.Table(ItemPg{}.TableName() + " AS item").
+		Select("item.*, COALESCE(SUM(stock.quantity), 0) AS total").
+		Joins("LEFT JOIN " + StockPg{}.TableName() + " AS stock ON stock.id_item = item.id").
+		Group("item.id")
+}
+
+func itemStock(db *gorm.DB, itemID string) (*ItemStock, error) {
+	var items []*ItemStock
+	if err := stockQuery(db).Where("item.id = ?", itemID).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	if err := fillLocations(db, items); err != nil {
+		return nil, err
+	}
+
+	return items[0], nil
+}
+
+func (repo *InventoryPgRepo) GetStock(filter StockFilter) ([]*ItemStock, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db := repo.db.WithContext(ctx)
+	query := stockQuery(db)
+
+	if !filter.IncludeInactive {
+		query = query.Where("item.is_active = ?", true)
+	}
+	if filter.Search != "" {
+		query = query.Where("item.name ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.LocationID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM "+StockPg{}.TableName()+" AS here WHERE here.id_item = item.id AND here.id_location = ? AND here.quantity > 0)",
+			*filter.LocationID)
+	}
+	if filter.LowOnly {
+		query = query.Having("item.min_stock > 0 AND COALESCE(SUM(stock.quantity), 0) <= item.min_stock")
+	}
+
+	var total int64
+	if err := db.Table("(?) AS counted", query).Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count inventory items: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*ItemStock{}, 0, nil
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var items []*ItemStock
+	if err := query.Order("item.name").Scan(&items).Error; err != nil {
+		repo.logger.Warnf("failed to query inventory items: %v", err)
+		return nil, int(total), err
+	}
+
+	if err := fillLocations(db, items); err != nil {
+		repo.logger.Warnf("failed to get stock locations: %v", err)
+		return nil, int(total), err
+	}
+
+	return items, int(total), nil
+}
+
+func lockItem(tx *gorm.DB, itemID string) (*ItemPg, error) {
+	var itemPg ItemPg
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", itemID).First(&itemPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	return &itemPg, nil
+}
+
+func checkLocation(tx *gorm.DB, locationID string) error {
+	var count int64
+	if err := tx.Model(&LocationPg{}).Where("id = ?", locationID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrLocationNotFound
+	}
+	return nil
+}
+
+// changeStock adds delta to the stock of the item at the location, a negative delta must not take more than is there.
+func changeStock(tx *gorm.DB, itemID, locationID string, delta float64) error {
+	if delta >= 0 {
+		stockPg := StockPg{ItemID: itemID, LocationID: locationID, Quantity: delta}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id_item"}, {Name: "id_location"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr(StockPg{}.TableName() + ".quantity + EXCLUDED.quantity")}),
+		}).Create(&stockPg).Error
+	}
+
+	res := tx.Model(&StockPg{}).
+		Where("id_item = ? AND id_location = ? AND quantity >= ?", itemID, locationID, -delta).
+		Update("quantity", gorm.Expr("quantity + ?", delta))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func createMovement(tx *gorm.DB, movementPg *MovementPg) error {
+	movementPg.CreatedAt = time.Now()
+
+	for i := 0; i < utils.GetRetries(); i++ {
+		movementID, err := utils.GenerateID()
+		if err != nil {
+			continue
+		}
+
+		movementPg.ID = movementID
+
+		upsertRes := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(movementPg)
+		if upsertRes.Error != nil {
+			return upsertRes.Error
+		}
+		if upsertRes.RowsAffected == 1 {
+			return nil
+		}
+	}
+
+	return ErrCreatingMovement
+}
+
+func optionalComment(comment string) *string {
+	if comment == "" {
+		return nil
+	}
+	return &comment
+}
+
+func (repo *InventoryPgRepo) Receive(itemID, locationID string, quantity float64, author, comment string) (*Movement, error) {
+	quantity = roundQuantity(quantity)
+	if quantity <= 0 {
+		return nil, ErrNonPositiveQuantity
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var movementPg MovementPg
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itemPg, err := lockItem(tx, itemID)
+		if err != nil {
+			return err
+		}
+		if !itemPg.IsActive {
+			return ErrItemInactive
+		}
+		if err := checkLocation(tx, locationID); err != nil {
+			return err
+		}
+
+		if err := changeStock(tx, itemID, locationID, quantity); err != nil {
+			return err
+		}
+
+		movementPg = MovementPg{
+			ItemID:     itemID,
+			LocationID: locationID,
+			Kind:       MovementReceipt,
+			Quantity:   quantity,
+			Author:     author,
+			Comment:    optionalComment(comment),
+		}
+		return createMovement(tx, &movementPg)
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to receive %.2f of item %s at %s: %v", quantity, itemID, locationID, err)
+		return nil, err
+	}
+
+	movement := Movement(movementPg)
+	return &movement, nil
+}
+
+func (repo *InventoryPgRepo) WriteOff(itemID, locationID string, quantity float64, author, comment string) (*Movement, *ItemStock, error) {
+	quantity = roundQuantity(quantity)
+	if quantity <= 0 {
+		return nil, nil, ErrNonPositiveQuantity
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var movementPg MovementPg
+	var stock *ItemStock
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockItem(tx, itemID); err != nil {
+			return err
+		}
+		if err := checkLocation(tx, locationID); err != nil {
+			return err
+		}
+
+		if err := changeStock(tx, itemID, locationID, -quantity); err != nil {
+			return err
+		}
+
+		movementPg = MovementPg{
+			ItemID:     itemID,
+			LocationID: locationID,
+			Kind:       MovementWriteOff,
+			Quantity:   -quantity,
+			Author:     author,
+			Comment:    optionalComment(comment),
+		}
+		if err := createMovement(tx, &movementPg); err != nil {
+			return err
+		}
+
+		var err error
+		stock, err = itemStock(tx, itemID)
+		return err
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to write off %.2f of item %s at %s: %v", quantity, itemID, locationID, err)
+		return nil, nil, err
+	}
+
+	movement := Movement(movementPg)
+	return &movement, stock, nil
+}
+
+// Transfer records the move as two movements, out of one location and into the other.
+func (repo *InventoryPgRepo) Transfer(itemID, fromLocationID, toLocationID string, quantity float64, author string) error {
+	quantity = roundQuantity(quantity)
+	if quantity <= 0 {
+		return ErrNonPositiveQuantity
+	}
+	if fromLocationID == toLocationID {
+		return ErrSameLocation
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockItem(tx, itemID); err != nil {
+			return err
+		}
+		if err := checkLocation(tx, fromLocationID); err != nil {
+			return err
+		}
+		if err := checkLocation(tx, toLocationID); err != nil {
+			return err
+		}
+
+		if err := changeStock(tx, itemID, fromLocationID, -quantity); err != nil {
+			return err
+		}
+		if err := changeStock(tx, itemID, toLocationID, quantity); err != nil {
+			return err
+		}
+
+		outPg := MovementPg{ItemID: itemID, LocationID: fromLocationID, Kind: MovementTransfer, Quantity: -quantity, Author: author}
+		if err := createMovement(tx, &outPg); err != nil {
+			return err
+		}
+		inPg := MovementPg{ItemID: itemID, LocationID: toLocationID, Kind: MovementTransfer, Quantity: quantity, Author: author}
+		return createMovement(tx, &inPg)
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to move %.2f of item %s from %s to %s: %v", quantity, itemID, fromLocationID, toLocationID, err)
+		return err
+	}
+
+	return nil
+}
+
+// UseForRequest takes the materials off the stock and adds their cost to the request as a line item,
+// the cost reaches Request.Cost once an accountant approves the item.
+func (repo *InventoryPgRepo) UseForRequest(usage MaterialUsage) (*Usage, error) {
+	quantity := roundQuantity(usage.Quantity)
+	if quantity <= 0 {
+		return nil, ErrNonPositiveQuantity
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := &Usage{}
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requestPg requests.RequestPg
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", usage.RequestID).First(&requestPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return requests.ErrNoRequestsFound
+			}
+			return err
+		}
+		if requestPg.ParentID != nil {
+			return requests.ErrMergedRequest
+		}
+		if requestPg.Status == requests.StatusCancelled {
+			return ErrRequestCancelled
+		}
+
+		itemPg, err := lockItem(tx, usage.ItemID)
+		if err != nil {
+			return err
+		}
+		if !itemPg.IsActive {
+			return ErrItemInactive
+		}
+		if err := checkLocation(tx, usage.LocationID); err != nil {
+			return err
+		}
+
+		if err := changeStock(tx, usage.ItemID, usage.LocationID, -quantity); err != nil {
+			return err
+		}
+
+		payer := usage.Payer
+		if payer == "" {
+			payer = billing.DefaultPayer(requestPg.RequestType)
+		}
+
+		lineItem, err := billing.CreateLineItem(tx, billing.NewLineItem{
+			RequestID:   requestPg.ID,
+			Kind:        billing.KindMaterials,
+			Description: fmt.Sprintf("%s, %s", itemPg.Name, itemPg.Unit),
+			Quantity:    quantity,
+			UnitPrice:   itemPg.UnitPrice,
+			Payer:       payer,
+			CreatedBy:   usage.Author,
+		})
+		if err != nil {
+			return err
+		}
+
+		requestRef := requestPg.ID
+		lineItemRef := lineItem.ID
+		movementPg := MovementPg{
+			ItemID:     usage.ItemID,
+			LocationID: usage.LocationID,
+			Kind:       MovementUsage,
+			Quantity:   -quantity,
+			RequestID:  &requestRef,
+			LineItemID: &lineItemRef,
+			Author:     usage.Author,
+		}
+		if err := createMovement(tx, &movementPg); err != nil {
+			return err
+		}
+
+		movement := Movement(movementPg)
+		result.Movement = &movement
+		result.LineItem = lineItem
+
+		result.Stock, err = itemStock(tx, usage.ItemID)
+		return err
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to use %.2f of item %s for request %s: %v", quantity, usage.ItemID, usage.RequestID, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReturnFromRequest undoes a usage entered by mistake. The line item goes away with it, so once an accountant
+// reviewed the item the usage stays, a line item deleted by hand before does not stop the return.
+func (repo *InventoryPgRepo) ReturnFromRequest(movementID, author string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var usagePg MovementPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", movementID).First(&usagePg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMovementNotFound
+			}
+			return err
+		}
+		if usagePg.Kind != MovementUsage {
+			return ErrNotUsage
+		}
+		if usagePg.ReturnedAt != nil {
+			return ErrAlreadyReturned
+		}
+
+		if usagePg.LineItemID != nil {
+			var lineItemPg billing.LineItemPg
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", *usagePg.LineItemID).First(&lineItemPg).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+			case err != nil:
+				return err
+			case lineItemPg.Status != billing.ApprovalPending:
+				return billing.ErrAlreadyReviewed
+			default:
+				if err := tx.Where("id = ?", lineItemPg.ID).Delete(&billing.LineItemPg{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := changeStock(tx, usagePg.ItemID, usagePg.LocationID, -usagePg.Quantity); err != nil {
+			return err
+		}
+
+		returnPg := MovementPg{
+			ItemID:     usagePg.ItemID,
+			LocationID: usagePg.LocationID,
+			Kind:       MovementReturn,
+			Quantity:   -usagePg.Quantity,
+			RequestID:  usagePg.RequestID,
+			Author:     author,
+		}
+		if err := createMovement(tx, &returnPg); err != nil {
+			return err
+		}
+
+		return tx.Model(&MovementPg{}).Where("id = ?", movementID).Update("returned_at", returnPg.CreatedAt).Error
+	})
+	if err != nil {
+		repo.logger.Warnf("failed to return materials of movement %s: %v", movementID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *InventoryPgRepo) GetMovements(filter MovementFilter) ([]*MovementView, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).Table(MovementPg{}.TableName() + " AS m")
+	if filter.ItemID != nil {
+		query = query.Where("m.id_item = ?", *filter.ItemID)
+	}
+	if filter.LocationID != nil {
+		query = query.Where("m.id_location = ?", *filter.LocationID)
+	}
+	if filter.RequestID != nil {
+		query = query.Where("m.id_request = ?", *filter.RequestID)
+	}
+	if filter.Kind != nil {
+		query = query.Where("m.kind = ?", *filter.Kind)
+	}
+	if filter.From != nil {
+		query = query.Where("m.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("m.created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count stock movements: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*MovementView{}, 0, nil
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var movements []*MovementView
+	err := query.
+		Select("m.*, item.name AS item_name, item.unit, loc.name AS location_name").
+		Joins("JOIN " + ItemPg{}.TableName() + " AS item ON item.id = m.id_item").
+		Joins("LEFT JOIN " + LocationPg{}.TableName() + " AS loc ON loc.id = m.id_location").
+		Order("m.created_at DESC, m.id").
+		Scan(&movements).Error
+	if err != nil {
+		repo.logger.Warnf("failed to query stock movements: %v", err)
+		return nil, int(total), err
+	}
+
+	return movements, int(total), nil
+}
+
+func (repo *InventoryPgRepo) GetMovementReport(from, to time.Time, locationID *string) ([]*ReportRow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// every movement before the end of the period makes the closing balance, the ones before its start the opening
+	join := "LEFT JOIN " + MovementPg{}.TableName() + " AS m ON m.id_item = item.id AND m.created_at < ?"
+	joinArgs := []interface{}{to}
+	if locationID != nil {
+		join += " AND m.id_location = ?"
+		joinArgs = append(joinArgs, *locationID)
+	}
+
+	var rows []*ReportRow
+	err := repo.db.WithContext(ctx).
+		Table(ItemPg{}.TableName()+" AS item").
+		Select(`item.id AS item_id, item.name, item.unit,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at < ?), 0) AS opening,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= ? AND m.kind = ?), 0) AS received,
+			-COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= ? AND m.kind IN ?), 0) AS used,
+			-COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= ? AND m.kind = ?), 0) AS written_off,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= ? AND m.kind = ?), 0) AS transferred,
+			COALESCE(SUM(m.quantity), 0) AS closing`,
+			from,
+			from, MovementReceipt,
+			from, []MovementKind{MovementUsage, MovementReturn},
+			from, MovementWriteOff,
+			from, MovementTransfer).
+		Joins(join, joinArgs...).
+		Group("item.id, item.name, item.unit").
+		Having("item.is_active OR COUNT(m.id) > 0").
+		Order("item.name").
+		Scan(&rows).Error
+	if err != nil {
+		repo.logger.Warnf("failed to build stock movement report: %v", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
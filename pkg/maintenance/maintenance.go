@@ -0,0 +1,49 @@
+package maintenance
+
+import "time"
+
+// Plan is a recurring preventive job in a house, e.g. an elevator inspection, it produces planned requests.
+type Plan struct {
+	ID               string    `gorm:"type:char(40);primaryKey"`
+	HouseID          int       `gorm:"column:id_house;type:bigint;not null;index"`
+	SpecializationID string    `gorm:"column:id_specialization;type:char(40);not null"`
+	Title            string    `gorm:"type:varchar(100);not null"`
+	Description      string    `gorm:"type:varchar(1000);not null;default:''"`
+	Rule             string    `gorm:"type:varchar(200);not null"`
+	StartsAt         time.Time `gorm:"column:starts_at;type:timestamp;not null"`
+	// NextRunAt is empty once the rule is over
+	NextRunAt *time.Time `gorm:"column:next_run_at;type:timestamp;index"`
+	LastRunAt *time.Time `gorm:"column:last_run_at;type:timestamp"`
+	IsActive  bool       `gorm:"column:is_active;not null;default:true"`
+	CreatedBy string     `gorm:"column:created_by;type:varchar(40);not null"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
+type NewPlan struct {
+	HouseID          int
+	SpecializationID string
+	Title            string
+	Description      string
+	Rule             string
+	StartsAt         time.Time
+	CreatedBy        string
+}
+
+// Generated is a request the scheduler created for a plan occurrence.
+type Generated struct {
+	PlanID           string
+	RequestID        string
+	SpecializationID string
+	OccurrenceAt     time.Time
+}
+
+type MaintenanceRepo interface {
+	CreatePlan(plan NewPlan) (*Plan, error)
+	GetPlans(houseID *int, limit, offset int) ([]*Plan, int, error)
+	GetPlanByID(id string) (*Plan, error)
+	// SetPlanActive pauses or resumes a plan, a resumed plan skips the occurrences missed while paused
+	SetPlanActive(id string, active bool) error
+	DeletePlan(id string) error
+	// GenerateDue creates a request for every active plan whose occurrence is due and moves the plan on
+	GenerateDue(now time.Time) ([]*Generated, error)
+}
@@ -0,0 +1,338 @@
+package maintenance
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"DBPrototyping/pkg/residence"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPlanNotFound           = errors.New("maintenance plan not found")
+	ErrCreatingPlan           = errors.New("error creating maintenance plan")
+	ErrSpecializationNotFound = errors.New("specialization not found")
+	ErrRuleOver               = errors.New("schedule has no occurrences left")
+)
+
+type PlanPg Plan
+
+func (PlanPg) TableName() string {
+	return "maintenance_plans"
+}
+
+type MaintenancePgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewMaintenancePgRepo(logger *zap.SugaredLogger, db *gorm.DB) *MaintenancePgRepo {
+	return &MaintenancePgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (repo *MaintenancePgRepo) CreatePlan(plan NewPlan) (*Plan, error) {
+	rule, err := ParseRule(plan.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// the start itself is the first occurrence when it matches the rule
+	next, ok := rule.Next(plan.StartsAt, plan.StartsAt.Add(-time.Second))
+	if !ok {
+		return nil, ErrRuleOver
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&residence.HousePg{}).Where("id = ?", plan.HouseID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check house %d: %v", plan.HouseID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, residence.ErrNoHouseFound
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&company.SpecializationPg{}).Where("id = ?", plan.SpecializationID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check specialization %s: %v", plan.SpecializationID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSpecializationNotFound
+	}
+
+	planPg := PlanPg{
+		HouseID:          plan.HouseID,
+		SpecializationID: plan.SpecializationID,
+		Title:            plan.Title,
+		Description:      plan.Description,
+		Rule:             rule.String(),
+		StartsAt:         plan.StartsAt,
+		NextRunAt:        &next,
+		IsActive:         true,
+		CreatedBy:        plan.CreatedBy,
+		CreatedAt:        time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+		planID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate plan ID, %v", err)
+			continue
+		}
+
+		planPg.ID = planID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&planPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingPlan
+	}
+
+	created := Plan(planPg)
+	return &created, nil
+}
+
+func (repo *MaintenancePgRepo) GetPlans(houseID *int, limit, offset int) ([]*Plan, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).Model(&PlanPg{})
+	if houseID != nil {
+		query = query.Where("id_house = ?", *houseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count maintenance plans: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*Plan{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var plansPg []PlanPg
+	if err := query.Order("is_active DESC, next_run_at NULLS LAST, id").Find(&plansPg).Error; err != nil {
+		repo.logger.Warnf("failed to query maintenance plans: %v", err)
+		return nil, int(total), err
+	}
+
+	result := make([]*Plan, len(plansPg))
+	for i := range plansPg {
+		result[i] = (*Plan)(&plansPg[i])
+	}
+
+	return result, int(total), nil
+}
+
+func (repo *MaintenancePgRepo) GetPlanByID(id string) (*Plan, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var planPg PlanPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&planPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		repo.logger.Warnf("failed to get maintenance plan %s: %v", id, err)
+		return nil, err
+	}
+
+	plan := Plan(planPg)
+	return &plan, nil
+}
+
+func (repo *MaintenancePgRepo) SetPlanActive(id string, active bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var planPg PlanPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&planPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlanNotFound
+			}
+			return err
+		}
+
+		updates := map[string]interface{}{"is_active": active}
+		if active && !planPg.IsActive {
+			rule, err := ParseRule(planPg.Rule)
+			if err != nil {
+				return err
+			}
+
+			// occurrences missed while paused are not made up for
+			from := time.Now()
+			if planPg.StartsAt.After(from) {
+				from = planPg.StartsAt.Add(-time.Second)
+			}
+			if next, ok := rule.Next(planPg.StartsAt, from); ok {
+				updates["next_run_at"] = next
+			} else {
+				updates["next_run_at"] = nil
+			}
+		}
+
+		if err := tx.Model(&PlanPg{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			repo.logger.Warnf("failed to change maintenance plan %s: %v", id, err)
+			return err
+		}
+		return nil
+	})
+}
+
+// DeletePlan removes the plan only, its requests stay and keep the mark of planned work.
+func (repo *MaintenancePgRepo) DeletePlan(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).Where("id = ?", id).Delete(&PlanPg{})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to delete maintenance plan %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPlanNotFound
+	}
+
+	return nil
+}
+
+func planComplaint(plan *PlanPg) string {
+	if plan.Description == "" {
+		return plan.Title
+	}
+	return strings.Join([]string{plan.Title, plan.Description}, "\n\n")
+}
+
+func (repo *MaintenancePgRepo) GenerateDue(now time.Time) ([]*Generated, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var dueIDs []string
+	err := repo.db.WithContext(ctx).Model(&PlanPg{}).
+		Where("is_active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at").
+		Pluck("id", &dueIDs).Error
+	if err != nil {
+		repo.logger.Warnf("failed to find due maintenance plans: %v", err)
+		return nil, err
+	}
+
+	result := make([]*Generated, 0, len(dueIDs))
+	for _, planID := range dueIDs {
+		generated, err := repo.generateOne(ctx, planID, now)
+		if err != nil {
+			// one broken plan must not stop the others
+			repo.logger.Errorf("failed to generate request of maintenance plan %s: %v", planID, err)
+			continue
+		}
+		if generated != nil {
+			result = append(result, generated)
+		}
+	}
+
+	return result, nil
+}
+
+// generateOne creates the request of a due plan and moves the plan to the next occurrence after now, so a
+// scheduler that was down does not flood the house with the missed ones. Another instance may have done it
+// already, then nothing is generated.
+func (repo *MaintenancePgRepo) generateOne(ctx context.Context, planID string, now time.Time) (*Generated, error) {
+	var generated *Generated
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var planPg PlanPg
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND is_active = ? AND next_run_at <= ?", planID, true, now).
+			First(&planPg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"last_run_at": now, "next_run_at": nil}
+		rule, errRule := ParseRule(planPg.Rule)
+		if errRule != nil {
+			// a rule that can not be read any more stops the plan instead of failing every run
+			updates["is_active"] = false
+			repo.logger.Errorf("maintenance plan %s is paused, its rule %q is invalid: %v", planID, planPg.Rule, errRule)
+		} else if next, ok := rule.Next(planPg.StartsAt, now); ok {
+			updates["next_run_at"] = next
+		}
+
+		planRef := planPg.ID
+		requestPg := requests.RequestPg{
+			HouseID:     planPg.HouseID,
+			RequestType: requests.TypeHouseCommon,
+			Complaint:   planComplaint(&planPg),
+			Status:      requests.StatusCreated,
+			PlanID:      &planRef,
+			CreatedAt:   now,
+		}
+
+		createdFlag := false
+		for i := 0; i < utils.GetRetries() && !createdFlag; i++ {
+			requestID, err := utils.GenerateID()
+			if err != nil {
+				repo.logger.Warnf("failed to generate request ID, %v", err)
+				continue
+			}
+
+			requestPg.ID = requestID
+
+			upsertRes := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&requestPg)
+			if upsertRes.Error != nil {
+				return upsertRes.Error
+			}
+			createdFlag = upsertRes.RowsAffected == 1
+		}
+		if !createdFlag {
+			return requests.ErrCreatingRequestPg
+		}
+
+		if err := tx.Model(&PlanPg{}).Where("id = ?", planID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		generated = &Generated{
+			PlanID:           planID,
+			RequestID:        requestPg.ID,
+			SpecializationID: planPg.SpecializationID,
+			OccurrenceAt:     *planPg.NextRunAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return generated, nil
+}
@@ -0,0 +1,295 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid schedule rule")
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the search for the next occurrence, a daily rule reaches more than a hundred years with it.
+const maxPeriods = 50000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is the subset of the iCalendar RRULE the plans need: FREQ, INTERVAL, BYDAY for daily and weekly rules,
+// BYMONTHDAY for monthly and yearly ones, BYMONTH for yearly ones, and UNTIL or COUNT. The time of day comes
+// from the plan start.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	Until      *time.Time
+	Count      int
+}
+
+func parseInts(value string, min, max int, allowNegative bool) ([]int, error) {
+	var result []int
+	for _, part := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		abs := number
+		if allowNegative && number < 0 {
+			abs = -number
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("%d is out of range", number)
+		}
+		result = append(result, number)
+	}
+	return result, nil
+}
+
+// ParseRule reads a rule like "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1", the "RRULE:" prefix is optional.
+func ParseRule(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	rule := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, found := strings.Cut(part, "=")
+		if !found || val == "" {
+			return nil, fmt.Errorf("%w: %q is not KEY=VALUE", ErrInvalidRule, part)
+		}
+
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				weekday, ok := weekdayCodes[strings.TrimSpace(code)]
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown day %q, use MO to SU", ErrInvalidRule, code)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			days, err := parseInts(val, 1, 31, true)
+			if err != nil {
+				return nil, fmt.Errorf("%w: BYMONTHDAY: %v", ErrInvalidRule, err)
+			}
+			rule.ByMonthDay = days
+		case "BYMONTH":
+			months, err := parseInts(val, 1, 12, false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: BYMONTH: %v", ErrInvalidRule, err)
+			}
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSS", ErrInvalidRule)
+			}
+			rule.Until = &until
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRule)
+			}
+			rule.Count = count
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRule, key)
+		}
+	}
+
+	switch {
+	case rule.Freq != FreqDaily && rule.Freq != FreqWeekly && rule.Freq != FreqMonthly && rule.Freq != FreqYearly:
+		return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY", ErrInvalidRule)
+	case len(rule.ByDay) > 0 && rule.Freq != FreqDaily && rule.Freq != FreqWeekly:
+		return nil, fmt.Errorf("%w: BYDAY is supported for daily and weekly rules", ErrInvalidRule)
+	case len(rule.ByMonthDay) > 0 && rule.Freq != FreqMonthly && rule.Freq != FreqYearly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY is supported for monthly and yearly rules", ErrInvalidRule)
+	case len(rule.ByMonth) > 0 && rule.Freq != FreqYearly:
+		return nil, fmt.Errorf("%w: BYMONTH is supported for yearly rules", ErrInvalidRule)
+	case rule.Until != nil && rule.Count > 0:
+		return nil, fmt.Errorf("%w: UNTIL and COUNT can not be used together", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+// parseUntil takes a date as the whole day and a time without a zone as local, like the rest of the app.
+func parseUntil(value string) (time.Time, error) {
+	if day, err := time.ParseInLocation("20060102", value, time.Local); err == nil {
+		return day.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	if moment, err := time.Parse("20060102T150405Z", value); err == nil {
+		return moment.In(time.Local), nil
+	}
+	return time.ParseInLocation("20060102T150405", value, time.Local)
+}
+
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, weekday := range r.ByDay {
+			codes[i] = strings.ToUpper(weekday.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// expand lists the occurrences of the period-th period after the start, sorted, some may precede the start.
+func (r *Rule) expand(start time.Time, period int) []time.Time {
+	hour, minute, second := start.Clock()
+	loc := start.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	var result []time.Time
+	step := period * r.Interval
+
+	switch r.Freq {
+	case FreqDaily:
+		day := at(start.Year(), start.Month(), start.Day()+step)
+		if len(r.ByDay) == 0 || containsWeekday(r.ByDay, day.Weekday()) {
+			result = append(result, day)
+		}
+	case FreqWeekly:
+		// weeks start on Monday, as RRULE's default WKST
+		monday := start.Day() - (int(start.Weekday())+6)%7 + 7*step
+		weekdays := r.ByDay
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{start.Weekday()}
+		}
+		for _, weekday := range weekdays {
+			result = append(result, at(start.Year(), start.Month(), monday+(int(weekday)+6)%7))
+		}
+	case FreqMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		result = r.monthDays(first.Year(), first.Month(), start.Day(), at)
+	case FreqYearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			result = append(result, r.monthDays(start.Year()+step, month, start.Day(), at)...)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// monthDays resolves BYMONTHDAY in one month, negative days count from the end and missing days are skipped.
+func (r *Rule) monthDays(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{defaultDay}
+	}
+
+	total := daysIn(year, month)
+	var result []time.Time
+	for _, day := range days {
+		if day < 0 {
+			day = total + day + 1
+		}
+		if day < 1 || day > total {
+			continue
+		}
+		result = append(result, at(year, month, day))
+	}
+	return result
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, candidate := range weekdays {
+		if candidate == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Next gives the first occurrence after the moment, false when the rule is over. The start is an occurrence
+// only when it matches the rule, like DTSTART in RRULE.
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	seen := 0
+	for period := 0; period < maxPeriods; period++ {
+		var last time.Time
+		for _, occurrence := range r.expand(start, period) {
+			if occurrence.Before(start) || occurrence.Equal(last) {
+				continue
+			}
+			last = occurrence
+
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
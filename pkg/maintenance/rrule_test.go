@@ -0,0 +1,119 @@
+package maintenance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"prefix and lower case", "rrule:freq=daily", "FREQ=DAILY"},
+		{"weekly days", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12"},
+		{"yearly months", "FREQ=YEARLY;BYMONTH=3,9;BYMONTHDAY=1", "FREQ=YEARLY;BYMONTHDAY=1;BYMONTH=3,9"},
+		{"interval of one is dropped", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"until a local moment", "FREQ=DAILY;UNTIL=20250105T100000", "FREQ=DAILY;UNTIL=20250105T100000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.value)
+			if err != nil {
+				t.Fatalf("ParseRule(%q) error: %v", tt.value, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseRule(%q).String() = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"empty", ""},
+		{"no value", "FREQ"},
+		{"unsupported frequency", "FREQ=HOURLY"},
+		{"unsupported key", "FREQ=DAILY;BYSETPOS=1"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0"},
+		{"unknown day", "FREQ=WEEKLY;BYDAY=XX"},
+		{"month day zero", "FREQ=MONTHLY;BYMONTHDAY=0"},
+		{"month day too large", "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{"month day too small", "FREQ=MONTHLY;BYMONTHDAY=-32"},
+		{"negative month", "FREQ=YEARLY;BYMONTH=-1"},
+		{"days of a monthly rule", "FREQ=MONTHLY;BYDAY=MO"},
+		{"month days of a weekly rule", "FREQ=WEEKLY;BYMONTHDAY=1"},
+		{"months of a monthly rule", "FREQ=MONTHLY;BYMONTH=1"},
+		{"zero count", "FREQ=DAILY;COUNT=0"},
+		{"bad until", "FREQ=DAILY;UNTIL=2025-01-05"},
+		{"until and count", "FREQ=DAILY;UNTIL=20250105;COUNT=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRule(tt.value); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("ParseRule(%q) error = %v, want ErrInvalidRule", tt.value, err)
+			}
+		})
+	}
+}
+
+func TestRuleNext(t *testing.T) {
+	day := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		after time.Time
+		want  time.Time
+		ok    bool
+	}{
+		{"daily", "FREQ=DAILY", day(2025, 1, 1, 9), day(2025, 1, 1, 9), day(2025, 1, 2, 9), true},
+		{"start is the first occurrence", "FREQ=DAILY", day(2025, 1, 1, 9), day(2025, 1, 1, 8), day(2025, 1, 1, 9), true},
+		{"daily on weekdays skips the weekend", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", day(2025, 1, 3, 9), day(2025, 1, 3, 9), day(2025, 1, 6, 9), true},
+		{"weekly within the week", "FREQ=WEEKLY;BYDAY=MO,FR", day(2025, 1, 29, 10), day(2025, 1, 29, 10), day(2025, 1, 31, 10), true},
+		{"weekly week crossing months", "FREQ=WEEKLY;BYDAY=MO,FR", day(2025, 1, 29, 10), day(2025, 1, 31, 10), day(2025, 2, 3, 10), true},
+		{"weekly crossing years", "FREQ=WEEKLY;INTERVAL=2", day(2025, 12, 24, 10), day(2025, 12, 24, 10), day(2026, 1, 7, 10), true},
+		{"start not matching the days", "FREQ=WEEKLY;BYDAY=MO", day(2025, 1, 29, 10), day(2025, 1, 29, 9), day(2025, 2, 3, 10), true},
+		{"last day of a long month", "FREQ=MONTHLY;BYMONTHDAY=-1", day(2025, 1, 15, 9), day(2025, 1, 15, 9), day(2025, 1, 31, 9), true},
+		{"last day of February", "FREQ=MONTHLY;BYMONTHDAY=-1", day(2025, 1, 15, 9), day(2025, 1, 31, 9), day(2025, 2, 28, 9), true},
+		{"last day of a leap February", "FREQ=MONTHLY;BYMONTHDAY=-1", day(2024, 1, 15, 9), day(2024, 1, 31, 9), day(2024, 2, 29, 9), true},
+		{"31st skips short months", "FREQ=MONTHLY", day(2025, 1, 31, 9), day(2025, 1, 31, 9), day(2025, 3, 31, 9), true},
+		{"31st skips April", "FREQ=MONTHLY", day(2025, 1, 31, 9), day(2025, 3, 31, 9), day(2025, 5, 31, 9), true},
+		{"29th to 31st skip February", "FREQ=MONTHLY;BYMONTHDAY=29,30,31", day(2025, 1, 1, 9), day(2025, 1, 31, 9), day(2025, 3, 29, 9), true},
+		{"29th in a leap February", "FREQ=MONTHLY;BYMONTHDAY=29", day(2024, 1, 1, 9), day(2024, 1, 29, 9), day(2024, 2, 29, 9), true},
+		{"quarterly", "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1", day(2025, 11, 1, 9), day(2025, 11, 1, 9), day(2026, 2, 1, 9), true},
+		{"yearly on February 29", "FREQ=YEARLY", day(2024, 2, 29, 9), day(2024, 2, 29, 9), day(2028, 2, 29, 9), true},
+		{"yearly months", "FREQ=YEARLY;BYMONTH=3,9;BYMONTHDAY=1", day(2025, 1, 1, 9), day(2025, 3, 1, 9), day(2025, 9, 1, 9), true},
+		{"count not reached", "FREQ=DAILY;COUNT=3", day(2025, 1, 1, 9), day(2025, 1, 2, 9), day(2025, 1, 3, 9), true},
+		{"count reached", "FREQ=DAILY;COUNT=3", day(2025, 1, 1, 9), day(2025, 1, 3, 9), time.Time{}, false},
+		{"count skips the unmatched start", "FREQ=WEEKLY;BYDAY=MO;COUNT=1", day(2025, 1, 29, 10), day(2025, 2, 3, 10), time.Time{}, false},
+		{"until day is included", "FREQ=DAILY;UNTIL=20250105", day(2025, 1, 1, 9), day(2025, 1, 4, 9), day(2025, 1, 5, 9), true},
+		{"until passed", "FREQ=DAILY;UNTIL=20250105", day(2025, 1, 1, 9), day(2025, 1, 5, 9), time.Time{}, false},
+		{"until moment", "FREQ=DAILY;UNTIL=20250105T080000", day(2025, 1, 1, 9), day(2025, 1, 4, 9), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRule(%q) error: %v", tt.rule, err)
+			}
+
+			got, ok := rule.Next(tt.start, tt.after)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("Next(%s, %s) = %s, %v, want %s, %v", tt.start, tt.after, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
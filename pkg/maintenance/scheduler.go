@@ -0,0 +1,84 @@
+package maintenance
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const DefaultSchedulerInterval = 10 * time.Minute
+
+// Scheduler periodically turns due plan occurrences into requests and gives each one to the least busy staff
+// member of the plan's specialization. A request nobody can take stays unassigned for the staff to handle.
+type Scheduler struct {
+	Repo         MaintenanceRepo
+	StaffRepo    company.StaffRepo
+	RequestsRepo requests.RequestRepo
+	Interval     time.Duration
+	Logger       *zap.SugaredLogger
+}
+
+// Run generates the due requests right away and then every Interval until the context is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultSchedulerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(time.Now()); err != nil {
+			s.Logger.Errorf("maintenance scheduler run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) RunOnce(now time.Time) ([]*Generated, error) {
+	generated, err := s.Repo.GenerateDue(now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range generated {
+		s.assign(item)
+	}
+
+	if len(generated) > 0 {
+		s.Logger.Infof("maintenance scheduler generated %d planned requests", len(generated))
+	}
+
+	return generated, nil
+}
+
+func (s *Scheduler) assign(item *Generated) {
+	member, err := s.StaffRepo.FindLeastBusyByJobID(item.SpecializationID, requests.PriorityNormal)
+	if err != nil {
+		if !errors.Is(err, company.ErrStaffMemberNotFound) {
+			s.Logger.Errorf("failed to find staff for planned request %s: %v", item.RequestID, err)
+		}
+		return
+	}
+
+	request, err := s.RequestsRepo.GetByID(item.RequestID)
+	if err != nil {
+		s.Logger.Errorf("failed to get planned request %s: %v", item.RequestID, err)
+		return
+	}
+
+	request.ResponsibleID = &member.ID
+	if err := s.RequestsRepo.UpdateRequest(request); err != nil {
+		s.Logger.Errorf("failed to assign planned request %s to staff member %d: %v", item.RequestID, member.ID, err)
+	}
+}
@@ -0,0 +1,65 @@
+package ratings
+
+import "time"
+
+// Rating is the resident's satisfaction with a completed request, one per request.
+type Rating struct {
+	RequestID  string    `gorm:"column:id_request;type:char(40);primaryKey"`
+	ResidentID string    `gorm:"column:id_resident;type:char(40);not null;index"`
+	Score      int       `gorm:"type:smallint;not null"`
+	Comment    *string   `gorm:"type:varchar(1000)"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp;not null;default:now();index"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp;not null;default:now()"`
+}
+
+// Summary aggregates the ratings of one staff member, specialization or organization, Key is its ID.
+type Summary struct {
+	Key     string
+	Name    string
+	Count   int
+	Average float64
+}
+
+// RatingFilter narrows the list of ratings, From is inclusive and To is exclusive.
+type RatingFilter struct {
+	StaffMemberID  *int
+	OrganizationID *string
+	MaxScore       *int
+	From           *time.Time
+	To             *time.Time
+
+	Limit  int
+	Offset int
+}
+
+type RatingsRepo interface {
+	// Rate leaves or changes the rating of a completed request of the resident
+	Rate(requestID, residentID string, score int, comment *string) (*Rating, error)
+	GetByRequestIDs(requestIDs []string) (map[string]*Rating, error)
+	GetRatings(filter RatingFilter) ([]*Rating, int, error)
+	// Summarize groups the ratings given in [from, to), nil bounds are open
+	Summarize(groupBy GroupBy, from, to *time.Time) ([]*Summary, error)
+	GetTotal(from, to *time.Time) (*Summary, error)
+	GetStaffMemberSummary(staffMemberID int) (*Summary, error)
+}
+
+const (
+	MinScore = 1
+	MaxScore = 5
+)
+
+type GroupBy string
+
+const (
+	ByStaffMember    GroupBy = "staff"
+	BySpecialization GroupBy = "specialization"
+	ByOrganization   GroupBy = "organization"
+)
+
+func (g GroupBy) IsValid() bool {
+	switch g {
+	case ByStaffMember, BySpecialization, ByOrganization:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package ratings
+
+import (
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/requests"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRequestNotCompleted = errors.New("only completed requests can be rated")
+	ErrInvalidScore        = errors.New("score must be from 1 to 5")
+)
+
+type RatingPg Rating
+
+func (RatingPg) TableName() string {
+	return "request_ratings"
+}
+
+type RatingsPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewRatingsPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *RatingsPgRepo {
+	return &RatingsPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (repo *RatingsPgRepo) Rate(requestID, residentID string, score int, comment *string) (*Rating, error) {
+	if score < MinScore || score > MaxScore {
+		return nil, ErrInvalidScore
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ratingPg RatingPg
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reqPg requests.RequestPg
+		// someone else's request is reported as missing, so its existence does not leak
+		if err := tx.Where("id = ? AND id_resident = ?", requestID, residentID).First(&reqPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return requests.ErrNoRequestsFound
+			}
+			return err
+		}
+
+		if reqPg.Status != requests.StatusCompleted {
+			return ErrRequestNotCompleted
+		}
+
+		now := time.Now()
+		ratingPg = RatingPg{
+			RequestID:  requestID,
+			ResidentID: residentID,
+			Score:      score,
+			Comment:    comment,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+
+		upsertRes := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id_request"}},
+			DoUpdates: clause.AssignmentColumns([]string{"score", "comment", "updated_at"}),
+		}).Create(&ratingPg)
+		if upsertRes.Error != nil {
+			return upsertRes.Error
+		}
+
+		// the first rating time stays, the upsert only moved updated_at
+		return tx.Where("id_request = ?", requestID).First(&ratingPg).Error
+	})
+	if err != nil {
+		if !errors.Is(err, requests.ErrNoRequestsFound) && !errors.Is(err, ErrRequestNotCompleted) {
+			repo.logger.Warnf("failed to rate request %s: %v", requestID, err)
+		}
+		return nil, err
+	}
+
+	rating := Rating(ratingPg)
+	return &rating, nil
+}
+
+func (repo *RatingsPgRepo) GetByRequestIDs(requestIDs []string) (map[string]*Rating, error) {
+	result := make(map[string]*Rating, len(requestIDs))
+	if len(requestIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ratingsPg []RatingPg
+	if err := repo.db.WithContext(ctx).Where("id_request IN ?", requestIDs).Find(&ratingsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get ratings of %d requests: %v", len(requestIDs), err)
+		return nil, err
+	}
+
+	for i := range ratingsPg {
+		result[ratingsPg[i].RequestID] = (*Rating)(&ratingsPg[i])
+	}
+
+	return result, nil
+}
+
+// ratedRequests joins every rating with the request that was actually worked on, for a merged duplicate it is
+// the parent, since the duplicate itself never has a responsible.
+func (repo *RatingsPgRepo) ratedRequests(ctx context.Context) *gorm.DB {
+	reqTable := requests.RequestPg{}.TableName()
+
+	return repo.db.WithContext(ctx).
+		Table(RatingPg{}.TableName() + " AS rt").
+		Joins("JOIN " + reqTable + " AS req ON req.id = rt.id_request").
+		Joins("JOIN " + reqTable + " AS root ON root.id = COALESCE(req.id_parent, req.id)")
+}
+
+func ratedBetween(query *gorm.DB, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		query = query.Where("rt.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("rt.created_at < ?", *to)
+	}
+	return query
+}
+
+func (repo *RatingsPgRepo) GetRatings(filter RatingFilter) ([]*Rating, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := ratedBetween(repo.ratedRequests(ctx), filter.From, filter.To)
+
+	if filter.StaffMemberID != nil {
+		query = query.Where("root.id_responsible = ?", *filter.StaffMemberID)
+	}
+	if filter.OrganizationID != nil {
+		query = query.Where("root.id_organization = ?", *filter.OrganizationID)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("rt.score <= ?", *filter.MaxScore)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count ratings: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*Rating{}, 0, nil
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var ratingsPg []RatingPg
+	if err := query.Select("rt.*").Order("rt.created_at DESC").Scan(&ratingsPg).Error; err != nil {
+		repo.logger.Warnf("failed to query ratings: %v", err)
+		return nil, int(total), err
+	}
+
+	result := make([]*Rating, len(ratingsPg))
+	for i := range ratingsPg {
+		result[i] = (*Rating)(&ratingsPg[i])
+	}
+
+	return result, int(total), nil
+}
+
+const summaryColumns = "COUNT(*) AS count, ROUND(AVG(rt.score)::numeric, 2) AS average"
+
+// groupedRatings joins the rated requests with the grouping table, a staff member's rating counts for every
+// specialization they have, as the request does not record which one was needed.
+func (repo *RatingsPgRepo) groupedRatings(ctx context.Context, groupBy GroupBy) *gorm.DB {
+	query := repo.ratedRequests(ctx)
+
+	switch groupBy {
+	case BySpecialization:
+		return query.
+			Joins("JOIN " + company.StaffMemberSpecializationPg{}.TableName() + " AS sms ON sms.id_member = root.id_responsible").
+			Joins("JOIN " + company.SpecializationPg{}.TableName() + " AS spec ON spec.id = sms.id_specialization").
+			Select("spec.id AS key, spec.name AS name, " + summaryColumns).
+			Group("spec.id, spec.name")
+	case ByOrganization:
+		return query.
+			Joins("JOIN " + company.OrganizationPg{}.TableName() + " AS org ON org.id = root.id_organization").
+			Select("org.id AS key, org.name AS name, " + summaryColumns).
+			Group("org.id, org.name")
+	default:
+		return query.
+			Joins("JOIN " + company.StaffMemberPg{}.TableName() + " AS member ON member.id = root.id_responsible").
+			Select("CAST(member.id AS text) AS key, member.full_name AS name, " + summaryColumns).
+			Group("member.id, member.full_name")
+	}
+}
+
+func (repo *RatingsPgRepo) Summarize(groupBy GroupBy, from, to *time.Time) ([]*Summary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var summaries []*Summary
+	query := ratedBetween(repo.groupedRatings(ctx, groupBy), from, to)
+	if err := query.Order("average DESC, count DESC, name").Scan(&summaries).Error; err != nil {
+		repo.logger.Warnf("failed to summarize ratings by %s: %v", groupBy, err)
+		return nil, err
+	}
+
+	if summaries == nil {
+		summaries = []*Summary{}
+	}
+
+	return summaries, nil
+}
+
+func (repo *RatingsPgRepo) GetTotal(from, to *time.Time) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	summary := &Summary{Name: "all"}
+	query := ratedBetween(repo.ratedRequests(ctx), from, to).Select("COUNT(*) AS count, COALESCE(ROUND(AVG(rt.score)::numeric, 2), 0) AS average")
+	if err := query.Scan(summary).Error; err != nil {
+		repo.logger.Warnf("failed to summarize all ratings: %v", err)
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// GetStaffMemberSummary gives a zero summary to a member nobody rated yet.
+func (repo *RatingsPgRepo) GetStaffMemberSummary(staffMemberID int) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var summaries []*Summary
+	query := repo.groupedRatings(ctx, ByStaffMember).Where("member.id = ?", staffMemberID)
+	if err := query.Scan(&summaries).Error; err != nil {
+		repo.logger.Warnf("failed to summarize ratings of staff member %d: %v", staffMemberID, err)
+		return nil, err
+	}
+
+	if len(summaries) == 0 {
+		return &Summary{Key: strconv.Itoa(staffMemberID)}, nil
+	}
+
+	return summaries[0], nil
+}
@@ -0,0 +1,90 @@
+package intake
+
+import (
+	"DBPrototyping/pkg/announcements"
+	"DBPrototyping/pkg/company"
+	"DBPrototyping/pkg/i18n"
+	"DBPrototyping/pkg/requests"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxSimilarRequests is how many possible duplicates a resident is offered to join
+const maxSimilarRequests = 5
+
+// Service holds what happens to a new request apart from storing it, the same for the web form and /api/v1.
+type Service struct {
+	RequestsRepo      requests.RequestRepo
+	StaffRepo         company.StaffRepo
+	AnnouncementsRepo announcements.AnnouncementsRepo
+	Logger            *zap.SugaredLogger
+}
+
+// ActiveOutages lists the outages in force in the house. An outage everybody in the house already knows about
+// brings dozens of identical requests, so the resident confirms the request after seeing the notice. A failed
+// lookup does not stop the request.
+func (s *Service) ActiveOutages(houseID int) []*announcements.Announcement {
+	outageKind := announcements.KindOutage
+	outages, err := s.AnnouncementsRepo.GetActiveForHouses([]int{houseID}, &outageKind, time.Now())
+	if err != nil {
+		s.Logger.Errorf("failed to check outages of house %d: %v", houseID, err)
+		return nil
+	}
+	return outages
+}
+
+// OutageWarning is the question the resident answers before sending a request during the outage.
+func OutageWarning(lang i18n.Lang, outage *announcements.Announcement) string {
+	return fmt.Sprintf(i18n.T(lang, "an outage is in progress in this house: %s (until %s). Send the request anyway?"),
+		outage.Title, outage.EndsAt.Format("02.01.2006 15:04"))
+}
+
+// SimilarOpen finds the open requests of the house a new complaint may duplicate. Common property breaks for
+// the whole house at once, so the resident is offered to join the request of a neighbour instead of creating
+// another one. Apartment repairs are never duplicates, a failed lookup does not stop the request.
+func (s *Service) SimilarOpen(houseID int, requestType requests.RequestType, complaint string) []*requests.SimilarRequest {
+	if requestType != requests.TypeHouseCommon {
+		return nil
+	}
+
+	similar, err := s.RequestsRepo.FindSimilarOpen(houseID, requestType, complaint, maxSimilarRequests)
+	if err != nil {
+		s.Logger.Errorf("failed to look for duplicates in house %d: %v", houseID, err)
+		return nil
+	}
+	return similar
+}
+
+// AssignByCategory hands a new request to the least busy of the staff having a default specialization of its
+// category. Without the specializations or anybody available the request waits for staff as before.
+func (s *Service) AssignByCategory(request *requests.Request, specializationIDs []string) {
+	if len(specializationIDs) == 0 {
+		return
+	}
+
+	member, err := s.StaffRepo.FindLeastBusy(specializationIDs, request.Priority)
+	if err != nil {
+		if errors.Is(err, company.ErrStaffMemberNotFound) {
+			s.Logger.Infof("nobody available for request %s of category %v, it waits for staff", request.ID, request.CategoryID)
+		} else {
+			s.Logger.Errorf("failed to find the responsible for request %s: %v", request.ID, err)
+		}
+		return
+	}
+
+	assigned := requests.Request{
+		ID:            request.ID,
+		Status:        requests.StatusCreated,
+		ResponsibleID: &member.ID,
+	}
+	if err := s.RequestsRepo.UpdateRequest(&assigned); err != nil {
+		s.Logger.Errorf("failed to assign request %s to staff member %d: %v", request.ID, member.ID, err)
+		return
+	}
+
+	request.ResponsibleID = assigned.ResponsibleID
+	request.Status = assigned.Status
+}
@@ -0,0 +1,214 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMergedRequest = errors.New("request is merged into another one, change the parent request instead")
+	ErrMergeMismatch = errors.New("merged requests must be open, not transferred and of the same house and type")
+	ErrSelfMerge     = errors.New("request can not be merged into itself")
+	ErrNotMerged     = errors.New("request is not merged into another one")
+)
+
+const (
+	// similarComplaintThreshold is the least ComplaintSimilarity of a possible duplicate
+	similarComplaintThreshold = 0.5
+	// similarCandidatesLimit bounds how many open requests of the house are compared with a new complaint
+	similarCandidatesLimit = 200
+)
+
+// SyncMergedStatus copies the status of the parents to their merged duplicates, every status change of a
+// request goes through it so that all reporters see the same progress.
+func SyncMergedStatus(tx *gorm.DB, parentIDs ...string) error {
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	reqTable := RequestPg{}.TableName()
+
+	return tx.Exec("UPDATE "+reqTable+" AS child SET status = parent.status FROM "+reqTable+" AS parent "+
+		"WHERE child.id_parent = parent.id AND parent.id IN ? AND child.status <> parent.status", parentIDs).Error
+}
+
+// detachedStatus is the status a duplicate keeps after leaving its parent, it has neither a responsible
+// nor an organization of its own, so work in progress starts over.
+func detachedStatus(status RequestStatus) RequestStatus {
+	switch status {
+	case StatusAssigned, StatusSuspended, StatusTransferred:
+		return StatusCreated
+	default:
+		return status
+	}
+}
+
+func detachChildren(tx *gorm.DB, parentID string) error {
+	var children []RequestPg
+	if err := tx.Select("id", "status").Where("id_parent = ?", parentID).Find(&children).Error; err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := tx.Model(&RequestPg{}).Where("id = ?", child.ID).Updates(map[string]interface{}{
+			"id_parent": nil,
+			"status":    detachedStatus(child.Status),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *RequestPgRepo) FindSimilarOpen(houseID int, requestType RequestType, complaint string, limit int) ([]*SimilarRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var candidatesPg []RequestPg
+	if err := repo.db.WithContext(ctx).
+		Where("id_house = ? AND type = ? AND id_parent IS NULL AND status NOT IN ?", houseID, requestType,
+			[]RequestStatus{StatusCompleted, StatusCancelled}).
+		Order("created_at DESC").
+		Limit(similarCandidatesLimit).
+		Find(&candidatesPg).Error; err != nil {
+		repo.logger.Warnf("failed to get open requests of house %d: %v", houseID, err)
+		return nil, err
+	}
+
+	similar := make([]*SimilarRequest, 0)
+	for i := range candidatesPg {
+		similarity := ComplaintSimilarity(complaint, candidatesPg[i].Complaint)
+		if similarity < similarComplaintThreshold {
+			continue
+		}
+		similar = append(similar, &SimilarRequest{
+			Request:    Request(candidatesPg[i]),
+			Similarity: similarity,
+			Reporters:  1,
+		})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	if len(similar) == 0 {
+		return similar, nil
+	}
+
+	ids := make([]string, len(similar))
+	byID := make(map[string]*SimilarRequest, len(similar))
+	for i, s := range similar {
+		ids[i] = s.ID
+		byID[s.ID] = s
+	}
+
+	var counts []struct {
+		ParentID string `gorm:"column:id_parent"`
+		Count    int    `gorm:"column:count"`
+	}
+	if err := repo.db.WithContext(ctx).Model(&RequestPg{}).
+		Select("id_parent, COUNT(*) AS count").
+		Where("id_parent IN ?", ids).
+		Group("id_parent").
+		Scan(&counts).Error; err != nil {
+		repo.logger.Warnf("failed to count duplicates of requests %v: %v", ids, err)
+		return nil, err
+	}
+	for _, count := range counts {
+		byID[count.ParentID].Reporters += count.Count
+	}
+
+	return similar, nil
+}
+
+func (repo *RequestPgRepo) Merge(parentID string, childIDs []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var parent RequestPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", parentID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoRequestsFound
+			}
+			return err
+		}
+		if parent.ParentID != nil {
+			return ErrMergedRequest
+		}
+		if !parent.Status.IsOpen() {
+			return ErrRequestClosed
+		}
+
+		for _, childID := range childIDs {
+			if childID == parentID {
+				return ErrSelfMerge
+			}
+
+			var child RequestPg
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", childID).First(&child).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrNoRequestsFound
+				}
+				return err
+			}
+
+			if child.HouseID != parent.HouseID || child.RequestType != parent.RequestType ||
+				!child.Status.IsOpen() || child.Status == StatusTransferred {
+				return ErrMergeMismatch
+			}
+
+			if err := tx.Model(&RequestPg{}).Where("id = ?", childID).Updates(map[string]interface{}{
+				"id_parent":      parentID,
+				"status":         parent.Status,
+				"id_responsible": nil,
+			}).Error; err != nil {
+				repo.logger.Warnf("failed to merge request %s into %s: %v", childID, parentID, err)
+				return err
+			}
+
+			// duplicates of the merged request move to the new parent, the tree stays one level deep
+			if err := tx.Model(&RequestPg{}).Where("id_parent = ?", childID).Update("id_parent", parentID).Error; err != nil {
+				repo.logger.Warnf("failed to move duplicates of request %s to %s: %v", childID, parentID, err)
+				return err
+			}
+		}
+
+		return SyncMergedStatus(tx, parentID)
+	})
+}
+
+func (repo *RequestPgRepo) Unmerge(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var requestPg RequestPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&requestPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoRequestsFound
+		}
+		return err
+	}
+	if requestPg.ParentID == nil {
+		return ErrNotMerged
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&RequestPg{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"id_parent": nil,
+		"status":    detachedStatus(requestPg.Status),
+	}).Error; err != nil {
+		repo.logger.Warnf("failed to unmerge request %s: %v", id, err)
+		return err
+	}
+
+	return nil
+}
@@ -13,6 +13,23 @@ type Request struct {
 	ResponsibleID  *int          `gorm:"column:id_responsible;type:bigint"`
 	OrganizationID *string       `gorm:"column:id_organization;type:char(40)"`
 	CreatedAt      time.Time     `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+	// ParentID points to the request this duplicate was merged into, the status then follows the parent
+	ParentID *string `gorm:"column:id_parent;type:char(40);index"`
+	// PlanID marks a planned request generated by a maintenance plan, such a request has no resident
+	PlanID *string `gorm:"column:id_plan;type:char(40);index"`
+	// Priority is set by staff, SuggestedPriority is what the resident asked for when reporting the problem
+	Priority          RequestPriority  `gorm:"type:varchar(20);not null;default:'обычная';index"`
+	SuggestedPriority *RequestPriority `gorm:"column:suggested_priority;type:varchar(20)"`
+	// CategoryID refines the type, DueAt is the deadline the SLA of the category gave the request
+	CategoryID *string    `gorm:"column:id_category;type:char(40);index"`
+	DueAt      *time.Time `gorm:"column:due_at;type:timestamp"`
+
+	// what the contractor reported after the request was transferred to its organization
+	TransferredAt        *time.Time `gorm:"column:transferred_at;type:timestamp"`
+	ContractorAcceptedAt *time.Time `gorm:"column:contractor_accepted_at;type:timestamp"`
+	ContractorDoneAt     *time.Time `gorm:"column:contractor_done_at;type:timestamp"`
+	CompletionReport     *string    `gorm:"column:completion_report;type:text"`
+	InvoiceAmount        *float64   `gorm:"column:invoice_amount;type:numeric(10,2)"`
 }
 
 type RequestFilter struct {
@@ -25,17 +42,52 @@ type RequestFilter struct {
 	ResponsibleID  *int
 	OrganizationID *string
 	CreatedAt      *time.Time
+	ParentID       *string
+	// Planned keeps only the requests of maintenance plans when true and only the reported ones when false
+	Planned  *bool
+	Priority *RequestPriority
+	// CategoryIDs keeps the requests of any of the categories, a branch of the tree is passed with its subcategories
+	CategoryIDs []string
+	// Overdue keeps the open requests past their deadline
+	Overdue bool
+
+	// OrganizationScope restricts the result to one organization by exact match, unlike the search by
+	// OrganizationID it is meant for callers that must not see other organizations' requests
+	OrganizationScope *string
 
 	Limit  int
 	Offset int
 	Sort   string
 }
 
+// RequestUpdate is a progress note posted on a request, for now by contractors and staff.
+type RequestUpdate struct {
+	ID          string    `gorm:"type:char(40);primaryKey"`
+	RequestID   string    `gorm:"column:id_request;type:char(40);not null;index"`
+	AuthorPhone string    `gorm:"column:author_phone;type:varchar(40);not null"`
+	AuthorRole  string    `gorm:"column:author_role;type:varchar(20);not null"`
+	Text        string    `gorm:"type:text;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+}
+
 type InitialRequestData struct {
 	ResidentID  string
 	HouseID     int
 	RequestType RequestType
 	Complaint   string
+	// ParentID is set when the resident joins an open request instead of reporting the problem again
+	ParentID          *string
+	SuggestedPriority *RequestPriority
+	CategoryID        *string
+	// SLAHours sets the deadline counting from the creation, empty leaves the request without one
+	SLAHours *int
+}
+
+// SimilarRequest is an open request that looks like the one being reported, Reporters counts it with its merged duplicates.
+type SimilarRequest struct {
+	Request
+	Similarity float64
+	Reporters  int
 }
 
 type RequestRepo interface {
@@ -44,6 +96,19 @@ type RequestRepo interface {
 	DeleteByID(id string) error
 	UpdateRequest(updatedRequest *Request) error
 	GetByFilter(filter RequestFilter) ([]*Request, int, error)
+	GetByID(id string) (*Request, error)
+	TransferToOrganization(id, organizationID string) error
+	RecordContractorAcceptance(id, organizationID string) error
+	RecordContractorCompletion(id, organizationID, report string, invoiceAmount float64) error
+	DeclineTransfer(id, organizationID string) error
+	AddUpdate(requestID, authorPhone, authorRole, text string) (*RequestUpdate, error)
+	GetUpdates(requestID string) ([]*RequestUpdate, error)
+	FindSimilarOpen(houseID int, requestType RequestType, complaint string, limit int) ([]*SimilarRequest, error)
+	// Merge makes the children duplicates of the parent, their status follows the parent from then on
+	Merge(parentID string, childIDs []string) error
+	Unmerge(id string) error
+	// SetCategory moves the request to another category, the deadline is replaced as the new SLA gives it
+	SetCategory(id string, categoryID *string, dueAt *time.Time) error
 }
 
 type RequestType string
@@ -62,6 +127,31 @@ func (s RequestType) IsValid() bool {
 	}
 }
 
+// Code is the stable machine name of the type for clients and translations, the stored value stays Russian.
+func (s RequestType) Code() string {
+	switch s {
+	case TypeApartmentInternal:
+		return "apartment_internal"
+	case TypeHouseCommon:
+		return "house_common"
+	default:
+		return ""
+	}
+}
+
+// RequestTypes lists every request type, e.g. to translate them all.
+var RequestTypes = []RequestType{TypeApartmentInternal, TypeHouseCommon}
+
+// RequestTypeByCode finds the type by its Code.
+func RequestTypeByCode(code string) (RequestType, bool) {
+	for _, t := range RequestTypes {
+		if t.Code() == code {
+			return t, true
+		}
+	}
+	return "", false
+}
+
 type RequestStatus string
 
 const (
@@ -73,6 +163,11 @@ const (
 	StatusTransferred RequestStatus = "передана_организации"
 )
 
+// IsOpen tells whether the request still waits for work, only open requests collect duplicates.
+func (s RequestStatus) IsOpen() bool {
+	return s != StatusCompleted && s != StatusCancelled
+}
+
 func (s RequestStatus) IsValid() bool {
 	switch s {
 	case StatusCreated, StatusAssigned, StatusCompleted, StatusCancelled, StatusSuspended, StatusTransferred:
@@ -81,3 +176,102 @@ func (s RequestStatus) IsValid() bool {
 		return false
 	}
 }
+
+// CheckNoTransfer refuses a plain update that would hand the request to an organization: only the transfer checks
+// the contract and the covered types and resets the contractor's progress. Keeping the current organization and
+// status, or taking the request back from the contractor, is fine.
+func CheckNoTransfer(current *Request, status RequestStatus, organizationID *string) error {
+	if status == StatusTransferred && current.Status != StatusTransferred {
+		return ErrTransferRequired
+	}
+	if organizationID != nil && (current.OrganizationID == nil || *current.OrganizationID != *organizationID) {
+		return ErrTransferRequired
+	}
+	return nil
+}
+
+// Code is the stable machine name of the status for clients and translations, the stored value stays Russian.
+func (s RequestStatus) Code() string {
+	switch s {
+	case StatusCreated:
+		return "created"
+	case StatusAssigned:
+		return "assigned"
+	case StatusCompleted:
+		return "completed"
+	case StatusCancelled:
+		return "cancelled"
+	case StatusSuspended:
+		return "suspended"
+	case StatusTransferred:
+		return "transferred"
+	default:
+		return ""
+	}
+}
+
+// RequestStatuses lists every request status, e.g. to translate them all.
+var RequestStatuses = []RequestStatus{StatusCreated, StatusAssigned, StatusSuspended, StatusTransferred, StatusCompleted, StatusCancelled}
+
+// RequestStatusByCode finds the status by its Code.
+func RequestStatusByCode(code string) (RequestStatus, bool) {
+	for _, s := range RequestStatuses {
+		if s.Code() == code {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+type RequestPriority string
+
+const (
+	PriorityEmergency RequestPriority = "аварийная"
+	PriorityHigh      RequestPriority = "высокая"
+	PriorityNormal    RequestPriority = "обычная"
+	PriorityLow       RequestPriority = "низкая"
+)
+
+func (p RequestPriority) IsValid() bool {
+	switch p {
+	case PriorityEmergency, PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the priority for clients and translations, the stored value stays Russian.
+func (p RequestPriority) Code() string {
+	switch p {
+	case PriorityEmergency:
+		return "emergency"
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// RequestPriorities lists every request priority from the most urgent, e.g. to translate them all.
+var RequestPriorities = []RequestPriority{PriorityEmergency, PriorityHigh, PriorityNormal, PriorityLow}
+
+// RequestPriorityByCode finds the priority by its Code.
+func RequestPriorityByCode(code string) (RequestPriority, bool) {
+	for _, p := range RequestPriorities {
+		if p.Code() == code {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// PriorityOrder is an SQL expression ranking the priority in column from the most urgent, 0, to the least, 3.
+func PriorityOrder(column string) string {
+	return "CASE " + column + " WHEN '" + string(PriorityEmergency) + "' THEN 0 WHEN '" + string(PriorityHigh) +
+		"' THEN 1 WHEN '" + string(PriorityLow) + "' THEN 3 ELSE 2 END"
+}
@@ -20,10 +20,22 @@ func (RequestPg) TableName() string {
 	return "requests"
 }
 
+type RequestUpdatePg RequestUpdate
+
+func (RequestUpdatePg) TableName() string {
+	return "request_updates"
+}
+
 var (
 	ErrCreatingRequestPg      = errors.New("error creating request pg object")
 	ErrGettingResidentByPhone = errors.New("error matching resident by phone number")
 	ErrNoRequestsFound        = errors.New("no requests found")
+	ErrRequestClosed          = errors.New("request is already completed or cancelled")
+	ErrNotTransferred         = errors.New("request is not transferred to an organization")
+	ErrAlreadyAccepted        = errors.New("contractor has already accepted the request")
+	ErrNotAccepted            = errors.New("contractor has not accepted the request yet")
+	ErrCreatingUpdate         = errors.New("error creating request update")
+	ErrTransferRequired       = errors.New("a request is handed to an organization only by the transfer")
 )
 
 type RequestPgRepo struct {
@@ -46,20 +58,56 @@ func (repo *RequestPgRepo) CreateRequest(requestData InitialRequestData) (*Reque
 	}
 
 	requestPg := RequestPg{
-		ResidentID:     requestData.ResidentID,
-		HouseID:        requestData.HouseID,
-		RequestType:    requestData.RequestType,
-		Complaint:      requestData.Complaint,
-		Cost:           nil,
-		Status:         StatusCreated,
-		ResponsibleID:  nil,
-		OrganizationID: nil,
-		CreatedAt:      time.Now(),
+		ResidentID:        requestData.ResidentID,
+		HouseID:           requestData.HouseID,
+		RequestType:       requestData.RequestType,
+		Complaint:         requestData.Complaint,
+		Cost:              nil,
+		Status:            StatusCreated,
+		ResponsibleID:     nil,
+		OrganizationID:    nil,
+		CreatedAt:         time.Now(),
+		Priority:          PriorityNormal,
+		SuggestedPriority: requestData.SuggestedPriority,
+		CategoryID:        requestData.CategoryID,
+	}
+	if requestData.SLAHours != nil {
+		dueAt := requestPg.CreatedAt.Add(time.Duration(*requestData.SLAHours) * time.Hour)
+		requestPg.DueAt = &dueAt
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if requestData.ParentID != nil {
+		var parent RequestPg
+		if err := repo.db.WithContext(ctx).Where("id = ?", *requestData.ParentID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNoRequestsFound
+			}
+			repo.logger.Warnf("failed to get request %s to join: %v", *requestData.ParentID, err)
+			return nil, err
+		}
+
+		// joining a duplicate joins the request it was merged into
+		if parent.ParentID != nil {
+			if err := repo.db.WithContext(ctx).Where("id = ?", *parent.ParentID).First(&parent).Error; err != nil {
+				repo.logger.Warnf("failed to get parent request %s to join: %v", *parent.ParentID, err)
+				return nil, err
+			}
+		}
+
+		if !parent.Status.IsOpen() {
+			return nil, ErrRequestClosed
+		}
+		if parent.HouseID != requestData.HouseID || parent.RequestType != requestData.RequestType {
+			return nil, ErrMergeMismatch
+		}
+
+		requestPg.ParentID = &parent.ID
+		requestPg.Status = parent.Status
+	}
+
 	createdFlag := false
 	for i := 0; i < retries && !createdFlag; i++ {
 		requestID, err := utils.GenerateID()
@@ -177,6 +225,28 @@ func (repo *RequestPgRepo) GetByFilter(filter RequestFilter) ([]*Request, int, e
 	if filter.Complaint != nil {
 		query = query.Where("req.complaint LIKE ?", "%"+*filter.Complaint+"%")
 	}
+	if filter.OrganizationScope != nil {
+		query = query.Where("req.id_organization = ?", *filter.OrganizationScope)
+	}
+	if filter.ParentID != nil {
+		query = query.Where("req.id_parent = ?", *filter.ParentID)
+	}
+	if filter.Planned != nil {
+		if *filter.Planned {
+			query = query.Where("req.id_plan IS NOT NULL")
+		} else {
+			query = query.Where("req.id_plan IS NULL")
+		}
+	}
+	if filter.Priority != nil {
+		query = query.Where("req.priority = ?", string(*filter.Priority))
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("req.id_category IN ?", filter.CategoryIDs)
+	}
+	if filter.Overdue {
+		query = query.Where("req.due_at < ? AND req.status NOT IN ?", time.Now(), []RequestStatus{StatusCompleted, StatusCancelled})
+	}
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -199,6 +269,13 @@ func (repo *RequestPgRepo) GetByFilter(filter RequestFilter) ([]*Request, int, e
 		order = "req.type DESC"
 	case "created_asc":
 		order = "req.created_at ASC"
+	case "priority_asc":
+		// the most urgent first and the oldest first among equals, the order the work queue goes in
+		order = PriorityOrder("req.priority") + " ASC, req.created_at ASC"
+	case "priority_desc":
+		order = PriorityOrder("req.priority") + " DESC, req.created_at ASC"
+	case "due_asc":
+		order = "req.due_at ASC NULLS LAST, req.created_at ASC"
 	default:
 		order = "req.created_at DESC"
 	}
@@ -228,30 +305,63 @@ func (repo *RequestPgRepo) DeleteByID(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	res := repo.db.WithContext(ctx).Where("id = ?", id).Delete(&RequestPg{})
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// duplicates of a deleted request become requests of their own
+		if err := detachChildren(tx, id); err != nil {
+			repo.logger.Warnf("failed to detach duplicates of request %s: %v", id, err)
+			return err
+		}
+
+		res := tx.Where("id = ?", id).Delete(&RequestPg{})
+		if res.Error != nil {
+			repo.logger.Warnf("failed to delete request id %s: %v", id, res.Error)
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNoRequestsFound
+		}
+
+		return nil
+	})
+}
+
+func (repo *RequestPgRepo) UpdateRequest(updatedRequest *Request) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if updatedRequest.Status == StatusCreated && updatedRequest.ResponsibleID != nil {
+		updatedRequest.Status = StatusAssigned
+	}
+
+	requestPg := RequestPg(*updatedRequest)
+	res := repo.db.WithContext(ctx).Model(&RequestPg{}).Where("id = ? AND id_parent IS NULL", updatedRequest.ID).Updates(requestPg)
 	if res.Error != nil {
-		repo.logger.Warnf("failed to delete request id %s: %v", id, res.Error)
+		repo.logger.Warnf("failed to update request id %s: %v", updatedRequest.ID, res.Error)
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
+		if err := repo.explainMiss(ctx, updatedRequest.ID); errors.Is(err, ErrMergedRequest) {
+			return err
+		}
 		return ErrNoRequestsFound
 	}
 
-	return nil
+	return repo.syncMerged(ctx, updatedRequest.ID)
 }
 
-func (repo *RequestPgRepo) UpdateRequest(updatedRequest *Request) error {
+func (repo *RequestPgRepo) SetCategory(id string, categoryID *string, dueAt *time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if updatedRequest.Status == StatusCreated && updatedRequest.ResponsibleID != nil {
-		updatedRequest.Status = StatusAssigned
+	// a map writes the empty values too, a request of a category without SLA has no deadline
+	updates := map[string]interface{}{
+		"id_category": categoryID,
+		"due_at":      dueAt,
 	}
 
-	requestPg := RequestPg(*updatedRequest)
-	res := repo.db.WithContext(ctx).Model(&RequestPg{}).Where("id = ?", updatedRequest.ID).Updates(requestPg)
+	res := repo.db.WithContext(ctx).Model(&RequestPg{}).Where("id = ?", id).Updates(updates)
 	if res.Error != nil {
-		repo.logger.Warnf("failed to update request id %s: %v", updatedRequest.ID, res.Error)
+		repo.logger.Warnf("failed to set category of request %s: %v", id, res.Error)
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
@@ -260,3 +370,227 @@ func (repo *RequestPgRepo) UpdateRequest(updatedRequest *Request) error {
 
 	return nil
 }
+
+func (repo *RequestPgRepo) syncMerged(ctx context.Context, parentID string) error {
+	if err := SyncMergedStatus(repo.db.WithContext(ctx), parentID); err != nil {
+		repo.logger.Warnf("failed to pass the status of request %s to its duplicates: %v", parentID, err)
+		return err
+	}
+	return nil
+}
+
+func (repo *RequestPgRepo) GetByID(id string) (*Request, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var requestPg RequestPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&requestPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRequestsFound
+		}
+
+		repo.logger.Warnf("failed to get request id %s: %v", id, err)
+		return nil, err
+	}
+
+	request := Request(requestPg)
+	return &request, nil
+}
+
+// TransferToOrganization hands the request over to a contractor, a previous contractor's progress is dropped.
+// Coverage of the request type by the organization is checked by the caller.
+func (repo *RequestPgRepo) TransferToOrganization(id, organizationID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).
+		Model(&RequestPg{}).
+		Where("id = ? AND id_parent IS NULL AND status NOT IN ?", id, []RequestStatus{StatusCompleted, StatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":                 StatusTransferred,
+			"id_organization":        organizationID,
+			"id_responsible":         nil,
+			"transferred_at":         time.Now(),
+			"contractor_accepted_at": nil,
+			"contractor_done_at":     nil,
+			"completion_report":      nil,
+			"invoice_amount":         nil,
+		})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to transfer request %s to organization %s: %v", id, organizationID, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return repo.explainMiss(ctx, id)
+	}
+
+	return repo.syncMerged(ctx, id)
+}
+
+// RecordContractorAcceptance and RecordContractorCompletion only touch a request that is still transferred to
+// organizationID, the ownership check of the caller is a separate read a re-transfer may overtake.
+func (repo *RequestPgRepo) RecordContractorAcceptance(id, organizationID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).
+		Model(&RequestPg{}).
+		Where("id = ? AND id_organization = ? AND status = ? AND contractor_accepted_at IS NULL", id, organizationID, StatusTransferred).
+		Update("contractor_accepted_at", time.Now())
+	if res.Error != nil {
+		repo.logger.Warnf("failed to record acceptance of request %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if err := repo.explainTransferMiss(ctx, id, organizationID); err != nil {
+			return err
+		}
+		return ErrAlreadyAccepted
+	}
+
+	return nil
+}
+
+func (repo *RequestPgRepo) RecordContractorCompletion(id, organizationID, report string, invoiceAmount float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).
+		Model(&RequestPg{}).
+		Where("id = ? AND id_organization = ? AND status = ? AND contractor_accepted_at IS NOT NULL", id, organizationID, StatusTransferred).
+		Updates(map[string]interface{}{
+			"status":             StatusCompleted,
+			"contractor_done_at": time.Now(),
+			"completion_report":  report,
+			"invoice_amount":     invoiceAmount,
+		})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to record completion of request %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if err := repo.explainTransferMiss(ctx, id, organizationID); err != nil {
+			return err
+		}
+		return ErrNotAccepted
+	}
+
+	return repo.syncMerged(ctx, id)
+}
+
+// DeclineTransfer returns a request the contractor refused to the staff queue, only before it was accepted.
+func (repo *RequestPgRepo) DeclineTransfer(id, organizationID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := repo.db.WithContext(ctx).
+		Model(&RequestPg{}).
+		Where("id = ? AND id_organization = ? AND status = ? AND contractor_accepted_at IS NULL", id, organizationID, StatusTransferred).
+		Updates(map[string]interface{}{
+			"status":          StatusCreated,
+			"id_organization": nil,
+			"transferred_at":  nil,
+		})
+	if res.Error != nil {
+		repo.logger.Warnf("failed to decline transfer of request %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if err := repo.explainTransferMiss(ctx, id, organizationID); err != nil {
+			return err
+		}
+		return ErrAlreadyAccepted
+	}
+
+	return repo.syncMerged(ctx, id)
+}
+
+func (repo *RequestPgRepo) AddUpdate(requestID, authorPhone, authorRole, text string) (*RequestUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateID, err := utils.GenerateID()
+	if err != nil {
+		repo.logger.Warnf("failed to generate request update ID, %v", err)
+		return nil, ErrCreatingUpdate
+	}
+
+	updatePg := RequestUpdatePg{
+		ID:          updateID,
+		RequestID:   requestID,
+		AuthorPhone: authorPhone,
+		AuthorRole:  authorRole,
+		Text:        text,
+		CreatedAt:   time.Now(),
+	}
+
+	if err = repo.db.WithContext(ctx).Create(&updatePg).Error; err != nil {
+		repo.logger.Warnf("failed to insert update of request %s: %v", requestID, err)
+		return nil, err
+	}
+
+	update := RequestUpdate(updatePg)
+
+	return &update, nil
+}
+
+func (repo *RequestPgRepo) GetUpdates(requestID string) ([]*RequestUpdate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var updatesPg []RequestUpdatePg
+	if err := repo.db.WithContext(ctx).Where("id_request = ?", requestID).Order("created_at").Find(&updatesPg).Error; err != nil {
+		repo.logger.Warnf("failed to get updates of request %s: %v", requestID, err)
+		return nil, err
+	}
+
+	updates := make([]*RequestUpdate, len(updatesPg))
+	for i := range updatesPg {
+		updates[i] = (*RequestUpdate)(&updatesPg[i])
+	}
+
+	return updates, nil
+}
+
+// explainMiss finds out why a conditional update of a request touched no rows, nil means the request is
+// transferred and the contractor state was the obstacle.
+func (repo *RequestPgRepo) explainMiss(ctx context.Context, id string) error {
+	var requestPg RequestPg
+	if err := repo.db.WithContext(ctx).Select("status", "id_parent").Where("id = ?", id).First(&requestPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoRequestsFound
+		}
+		return err
+	}
+
+	if requestPg.ParentID != nil {
+		return ErrMergedRequest
+	}
+
+	switch requestPg.Status {
+	case StatusCompleted, StatusCancelled:
+		return ErrRequestClosed
+	case StatusTransferred:
+		return nil
+	default:
+		return ErrNotTransferred
+	}
+}
+
+// explainTransferMiss is explainMiss for the contractor steps, a request transferred to another organization
+// is not transferred as far as organizationID is concerned.
+func (repo *RequestPgRepo) explainTransferMiss(ctx context.Context, id, organizationID string) error {
+	if err := repo.explainMiss(ctx, id); err != nil {
+		return err
+	}
+
+	var requestPg RequestPg
+	if err := repo.db.WithContext(ctx).Select("id_organization").Where("id = ?", id).First(&requestPg).Error; err != nil {
+		return err
+	}
+	if requestPg.OrganizationID == nil || *requestPg.OrganizationID != organizationID {
+		return ErrNotTransferred
+	}
+
+	return nil
+}
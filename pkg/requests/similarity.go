@@ -0,0 +1,52 @@
+package requests
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// minSimilarWordLength drops prepositions and particles that match in any two complaints
+	minSimilarWordLength = 3
+	// similarStemLength cuts word endings, so "протекает" and "протекла" or "батарея" and "батареи" are the same word
+	similarStemLength = 5
+)
+
+func complaintStems(complaint string) map[string]bool {
+	stems := make(map[string]bool)
+
+	words := strings.FieldsFunc(strings.ToLower(complaint), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		runes := []rune(strings.ReplaceAll(word, "ё", "е"))
+		if len(runes) < minSimilarWordLength {
+			continue
+		}
+		if len(runes) > similarStemLength {
+			runes = runes[:similarStemLength]
+		}
+		stems[string(runes)] = true
+	}
+
+	return stems
+}
+
+// ComplaintSimilarity compares two complaints by the share of common word stems, from 0 to 1.
+func ComplaintSimilarity(a, b string) float64 {
+	stemsA := complaintStems(a)
+	stemsB := complaintStems(b)
+	if len(stemsA) == 0 || len(stemsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for stem := range stemsA {
+		if stemsB[stem] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(stemsA)+len(stemsB)-common)
+}
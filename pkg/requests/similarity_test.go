@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComplaintSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want float64
+	}{
+		{"identical", "Протекает кран на кухне", "Протекает кран на кухне", 1},
+		{"empty", "", "Протекает кран", 0},
+		{"only short words", "в на по", "в на по", 0},
+		{"short words are ignored", "Протекает кран на кухне", "Протекает кран в кухне", 1},
+		{"case, punctuation and ё", "Кран, кухня! Всё течёт", "кран кухня все течет", 1},
+		{"endings after five runes are cut", "протекает батарея", "протекла батареи", 1},
+		{"stems differ within five runes", "батарея", "батон", 0},
+		{"five runes word keeps its ending", "трубы", "трубой", 0},
+		{"short words keep their endings", "вода", "воды", 0},
+		{"digits are words", "квартира 125", "квартира 125", 1},
+		{"one of three stems shared", "течет кран", "течет батарея", 1.0 / 3},
+		{"repeated words count once", "кран кран кран", "кран", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComplaintSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ComplaintSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := ComplaintSimilarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ComplaintSimilarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package residence
+
+import (
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyLinked       = errors.New("resident is already linked to the house")
+	ErrLinkRequestPending  = errors.New("a request to link this house is already pending")
+	ErrLinkRequestNotFound = errors.New("house link request not found")
+	ErrLinkRequestReviewed = errors.New("house link request is already reviewed")
+	ErrCreatingLinkRequest = errors.New("error creating house link request")
+)
+
+type HouseLinkRequestPg HouseLinkRequest
+
+func (HouseLinkRequestPg) TableName() string {
+	return "house_link_requests"
+}
+
+func (repo *ResidentPgRepo) UpdateResidentProfile(residentID string, update ResidentProfileUpdate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateRes := repo.db.WithContext(ctx).
+		Model(&ResidentPg{}).
+		Where("id = ?", residentID).
+		Updates(map[string]interface{}{
+			"full_name":         update.FullName,
+			"email":             update.Email,
+			"preferred_contact": update.PreferredContact,
+		})
+
+	if updateRes.Error != nil {
+		repo.logger.Errorf("failed to update profile of resident %s: %v", residentID, updateRes.Error)
+		return updateRes.Error
+	}
+	if updateRes.RowsAffected != 1 {
+		return ErrResidentNotFound
+	}
+
+	return nil
+}
+
+func (repo *ResidentPgRepo) RequestHouseLink(residentID string, houseID int, comment *string) (*HouseLinkRequest, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&HousePg{}).Where("id = ?", houseID).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check house %d: %v", houseID, err)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrNoHouseFound
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&ResidentHousePg{}).
+		Where("id_resident = ? AND id_house = ?", residentID, houseID).
+		Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check link of resident %s to house %d: %v", residentID, houseID, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAlreadyLinked
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&HouseLinkRequestPg{}).
+		Where("id_resident = ? AND id_house = ? AND status = ?", residentID, houseID, LinkPending).
+		Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check pending link requests of resident %s: %v", residentID, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrLinkRequestPending
+	}
+
+	requestPg := HouseLinkRequestPg{
+		ResidentID: residentID,
+		HouseID:    houseID,
+		Comment:    comment,
+		Status:     LinkPending,
+		CreatedAt:  time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < retries && !createdFlag; i++ {
+		requestID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate house link request ID, %v", err)
+			continue
+		}
+
+		requestPg.ID = requestID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&requestPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingLinkRequest
+	}
+
+	request := HouseLinkRequest(requestPg)
+
+	return &request, nil
+}
+
+// GetHouseLinkRequests lists requests newest first, an empty residentID means every resident.
+func (repo *ResidentPgRepo) GetHouseLinkRequests(residentID string, status *LinkRequestStatus, limit, offset int) ([]*HouseLinkRequestView, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).
+		Table(HouseLinkRequestPg{}.TableName() + " AS link").
+		Joins("JOIN " + HousePg{}.TableName() + " AS house ON house.id = link.id_house").
+		Joins("JOIN " + ResidentPg{}.TableName() + " AS resident ON resident.id = link.id_resident")
+
+	if residentID != "" {
+		query = query.Where("link.id_resident = ?", residentID)
+	}
+	if status != nil {
+		query = query.Where("link.status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count house link requests: %v", err)
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []*HouseLinkRequestView{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var requests []*HouseLinkRequestView
+	if err := query.
+		Select("link.*, house.address, resident.full_name, resident.phone_number").
+		Order("link.created_at DESC").
+		Scan(&requests).Error; err != nil {
+		repo.logger.Warnf("failed to query house link requests: %v", err)
+		return nil, int(total), err
+	}
+
+	return requests, int(total), nil
+}
+
+// ReviewHouseLinkRequest decides a pending request, an approval links the resident to the house in the same transaction.
+func (repo *ResidentPgRepo) ReviewHouseLinkRequest(id, reviewerPhone string, approve bool, comment *string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requestPg HouseLinkRequestPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&requestPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrLinkRequestNotFound
+			}
+			return err
+		}
+
+		if requestPg.Status != LinkPending {
+			return ErrLinkRequestReviewed
+		}
+
+		status := LinkRejected
+		if approve {
+			status = LinkApproved
+		}
+
+		if err := tx.Model(&HouseLinkRequestPg{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":         status,
+			"reviewed_by":    reviewerPhone,
+			"reviewed_at":    time.Now(),
+			"review_comment": comment,
+		}).Error; err != nil {
+			repo.logger.Errorf("failed to review house link request %s: %v", id, err)
+			return err
+		}
+
+		if !approve {
+			return nil
+		}
+
+		mapping := ResidentHousePg{
+			ResidentID: requestPg.ResidentID,
+			HouseID:    requestPg.HouseID,
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mapping).Error; err != nil {
+			repo.logger.Errorf("failed to link resident %s to house %d: %v", requestPg.ResidentID, requestPg.HouseID, err)
+			return err
+		}
+
+		repo.logger.Infof("assigned house %d to resident %s by link request %s", requestPg.HouseID, requestPg.ResidentID, id)
+		return nil
+	})
+}
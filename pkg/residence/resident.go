@@ -1,10 +1,14 @@
 package residence
 
+import "time"
+
 type Resident struct {
-	ID       string  `gorm:"column:id;type:char(40);primaryKey"`
-	Phone    string  `gorm:"type:varchar(40);column:phone_number;not null;unique"`
-	FullName string  `gorm:"type:varchar(40);column:full_name;not null"`
-	Houses   []House `gorm:"many2many:residents_houses;"`
+	ID               string         `gorm:"column:id;type:char(40);primaryKey"`
+	Phone            string         `gorm:"type:varchar(40);column:phone_number;not null;unique"`
+	FullName         string         `gorm:"type:varchar(40);column:full_name;not null"`
+	Email            string         `gorm:"type:varchar(120);not null;default:''"`
+	PreferredContact ContactChannel `gorm:"column:preferred_contact;type:varchar(20);not null;default:'звонок'"`
+	Houses           []House        `gorm:"many2many:residents_houses;"`
 }
 
 type House struct {
@@ -18,6 +22,35 @@ type ResidentHouse struct {
 	HouseID    int    `gorm:"column:id_house;type:bigint;primaryKey"`
 }
 
+// ResidentProfileUpdate holds what a resident may change about themselves.
+type ResidentProfileUpdate struct {
+	FullName         string
+	Email            string
+	PreferredContact ContactChannel
+}
+
+// HouseLinkRequest is a resident's request to be linked to a house, staff approve or reject it.
+type HouseLinkRequest struct {
+	ID         string            `gorm:"type:char(40);primaryKey"`
+	ResidentID string            `gorm:"column:id_resident;type:char(40);not null;index"`
+	HouseID    int               `gorm:"column:id_house;type:bigint;not null"`
+	Comment    *string           `gorm:"type:varchar(200)"`
+	Status     LinkRequestStatus `gorm:"type:varchar(20);not null;index"`
+	CreatedAt  time.Time         `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+
+	ReviewedBy    *string    `gorm:"column:reviewed_by;type:varchar(40)"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at;type:timestamp"`
+	ReviewComment *string    `gorm:"column:review_comment;type:varchar(200)"`
+}
+
+// HouseLinkRequestView adds the address and the resident's name for the staff queue.
+type HouseLinkRequestView struct {
+	HouseLinkRequest
+	Address  string `gorm:"column:address"`
+	FullName string `gorm:"column:full_name"`
+	Phone    string `gorm:"column:phone_number"`
+}
+
 type ResidentsController interface {
 	RegisterNewResident(phone, fullName string) (*Resident, error)
 	RegisterNewHouse(address string) (*House, error)
@@ -28,6 +61,80 @@ type ResidentsController interface {
 	FindResidentHouses(residentID string) ([]*House, error)
 	DeleteResidentHouse(residentID string, houseID int) error
 	GetHouses(pattern string, limit, offset int) ([]*House, int, error)
+	GetHouseByID(houseID int) (*House, error)
 	GetResidentByID(residentID string) (*Resident, error)
 	UpdateHouseAddress(houseID int, updatedAddress string) error
+	UpdateResidentProfile(residentID string, update ResidentProfileUpdate) error
+	RequestHouseLink(residentID string, houseID int, comment *string) (*HouseLinkRequest, error)
+	GetHouseLinkRequests(residentID string, status *LinkRequestStatus, limit, offset int) ([]*HouseLinkRequestView, int, error)
+	ReviewHouseLinkRequest(id, reviewerPhone string, approve bool, comment *string) error
+}
+
+type ContactChannel string
+
+const (
+	ContactCall  ContactChannel = "звонок"
+	ContactSMS   ContactChannel = "sms"
+	ContactEmail ContactChannel = "email"
+)
+
+func (ch ContactChannel) IsValid() bool {
+	switch ch {
+	case ContactCall, ContactSMS, ContactEmail:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the channel for clients and translations, the stored value stays as it is.
+func (ch ContactChannel) Code() string {
+	switch ch {
+	case ContactCall:
+		return "call"
+	case ContactSMS:
+		return "sms"
+	case ContactEmail:
+		return "email"
+	default:
+		return ""
+	}
 }
+
+// ContactChannels lists every channel, e.g. to translate them all.
+var ContactChannels = []ContactChannel{ContactCall, ContactSMS, ContactEmail}
+
+type LinkRequestStatus string
+
+const (
+	LinkPending  LinkRequestStatus = "на_рассмотрении"
+	LinkApproved LinkRequestStatus = "одобрена"
+	LinkRejected LinkRequestStatus = "отклонена"
+)
+
+func (s LinkRequestStatus) IsValid() bool {
+	switch s {
+	case LinkPending, LinkApproved, LinkRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// Code is the stable machine name of the link request status for clients and translations, the stored value stays
+// Russian.
+func (s LinkRequestStatus) Code() string {
+	switch s {
+	case LinkPending:
+		return "pending"
+	case LinkApproved:
+		return "approved"
+	case LinkRejected:
+		return "rejected"
+	default:
+		return ""
+	}
+}
+
+// LinkRequestStatuses lists every link request status, e.g. to translate them all.
+var LinkRequestStatuses = []LinkRequestStatus{LinkPending, LinkApproved, LinkRejected}
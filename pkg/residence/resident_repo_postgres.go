@@ -204,6 +204,11 @@ func (repo *ResidentPgRepo) DeleteResidentByPhone(phoneNumber string) error {
 			return del.Error
 		}
 
+		if del := tx.Where("id_resident = ?", residentPg.ID).Delete(&HouseLinkRequestPg{}); del.Error != nil {
+			repo.logger.Errorf("error deleting house link requests for resident %s: %v", residentPg.ID, del.Error)
+			return del.Error
+		}
+
 		deleteRes := tx.Where("phone_number = ?", phoneNumber).Delete(&ResidentPg{})
 		if deleteRes.Error != nil {
 			repo.logger.Errorf("error deleting resident with phone number %s: %v", phoneNumber, deleteRes.Error)
@@ -272,6 +277,24 @@ func (repo *ResidentPgRepo) DeleteResidentHouse(residentID string, houseID int)
 	return nil
 }
 
+func (repo *ResidentPgRepo) GetHouseByID(houseID int) (*House, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var housePg HousePg
+	if err := repo.db.WithContext(ctx).Where("id = ?", houseID).First(&housePg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoHouseFound
+		}
+		repo.logger.Errorf("failed to get house %d: %v", houseID, err)
+		return nil, err
+	}
+
+	house := House(housePg)
+
+	return &house, nil
+}
+
 func (repo *ResidentPgRepo) GetHouses(pattern string, limit, offset int) ([]*House, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,55 @@
+package apitoken
+
+import "time"
+
+type Kind string
+
+const (
+	// KindPersonal is a long-lived token a staff member creates for an integration
+	KindPersonal Kind = "personal"
+	// KindAccess is a short-lived bearer token handed out together with a refresh token
+	KindAccess  Kind = "access"
+	KindRefresh Kind = "refresh"
+)
+
+const (
+	DefaultAccessTTL   = 15 * time.Minute
+	DefaultRefreshTTL  = 30 * 24 * time.Hour
+	DefaultPersonalTTL = 90 * 24 * time.Hour
+	MaxPersonalTTL     = 365 * 24 * time.Hour
+)
+
+type Token struct {
+	ID         int        `gorm:"type:bigint;primaryKey;autoIncrement" json:"id"`
+	TokenHash  string     `gorm:"type:char(64);column:token_hash;not null;uniqueIndex" json:"-"`
+	Phone      string     `gorm:"type:varchar(40);column:phone_number;not null;index" json:"phoneNumber"`
+	Kind       Kind       `gorm:"type:varchar(16);column:kind;not null" json:"kind"`
+	Role       string     `gorm:"type:varchar(16);column:role;not null" json:"role"`
+	Name       string     `gorm:"type:varchar(64);column:name" json:"name"`
+	FamilyID   string     `gorm:"type:varchar(40);column:family_id;index" json:"-"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;type:timestamp;not null" json:"expiresAt"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:timestamp" json:"lastUsedAt"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp" json:"-"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()" json:"createdAt"`
+}
+
+// Pair is what a resident client receives after logging in or refreshing, both tokens are shown only once.
+type Pair struct {
+	AccessToken      string    `json:"accessToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	TokenType        string    `json:"tokenType"`
+	ExpiresIn        int       `json:"expiresIn"`
+	AccessExpiresAt  time.Time `json:"accessExpiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+type TokenRepo interface {
+	CreatePersonal(phone, role, name string, ttl time.Duration) (string, *Token, error)
+	IssuePair(phone, role string, accessTTL, refreshTTL time.Duration) (*Pair, error)
+	Refresh(refreshToken string, accessTTL, refreshTTL time.Duration) (*Pair, *Token, error)
+	Authenticate(token string) (*Token, error)
+	ListByPhone(phone string) ([]*Token, error)
+	Revoke(phone string, id int) error
+	RevokeRefreshToken(refreshToken string) error
+	RevokeAllByPhone(phone string) (int, error)
+}
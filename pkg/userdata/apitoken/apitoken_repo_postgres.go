@@ -0,0 +1,307 @@
+package apitoken
+
+import (
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid or expired token")
+	ErrTokenNotFound = errors.New("token not found")
+)
+
+// prefixes make leaked tokens easy to recognise in logs and secret scanners
+var kindPrefixes = map[Kind]string{
+	KindPersonal: "hoa_pat_",
+	KindAccess:   "hoa_at_",
+	KindRefresh:  "hoa_rt_",
+}
+
+type TokenPg Token
+
+func (TokenPg) TableName() string {
+	return "api_tokens"
+}
+
+type TokenPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTokenPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *TokenPgRepo {
+	return &TokenPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func newTokenPg(phone, role, name, familyID string, kind Kind, ttl time.Duration, now time.Time) (string, *TokenPg, error) {
+	raw, err := utils.GenerateID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := kindPrefixes[kind] + raw
+
+	return token, &TokenPg{
+		TokenHash: utils.HashToken(token),
+		Phone:     phone,
+		Kind:      kind,
+		Role:      role,
+		Name:      name,
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+func (repo *TokenPgRepo) CreatePersonal(phone, role, name string, ttl time.Duration) (string, *Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token, tokenPg, err := newTokenPg(phone, role, name, "", KindPersonal, ttl, time.Now())
+	if err != nil {
+		repo.logger.Warnf("failed to generate personal access token, %v", err)
+		return "", nil, err
+	}
+
+	if err := repo.db.WithContext(ctx).Create(tokenPg).Error; err != nil {
+		repo.logger.Errorf("failed to store personal access token for %s: %v", phone, err)
+		return "", nil, err
+	}
+
+	created := Token(*tokenPg)
+	return token, &created, nil
+}
+
+func (repo *TokenPgRepo) issuePairTx(tx *gorm.DB, phone, role, familyID string, accessTTL, refreshTTL time.Duration) (*Pair, error) {
+	now := time.Now()
+
+	accessToken, accessPg, err := newTokenPg(phone, role, "", familyID, KindAccess, accessTTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshPg, err := newTokenPg(phone, role, "", familyID, KindRefresh, refreshTTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create([]*TokenPg{accessPg, refreshPg}).Error; err != nil {
+		return nil, err
+	}
+
+	return &Pair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(accessTTL.Seconds()),
+		AccessExpiresAt:  accessPg.ExpiresAt,
+		RefreshExpiresAt: refreshPg.ExpiresAt,
+	}, nil
+}
+
+func (repo *TokenPgRepo) IssuePair(phone, role string, accessTTL, refreshTTL time.Duration) (*Pair, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	familyID, err := utils.GenerateID()
+	if err != nil {
+		repo.logger.Warnf("failed to generate token family, %v", err)
+		return nil, err
+	}
+
+	pair, err := repo.issuePairTx(repo.db.WithContext(ctx), phone, role, familyID, accessTTL, refreshTTL)
+	if err != nil {
+		repo.logger.Errorf("failed to issue tokens for %s: %v", phone, err)
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Refresh rotates the refresh token. Presenting a refresh token that was already rotated means it leaked,
+// so the whole family of tokens descending from the same login is revoked.
+func (repo *TokenPgRepo) Refresh(refreshToken string, accessTTL, refreshTTL time.Duration) (*Pair, *Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		pair      *Pair
+		refreshed Token
+		reused    bool
+	)
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tokenPg TokenPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND kind = ?", utils.HashToken(refreshToken), KindRefresh).
+			First(&tokenPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		refreshed = Token(tokenPg)
+
+		if tokenPg.RevokedAt != nil {
+			reused = true
+			return tx.Model(&TokenPg{}).
+				Where("family_id = ? AND revoked_at IS NULL", tokenPg.FamilyID).
+				Update("revoked_at", now).Error
+		}
+		if now.After(tokenPg.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		// the access tokens of the family are replaced as well, a refresh ends the previous pair
+		if err := tx.Model(&TokenPg{}).
+			Where("family_id = ? AND revoked_at IS NULL", tokenPg.FamilyID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = repo.issuePairTx(tx, tokenPg.Phone, tokenPg.Role, tokenPg.FamilyID, accessTTL, refreshTTL)
+		return err
+	})
+
+	if err != nil {
+		repo.logger.Infof("token refresh failed: %v", err)
+		return nil, nil, err
+	}
+	if reused {
+		repo.logger.Warnf("revoked refresh token of %s reused, token family revoked", refreshed.Phone)
+		return nil, nil, ErrInvalidToken
+	}
+
+	return pair, &refreshed, nil
+}
+
+// Authenticate accepts personal and access tokens, refresh tokens are only good for Refresh.
+func (repo *TokenPgRepo) Authenticate(token string) (*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var tokenPg TokenPg
+	if err := repo.db.WithContext(ctx).
+		Where("token_hash = ? AND kind IN ?", utils.HashToken(token), []Kind{KindPersonal, KindAccess}).
+		First(&tokenPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+
+		repo.logger.Errorf("failed to find api token: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if tokenPg.RevokedAt != nil || now.After(tokenPg.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	// last use is informational, one update a minute is plenty
+	if tokenPg.LastUsedAt == nil || now.Sub(*tokenPg.LastUsedAt) > time.Minute {
+		if err := repo.db.WithContext(ctx).Model(&TokenPg{}).
+			Where("id = ?", tokenPg.ID).
+			Update("last_used_at", now).Error; err != nil {
+			repo.logger.Warnf("failed to update last use of token %d: %v", tokenPg.ID, err)
+		}
+		tokenPg.LastUsedAt = &now
+	}
+
+	authenticated := Token(tokenPg)
+	return &authenticated, nil
+}
+
+func (repo *TokenPgRepo) ListByPhone(phone string) ([]*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var tokensPg []TokenPg
+	if err := repo.db.WithContext(ctx).
+		Where("phone_number = ? AND revoked_at IS NULL AND expires_at > ?", phone, time.Now()).
+		Order("created_at DESC").
+		Find(&tokensPg).Error; err != nil {
+		repo.logger.Errorf("failed to list api tokens for %s: %v", phone, err)
+		return nil, err
+	}
+
+	tokens := make([]*Token, len(tokensPg))
+	for i := range tokensPg {
+		token := Token(tokensPg[i])
+		tokens[i] = &token
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes a token of the given phone, for access and refresh tokens the whole pair goes.
+func (repo *TokenPgRepo) Revoke(phone string, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var tokenPg TokenPg
+	if err := repo.db.WithContext(ctx).
+		Where("id = ? AND phone_number = ? AND revoked_at IS NULL", id, phone).
+		First(&tokenPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenNotFound
+		}
+
+		repo.logger.Errorf("failed to find api token %d: %v", id, err)
+		return err
+	}
+
+	query := repo.db.WithContext(ctx).Model(&TokenPg{}).Where("id = ?", id)
+	if tokenPg.FamilyID != "" {
+		query = repo.db.WithContext(ctx).Model(&TokenPg{}).Where("family_id = ? AND revoked_at IS NULL", tokenPg.FamilyID)
+	}
+
+	if err := query.Update("revoked_at", time.Now()).Error; err != nil {
+		repo.logger.Errorf("failed to revoke api token %d: %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *TokenPgRepo) RevokeRefreshToken(refreshToken string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var tokenPg TokenPg
+	if err := repo.db.WithContext(ctx).
+		Where("token_hash = ? AND kind = ? AND revoked_at IS NULL", utils.HashToken(refreshToken), KindRefresh).
+		First(&tokenPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	return repo.Revoke(tokenPg.Phone, tokenPg.ID)
+}
+
+func (repo *TokenPgRepo) RevokeAllByPhone(phone string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	revokeRes := repo.db.WithContext(ctx).Model(&TokenPg{}).
+		Where("phone_number = ? AND revoked_at IS NULL", phone).
+		Update("revoked_at", time.Now())
+	if revokeRes.Error != nil {
+		repo.logger.Errorf("failed to revoke api tokens for %s: %v", phone, revokeRes.Error)
+		return 0, revokeRes.Error
+	}
+
+	return int(revokeRes.RowsAffected), nil
+}
@@ -0,0 +1,77 @@
+package apitoken
+
+import (
+	"DBPrototyping/pkg/userdata/session"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TokenIDContextKey holds the ID of the token that authenticated the request.
+const TokenIDContextKey = "apiTokenID"
+
+type Authenticator struct {
+	Logger *zap.SugaredLogger
+	Tokens TokenRepo
+	Roles  session.RoleResolver
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="hoa"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+// BearerAuth authenticates requests with an Authorization: Bearer header and sets the same phoneNumber and
+// role context keys the cookie session does, requests without the header are left to the session.
+func (a *Authenticator) BearerAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, rawToken, ok := strings.Cut(header, " ")
+		rawToken = strings.TrimSpace(rawToken)
+		if !ok || !strings.EqualFold(scheme, "Bearer") || rawToken == "" {
+			abortUnauthorized(c, "malformed authorization header")
+			return
+		}
+
+		token, err := a.Tokens.Authenticate(rawToken)
+		if err != nil {
+			if errors.Is(err, ErrInvalidToken) {
+				abortUnauthorized(c, err.Error())
+				return
+			}
+
+			a.Logger.Errorf("authenticate api token: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate"})
+			return
+		}
+
+		roles, err := a.Roles.RolesForPhone(token.Phone)
+		if err != nil {
+			a.Logger.Errorf("resolve roles for token %d of %s: %v", token.ID, token.Phone, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate"})
+			return
+		}
+
+		if !slices.Contains(roles, session.Role(token.Role)) {
+			a.Logger.Infof("token %d of %s rejected, role %s is no longer held", token.ID, token.Phone, token.Role)
+			abortUnauthorized(c, ErrInvalidToken.Error())
+			return
+		}
+
+		c.Set("phoneNumber", token.Phone)
+		c.Set("role", token.Role)
+		c.Set(session.AuthMethodContextKey, session.AuthMethodToken)
+		c.Set(TokenIDContextKey, token.ID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package credentials
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigratePhonesToE164 rewrites digit-only phone numbers stored before normalization was introduced,
+// following the same rules as NormalizePhone. Every table must have a phone_number column.
+// All tables are migrated in one transaction, so a conflict leaves the data untouched.
+func MigratePhonesToE164(db *gorm.DB, logger *zap.SugaredLogger, tables ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			res := tx.Exec(`UPDATE `+table+` SET phone_number = CASE
+					WHEN phone_number ~ '^8[0-9]{10}$' THEN '+' || ? || substr(phone_number, 2)
+					WHEN phone_number ~ '^[0-9]{10}$' THEN '+' || ? || phone_number
+					ELSE '+' || phone_number
+				END
+				WHERE phone_number ~ '^[0-9]+$'`, DefaultCountryCode, DefaultCountryCode)
+			if res.Error != nil {
+				logger.Errorf("failed to migrate phone numbers in %s: %v", table, res.Error)
+				return res.Error
+			}
+
+			if res.RowsAffected > 0 {
+				logger.Infof("migrated %d phone numbers in %s to E.164", res.RowsAffected, table)
+			}
+		}
+
+		return nil
+	})
+}
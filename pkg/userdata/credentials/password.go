@@ -0,0 +1,175 @@
+package credentials
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// bcryptMaxBytes is the longest input bcrypt accepts, everything after it would be silently ignored.
+const bcryptMaxBytes = 72
+
+var (
+	ErrPasswordEmpty     = errors.New("password is required")
+	ErrPasswordTooShort  = errors.New("password is too short")
+	ErrPasswordTooLong   = errors.New("password is too long")
+	ErrPasswordClasses   = errors.New("password does not mix enough kinds of characters")
+	ErrPasswordCommon    = errors.New("password is too common, choose another one")
+	ErrPasswordPhone     = errors.New("password must not contain the phone number")
+	ErrPasswordNonPrints = errors.New("password contains control characters")
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = loadCommonPasswords(commonPasswordsFile)
+
+func loadCommonPasswords(file string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(file))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(norm.NFC.String(line))] = struct{}{}
+	}
+
+	return passwords
+}
+
+// PasswordPolicy is applied whenever a password is chosen, it is never applied on login so that accounts
+// created under an older policy keep working. Lengths are counted in characters, not bytes.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// MinClasses is how many of lower case, upper case, digits and symbols a password has to contain
+	MinClasses int
+	// PassphraseLength lifts the MinClasses requirement for passwords of at least this many characters
+	PassphraseLength int
+	RejectCommon     bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:        8,
+	MaxLength:        64,
+	MinClasses:       2,
+	PassphraseLength: 16,
+	RejectCommon:     true,
+}
+
+// LoadPasswordPolicy starts from DefaultPasswordPolicy and overrides whatever is set in the environment.
+func LoadPasswordPolicy() PasswordPolicy {
+	policy := DefaultPasswordPolicy
+
+	readInt := func(name string, target *int) {
+		if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+			*target = value
+		}
+	}
+
+	readInt("PASSWORD_MIN_LENGTH", &policy.MinLength)
+	readInt("PASSWORD_MAX_LENGTH", &policy.MaxLength)
+	readInt("PASSWORD_MIN_CLASSES", &policy.MinClasses)
+	readInt("PASSWORD_PASSPHRASE_LENGTH", &policy.PassphraseLength)
+
+	if value, err := strconv.ParseBool(os.Getenv("PASSWORD_REJECT_COMMON")); err == nil {
+		policy.RejectCommon = value
+	}
+
+	return policy
+}
+
+// NormalizePassword brings the password to NFC, so the same passphrase typed on different keyboards
+// hashes to the same value. Passwords must be normalized both when they are set and when they are checked.
+func NormalizePassword(password string) string {
+	return norm.NFC.String(password)
+}
+
+// CheckPasswordInput does the minimal sanity checks that are safe to run on login.
+func CheckPasswordInput(password string) error {
+	if password == "" {
+		return ErrPasswordEmpty
+	}
+	if !utf8.ValidString(password) {
+		return ErrPasswordNonPrints
+	}
+	if len(NormalizePassword(password)) > bcryptMaxBytes {
+		return ErrPasswordTooLong
+	}
+	return nil
+}
+
+func countClasses(password string) int {
+	var lower, upper, digit, symbol bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// Validate checks a new password, phone is the (normalized) phone of the account and may be empty.
+func (p PasswordPolicy) Validate(password, phone string) error {
+	if err := CheckPasswordInput(password); err != nil {
+		return err
+	}
+
+	password = NormalizePassword(password)
+
+	for _, r := range password {
+		if unicode.IsControl(r) {
+			return ErrPasswordNonPrints
+		}
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: at least %d characters required", ErrPasswordTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: at most %d characters allowed", ErrPasswordTooLong, p.MaxLength)
+	}
+
+	if (p.PassphraseLength <= 0 || length < p.PassphraseLength) && countClasses(password) < p.MinClasses {
+		return fmt.Errorf("%w: use at least %d of lower case, upper case, digits and symbols", ErrPasswordClasses, p.MinClasses)
+	}
+
+	if p.RejectCommon {
+		if _, common := commonPasswords[strings.ToLower(password)]; common {
+			return ErrPasswordCommon
+		}
+	}
+
+	// the national part of the number is what people actually type, with or without the country code
+	if digits := strings.TrimPrefix(phone, "+"); len(digits) >= 10 && strings.Contains(password, digits[len(digits)-10:]) {
+		return ErrPasswordPhone
+	}
+
+	return nil
+}
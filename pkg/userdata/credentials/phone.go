@@ -0,0 +1,79 @@
+package credentials
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultCountryCode is assumed for numbers written without one, the association only serves Russian addresses.
+const DefaultCountryCode = "7"
+
+// E.164 allows up to 15 digits including the country code, anything shorter than 8 is not a dialable number.
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// NormalizePhone converts a phone number as people type it ("8 (900) 123-45-67", "+7 900 1234567",
+// full-width digits) into E.164 form, e.g. "+79001234567".
+func NormalizePhone(raw string) (string, error) {
+	raw = strings.TrimSpace(norm.NFKC.String(raw))
+	if raw == "" {
+		return "", ErrInvalidPhone
+	}
+
+	hasPlus := strings.HasPrefix(raw, "+")
+	if hasPlus {
+		raw = raw[1:]
+	} else if strings.HasPrefix(raw, "00") {
+		// international call prefix used instead of a plus
+		hasPlus = true
+		raw = raw[2:]
+	}
+
+	digits := make([]byte, 0, len(raw))
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+			continue
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	number := string(digits)
+	if !hasPlus {
+		switch {
+		case len(number) == 11 && strings.HasPrefix(number, "8"):
+			// domestic trunk prefix
+			number = DefaultCountryCode + number[1:]
+		case len(number) == 10:
+			number = DefaultCountryCode + number
+		}
+	}
+
+	if len(number) < minPhoneDigits || len(number) > maxPhoneDigits || strings.HasPrefix(number, "0") {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + number, nil
+}
+
+// PhoneSearchPattern strips a partial phone number typed into a search box down to its digits.
+func PhoneSearchPattern(raw string) string {
+	raw = norm.NFKC.String(raw)
+
+	var sb strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
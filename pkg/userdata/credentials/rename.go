@@ -0,0 +1,57 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrPhoneTaken = errors.New("phone number is already in use")
+
+// PhoneRenamer moves an account with everything keyed by its phone number to a new number.
+type PhoneRenamer interface {
+	RenamePhone(oldPhone, newPhone string) error
+}
+
+// PgPhoneRenamer rewrites phone_number in every table in one transaction, like MigratePhonesToE164 does.
+// The new number must not appear in any of the tables, otherwise it belongs to another account.
+type PgPhoneRenamer struct {
+	DB     *gorm.DB
+	Logger *zap.SugaredLogger
+	Tables []string
+}
+
+func (r *PgPhoneRenamer) RenamePhone(oldPhone, newPhone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range r.Tables {
+			var count int64
+			if err := tx.Table(table).Where("phone_number = ?", newPhone).Count(&count).Error; err != nil {
+				r.Logger.Errorf("failed to check phone number in %s: %v", table, err)
+				return err
+			}
+			if count > 0 {
+				return ErrPhoneTaken
+			}
+		}
+
+		for _, table := range r.Tables {
+			res := tx.Exec(`UPDATE `+table+` SET phone_number = ? WHERE phone_number = ?`, newPhone, oldPhone)
+			if res.Error != nil {
+				r.Logger.Errorf("failed to rename phone number in %s: %v", table, res.Error)
+				return res.Error
+			}
+
+			if res.RowsAffected > 0 {
+				r.Logger.Infof("renamed phone number in %d rows of %s", res.RowsAffected, table)
+			}
+		}
+
+		return nil
+	})
+}
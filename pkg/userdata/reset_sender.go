@@ -0,0 +1,17 @@
+package userdata
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LogResetTokenSender writes reset tokens to the application log, it is meant for development only.
+type LogResetTokenSender struct {
+	Logger *zap.SugaredLogger
+}
+
+func (s *LogResetTokenSender) SendResetToken(phone, token string, expiresAt time.Time) error {
+	s.Logger.Infof("password reset token for %s: %s (expires at %s)", phone, token, expiresAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,28 @@
+package session
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const sessKeyLanguage string = "language"
+
+// CachedLanguage is the language the signed in user picked, kept in the session so that it is not read from the
+// database on every request. False means nothing is cached yet, an empty language means the browser's one. A
+// change made on another device shows up here after the next login.
+func CachedLanguage(c *gin.Context) (string, bool) {
+	language, ok := sessions.Default(c).Get(sessKeyLanguage).(string)
+	return language, ok
+}
+
+// CacheLanguage keeps the language in the session. Requests authenticated by a token have no session of their
+// own, for them nothing is stored.
+func CacheLanguage(c *gin.Context, language string) error {
+	if c.GetString(AuthMethodContextKey) == AuthMethodToken {
+		return nil
+	}
+
+	userSession := sessions.Default(c)
+	userSession.Set(sessKeyLanguage, language)
+	return userSession.Save()
+}
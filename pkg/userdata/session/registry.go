@@ -0,0 +1,43 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrRoleRevoked     = errors.New("user no longer holds the session role")
+)
+
+const (
+	DefaultIdleTimeout     = 30 * time.Minute
+	DefaultAbsoluteTimeout = 12 * time.Hour
+)
+
+// Info describes one logged in browser, the session cookie only carries its ID.
+type Info struct {
+	ID        string    `json:"id"`
+	Phone     string    `json:"phoneNumber"`
+	Role      Role      `json:"role"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Registry keeps track of active sessions per phone so they can be listed and revoked server-side.
+type Registry interface {
+	Register(info *Info, ttl time.Duration) error
+	Get(id string) (*Info, error)
+	Touch(id string, role Role, lastSeen time.Time) error
+	ListByPhone(phone string) ([]*Info, error)
+	Revoke(id string) error
+	RevokeAllByPhone(phone string) (int, error)
+}
+
+// RoleResolver looks up the roles a phone holds right now in the database.
+type RoleResolver interface {
+	RolesForPhone(phone string) ([]Role, error)
+}
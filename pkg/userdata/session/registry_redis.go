@@ -0,0 +1,178 @@
+package session
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	redisSessionPrefix   = "hoa_session:"
+	redisUserSetPrefix   = "hoa_user_sessions:"
+	redisSessionFieldNum = 7
+)
+
+type RedisRegistry struct {
+	pool *redis.Pool
+}
+
+func NewRedisRegistry(pool *redis.Pool) *RedisRegistry {
+	return &RedisRegistry{
+		pool: pool,
+	}
+}
+
+func infoFromHash(id string, fields map[string]string) *Info {
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	lastSeen, _ := strconv.ParseInt(fields["last_seen"], 10, 64)
+
+	return &Info{
+		ID:        id,
+		Phone:     fields["phone"],
+		Role:      Role(fields["role"]),
+		IP:        fields["ip"],
+		UserAgent: fields["user_agent"],
+		CreatedAt: time.Unix(createdAt, 0),
+		LastSeen:  time.Unix(lastSeen, 0),
+	}
+}
+
+func (r *RedisRegistry) Register(info *Info, ttl time.Duration) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	sessionKey := redisSessionPrefix + info.ID
+	userKey := redisUserSetPrefix + info.Phone
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	_ = conn.Send("HSET", sessionKey,
+		"phone", info.Phone,
+		"role", string(info.Role),
+		"ip", info.IP,
+		"user_agent", info.UserAgent,
+		"created_at", info.CreatedAt.Unix(),
+		"last_seen", info.LastSeen.Unix(),
+	)
+	_ = conn.Send("EXPIRE", sessionKey, int(ttl.Seconds()))
+	_ = conn.Send("SADD", userKey, info.ID)
+	_ = conn.Send("EXPIRE", userKey, int(ttl.Seconds()))
+
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (r *RedisRegistry) Get(id string) (*Info, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	fields, err := redis.StringMap(conn.Do("HGETALL", redisSessionPrefix+id))
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < redisSessionFieldNum {
+		return nil, ErrSessionNotFound
+	}
+
+	return infoFromHash(id, fields), nil
+}
+
+func (r *RedisRegistry) Touch(id string, role Role, lastSeen time.Time) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	// HSET on a missing key would resurrect a revoked session, so only existing entries are updated
+	exists, err := redis.Bool(conn.Do("EXISTS", redisSessionPrefix+id))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSessionNotFound
+	}
+
+	_, err = conn.Do("HSET", redisSessionPrefix+id, "role", string(role), "last_seen", lastSeen.Unix())
+	return err
+}
+
+func (r *RedisRegistry) ListByPhone(phone string) ([]*Info, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	userKey := redisUserSetPrefix + phone
+
+	ids, err := redis.Strings(conn.Do("SMEMBERS", userKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Info, 0, len(ids))
+	for _, id := range ids {
+		fields, err := redis.StringMap(conn.Do("HGETALL", redisSessionPrefix+id))
+		if err != nil {
+			return nil, err
+		}
+
+		// the entry has expired on its own, drop the dangling ID
+		if len(fields) < redisSessionFieldNum {
+			if _, err := conn.Do("SREM", userKey, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result = append(result, infoFromHash(id, fields))
+	}
+
+	return result, nil
+}
+
+func (r *RedisRegistry) Revoke(id string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	phone, err := redis.String(conn.Do("HGET", redisSessionPrefix+id, "phone"))
+	if errors.Is(err, redis.ErrNil) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	_ = conn.Send("DEL", redisSessionPrefix+id)
+	_ = conn.Send("SREM", redisUserSetPrefix+phone, id)
+
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (r *RedisRegistry) RevokeAllByPhone(phone string) (int, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	userKey := redisUserSetPrefix + phone
+
+	ids, err := redis.Strings(conn.Do("SMEMBERS", userKey))
+	if err != nil {
+		return 0, err
+	}
+
+	if err := conn.Send("MULTI"); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		_ = conn.Send("DEL", redisSessionPrefix+id)
+	}
+	_ = conn.Send("DEL", userKey)
+
+	if _, err := conn.Do("EXEC"); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,18 +11,34 @@ type Role string
 const (
 	StaffRole    Role = "staff"
 	ResidentRole Role = "resident"
+	// ContractorRole belongs to representatives of organizations that requests are transferred to
+	ContractorRole Role = "contractor"
 )
 
 const (
-	sessKeyRole  string = "role"
-	sessKeyPhone string = "phoneNumber"
+	sessKeyRole      string = "role"
+	sessKeyPhone     string = "phoneNumber"
+	sessKeySessionID string = "sessionID"
+
+	sessKeyPendingPhone string = "pendingTwoFactorPhone"
+	sessKeyPendingSince string = "pendingTwoFactorSince"
 )
 
+// PendingTwoFactorTTL limits how long a password-verified login may wait for its second factor.
+const PendingTwoFactorTTL = 5 * time.Minute
+
 type GinSessionManagerRepo interface {
 	RequireRoles(allowed ...Role) gin.HandlerFunc
 	UserFromSession() gin.HandlerFunc
-	SetUserSessionRole(c *gin.Context, role Role)
-	SetUserSessionPhone(c *gin.Context, phone string)
+	CSRFProtect(exemptPaths ...string) gin.HandlerFunc
+	StartUserSession(c *gin.Context, phone string, role Role) error
+	CurrentSessionID(c *gin.Context) string
+	ListUserSessions(phone string) ([]*Info, error)
+	RevokeUserSession(phone, id string) error
+	RevokeAllUserSessions(phone string) (int, error)
+	SetPendingTwoFactor(c *gin.Context, phone string)
+	GetPendingTwoFactor(c *gin.Context) (string, bool)
+	ClearPendingTwoFactor(c *gin.Context)
 	SaveSession(c *gin.Context) error
 	ClearSession(c *gin.Context)
 }
@@ -1,16 +1,41 @@
 package session
 
 import (
+	"DBPrototyping/pkg/utils"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// touchInterval limits how often a request refreshes the last seen time of its session in the registry.
+const touchInterval = time.Minute
+
 type GinSessionManager struct {
-	Logger *zap.SugaredLogger
+	Logger          *zap.SugaredLogger
+	Registry        Registry
+	Roles           RoleResolver
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+}
+
+func (sm *GinSessionManager) idleTimeout() time.Duration {
+	if sm.IdleTimeout <= 0 {
+		return DefaultIdleTimeout
+	}
+	return sm.IdleTimeout
+}
+
+func (sm *GinSessionManager) absoluteTimeout() time.Duration {
+	if sm.AbsoluteTimeout <= 0 {
+		return DefaultAbsoluteTimeout
+	}
+	return sm.AbsoluteTimeout
 }
 
 func (sm *GinSessionManager) RequireRoles(allowed ...Role) gin.HandlerFunc {
@@ -41,48 +66,226 @@ func (sm *GinSessionManager) RequireRoles(allowed ...Role) gin.HandlerFunc {
 	}
 }
 
+// UserFromSession trusts the cookie only as far as the registry and the database agree with it: a revoked,
+// expired or orphaned session is dropped and a role the user no longer holds is taken away. Every request is
+// checked, static assets are served without the check.
 func (sm *GinSessionManager) UserFromSession() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/static/") {
+			c.Next()
+			return
+		}
+
 		sess := sessions.Default(c)
 		if sess == nil {
 			c.Next()
 			return
 		}
 
-		if sessPhoneVal := sess.Get("phoneNumber"); sessPhoneVal != nil {
-			if phone, ok := sessPhoneVal.(string); ok && phone != "" {
-				c.Set("phoneNumber", phone)
-			}
+		phone, _ := sess.Get(sessKeyPhone).(string)
+		role, _ := sess.Get(sessKeyRole).(string)
+		if phone == "" || role == "" {
+			c.Next()
+			return
 		}
 
-		if sessRoleVal := sess.Get(sessKeyRole); sessRoleVal != nil {
-			switch val := sessRoleVal.(type) {
-			case string:
-				if val != "" {
-					c.Set(sessKeyRole, val)
-				}
-			default:
-				if s := fmt.Sprintf("%v", val); s != "" {
-					c.Set(sessKeyRole, s)
+		id, _ := sess.Get(sessKeySessionID).(string)
+
+		effectiveRole, err := sm.validateSession(c, id, phone, Role(role))
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionExpired) || errors.Is(err, ErrRoleRevoked) {
+				sm.Logger.Infof("dropping session %s of %s: %v", id, phone, err)
+				sess.Delete(sessKeyPhone)
+				sess.Delete(sessKeyRole)
+				sess.Delete(sessKeySessionID)
+
+				if errSave := sess.Save(); errSave != nil {
+					sm.Logger.Errorf("save session error: %s", errSave.Error())
 				}
+			} else {
+				// the cookie is kept, the user is only treated as anonymous until the registry or the database recover
+				sm.Logger.Errorf("validate session %s of %s: %v", id, phone, err)
+			}
+
+			c.Next()
+			return
+		}
+
+		if effectiveRole != Role(role) {
+			sess.Set(sessKeyRole, string(effectiveRole))
+			if errSave := sess.Save(); errSave != nil {
+				sm.Logger.Errorf("save session error: %s", errSave.Error())
 			}
 		}
 
+		c.Set(sessKeyPhone, phone)
+		c.Set(sessKeyRole, string(effectiveRole))
+
 		c.Next()
 	}
 }
 
-func (sm *GinSessionManager) setSessionValue(c *gin.Context, key, value string) {
+func (sm *GinSessionManager) validateSession(c *gin.Context, id, phone string, role Role) (Role, error) {
+	// sessions created before the registry existed carry no ID and cannot be revoked, so they are not accepted
+	if id == "" {
+		return "", ErrSessionNotFound
+	}
+
+	info, err := sm.Registry.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if info.Phone != phone {
+		return "", ErrSessionNotFound
+	}
+
+	now := time.Now()
+	if now.Sub(info.CreatedAt) > sm.absoluteTimeout() || now.Sub(info.LastSeen) > sm.idleTimeout() {
+		if errRevoke := sm.Registry.Revoke(id); errRevoke != nil && !errors.Is(errRevoke, ErrSessionNotFound) {
+			sm.Logger.Errorf("revoke expired session %s: %v", id, errRevoke)
+		}
+		return "", ErrSessionExpired
+	}
+
+	roles, err := sm.Roles.RolesForPhone(phone)
+	if err != nil {
+		return "", err
+	}
+
+	effectiveRole := effectiveRole(role, roles)
+	if effectiveRole == "" {
+		if errRevoke := sm.Registry.Revoke(id); errRevoke != nil && !errors.Is(errRevoke, ErrSessionNotFound) {
+			sm.Logger.Errorf("revoke session %s: %v", id, errRevoke)
+		}
+		return "", ErrRoleRevoked
+	}
+
+	if effectiveRole != info.Role || now.Sub(info.LastSeen) > touchInterval {
+		if errTouch := sm.Registry.Touch(id, effectiveRole, now); errTouch != nil {
+			return "", errTouch
+		}
+	}
+
+	return effectiveRole, nil
+}
+
+// effectiveRole keeps the role stored in the session while the user still holds it. A staff member who was
+// dismissed but still lives in the house falls back to the resident role, a resident promoted to staff keeps
+// the resident role until the next login, so the staff second factor cannot be skipped.
+func effectiveRole(stored Role, current []Role) Role {
+	var isResident bool
+
+	for _, role := range current {
+		if role == stored {
+			return stored
+		}
+		if role == ResidentRole {
+			isResident = true
+		}
+	}
+
+	if stored == StaffRole && isResident {
+		return ResidentRole
+	}
+
+	return ""
+}
+
+func (sm *GinSessionManager) StartUserSession(c *gin.Context, phone string, role Role) error {
 	userSession := sessions.Default(c)
-	userSession.Set(key, value)
+
+	// a login on top of an existing session replaces it instead of leaving it registered
+	if oldID, ok := userSession.Get(sessKeySessionID).(string); ok && oldID != "" {
+		if err := sm.Registry.Revoke(oldID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			sm.Logger.Errorf("revoke replaced session %s: %v", oldID, err)
+		}
+	}
+
+	id, err := utils.GenerateID()
+	if err != nil {
+		return fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	now := time.Now()
+	info := &Info{
+		ID:        id,
+		Phone:     phone,
+		Role:      role,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		CreatedAt: now,
+		LastSeen:  now,
+	}
+
+	if err := sm.Registry.Register(info, sm.absoluteTimeout()); err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+
+	userSession.Set(sessKeyPhone, phone)
+	userSession.Set(sessKeyRole, string(role))
+	userSession.Set(sessKeySessionID, id)
+	// a token seen before the login must not stay valid for the authenticated session
+	userSession.Delete(sessKeyCSRF)
+	// the language of whoever used the browser before is not the language of this user
+	userSession.Delete(sessKeyLanguage)
+
+	return sm.SaveSession(c)
 }
 
-func (sm *GinSessionManager) SetUserSessionRole(c *gin.Context, role Role) {
-	sm.setSessionValue(c, sessKeyRole, string(role))
+func (sm *GinSessionManager) CurrentSessionID(c *gin.Context) string {
+	id, _ := sessions.Default(c).Get(sessKeySessionID).(string)
+	return id
 }
 
-func (sm *GinSessionManager) SetUserSessionPhone(c *gin.Context, phone string) {
-	sm.setSessionValue(c, sessKeyPhone, phone)
+func (sm *GinSessionManager) ListUserSessions(phone string) ([]*Info, error) {
+	return sm.Registry.ListByPhone(phone)
+}
+
+// RevokeUserSession only revokes a session that belongs to the given phone.
+func (sm *GinSessionManager) RevokeUserSession(phone, id string) error {
+	info, err := sm.Registry.Get(id)
+	if err != nil {
+		return err
+	}
+	if info.Phone != phone {
+		return ErrSessionNotFound
+	}
+
+	return sm.Registry.Revoke(id)
+}
+
+func (sm *GinSessionManager) RevokeAllUserSessions(phone string) (int, error) {
+	return sm.Registry.RevokeAllByPhone(phone)
+}
+
+func (sm *GinSessionManager) SetPendingTwoFactor(c *gin.Context, phone string) {
+	userSession := sessions.Default(c)
+	userSession.Set(sessKeyPendingPhone, phone)
+	userSession.Set(sessKeyPendingSince, time.Now().Unix())
+}
+
+// GetPendingTwoFactor returns the phone that passed the password check but has not yet provided its second factor.
+func (sm *GinSessionManager) GetPendingTwoFactor(c *gin.Context) (string, bool) {
+	userSession := sessions.Default(c)
+
+	phone, okPhone := userSession.Get(sessKeyPendingPhone).(string)
+	since, okSince := userSession.Get(sessKeyPendingSince).(int64)
+	if !okPhone || !okSince || phone == "" {
+		return "", false
+	}
+
+	if time.Since(time.Unix(since, 0)) > PendingTwoFactorTTL {
+		sm.Logger.Infof("pending two-factor login for %s expired", phone)
+		return "", false
+	}
+
+	return phone, true
+}
+
+func (sm *GinSessionManager) ClearPendingTwoFactor(c *gin.Context) {
+	userSession := sessions.Default(c)
+	userSession.Delete(sessKeyPendingPhone)
+	userSession.Delete(sessKeyPendingSince)
 }
 
 func (sm *GinSessionManager) SaveSession(c *gin.Context) error {
@@ -95,5 +298,12 @@ func (sm *GinSessionManager) SaveSession(c *gin.Context) error {
 
 func (sm *GinSessionManager) ClearSession(c *gin.Context) {
 	userSession := sessions.Default(c)
+
+	if id, ok := userSession.Get(sessKeySessionID).(string); ok && id != "" {
+		if err := sm.Registry.Revoke(id); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			sm.Logger.Errorf("revoke session %s: %v", id, err)
+		}
+	}
+
 	userSession.Clear()
 }
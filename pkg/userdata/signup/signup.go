@@ -0,0 +1,70 @@
+package signup
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending  Status = "на_рассмотрении"
+	StatusApproved Status = "одобрена"
+	StatusRejected Status = "отклонена"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// Code is the stable machine name of the status for clients and translations, the stored value stays Russian.
+func (s Status) Code() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusApproved:
+		return "approved"
+	case StatusRejected:
+		return "rejected"
+	default:
+		return ""
+	}
+}
+
+// Statuses lists every sign-up status, e.g. to translate them all.
+var Statuses = []Status{StatusPending, StatusApproved, StatusRejected}
+
+// Signup is an account a resident asked for on their own, it can not log in until staff approve the claimed address.
+type Signup struct {
+	ID             string     `gorm:"column:id;type:char(40);primaryKey"`
+	Phone          string     `gorm:"type:varchar(40);column:phone_number;not null;index"`
+	FullName       string     `gorm:"type:varchar(40);column:full_name;not null"`
+	PasswordHash   string     `gorm:"type:varchar;column:password_hash;not null" json:"-"`
+	ClaimedAddress string     `gorm:"type:varchar(100);column:claimed_address;not null"`
+	Apartment      string     `gorm:"type:varchar(10);column:apartment;not null;default:''"`
+	Status         Status     `gorm:"type:varchar(20);column:status;not null;index"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+	ReviewedBy     *string    `gorm:"type:varchar(40);column:reviewed_by"`
+	ReviewedAt     *time.Time `gorm:"column:reviewed_at;type:timestamp"`
+	HouseID        *int       `gorm:"column:id_house;type:bigint"`
+	ReviewComment  *string    `gorm:"type:varchar(200);column:review_comment"`
+}
+
+type NewSignup struct {
+	Phone          string
+	FullName       string
+	PasswordHash   string
+	ClaimedAddress string
+	Apartment      string
+}
+
+type SignupRepo interface {
+	Create(newSignup NewSignup) (*Signup, error)
+	GetByID(id string) (*Signup, error)
+	// GetLatestByPhone returns the most recent sign-ups of the phone number, newest first
+	GetLatestByPhone(phone string, limit int) ([]*Signup, error)
+	GetAll(pattern string, status *Status, limit, offset int) ([]*Signup, int, error)
+	// Review closes a pending sign-up, houseID is the house the claim was checked against and is kept only on approval
+	Review(id, reviewerPhone string, approve bool, houseID *int, comment *string) error
+}
@@ -0,0 +1,231 @@
+package signup
+
+import (
+	"DBPrototyping/pkg/userdata"
+	"DBPrototyping/pkg/utils"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSignupNotFound     = errors.New("sign-up not found")
+	ErrSignupPending      = errors.New("a sign-up for this phone number is already pending")
+	ErrPhoneRegistered    = errors.New("phone number is already registered")
+	ErrSignupReviewed     = errors.New("sign-up is already reviewed")
+	ErrCreatingSignup     = errors.New("error creating sign-up")
+	ErrApprovalNeedsHouse = errors.New("approval needs the house the claim was checked against")
+)
+
+type SignupPg Signup
+
+func (SignupPg) TableName() string {
+	return "resident_signups"
+}
+
+type SignupPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewSignupPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *SignupPgRepo {
+	return &SignupPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (repo *SignupPgRepo) Create(newSignup NewSignup) (*Signup, error) {
+	retryFactor := os.Getenv("RETRY_FACTOR")
+	retries, errConversion := strconv.Atoi(retryFactor)
+	if errConversion != nil || retries <= 0 {
+		retries = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int64
+	if err := repo.db.WithContext(ctx).Model(&userdata.UserPg{}).Where("phone_number = ?", newSignup.Phone).Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check login of %s: %v", newSignup.Phone, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrPhoneRegistered
+	}
+
+	if err := repo.db.WithContext(ctx).Model(&SignupPg{}).
+		Where("phone_number = ? AND status = ?", newSignup.Phone, StatusPending).
+		Count(&count).Error; err != nil {
+		repo.logger.Warnf("failed to check pending sign-ups of %s: %v", newSignup.Phone, err)
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrSignupPending
+	}
+
+	signupPg := SignupPg{
+		Phone:          newSignup.Phone,
+		FullName:       newSignup.FullName,
+		PasswordHash:   newSignup.PasswordHash,
+		ClaimedAddress: newSignup.ClaimedAddress,
+		Apartment:      newSignup.Apartment,
+		Status:         StatusPending,
+		CreatedAt:      time.Now(),
+	}
+
+	createdFlag := false
+	for i := 0; i < retries && !createdFlag; i++ {
+		signupID, err := utils.GenerateID()
+		if err != nil {
+			repo.logger.Warnf("failed to generate sign-up ID, %v", err)
+			continue
+		}
+
+		signupPg.ID = signupID
+
+		upsertRes := repo.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&signupPg)
+		if upsertRes.Error != nil || upsertRes.RowsAffected != 1 {
+			continue
+		}
+		createdFlag = true
+	}
+
+	if !createdFlag {
+		return nil, ErrCreatingSignup
+	}
+
+	signup := Signup(signupPg)
+
+	return &signup, nil
+}
+
+func (repo *SignupPgRepo) GetByID(id string) (*Signup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var signupPg SignupPg
+	if err := repo.db.WithContext(ctx).Where("id = ?", id).First(&signupPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSignupNotFound
+		}
+		repo.logger.Warnf("failed to get sign-up %s: %v", id, err)
+		return nil, err
+	}
+
+	signup := Signup(signupPg)
+
+	return &signup, nil
+}
+
+func (repo *SignupPgRepo) GetLatestByPhone(phone string, limit int) ([]*Signup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var signupsPg []SignupPg
+	if err := repo.db.WithContext(ctx).Where("phone_number = ?", phone).
+		Order("created_at DESC").Limit(limit).Find(&signupsPg).Error; err != nil {
+		repo.logger.Warnf("failed to get sign-ups of %s: %v", phone, err)
+		return nil, err
+	}
+
+	signups := make([]*Signup, len(signupsPg))
+	for i := range signupsPg {
+		signups[i] = (*Signup)(&signupsPg[i])
+	}
+
+	return signups, nil
+}
+
+func (repo *SignupPgRepo) GetAll(pattern string, status *Status, limit, offset int) ([]*Signup, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := repo.db.WithContext(ctx).Model(&SignupPg{})
+
+	if pattern != "" {
+		like := "%" + pattern + "%"
+		query = query.Where("phone_number LIKE ? OR full_name ILIKE ? OR claimed_address ILIKE ?", like, like, like)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		repo.logger.Warnf("failed to count sign-ups: %v", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*Signup{}, 0, nil
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var signupsPg []SignupPg
+	if err := query.Order("created_at").Find(&signupsPg).Error; err != nil {
+		repo.logger.Warnf("failed to query sign-ups: %v", err)
+		return nil, int(total), err
+	}
+
+	signups := make([]*Signup, len(signupsPg))
+	for i := range signupsPg {
+		signups[i] = (*Signup)(&signupsPg[i])
+	}
+
+	return signups, int(total), nil
+}
+
+func (repo *SignupPgRepo) Review(id, reviewerPhone string, approve bool, houseID *int, comment *string) error {
+	if approve && houseID == nil {
+		return ErrApprovalNeedsHouse
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := StatusRejected
+	if approve {
+		status = StatusApproved
+	} else {
+		houseID = nil
+	}
+
+	updates := map[string]interface{}{
+		"status":         status,
+		"reviewed_by":    reviewerPhone,
+		"reviewed_at":    time.Now(),
+		"id_house":       houseID,
+		"review_comment": comment,
+	}
+
+	// the status condition makes two staff members reviewing the same sign-up settle on the first decision
+	res := repo.db.WithContext(ctx).Model(&SignupPg{}).Where("id = ? AND status = ?", id, StatusPending).Updates(updates)
+	if res.Error != nil {
+		repo.logger.Warnf("failed to review sign-up %s: %v", id, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := repo.db.WithContext(ctx).Model(&SignupPg{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrSignupNotFound
+		}
+		return ErrSignupReviewed
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package throttle
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type LoginGuard struct {
+	store       AttemptStore
+	fallback    AttemptStore
+	phonePolicy Policy
+	ipPolicy    Policy
+	logger      *zap.SugaredLogger
+}
+
+// NewLoginGuard creates a limiter that keeps attempts in store and switches to fallback whenever store fails,
+// so that an unavailable Redis neither blocks every login nor disables the protection.
+func NewLoginGuard(logger *zap.SugaredLogger, store, fallback AttemptStore, phonePolicy, ipPolicy Policy) *LoginGuard {
+	return &LoginGuard{
+		store:       store,
+		fallback:    fallback,
+		phonePolicy: phonePolicy,
+		ipPolicy:    ipPolicy,
+		logger:      logger,
+	}
+}
+
+func (g *LoginGuard) withStore(op func(store AttemptStore) error) error {
+	err := op(g.store)
+	if err == nil || g.fallback == nil {
+		return err
+	}
+
+	g.logger.Warnf("login attempt store failed, using fallback: %v", err)
+	return op(g.fallback)
+}
+
+func (g *LoginGuard) policyFor(key string) Policy {
+	if strings.HasPrefix(key, ipKeyPrefix) {
+		return g.ipPolicy
+	}
+	return g.phonePolicy
+}
+
+// Check returns how long the caller has to wait before the next attempt for the given phone and ip is accepted.
+func (g *LoginGuard) Check(phone, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	var wait time.Duration
+	for _, key := range []string{PhoneKey(phone), IPKey(ip)} {
+		err := g.withStore(func(store AttemptStore) error {
+			attempts, err := store.Get(key)
+			if err != nil {
+				return err
+			}
+
+			if attempts != nil && attempts.IsBlocked(now) && attempts.LockedUntil.Sub(now) > wait {
+				wait = attempts.LockedUntil.Sub(now)
+			}
+			return nil
+		})
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return wait, nil
+}
+
+// RegisterFailure counts a failed attempt for both keys and returns the longest delay it imposes.
+func (g *LoginGuard) RegisterFailure(phone, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	var wait time.Duration
+	for _, key := range []string{PhoneKey(phone), IPKey(ip)} {
+		policy := g.policyFor(key)
+
+		err := g.withStore(func(store AttemptStore) error {
+			attempts, err := store.RegisterFailure(key, policy.ttl())
+			if err != nil {
+				return err
+			}
+
+			blockFor := policy.blockFor(attempts.Failures)
+			if blockFor <= 0 {
+				return nil
+			}
+
+			if blockFor > wait {
+				wait = blockFor
+			}
+
+			if attempts.Failures >= policy.MaxFailures {
+				g.logger.Warnf("login locked out for %s after %d failures", key, attempts.Failures)
+			}
+
+			return store.Block(key, now.Add(blockFor), policy.ttl())
+		})
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return wait, nil
+}
+
+// RegisterSuccess forgets failures for the phone, the ip counter is kept so that one valid account cannot be
+// used to reset the budget of an address that is guessing passwords for others.
+func (g *LoginGuard) RegisterSuccess(phone string) error {
+	return g.Clear(PhoneKey(phone))
+}
+
+func (g *LoginGuard) ListBlocked() ([]*Attempts, error) {
+	now := time.Now()
+
+	var attemptsList []*Attempts
+	err := g.withStore(func(store AttemptStore) error {
+		all, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		attemptsList = make([]*Attempts, 0, len(all))
+		for _, attempts := range all {
+			if attempts.IsBlocked(now) {
+				attemptsList = append(attemptsList, attempts)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return attemptsList, nil
+}
+
+func (g *LoginGuard) Clear(key string) error {
+	err := g.withStore(func(store AttemptStore) error {
+		return store.Delete(key)
+	})
+
+	// the fallback may hold a copy from the time the primary store was unavailable
+	if err == nil && g.fallback != nil {
+		if errFallback := g.fallback.Delete(key); errFallback != nil {
+			g.logger.Warnf("failed to clear fallback attempts for %s: %v", key, errFallback)
+		}
+	}
+
+	return err
+}
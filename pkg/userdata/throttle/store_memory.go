@@ -0,0 +1,115 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	attempts  Attempts
+	expiresAt time.Time
+}
+
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// getLocked must be called with mu held, expired entries are dropped on access.
+func (s *MemoryAttemptStore) getLocked(key string, now time.Time) *memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if !entry.expiresAt.After(now) {
+		delete(s.entries, key)
+		return nil
+	}
+
+	return entry
+}
+
+func (s *MemoryAttemptStore) Get(key string) (*Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.getLocked(key, time.Now())
+	if entry == nil {
+		return nil, nil
+	}
+
+	attempts := entry.attempts
+	return &attempts, nil
+}
+
+func (s *MemoryAttemptStore) RegisterFailure(key string, ttl time.Duration) (*Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry := s.getLocked(key, now)
+	if entry == nil {
+		entry = &memoryEntry{attempts: Attempts{Key: key}}
+		s.entries[key] = entry
+	}
+
+	entry.attempts.Failures++
+	entry.attempts.LastFailure = now
+	entry.expiresAt = now.Add(ttl)
+
+	attempts := entry.attempts
+	return &attempts, nil
+}
+
+func (s *MemoryAttemptStore) Block(key string, until time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry := s.getLocked(key, now)
+	if entry == nil {
+		entry = &memoryEntry{attempts: Attempts{Key: key}}
+		s.entries[key] = entry
+	}
+
+	entry.attempts.LockedUntil = until
+	if expiresAt := now.Add(ttl); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+
+	return nil
+}
+
+func (s *MemoryAttemptStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryAttemptStore) List() ([]*Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	result := make([]*Attempts, 0, len(s.entries))
+	for key := range s.entries {
+		if entry := s.getLocked(key, now); entry != nil {
+			attempts := entry.attempts
+			result = append(result, &attempts)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package throttle
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const redisKeyPrefix = "hoa_login_attempts:"
+
+type RedisAttemptStore struct {
+	pool *redis.Pool
+}
+
+func NewRedisAttemptStore(pool *redis.Pool) *RedisAttemptStore {
+	return &RedisAttemptStore{
+		pool: pool,
+	}
+}
+
+func unixToTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+func attemptsFromHash(key string, fields map[string]string) *Attempts {
+	failures, _ := strconv.Atoi(fields["failures"])
+
+	return &Attempts{
+		Key:         key,
+		Failures:    failures,
+		LastFailure: unixToTime(fields["last_failure"]),
+		LockedUntil: unixToTime(fields["locked_until"]),
+	}
+}
+
+func (s *RedisAttemptStore) Get(key string) (*Attempts, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	fields, err := redis.StringMap(conn.Do("HGETALL", redisKeyPrefix+key))
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return attemptsFromHash(key, fields), nil
+}
+
+func (s *RedisAttemptStore) RegisterFailure(key string, ttl time.Duration) (*Attempts, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	redisKey := redisKeyPrefix + key
+	now := time.Now()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return nil, err
+	}
+	_ = conn.Send("HINCRBY", redisKey, "failures", 1)
+	_ = conn.Send("HSET", redisKey, "last_failure", now.Unix())
+	_ = conn.Send("EXPIRE", redisKey, int(ttl.Seconds()))
+	_ = conn.Send("HGETALL", redisKey)
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := redis.StringMap(replies[len(replies)-1], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return attemptsFromHash(key, fields), nil
+}
+
+func (s *RedisAttemptStore) Block(key string, until time.Time, ttl time.Duration) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	redisKey := redisKeyPrefix + key
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	_ = conn.Send("HSET", redisKey, "locked_until", until.Unix())
+	_ = conn.Send("EXPIRE", redisKey, int(ttl.Seconds()))
+
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (s *RedisAttemptStore) Delete(key string) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", redisKeyPrefix+key)
+	return err
+}
+
+func (s *RedisAttemptStore) List() ([]*Attempts, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	var result []*Attempts
+
+	cursor := 0
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", 100))
+		if err != nil {
+			return nil, err
+		}
+
+		var keys []string
+		if _, err := redis.Scan(reply, &cursor, &keys); err != nil {
+			return nil, err
+		}
+
+		for _, redisKey := range keys {
+			fields, err := redis.StringMap(conn.Do("HGETALL", redisKey))
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			result = append(result, attemptsFromHash(strings.TrimPrefix(redisKey, redisKeyPrefix), fields))
+		}
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package throttle
+
+import (
+	"time"
+)
+
+const (
+	phoneKeyPrefix = "phone:"
+	ipKeyPrefix    = "ip:"
+)
+
+type Attempts struct {
+	Key         string    `json:"key"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+func (a *Attempts) IsBlocked(now time.Time) bool {
+	return a.LockedUntil.After(now)
+}
+
+// Policy describes how failed logins are punished for one kind of key.
+// The first FreeAttempts failures cost nothing, every next one doubles the delay starting from BaseDelay
+// up to MaxDelay, and reaching MaxFailures locks the key for Lockout. Failures are forgotten after Window
+// of inactivity.
+type Policy struct {
+	FreeAttempts int
+	MaxFailures  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	Lockout      time.Duration
+	Window       time.Duration
+}
+
+func (p Policy) blockFor(failures int) time.Duration {
+	if failures >= p.MaxFailures {
+		return p.Lockout
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay << (failures - p.FreeAttempts - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+func (p Policy) ttl() time.Duration {
+	if p.Lockout > p.Window {
+		return p.Lockout
+	}
+	return p.Window
+}
+
+var (
+	DefaultPhonePolicy = Policy{
+		FreeAttempts: 3,
+		MaxFailures:  10,
+		BaseDelay:    2 * time.Second,
+		MaxDelay:     time.Minute,
+		Lockout:      15 * time.Minute,
+		Window:       15 * time.Minute,
+	}
+
+	// DefaultIPPolicy is looser than the phone one since a whole building may share a single address.
+	DefaultIPPolicy = Policy{
+		FreeAttempts: 10,
+		MaxFailures:  50,
+		BaseDelay:    time.Second,
+		MaxDelay:     30 * time.Second,
+		Lockout:      30 * time.Minute,
+		Window:       30 * time.Minute,
+	}
+)
+
+func PhoneKey(phone string) string {
+	return phoneKeyPrefix + phone
+}
+
+func IPKey(ip string) string {
+	return ipKeyPrefix + ip
+}
+
+type AttemptStore interface {
+	Get(key string) (*Attempts, error)
+	RegisterFailure(key string, ttl time.Duration) (*Attempts, error)
+	Block(key string, until time.Time, ttl time.Duration) error
+	Delete(key string) error
+	List() ([]*Attempts, error)
+}
+
+type LoginLimiter interface {
+	Check(phone, ip string) (time.Duration, error)
+	RegisterFailure(phone, ip string) (time.Duration, error)
+	RegisterSuccess(phone string) error
+	ListBlocked() ([]*Attempts, error)
+	Clear(key string) error
+}
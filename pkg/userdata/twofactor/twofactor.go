@@ -0,0 +1,33 @@
+package twofactor
+
+import "time"
+
+type TwoFactor struct {
+	Phone        string     `gorm:"type:varchar(40);column:phone_number;primaryKey"`
+	Secret       string     `gorm:"type:varchar(64);column:secret;not null"`
+	Enabled      bool       `gorm:"column:is_enabled;not null;default:false"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt    time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at;type:timestamp"`
+}
+
+type RecoveryCode struct {
+	ID       int        `gorm:"type:bigint;primaryKey;autoIncrement"`
+	Phone    string     `gorm:"type:varchar(40);column:phone_number;not null;index"`
+	CodeHash string     `gorm:"type:char(64);column:code_hash;not null"`
+	UsedAt   *time.Time `gorm:"column:used_at;type:timestamp"`
+}
+
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorRepo interface {
+	IsEnabled(phone string) (bool, error)
+	BeginEnrollment(phone string) (*Enrollment, error)
+	ConfirmEnrollment(phone, code string) ([]string, error)
+	Verify(phone, code string) error
+	RegenerateRecoveryCodes(phone string) ([]string, error)
+	Disable(phone string) error
+}
@@ -0,0 +1,288 @@
+package twofactor
+
+import (
+	"DBPrototyping/pkg/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const recoveryCodesCount = 10
+
+var (
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	ErrAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrNoPendingEnrollment = errors.New("no pending two-factor enrollment")
+	ErrInvalidCode         = errors.New("invalid two-factor code")
+)
+
+type TwoFactorPg TwoFactor
+
+func (TwoFactorPg) TableName() string {
+	return "two_factor_secrets"
+}
+
+type RecoveryCodePg RecoveryCode
+
+func (RecoveryCodePg) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+type TwoFactorPgRepo struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTwoFactorPgRepo(logger *zap.SugaredLogger, db *gorm.DB) *TwoFactorPgRepo {
+	return &TwoFactorPgRepo{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 5)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	code := hex.EncodeToString(bytes)
+	return code[:5] + "-" + code[5:], nil
+}
+
+func (repo *TwoFactorPgRepo) IsEnabled(phone string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var twoFactorPg TwoFactorPg
+	if err := repo.db.WithContext(ctx).Where("phone_number = ?", phone).First(&twoFactorPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		repo.logger.Errorf("failed to find two-factor settings for %s: %v", phone, err)
+		return false, err
+	}
+
+	return twoFactorPg.Enabled, nil
+}
+
+func (repo *TwoFactorPgRepo) BeginEnrollment(phone string) (*Enrollment, error) {
+	enabled, err := repo.IsEnabled(phone)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		repo.logger.Warnf("failed to generate two-factor secret, %v", err)
+		return nil, err
+	}
+
+	twoFactorPg := TwoFactorPg{
+		Phone:     phone,
+		Secret:    secret,
+		Enabled:   false,
+		CreatedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// a previous unfinished enrollment is simply replaced by the new secret
+	if err := repo.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "phone_number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "created_at", "last_used_step"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: TwoFactorPg{}.TableName() + ".is_enabled = false"}}},
+		}).
+		Create(&twoFactorPg).Error; err != nil {
+		repo.logger.Errorf("failed to store two-factor secret for %s: %v", phone, err)
+		return nil, err
+	}
+
+	return &Enrollment{
+		Secret: secret,
+		URI:    ProvisioningURI(secret, phone),
+	}, nil
+}
+
+func (repo *TwoFactorPgRepo) ConfirmEnrollment(phone, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var codes []string
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var twoFactorPg TwoFactorPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("phone_number = ?", phone).
+			First(&twoFactorPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoPendingEnrollment
+			}
+			return err
+		}
+
+		if twoFactorPg.Enabled {
+			return ErrAlreadyEnabled
+		}
+
+		step, ok := ValidateCode(twoFactorPg.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		now := time.Now()
+		if err := tx.Model(&TwoFactorPg{}).Where("phone_number = ?", phone).Updates(map[string]interface{}{
+			"is_enabled":     true,
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = repo.replaceRecoveryCodesTx(tx, phone)
+		return err
+	})
+
+	if err != nil {
+		repo.logger.Infof("two-factor enrollment confirmation failed for %s: %v", phone, err)
+		return nil, err
+	}
+
+	repo.logger.Infof("two-factor authentication enabled for %s", phone)
+	return codes, nil
+}
+
+func (repo *TwoFactorPgRepo) replaceRecoveryCodesTx(tx *gorm.DB, phone string) ([]string, error) {
+	if err := tx.Where("phone_number = ?", phone).Delete(&RecoveryCodePg{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodesCount)
+	codesPg := make([]RecoveryCodePg, recoveryCodesCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes[i] = code
+		codesPg[i] = RecoveryCodePg{
+			Phone:    phone,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := tx.Create(&codesPg).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify accepts either a current TOTP code or one of the unused recovery codes.
+func (repo *TwoFactorPgRepo) Verify(phone, code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var twoFactorPg TwoFactorPg
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("phone_number = ? AND is_enabled = ?", phone, true).
+			First(&twoFactorPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			return err
+		}
+
+		if step, ok := ValidateCode(twoFactorPg.Secret, code, time.Now()); ok {
+			if step <= twoFactorPg.LastUsedStep {
+				repo.logger.Warnf("two-factor code replay attempt for %s", phone)
+				return ErrInvalidCode
+			}
+
+			return tx.Model(&TwoFactorPg{}).Where("phone_number = ?", phone).Update("last_used_step", step).Error
+		}
+
+		useRes := tx.Model(&RecoveryCodePg{}).
+			Where("phone_number = ? AND code_hash = ? AND used_at IS NULL", phone, utils.HashToken(normalizeRecoveryCode(code))).
+			Update("used_at", time.Now())
+		if useRes.Error != nil {
+			return useRes.Error
+		}
+		if useRes.RowsAffected == 0 {
+			return ErrInvalidCode
+		}
+
+		repo.logger.Infof("recovery code used by %s", phone)
+		return nil
+	})
+}
+
+func (repo *TwoFactorPgRepo) RegenerateRecoveryCodes(phone string) ([]string, error) {
+	enabled, err := repo.IsEnabled(phone)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var codes []string
+	err = repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var errReplace error
+		codes, errReplace = repo.replaceRecoveryCodesTx(tx, phone)
+		return errReplace
+	})
+
+	if err != nil {
+		repo.logger.Errorf("failed to regenerate recovery codes for %s: %v", phone, err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (repo *TwoFactorPgRepo) Disable(phone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("phone_number = ?", phone).Delete(&RecoveryCodePg{}).Error; err != nil {
+			repo.logger.Errorf("failed to delete recovery codes for %s: %v", phone, err)
+			return err
+		}
+
+		deleteRes := tx.Where("phone_number = ?", phone).Delete(&TwoFactorPg{})
+		if deleteRes.Error != nil {
+			repo.logger.Errorf("failed to delete two-factor settings for %s: %v", phone, deleteRes.Error)
+			return deleteRes.Error
+		}
+		if deleteRes.RowsAffected != 1 {
+			return ErrTwoFactorNotEnabled
+		}
+
+		repo.logger.Infof("two-factor authentication disabled for %s", phone)
+		return nil
+	})
+}
@@ -1,13 +1,38 @@
 package userdata
 
+import "time"
+
 type User struct {
 	Phone        string `gorm:"type:varchar(40);column:phone_number;primaryKey"`
 	PasswordHash string `gorm:"type:varchar;column:password_hash;type:varchar;not null"`
+	// Language is the preferred interface language, empty means the browser's one
+	Language string `gorm:"type:varchar(5);column:language;not null;default:''"`
+}
+
+type PasswordResetToken struct {
+	TokenHash string     `gorm:"type:char(64);column:token_hash;primaryKey"`
+	Phone     string     `gorm:"type:varchar(40);column:phone_number;not null;index"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;not null;default:now()"`
 }
 
 type UserRepo interface {
 	Authorize(phone, password string) (*User, error)
 	Register(phone, password string) (*User, error)
+	// RegisterHashed creates a login from a password that was hashed earlier, e.g. when a sign-up is approved
+	RegisterHashed(phone, passwordHash string) (*User, error)
 	DeleteByPhone(phone string) error
 	GetAll(phoneNumber string, limit, offset int) ([]*User, int, error)
+	ChangePassword(phone, oldPassword, newPassword string) error
+	SetPassword(phone, newPassword string) error
+	CreateResetToken(phone string, ttl time.Duration) (string, *PasswordResetToken, error)
+	ResetPasswordByToken(token, newPassword string) (*User, error)
+	GetLanguage(phone string) (string, error)
+	SetLanguage(phone, language string) error
+}
+
+// ResetTokenSender delivers a one-time password reset token to the owner of the phone number.
+type ResetTokenSender interface {
+	SendResetToken(phone, token string, expiresAt time.Time) error
 }
@@ -16,8 +16,12 @@ var (
 	ErrUserExists    = errors.New("user already exists")
 	ErrWrongPassword = errors.New("wrong password")
 	ErrCreatingUser  = errors.New("error creating a new user")
+	ErrInvalidToken  = errors.New("reset token is invalid, expired or already used")
 )
 
+// dummyPasswordHash is a bcrypt hash with the default cost of a password nobody has
+const dummyPasswordHash = "$2a$10$ULpFbWoZbRxXoyzYdqtv/eXyo1clMS86GQHf6MImuyFPp64.xyNeO"
+
 type UserRepoPg struct {
 	db     *gorm.DB
 	logger *zap.SugaredLogger
@@ -29,6 +33,12 @@ func (UserPg) TableName() string {
 	return "login_credentials"
 }
 
+type PasswordResetTokenPg PasswordResetToken
+
+func (PasswordResetTokenPg) TableName() string {
+	return "password_reset_tokens"
+}
+
 func NewUserRepoPg(db *gorm.DB, logger *zap.SugaredLogger) *UserRepoPg {
 	return &UserRepoPg{
 		db:     db,
@@ -44,6 +54,9 @@ func (repo *UserRepoPg) Authorize(login, password string) (*User, error) {
 
 	if err := repo.db.WithContext(ctx).Where("phone_number = ?", login).First(&userPg).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// spend the same time as a real check would, otherwise response timing tells which phones exist
+			utils.CheckPassword(dummyPasswordHash, password)
+
 			repo.logger.Debugf("User %s not found", login)
 			return nil, ErrUserNotFound
 		}
@@ -71,6 +84,10 @@ func (repo *UserRepoPg) Register(phone, password string) (*User, error) {
 		return nil, errHashing
 	}
 
+	return repo.RegisterHashed(phone, passwordHash)
+}
+
+func (repo *UserRepoPg) RegisterHashed(phone, passwordHash string) (*User, error) {
 	userPg := UserPg{
 		Phone:        phone,
 		PasswordHash: passwordHash,
@@ -152,3 +169,159 @@ func (repo *UserRepoPg) GetAll(phoneNumber string, limit, offset int) ([]*User,
 
 	return users, int(total), nil
 }
+
+func (repo *UserRepoPg) ChangePassword(phone, oldPassword, newPassword string) error {
+	if _, err := repo.Authorize(phone, oldPassword); err != nil {
+		return err
+	}
+
+	return repo.SetPassword(phone, newPassword)
+}
+
+func (repo *UserRepoPg) SetPassword(phone, newPassword string) error {
+	passwordHash, errHashing := utils.HashPassword(newPassword)
+	if errHashing != nil {
+		repo.logger.Debugf("Error hashing password: %v", errHashing)
+		return errHashing
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return repo.setPasswordTx(tx, phone, passwordHash)
+	})
+}
+
+func (repo *UserRepoPg) setPasswordTx(tx *gorm.DB, phone, passwordHash string) error {
+	updateRes := tx.Model(&UserPg{}).Where("phone_number = ?", phone).Update("password_hash", passwordHash)
+	if updateRes.Error != nil {
+		repo.logger.Warnf("failed to update password for %s, err %v", phone, updateRes.Error)
+		return updateRes.Error
+	}
+	if updateRes.RowsAffected != 1 {
+		repo.logger.Warnf("failed to update password, user %s does not exist", phone)
+		return ErrUserNotFound
+	}
+
+	// any reset token issued before the password changed must not be usable afterwards
+	if err := tx.Model(&PasswordResetTokenPg{}).
+		Where("phone_number = ? AND used_at IS NULL", phone).
+		Update("used_at", time.Now()).Error; err != nil {
+		repo.logger.Warnf("failed to invalidate reset tokens for %s, err %v", phone, err)
+		return err
+	}
+
+	return nil
+}
+
+func (repo *UserRepoPg) CreateResetToken(phone string, ttl time.Duration) (string, *PasswordResetToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var userPg UserPg
+	if err := repo.db.WithContext(ctx).Where("phone_number = ?", phone).First(&userPg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			repo.logger.Debugf("User %s not found", phone)
+			return "", nil, ErrUserNotFound
+		}
+
+		repo.logger.Warnf("failed to find user %s, err %v", phone, err)
+		return "", nil, err
+	}
+
+	token, err := utils.GenerateID()
+	if err != nil {
+		repo.logger.Warnf("failed to generate reset token, %v", err)
+		return "", nil, err
+	}
+
+	now := time.Now()
+	tokenPg := PasswordResetTokenPg{
+		TokenHash: utils.HashToken(token),
+		Phone:     userPg.Phone,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := repo.db.WithContext(ctx).Create(&tokenPg).Error; err != nil {
+		repo.logger.Warnf("failed to insert reset token for %s, err %v", phone, err)
+		return "", nil, err
+	}
+
+	resetToken := PasswordResetToken(tokenPg)
+	return token, &resetToken, nil
+}
+
+func (repo *UserRepoPg) ResetPasswordByToken(token, newPassword string) (*User, error) {
+	passwordHash, errHashing := utils.HashPassword(newPassword)
+	if errHashing != nil {
+		repo.logger.Debugf("Error hashing password: %v", errHashing)
+		return nil, errHashing
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user *User
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tokenPg PasswordResetTokenPg
+
+		// the row lock guarantees that two concurrent resets cannot both consume the same token
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", utils.HashToken(token), time.Now()).
+			First(&tokenPg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				repo.logger.Debugf("reset token not found or no longer valid")
+				return ErrInvalidToken
+			}
+
+			return err
+		}
+
+		if err := repo.setPasswordTx(tx, tokenPg.Phone, passwordHash); err != nil {
+			return err
+		}
+
+		user = &User{Phone: tokenPg.Phone, PasswordHash: passwordHash}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (repo *UserRepoPg) GetLanguage(phone string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var language string
+	err := repo.db.WithContext(ctx).Model(&UserPg{}).Where("phone_number = ?", phone).
+		Limit(1).Pluck("language", &language).Error
+	if err != nil {
+		repo.logger.Warnf("failed to get language of %s, err %v", phone, err)
+		return "", err
+	}
+
+	return language, nil
+}
+
+func (repo *UserRepoPg) SetLanguage(phone, language string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateRes := repo.db.WithContext(ctx).Model(&UserPg{}).Where("phone_number = ?", phone).Update("language", language)
+	if updateRes.Error != nil {
+		repo.logger.Warnf("failed to update language for %s, err %v", phone, updateRes.Error)
+		return updateRes.Error
+	}
+	if updateRes.RowsAffected != 1 {
+		repo.logger.Warnf("failed to update language, user %s does not exist", phone)
+		return ErrUserNotFound
+	}
+
+	return nil
+}
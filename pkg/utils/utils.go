@@ -2,15 +2,22 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"math"
+	"os"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
 )
 
+// HashPassword and CheckPassword work on the NFC form, so a password typed with composed or decomposed
+// characters is the same password.
 func HashPassword(password string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashed, err := bcrypt.GenerateFromPassword([]byte(norm.NFC.String(password)), bcrypt.DefaultCost)
 	if err != nil {
 		return "", err
 	}
@@ -18,7 +25,7 @@ func HashPassword(password string) (string, error) {
 }
 
 func CheckPassword(hashedPassword, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(norm.NFC.String(password)))
 	return err == nil
 }
 
@@ -31,24 +38,28 @@ func GenerateID() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func IsNumbers(s string) bool {
-	for i := range len(s) {
-		if !(s[i] >= '0' && s[i] <= '9') {
-			return false
-		}
+var ErrNotFinite = errors.New("number is not finite")
+
+// ParseFinite parses a float form value, NaN and infinities are refused: they pass every comparison check and
+// then break the JSON encoding of whatever they are stored in.
+func ParseFinite(value string) (float64, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, ErrNotFinite
 	}
-	return true
+	return number, nil
 }
 
-func IsNumbersAndLetters(s string) bool {
-	for i := range len(s) {
-		if !((s[i] >= 'a' && s[i] <= 'z') ||
-			(s[i] >= 'A' && s[i] <= 'Z') ||
-			(s[i] >= '0' && s[i] <= '9')) {
-			return false
-		}
+// GetRetries is how many times a repo retries an insert that hit a generated ID collision, RETRY_FACTOR or 1.
+func GetRetries() int {
+	retries, errConversion := strconv.Atoi(os.Getenv("RETRY_FACTOR"))
+	if errConversion != nil || retries <= 0 {
+		return 1
 	}
-	return true
+	return retries
 }
 
 func GetPageAndLimitFromContext(c *gin.Context) (int, int) {
@@ -79,3 +90,8 @@ func CountPages(total, limit int) int {
 	}
 	return pages
 }
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
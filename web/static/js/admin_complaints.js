@@ -0,0 +1,170 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 20;
+    let lastPages = 1;
+
+    const list = document.getElementById("complaints-list");
+    const out = document.getElementById("complaints-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const filterHouse = document.getElementById("filter-house");
+    const filterKind = document.getElementById("filter-kind");
+    const filterStatus = document.getElementById("filter-status");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const when = (value) => new Date(value).toLocaleString();
+
+    const move = async (cm, status, fields, done) => {
+        const formData = new FormData();
+        formData.append('id', cm.ID);
+        formData.append('status', status);
+        Object.keys(fields).forEach(k => formData.append(k, fields[k]));
+        try {
+            const res = await fetch('/api/staff/complaints/move', { method: 'POST', body: formData, credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(out, done, false);
+            load();
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    const button = (label, onClick) => {
+        const btn = document.createElement('button');
+        btn.className = 'btn';
+        btn.textContent = label;
+        btn.addEventListener('click', onClick);
+        return btn;
+    };
+
+    const render = (data) => {
+        list.innerHTML = '';
+        const complaints = data.complaints || [];
+        const meta = data.meta || {};
+        lastPages = meta.pages || 1;
+        totalCountEl.textContent = String(meta.total || 0);
+        currentPageEl.textContent = String(page);
+        totalPagesEl.textContent = String(lastPages);
+        prevBtn.disabled = page <= 1;
+        nextBtn.disabled = page >= lastPages;
+
+        if (!complaints.length) {
+            showMessage(out, label('script', 'no_complaints'), false);
+            return;
+        }
+
+        complaints.forEach(cm => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const head = document.createElement('div');
+            head.style.fontWeight = '700';
+            head.textContent = label('complaint_kind', cm.Kind) + ' • ' + label('complaint_status', cm.Status) + ' • ' + cm.Address + (cm.Apartment ? label('script', 'apartment_short') + cm.Apartment : '');
+            card.appendChild(head);
+
+            const text = document.createElement('div');
+            text.textContent = cm.Text;
+            card.appendChild(text);
+
+            const parties = document.createElement('div');
+            parties.style.fontSize = '12px';
+            parties.style.color = 'var(--muted)';
+            parties.textContent = label('script', 'reporter_prefix') + cm.ReporterName + ' (' + cm.ReporterID + ')' +
+                (cm.AccusedResidentID ? label('script', 'about_sep') + (cm.AccusedName || '') + ' (' + cm.AccusedResidentID + ')' : '') +
+                label('script', 'sent_sep') + when(cm.CreatedAt) + (cm.HandledBy ? label('script', 'handled_by_sep') + cm.HandledBy : '');
+            card.appendChild(parties);
+
+            if (cm.WarningText) {
+                const warning = document.createElement('div');
+                warning.style.marginTop = '4px';
+                warning.textContent = label('script', 'warning_at').replace('%s', when(cm.WarningAt)) + cm.WarningText;
+                card.appendChild(warning);
+            }
+            if (cm.Resolution) {
+                const resolution = document.createElement('div');
+                resolution.style.marginTop = '4px';
+                resolution.textContent = label('script', 'resolution') + cm.Resolution;
+                card.appendChild(resolution);
+            }
+
+            const actions = document.createElement('div');
+            actions.style.display = 'flex';
+            actions.style.gap = '8px';
+            actions.style.marginTop = '6px';
+
+            if (cm.Status === 'submitted') {
+                actions.appendChild(button(label('script', 'complaint_reviewed'), () => {
+                    const accused = prompt(label('script', 'accused_prompt'), cm.AccusedResidentID || '');
+                    if (accused === null) return;
+                    move(cm, 'reviewed', { accusedResidentID: accused.trim() }, label('script', 'complaint_reviewed'));
+                }));
+            }
+            if (cm.Status === 'reviewed') {
+                actions.appendChild(button(label('script', 'issue_warning'), () => {
+                    const accused = prompt(label('script', 'warning_resident_prompt'), cm.AccusedResidentID || '');
+                    if (!accused) return;
+                    const note = prompt(label('script', 'warning_text_prompt'));
+                    if (!note) return;
+                    move(cm, 'warning_issued', { accusedResidentID: accused.trim(), note: note.trim() }, label('script', 'warning_issued'));
+                }));
+            }
+            if (cm.Status === 'reviewed' || cm.Status === 'warning_issued') {
+                actions.appendChild(button(label('script', 'resolve'), () => {
+                    const note = prompt(label('script', 'resolution_prompt'));
+                    if (note === null) return;
+                    move(cm, 'resolved', { note: note.trim() }, label('script', 'complaint_resolved'));
+                }));
+            }
+
+            if (actions.childElementCount) card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const url = new URL('/api/staff/complaints', window.location.origin);
+        url.searchParams.set('page', String(page));
+        url.searchParams.set('limit', String(limit));
+        if (filterHouse.value) url.searchParams.set('houseID', filterHouse.value);
+        if (filterKind.value) url.searchParams.set('kind', filterKind.value);
+        if (filterStatus.value) url.searchParams.set('status', filterStatus.value);
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            render(data);
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    refreshBtn.addEventListener('click', () => { page = 1; load(); });
+    prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+});
@@ -52,7 +52,7 @@ document.addEventListener("DOMContentLoaded", () => {
     if (addHouseForm) {
         addHouseForm.addEventListener("submit", async (e) => {
             e.preventDefault();
-            if (addHouseOutput) { addHouseOutput.textContent = "Saving..."; addHouseOutput.className = "form-output"; }
+            if (addHouseOutput) { addHouseOutput.textContent = label("script", "saving"); addHouseOutput.className = "form-output"; }
 
             const formData = new FormData(addHouseForm);
             try {
@@ -70,12 +70,12 @@ document.addEventListener("DOMContentLoaded", () => {
                     return;
                 }
 
-                if (addHouseOutput) { addHouseOutput.textContent = 'Created'; addHouseOutput.className = 'form-output success'; }
+                if (addHouseOutput) { addHouseOutput.textContent = label('script', 'created'); addHouseOutput.className = 'form-output success'; }
                 if (addHouseForm) addHouseForm.reset()
                 toggleAddModal(false);
                 if (typeof load === 'function') load();
             } catch {
-                if (addHouseOutput) { addHouseOutput.textContent = 'Network error'; addHouseOutput.className = 'form-output error'; }
+                if (addHouseOutput) { addHouseOutput.textContent = label('script', 'network_error'); addHouseOutput.className = 'form-output error'; }
             }
         });
     }
@@ -87,7 +87,7 @@ document.addEventListener("DOMContentLoaded", () => {
     if (updateHouseForm) {
         updateHouseForm.addEventListener("submit", async (e) => {
             e.preventDefault();
-            if (updateHouseOutput) { updateHouseOutput.textContent = "Saving..."; updateHouseOutput.className = "form-output"; }
+            if (updateHouseOutput) { updateHouseOutput.textContent = label("script", "saving"); updateHouseOutput.className = "form-output"; }
 
             const formData = new FormData(updateHouseForm);
             try {
@@ -105,11 +105,11 @@ document.addEventListener("DOMContentLoaded", () => {
                     return;
                 }
 
-                if (updateHouseOutput) { updateHouseOutput.textContent = 'Updated'; updateHouseOutput.className = 'form-output success'; }
+                if (updateHouseOutput) { updateHouseOutput.textContent = label('script', 'updated'); updateHouseOutput.className = 'form-output success'; }
                 toggleUpdateModal(false);
                 if (typeof load === 'function') load();
             } catch {
-                if (updateHouseOutput) { updateHouseOutput.textContent = 'Network error'; updateHouseOutput.className = 'form-output error'; }
+                if (updateHouseOutput) { updateHouseOutput.textContent = label('script', 'network_error'); updateHouseOutput.className = 'form-output error'; }
             }
         });
     }
@@ -177,7 +177,7 @@ document.addEventListener("DOMContentLoaded", () => {
         updateControls();
 
         if (!houses.length) {
-            if (out) out.textContent = "No houses found";
+            if (out) out.textContent = label("script", "no_houses");
             return;
         }
 
@@ -209,20 +209,20 @@ document.addEventListener("DOMContentLoaded", () => {
             copyBtn.style.padding = "2px 8px";
             copyBtn.style.fontSize = "12px";
             copyBtn.type = "button";
-            copyBtn.title = "Copy ID";
-            copyBtn.textContent = "Copy";
+            copyBtn.title = label("script", "copy_id");
+            copyBtn.textContent = label("script", "copy");
 
             copyBtn.addEventListener("click", async () => {
                 const prevText = copyBtn.textContent;
                 copyBtn.disabled = true;
-                copyBtn.textContent = "Copying...";
+                copyBtn.textContent = label("script", "copying");
                 try {
                     await copyToClipboard(idText);
-                    copyBtn.textContent = "Copied";
-                    if (out) { out.textContent = "ID copied to clipboard"; out.className = "form-output success"; }
+                    copyBtn.textContent = label("script", "copied");
+                    if (out) { out.textContent = label("script", "id_copied"); out.className = "form-output success"; }
                 } catch {
-                    copyBtn.textContent = "Failed";
-                    if (out) { out.textContent = "Copy failed"; out.className = "form-output error"; }
+                    copyBtn.textContent = label("script", "failed");
+                    if (out) { out.textContent = label("script", "copy_failed"); out.className = "form-output error"; }
                 } finally {
                     setTimeout(() => {
                         copyBtn.disabled = false;
@@ -237,7 +237,7 @@ document.addEventListener("DOMContentLoaded", () => {
             const addrRow = document.createElement("div");
             const addrLabel = document.createElement("span");
             addrLabel.style.color = "var(--muted)";
-            addrLabel.textContent = "Address: ";
+            addrLabel.textContent = label("script", "address_prefix");
             const addrValue = document.createElement("span");
             addrValue.textContent = addressText;
 
@@ -254,7 +254,7 @@ document.addEventListener("DOMContentLoaded", () => {
             const editBtn = document.createElement("button");
             editBtn.className = "btn";
             editBtn.type = "button";
-            editBtn.textContent = "Edit address";
+            editBtn.textContent = label("script", "edit_address");
             editBtn.addEventListener("click", () => {
                 if (updateHouseId) updateHouseId.value = idText;
                 if (updateHouseAddress) updateHouseAddress.value = addressText;
@@ -272,7 +272,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     const load = () => {
         clear();
-        if (out) { out.textContent = "Loading..."; out.className = "form-output"; }
+        if (out) { out.textContent = label("script", "loading"); out.className = "form-output"; }
         fetch(buildUrl(), { credentials: 'same-origin' })
             .then(async res => {
                 const text = await res.text();
@@ -311,5 +311,100 @@ document.addEventListener("DOMContentLoaded", () => {
         if (!isNaN(v) && v > 0) { limit = v; page = 1; load(); }
     });
 
+    const linkList = document.getElementById("link-requests-list");
+    const linkOut = document.getElementById("link-requests-output");
+    const linkStatus = document.getElementById("link-status");
+    const linkRefresh = document.getElementById("link-refresh");
+
+    const parseJSON = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const reviewLink = async (lr, approve) => {
+        const comment = prompt(label('script', approve ? 'comment_optional_prompt' : 'rejection_reason_prompt'), '');
+        if (comment === null) return;
+
+        const formData = new FormData();
+        formData.append('id', lr.ID);
+        formData.append('decision', approve ? 'approve' : 'reject');
+        formData.append('comment', comment);
+
+        try {
+            const res = await fetch('/api/staff/houses/link-requests/review', {
+                method: 'POST',
+                credentials: 'same-origin',
+                body: formData
+            });
+            const data = await parseJSON(res);
+            if (!res.ok) {
+                if (linkOut) { linkOut.textContent = data.error || (label('script', 'error') + ' ' + res.status); linkOut.className = 'form-output error'; }
+                return;
+            }
+            loadLinkRequests();
+        } catch (err) {
+            if (linkOut) { linkOut.textContent = label('script', 'network_error'); linkOut.className = 'form-output error'; }
+        }
+    };
+
+    const loadLinkRequests = async () => {
+        const params = new URLSearchParams({ page: 1, limit: 50, status: linkStatus ? linkStatus.value : '' });
+        try {
+            const res = await fetch('/api/staff/houses/link-requests?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parseJSON(res);
+            if (!res.ok) {
+                if (linkOut) { linkOut.textContent = data.error || (label('script', 'error') + ' ' + res.status); linkOut.className = 'form-output error'; }
+                return;
+            }
+            if (linkOut) { linkOut.textContent = ''; linkOut.className = 'form-output'; }
+            if (!linkList) return;
+
+            linkList.innerHTML = '';
+            const reqs = data.linkRequests || [];
+            if (!reqs.length) {
+                const empty = document.createElement('div');
+                empty.style.color = 'var(--muted)';
+                empty.textContent = label('script', 'no_link_requests');
+                linkList.appendChild(empty);
+            }
+
+            reqs.forEach(lr => {
+                const line = document.createElement('div');
+                line.className = 'card';
+                line.style.margin = '6px 0';
+
+                let text = new Date(lr.CreatedAt).toLocaleString() + ' • ' + lr.FullName + ' (' + lr.Phone + ') → ' +
+                    lr.Address + ' (' + lr.HouseID + ') • ' + label('link_status', lr.Status);
+                if (lr.Comment) text += ' • ' + lr.Comment;
+                if (lr.ReviewComment) text += label('script', 'review_sep') + lr.ReviewComment;
+                line.appendChild(document.createTextNode(text + ' '));
+
+                if (lr.Status === 'на_рассмотрении') {
+                    const approveBtn = document.createElement('button');
+                    approveBtn.className = 'btn';
+                    approveBtn.textContent = label('script', 'approve');
+                    approveBtn.addEventListener('click', () => reviewLink(lr, true));
+
+                    const rejectBtn = document.createElement('button');
+                    rejectBtn.className = 'btn';
+                    rejectBtn.style.marginLeft = '6px';
+                    rejectBtn.textContent = label('script', 'reject');
+                    rejectBtn.addEventListener('click', () => reviewLink(lr, false));
+
+                    line.appendChild(approveBtn);
+                    line.appendChild(rejectBtn);
+                }
+
+                linkList.appendChild(line);
+            });
+        } catch (err) {
+            if (linkOut) { linkOut.textContent = label('script', 'network_error'); linkOut.className = 'form-output error'; }
+        }
+    };
+
+    if (linkRefresh) linkRefresh.addEventListener('click', () => loadLinkRequests());
+    if (linkStatus) linkStatus.addEventListener('change', () => loadLinkRequests());
+
     load();
+    loadLinkRequests();
 });
\ No newline at end of file
@@ -32,6 +32,19 @@ document.addEventListener("DOMContentLoaded", () => {
     const updateOrgCancel = document.getElementById("update-org-cancel");
     const updateOrgOutput = document.getElementById("update-org-output");
 
+    const detailsModal = document.getElementById("details-org-modal");
+    const detailsName = document.getElementById("details-org-name");
+    const detailsClose = document.getElementById("details-org-close");
+    const contactsForm = document.getElementById("contacts-form");
+    const contactsOutput = document.getElementById("contacts-output");
+    const categoriesForm = document.getElementById("categories-form");
+    const categoriesOutput = document.getElementById("categories-output");
+    const contractsList = document.getElementById("contracts-list");
+    const contractForm = document.getElementById("contract-form");
+    const contractOutput = document.getElementById("contract-output");
+    const representativesList = document.getElementById("representatives-list");
+    let detailsOrgId = "";
+
     const toggleAddModal = (show) => {
         if (!addOrgModal) return;
         if (show) addOrgModal.classList.remove("hidden");
@@ -52,7 +65,7 @@ document.addEventListener("DOMContentLoaded", () => {
     if (addOrgForm) {
         addOrgForm.addEventListener("submit", async (e) => {
             e.preventDefault();
-            if (addOrgOutput) { addOrgOutput.textContent = "Saving..."; addOrgOutput.className = "form-output"; }
+            if (addOrgOutput) { addOrgOutput.textContent = label("script", "saving"); addOrgOutput.className = "form-output"; }
             const formData = new FormData(addOrgForm);
             try {
                 const res = await fetch(addOrgForm.dataset.endpoint || "/api/staff/organizations/create", {
@@ -67,12 +80,12 @@ document.addEventListener("DOMContentLoaded", () => {
                     if (addOrgOutput) { addOrgOutput.textContent = data.error || data.raw || ("HTTP " + res.status); addOrgOutput.className = "form-output error"; }
                     return;
                 }
-                if (addOrgOutput) { addOrgOutput.textContent = "Created"; addOrgOutput.className = "form-output success"; }
+                if (addOrgOutput) { addOrgOutput.textContent = label("script", "created"); addOrgOutput.className = "form-output success"; }
                 if (addOrgForm) addOrgForm.reset();
                 toggleAddModal(false);
                 if (typeof load === "function") load();
             } catch {
-                if (addOrgOutput) { addOrgOutput.textContent = "Network error"; addOrgOutput.className = "form-output error"; }
+                if (addOrgOutput) { addOrgOutput.textContent = label("script", "network_error"); addOrgOutput.className = "form-output error"; }
             }
         });
     }
@@ -84,17 +97,17 @@ document.addEventListener("DOMContentLoaded", () => {
     if (updateOrgForm) {
         updateOrgForm.addEventListener("submit", async (e) => {
             e.preventDefault();
-            if (updateOrgOutput) { updateOrgOutput.textContent = "Saving..."; updateOrgOutput.className = "form-output"; }
+            if (updateOrgOutput) { updateOrgOutput.textContent = label("script", "saving"); updateOrgOutput.className = "form-output"; }
 
             const id = (updateOrgId && updateOrgId.value.trim()) || "";
             const nameVal = (updateOrgName && updateOrgName.value.trim()) || "";
 
             if (!id) {
-                if (updateOrgOutput) { updateOrgOutput.textContent = "Organization ID is empty"; updateOrgOutput.className = "form-output error"; }
+                if (updateOrgOutput) { updateOrgOutput.textContent = label("script", "org_id_empty"); updateOrgOutput.className = "form-output error"; }
                 return;
             }
             if (!nameVal) {
-                if (updateOrgOutput) { updateOrgOutput.textContent = "Name is required"; updateOrgOutput.className = "form-output error"; }
+                if (updateOrgOutput) { updateOrgOutput.textContent = label("script", "name_required"); updateOrgOutput.className = "form-output error"; }
                 return;
             }
 
@@ -118,15 +131,141 @@ document.addEventListener("DOMContentLoaded", () => {
                     return;
                 }
 
-                if (updateOrgOutput) { updateOrgOutput.textContent = "Updated"; updateOrgOutput.className = "form-output success"; }
+                if (updateOrgOutput) { updateOrgOutput.textContent = label("script", "updated"); updateOrgOutput.className = "form-output success"; }
                 toggleUpdateModal(false);
                 if (typeof load === "function") load();
             } catch {
-                if (updateOrgOutput) { updateOrgOutput.textContent = "Network error"; updateOrgOutput.className = "form-output error"; }
+                if (updateOrgOutput) { updateOrgOutput.textContent = label("script", "network_error"); updateOrgOutput.className = "form-output error"; }
             }
         });
     }
 
+    const setOutput = (el, text, kind) => {
+        if (!el) return;
+        el.textContent = text;
+        el.className = "form-output" + (kind ? " " + kind : "");
+    };
+
+    const readJSON = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || "{}"); } catch { return { raw: text }; }
+    };
+
+    const formatDate = (value) => value ? new Date(value).toLocaleDateString() : "";
+
+    const renderContracts = (contracts) => {
+        if (!contractsList) return;
+        contractsList.innerHTML = "";
+        if (!contracts.length) {
+            contractsList.textContent = label("script", "no_contracts");
+            return;
+        }
+        contracts.forEach(ct => {
+            const row = document.createElement("div");
+            row.className = "form-row";
+            row.style.gap = "8px";
+            row.style.alignItems = "center";
+
+            const text = document.createElement("span");
+            text.textContent = "№ " + ct.Number + ": " + formatDate(ct.StartsAt) + " — " + (ct.EndsAt ? formatDate(ct.EndsAt) : label("script", "open_ended")) + (ct.IsActive ? "" : label("script", "inactive_suffix"));
+            row.appendChild(text);
+
+            if (ct.IsActive) {
+                const btn = document.createElement("button");
+                btn.className = "btn";
+                btn.type = "button";
+                btn.textContent = label("script", "deactivate");
+                btn.addEventListener("click", async () => {
+                    if (!confirm(label("script", "deactivate_confirm").replace("%s", ct.Number))) return;
+                    try {
+                        const url = "/api/staff/organizations/contracts/" + encodeURIComponent(ct.ID) + "?organizationID=" + encodeURIComponent(detailsOrgId);
+                        const res = await fetch(url, { method: "DELETE", credentials: "same-origin" });
+                        const data = await readJSON(res);
+                        if (!res.ok) { setOutput(contractOutput, data.error || data.raw || ("HTTP " + res.status), "error"); return; }
+                        loadDetails();
+                    } catch {
+                        setOutput(contractOutput, label("script", "network_error"), "error");
+                    }
+                });
+                row.appendChild(btn);
+            }
+
+            contractsList.appendChild(row);
+        });
+    };
+
+    const loadDetails = async () => {
+        try {
+            const res = await fetch("/api/staff/organizations/info?organizationID=" + encodeURIComponent(detailsOrgId), { credentials: "same-origin" });
+            const data = await readJSON(res);
+            if (!res.ok) { setOutput(contactsOutput, data.error || data.raw || ("HTTP " + res.status), "error"); return; }
+
+            const org = data.organization || {};
+            if (detailsName) detailsName.textContent = org.Name || "";
+            if (contactsForm) {
+                contactsForm.elements["contactPerson"].value = org.ContactPerson || "";
+                contactsForm.elements["phoneNumber"].value = org.Phone || "";
+                contactsForm.elements["email"].value = org.Email || "";
+            }
+            const categories = org.Categories || [];
+            if (categoriesForm) {
+                categoriesForm.querySelectorAll("input[name=category]").forEach(cb => {
+                    cb.checked = categories.includes(cb.value);
+                });
+            }
+            renderContracts(org.Contracts || []);
+
+            if (representativesList) {
+                const repsRes = await fetch("/api/staff/organizations/representatives?organizationID=" + encodeURIComponent(detailsOrgId), { credentials: "same-origin" });
+                const repsData = await readJSON(repsRes);
+                const reps = repsData.representatives || [];
+                representativesList.textContent = repsRes.ok
+                    ? (reps.length ? reps.map(rp => rp.FullName + " (" + rp.Phone + ")").join(", ") : label("script", "no_representatives"))
+                    : (repsData.error || ("HTTP " + repsRes.status));
+            }
+        } catch {
+            setOutput(contactsOutput, label("script", "network_error"), "error");
+        }
+    };
+
+    const openDetails = (orgId) => {
+        detailsOrgId = orgId;
+        [contactsOutput, categoriesOutput, contractOutput].forEach(el => setOutput(el, "", ""));
+        if (contractForm) contractForm.reset();
+        if (detailsModal) detailsModal.classList.remove("hidden");
+        window.scrollTo(0, 0);
+        loadDetails();
+    };
+
+    if (detailsClose) detailsClose.addEventListener("click", () => {
+        if (detailsModal) detailsModal.classList.add("hidden");
+        load();
+    });
+
+    const submitDetailsForm = (form, output, okText) => {
+        if (!form) return;
+        form.addEventListener("submit", async (e) => {
+            e.preventDefault();
+            setOutput(output, label("script", "saving"), "");
+            const formData = new FormData(form);
+            formData.append("organizationID", detailsOrgId);
+            try {
+                const res = await fetch(form.dataset.endpoint, { method: "POST", body: formData, credentials: "same-origin" });
+                const data = await readJSON(res);
+                if (!res.ok) { setOutput(output, data.error || data.raw || ("HTTP " + res.status), "error"); return; }
+                setOutput(output, okText, "success");
+                if (form === contractForm) form.reset();
+                loadDetails();
+            } catch {
+                setOutput(output, label("script", "network_error"), "error");
+            }
+        });
+    };
+
+    submitDetailsForm(contactsForm, contactsOutput, label("script", "contacts_saved"));
+    submitDetailsForm(categoriesForm, categoriesOutput, label("script", "categories_saved"));
+    submitDetailsForm(contractForm, contractOutput, label("script", "contract_added"));
+
     const buildUrl = () => {
         const url = new URL("/api/staff/organizations/list", window.location.origin);
         url.searchParams.set("page", String(page));
@@ -188,7 +327,7 @@ document.addEventListener("DOMContentLoaded", () => {
         updateControls();
 
         if (!orgs.length) {
-            if (out) out.textContent = "No organizations found";
+            if (out) out.textContent = label("script", "no_organizations");
             return;
         }
 
@@ -219,19 +358,19 @@ document.addEventListener("DOMContentLoaded", () => {
             copyBtn.style.padding = "2px 8px";
             copyBtn.style.fontSize = "12px";
             copyBtn.type = "button";
-            copyBtn.title = "Copy ID";
-            copyBtn.textContent = "Copy";
+            copyBtn.title = label("script", "copy_id");
+            copyBtn.textContent = label("script", "copy");
             copyBtn.addEventListener("click", async () => {
                 const prevText = copyBtn.textContent;
                 copyBtn.disabled = true;
-                copyBtn.textContent = "Copying...";
+                copyBtn.textContent = label("script", "copying");
                 try {
                     await copyToClipboard(idText);
-                    copyBtn.textContent = "Copied";
-                    if (out) { out.textContent = "ID copied to clipboard"; out.className = "form-output success"; }
+                    copyBtn.textContent = label("script", "copied");
+                    if (out) { out.textContent = label("script", "id_copied"); out.className = "form-output success"; }
                 } catch {
-                    copyBtn.textContent = "Failed";
-                    if (out) { out.textContent = "Copy failed"; out.className = "form-output error"; }
+                    copyBtn.textContent = label("script", "failed");
+                    if (out) { out.textContent = label("script", "copy_failed"); out.className = "form-output error"; }
                 } finally {
                     setTimeout(() => {
                         copyBtn.disabled = false;
@@ -245,7 +384,7 @@ document.addEventListener("DOMContentLoaded", () => {
             const nameRow = document.createElement("div");
             const nameLabel = document.createElement("span");
             nameLabel.style.color = "var(--muted)";
-            nameLabel.textContent = "Name: ";
+            nameLabel.textContent = label("script", "name_prefix");
             const nameValue = document.createElement("span");
             nameValue.textContent = nameText;
             nameRow.appendChild(nameLabel);
@@ -255,6 +394,17 @@ document.addEventListener("DOMContentLoaded", () => {
             info.appendChild(document.createElement("br"));
             info.appendChild(nameRow);
 
+            const categories = o.Categories || [];
+            const activeContracts = (o.Contracts || []).filter(ct => ct.IsActive).length;
+            const scopeRow = document.createElement("div");
+            scopeRow.style.fontWeight = "400";
+            scopeRow.style.fontSize = "12px";
+            scopeRow.style.color = "var(--muted)";
+            scopeRow.textContent = label("script", "serves_prefix") + (categories.length ? categories.map(c => label("request_type", c)).join(", ") : label("script", "nothing")) +
+                label("script", "active_contracts_sep") + activeContracts +
+                (o.ContactPerson || o.Phone || o.Email ? label("script", "contact_sep") + [o.ContactPerson, o.Phone, o.Email].filter(Boolean).join(", ") : "");
+            info.appendChild(scopeRow);
+
             const actions = document.createElement("div");
             actions.className = "form-row";
             actions.style.marginTop = "8px";
@@ -262,7 +412,7 @@ document.addEventListener("DOMContentLoaded", () => {
             const editBtn = document.createElement("button");
             editBtn.className = "btn";
             editBtn.type = "button";
-            editBtn.textContent = "Edit name";
+            editBtn.textContent = label("script", "edit_name");
             editBtn.addEventListener("click", () => {
                 if (updateOrgId) updateOrgId.value = idText;
                 if (updateOrgName) updateOrgName.value = nameText;
@@ -271,6 +421,13 @@ document.addEventListener("DOMContentLoaded", () => {
             });
             actions.appendChild(editBtn);
 
+            const detailsBtn = document.createElement("button");
+            detailsBtn.className = "btn";
+            detailsBtn.type = "button";
+            detailsBtn.textContent = label("script", "contacts_contracts");
+            detailsBtn.addEventListener("click", () => openDetails(idText));
+            actions.appendChild(detailsBtn);
+
             card.appendChild(info);
             card.appendChild(actions);
             list.appendChild(card);
@@ -279,7 +436,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     const load = () => {
         clear();
-        if (out) { out.textContent = "Loading..."; out.className = "form-output"; }
+        if (out) { out.textContent = label("script", "loading"); out.className = "form-output"; }
         fetch(buildUrl(), { credentials: "same-origin" })
             .then(async res => {
                 const text = await res.text();
@@ -23,9 +23,16 @@ document.addEventListener("DOMContentLoaded", () => {
     const filterHouse = document.getElementById("filter-house");
     const filterResponsible = document.getElementById("filter-resp");
     const filterOrg = document.getElementById("filter-organization-id");
+    const filterParent = document.getElementById("filter-parent-id");
+    const filterPlanned = document.getElementById("filter-planned");
     const filterType = document.getElementById("filter-type");
     const filterStatus = document.getElementById("filter-status");
+    const filterPriority = document.getElementById("filter-priority");
     const filterComplaint = document.getElementById("filter-complaint");
+    const filterCategory = document.getElementById("filter-category");
+    const filterOverdue = document.getElementById("filter-overdue");
+    const editCategory = document.getElementById("edit-category");
+    const editType = document.getElementById("edit-type");
     const applyBtn = document.getElementById("apply-filters");
 
     const modal = document.getElementById("edit-modal");
@@ -45,10 +52,8 @@ document.addEventListener("DOMContentLoaded", () => {
         if (!organizationBlock || !organizationInput) return;
         if (status === "передана_организации") {
             organizationBlock.classList.remove("hidden");
-            organizationInput.required = true;
         } else {
             organizationBlock.classList.add("hidden");
-            organizationInput.required = false;
             organizationInput.value = "";
         }
     };
@@ -78,13 +83,66 @@ document.addEventListener("DOMContentLoaded", () => {
         if (filterHouse && filterHouse.value) url.searchParams.set('houseID', filterHouse.value);
         if (filterResponsible && filterResponsible.value) url.searchParams.set('responsibleID', filterResponsible.value);
         if (filterOrg && filterOrg.value) url.searchParams.set('organizationID', filterOrg.value);
+        if (filterParent && filterParent.value) url.searchParams.set('parentID', filterParent.value.trim());
+        if (filterPlanned && filterPlanned.value) url.searchParams.set('planned', filterPlanned.value);
         if (filterType && filterType.value) url.searchParams.set('type', filterType.value);
         if (filterStatus && filterStatus.value) url.searchParams.set('status', filterStatus.value);
+        if (filterPriority && filterPriority.value) url.searchParams.set('priority', filterPriority.value);
         if (filterComplaint && filterComplaint.value) url.searchParams.set('complaint', filterComplaint.value);
+        if (filterCategory && filterCategory.value) url.searchParams.set('categoryID', filterCategory.value);
+        if (filterOverdue && filterOverdue.checked) url.searchParams.set('overdue', 'true');
 
         return url.toString();
     };
 
+    // titles of the categories by ID, the requests only carry the ID
+    const categoryTitles = {};
+
+    const addCategoryOptions = (select, type, nodes, depth) => {
+        nodes.forEach(node => {
+            const option = document.createElement('option');
+            option.value = node.ID;
+            option.dataset.type = type;
+            option.textContent = '\u00a0\u00a0'.repeat(depth) + node.Title + (node.IsActive ? '' : label('script', 'hidden_suffix'));
+            select.appendChild(option);
+            addCategoryOptions(select, type, node.Children || [], depth + 1);
+        });
+    };
+
+    const collectTitles = (nodes) => {
+        nodes.forEach(node => {
+            categoryTitles[node.ID] = node.Title;
+            collectTitles(node.Children || []);
+        });
+    };
+
+    const loadCategories = async () => {
+        try {
+            const res = await fetch('/api/staff/categories?inactive=true', { credentials: 'same-origin' });
+            if (!res.ok) return;
+            const data = await res.json();
+            (data.groups || []).forEach(group => {
+                collectTitles(group.Categories || []);
+                [filterCategory, editCategory].forEach(select => {
+                    if (!select || !(group.Categories || []).length) return;
+                    const optgroup = document.createElement('optgroup');
+                    optgroup.label = label('request_type', group.Type);
+                    select.appendChild(optgroup);
+                    addCategoryOptions(optgroup, group.Type, group.Categories, 0);
+                });
+            });
+        } catch {
+            // the panel works without categories, they are only not shown
+        }
+    };
+
+    if (editCategory && editType) {
+        editCategory.addEventListener("change", () => {
+            const option = editCategory.selectedOptions[0];
+            if (option && option.dataset.type) editType.value = option.dataset.type;
+        });
+    }
+
     const openModal = (req) => {
         if (!modal) return;
         modal.classList.remove("hidden");
@@ -109,6 +167,12 @@ document.addEventListener("DOMContentLoaded", () => {
         document.getElementById("edit-type").value = req.RequestType || "";
         document.getElementById("edit-complaint").value = req.Complaint || "";
         document.getElementById("edit-status").value = req.Status || "";
+        document.getElementById("edit-priority").value = req.Priority || "обычная";
+        if (editCategory) editCategory.value = req.CategoryID || "";
+        const suggestedEl = document.getElementById("edit-suggested-priority");
+        if (suggestedEl) {
+            suggestedEl.textContent = req.SuggestedPriority ? (label('script', 'resident_suggested') + label('request_priority', req.SuggestedPriority)) : '';
+        }
 
         const orgEl = document.getElementById("edit-organizationID");
         if (orgEl) {
@@ -126,6 +190,24 @@ document.addEventListener("DOMContentLoaded", () => {
         modal.classList.add("hidden");
     };
 
+    const postForm = async (url, fields) => {
+        const body = new FormData();
+        Object.keys(fields).forEach(k => body.append(k, fields[k]));
+        try {
+            const res = await fetch(url, { method: 'POST', body, credentials: 'same-origin' });
+            const text = await res.text();
+            let json;
+            try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+            if (!res.ok) {
+                alert(json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+            load();
+        } catch {
+            alert(label('script', 'network_error'));
+        }
+    };
+
     const renderRequests = (data) => {
         clear();
 
@@ -144,7 +226,7 @@ document.addEventListener("DOMContentLoaded", () => {
         if (totalPagesEl) totalPagesEl.textContent = String(pages);
 
         if (!requests.length) {
-            if (out) out.textContent = 'No requests found';
+            if (out) out.textContent = label('script', 'no_requests');
             updateControls();
             return;
         }
@@ -163,15 +245,74 @@ document.addEventListener("DOMContentLoaded", () => {
             const houseID = r.HouseID || '';
             const responsible = r.ResponsibleID || '';
             const organization = r.OrganizationID || '';
+            const priority = r.Priority || 'обычная';
 
             const createdStr = created ? (new Date(created)).toLocaleString() : '';
-            const orgPart = (status === 'передана_организации' && organization) ? (' • organization: ' + organization) : '';
+            const orgPart = (status === 'передана_организации' && organization) ? (label('script', 'organization_sep') + organization) : '';
 
             card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
-                '<span style="color:var(--muted);">ID: </span> ' + id + '<br><span style="color:var(--muted);">Resident: </span>' + residentID + '<br><span style="color:var(--muted);">House: </span>' + houseID + '<br><span style="color:var(--muted);">Тип: </span>' + type + '<br><span style="color:var(--muted);">Статус: </span>' + status +
+                '<span style="color:var(--muted);">ID: </span> ' + id + '<br><span style="color:var(--muted);">' + label('script', 'resident') + ' </span>' + residentID + '<br><span style="color:var(--muted);">' + label('script', 'house') + ' </span>' + houseID + '<br><span style="color:var(--muted);">' + label('script', 'type') + ' </span>' + label('request_type', type) + '<br><span style="color:var(--muted);">' + label('script', 'status') + ' </span>' + label('request_status', status) +
+                '<br><span style="color:var(--muted);">' + label('script', 'priority') + ' </span>' + label('request_priority', priority) +
+                (r.SuggestedPriority && r.SuggestedPriority !== priority ? ' <span style="color:var(--muted);">' + label('script', 'resident_suggests').replace('%s', label('request_priority', r.SuggestedPriority)) + '</span>' : '') +
                 '</div>' +
                 '<div style="margin-bottom:8px;">' + (complaint || '') + '</div>' +
-                '<div style="font-size:12px;color:var(--muted);">' + createdStr + (responsible ? (' • responsible: '+responsible) : '') + orgPart + '</div>';
+                '<div style="font-size:12px;color:var(--muted);">' + createdStr + (responsible ? (label('script', 'responsible_sep') + responsible) : '') + orgPart + '</div>';
+
+            if (priority === 'аварийная') {
+                card.style.borderLeft = '4px solid var(--danger, #c0392b)';
+            }
+
+            if (r.CategoryID || r.DueAt) {
+                const category = document.createElement('div');
+                category.style.fontSize = '12px';
+                category.style.color = 'var(--muted)';
+                const parts = [];
+                if (r.CategoryID) parts.push(label('script', 'category_prefix') + (categoryTitles[r.CategoryID] || r.CategoryID));
+                if (r.DueAt) parts.push(label('script', 'due_prefix') + new Date(r.DueAt).toLocaleString());
+                category.textContent = parts.join(' • ');
+
+                const finished = status === 'выполнена' || status === 'отменена';
+                if (r.DueAt && !finished && new Date(r.DueAt) < new Date()) {
+                    category.textContent += label('script', 'overdue_suffix');
+                    category.style.color = 'var(--danger, #c0392b)';
+                }
+                card.appendChild(category);
+            }
+
+            if (r.PlanID) {
+                const planned = document.createElement('div');
+                planned.style.fontSize = '12px';
+                planned.style.color = 'var(--accent)';
+                planned.textContent = label('script', 'planned_maintenance').replace('%s', r.PlanID);
+                card.appendChild(planned);
+            }
+
+            if (r.ParentID) {
+                const merged = document.createElement('div');
+                merged.style.fontSize = '12px';
+                merged.style.color = 'var(--muted)';
+                merged.textContent = label('script', 'duplicate_of').replace('%s', r.ParentID);
+                card.appendChild(merged);
+            }
+
+            if (r.TransferredAt) {
+                const contractor = document.createElement('div');
+                contractor.style.fontSize = '12px';
+                contractor.style.color = 'var(--muted)';
+                const parts = [label('script', 'transferred_prefix') + new Date(r.TransferredAt).toLocaleString()];
+                parts.push(r.ContractorAcceptedAt ? (label('script', 'accepted_prefix') + new Date(r.ContractorAcceptedAt).toLocaleString()) : label('script', 'not_accepted'));
+                if (r.ContractorDoneAt) parts.push(label('script', 'done_prefix') + new Date(r.ContractorDoneAt).toLocaleString());
+                if (r.InvoiceAmount !== null && r.InvoiceAmount !== undefined) parts.push(label('script', 'invoice_prefix') + r.InvoiceAmount);
+                contractor.textContent = parts.join(' • ');
+                card.appendChild(contractor);
+
+                if (r.CompletionReport) {
+                    const report = document.createElement('div');
+                    report.style.marginTop = '4px';
+                    report.textContent = label('script', 'report_prefix') + r.CompletionReport;
+                    card.appendChild(report);
+                }
+            }
 
             const actions = document.createElement('div');
             actions.style.marginTop = '8px';
@@ -180,17 +321,19 @@ document.addEventListener("DOMContentLoaded", () => {
 
             const editBtn = document.createElement('button');
             editBtn.className = 'btn';
-            editBtn.textContent = 'Edit';
+            editBtn.textContent = label('script', 'edit');
             editBtn.addEventListener('click', () => {
                 openModal(r);
             });
 
             const phoneBtn = document.createElement('button');
             phoneBtn.className = 'btn';
-            phoneBtn.textContent = 'Get phone';
+            phoneBtn.textContent = label('script', 'get_phone');
+            // planned requests come from maintenance plans and have no resident
+            phoneBtn.disabled = !residentID;
             phoneBtn.addEventListener('click', async () => {
                 if (!residentID) {
-                    alert('No resident ID');
+                    alert(label('script', 'no_resident_id'));
                     return;
                 }
                 try {
@@ -200,24 +343,24 @@ document.addEventListener("DOMContentLoaded", () => {
                     let data;
                     try { data = JSON.parse(text || '{}'); } catch { data = { raw: text }; }
                     if (!res.ok) {
-                        alert(data.error || data.raw || ('Failed to get phone, HTTP ' + res.status));
+                        alert(data.error || data.raw || label('script', 'phone_failed').replace('%s', res.status));
                         return;
                     }
                     if (data.phone) {
-                        alert('Phone: ' + data.phone);
+                        alert(label('script', 'phone_prefix') + data.phone);
                     } else {
-                        alert('Phone not found');
+                        alert(label('script', 'phone_not_found'));
                     }
                 } catch {
-                    alert('Network error');
+                    alert(label('script', 'network_error'));
                 }
             });
 
             const delBtn = document.createElement('button');
             delBtn.className = 'btn';
-            delBtn.textContent = 'Delete';
+            delBtn.textContent = label('script', 'delete');
             delBtn.addEventListener('click', async () => {
-                if (!confirm('Delete request ' + id + '?')) return;
+                if (!confirm(label('script', 'delete_request_confirm').replace('%s', id))) return;
                 try {
                     const res = await fetch('/api/staff/requests/panel/delete/' + encodeURIComponent(id), {
                         method: 'DELETE',
@@ -227,17 +370,273 @@ document.addEventListener("DOMContentLoaded", () => {
                     let json;
                     try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
                     if (!res.ok) {
-                        alert(json.error || json.message || ('Delete failed: ' + res.status));
+                        alert(json.error || json.message || label('script', 'delete_failed').replace('%s', res.status));
                     } else {
                         load();
                     }
                 } catch (err) {
-                    alert('Network error');
+                    alert(label('script', 'network_error'));
                 }
             });
 
+            const closed = status === 'выполнена' || status === 'отменена';
+
+            const transferBtn = document.createElement('button');
+            transferBtn.className = 'btn';
+            transferBtn.textContent = status === 'передана_организации' ? label('script', 'retransfer') : label('script', 'transfer');
+            transferBtn.disabled = closed;
+            transferBtn.addEventListener('click', () => {
+                const orgID = prompt(label('script', 'transfer_prompt').replace('%s', id), organization);
+                if (!orgID) return;
+                postForm('/api/staff/requests/panel/transfer', { id, organizationID: orgID.trim() });
+            });
+
             actions.appendChild(editBtn);
             actions.appendChild(phoneBtn);
+            actions.appendChild(transferBtn);
+
+            if (status === 'передана_организации' && !r.ContractorAcceptedAt) {
+                const acceptBtn = document.createElement('button');
+                acceptBtn.className = 'btn';
+                acceptBtn.textContent = label('script', 'contractor_accepted');
+                acceptBtn.addEventListener('click', () => {
+                    postForm('/api/staff/requests/panel/contractor/accept', { id });
+                });
+                actions.appendChild(acceptBtn);
+            }
+
+            if (status === 'передана_организации' && r.ContractorAcceptedAt) {
+                const doneBtn = document.createElement('button');
+                doneBtn.className = 'btn';
+                doneBtn.textContent = label('script', 'contractor_done');
+                doneBtn.addEventListener('click', () => {
+                    const report = prompt(label('script', 'completion_report'));
+                    if (!report) return;
+                    const invoice = prompt(label('script', 'invoice_amount'));
+                    if (invoice === null || invoice.trim() === '' || isNaN(Number(invoice))) {
+                        alert(label('script', 'invoice_not_number'));
+                        return;
+                    }
+                    postForm('/api/staff/requests/panel/contractor/complete', { id, report, invoiceAmount: invoice.trim() });
+                });
+                actions.appendChild(doneBtn);
+            }
+
+            const updatesBtn = document.createElement('button');
+            updatesBtn.className = 'btn';
+            updatesBtn.textContent = label('script', 'updates');
+            updatesBtn.addEventListener('click', async () => {
+                try {
+                    const res = await fetch('/api/staff/requests/panel/updates?id=' + encodeURIComponent(id), { credentials: 'same-origin' });
+                    const text = await res.text();
+                    let json;
+                    try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                    if (!res.ok) {
+                        alert(json.error || json.raw || ('HTTP ' + res.status));
+                        return;
+                    }
+                    const updates = json.updates || [];
+                    alert(updates.length
+                        ? updates.map(u => new Date(u.CreatedAt).toLocaleString() + ' [' + u.AuthorRole + ' ' + u.AuthorPhone + '] ' + u.Text).join('\n')
+                        : label('script', 'no_updates'));
+                } catch {
+                    alert(label('script', 'network_error'));
+                }
+            });
+            actions.appendChild(updatesBtn);
+
+            const costsBtn = document.createElement('button');
+            costsBtn.className = 'btn';
+            costsBtn.textContent = label('script', 'costs');
+            costsBtn.addEventListener('click', async () => {
+                try {
+                    const res = await fetch('/api/staff/requests/panel/costs?requestID=' + encodeURIComponent(id), { credentials: 'same-origin' });
+                    const text = await res.text();
+                    let json;
+                    try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                    if (!res.ok) {
+                        alert(json.error || json.raw || ('HTTP ' + res.status));
+                        return;
+                    }
+                    const items = json.items || [];
+                    const listing = items.length
+                        ? items.map(i => label('billing_item', i.Kind) + ': ' + i.Description + ' — ' + i.Quantity + ' × ' + i.UnitPrice + ' = ' + i.Amount + ' [' + label('billing_payer', i.Payer) + ', ' + label('billing_approval', i.Status) + ']').join('\n')
+                        : label('script', 'no_line_items');
+                    if (!confirm(listing + '\n\n' + label('script', 'add_line_item'))) return;
+
+                    const kind = prompt(label('script', 'line_kind_prompt'), 'материалы');
+                    if (!kind) return;
+                    const description = prompt(label('script', 'description_prompt'));
+                    if (!description) return;
+                    const quantity = prompt(label('script', 'line_quantity_prompt'), '1');
+                    if (quantity === null || isNaN(Number(quantity))) return;
+                    const unitPrice = prompt(label('script', 'unit_price_prompt'));
+                    if (unitPrice === null || isNaN(Number(unitPrice))) return;
+                    const payer = prompt(label('script', 'payer_prompt'), '');
+                    if (payer === null) return;
+
+                    postForm('/api/staff/requests/panel/costs', {
+                        requestID: id,
+                        kind: kind.trim(),
+                        description,
+                        quantity: quantity.trim(),
+                        unitPrice: unitPrice.trim(),
+                        payer: payer.trim()
+                    });
+                } catch {
+                    alert(label('script', 'network_error'));
+                }
+            });
+            actions.appendChild(costsBtn);
+
+            if (!r.ParentID) {
+                const materialsBtn = document.createElement('button');
+                materialsBtn.className = 'btn';
+                materialsBtn.textContent = label('script', 'materials');
+                materialsBtn.addEventListener('click', async () => {
+                    try {
+                        const res = await fetch('/api/staff/requests/panel/materials?requestID=' + encodeURIComponent(id), { credentials: 'same-origin' });
+                        const text = await res.text();
+                        let json;
+                        try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                        if (!res.ok) {
+                            alert(json.error || json.raw || ('HTTP ' + res.status));
+                            return;
+                        }
+                        const used = (json.materials || []).filter(m => !m.ReturnedAt);
+                        const listing = used.length
+                            ? used.map((m, i) => (i + 1) + '. ' + m.ItemName + ' — ' + (-m.Quantity) + ' ' + m.Unit + label('script', 'from_location').replace('%s', m.LocationName || label('script', 'deleted_location'))).join('\n')
+                            : label('script', 'no_materials');
+
+                        const answer = prompt(listing + '\n\n' + label('script', 'materials_prompt'), 'new');
+                        if (!answer) return;
+
+                        if (answer.trim() !== 'new') {
+                            const usage = used[Number(answer) - 1];
+                            if (!usage) { alert(label('script', 'no_such_entry')); return; }
+                            if (!confirm(label('script', 'return_confirm').replace('%s', (-usage.Quantity) + ' ' + usage.Unit).replace('%s', usage.ItemName))) return;
+                            postForm('/api/staff/requests/panel/materials/return', { id: usage.ID });
+                            return;
+                        }
+
+                        const search = prompt(label('script', 'item_search_prompt'), '');
+                        if (search === null) return;
+                        const itemsRes = await fetch('/api/staff/inventory/items?limit=20&search=' + encodeURIComponent(search.trim()), { credentials: 'same-origin' });
+                        const itemsJson = JSON.parse((await itemsRes.text()) || '{}');
+                        const items = (itemsJson.items || []).filter(i => (i.Locations || []).length);
+                        if (!items.length) { alert(label('script', 'no_items_in_stock')); return; }
+
+                        const itemAnswer = prompt(items.map((i, n) => (n + 1) + '. ' + i.Name + ' — ' + i.Total + ' ' + i.Unit + ' × ' + i.UnitPrice).join('\n'), '1');
+                        const item = items[Number(itemAnswer) - 1];
+                        if (!item) return;
+
+                        const places = item.Locations;
+                        const placeAnswer = places.length === 1 ? '1'
+                            : prompt(places.map((l, n) => (n + 1) + '. ' + l.LocationName + ' (' + l.Quantity + ')').join('\n'), '1');
+                        const place = places[Number(placeAnswer) - 1];
+                        if (!place) return;
+
+                        const quantity = prompt(label('script', 'quantity_unit_prompt').replace('%s', item.Unit), '1');
+                        if (quantity === null || !(Number(quantity) > 0)) return;
+                        const payer = prompt(label('script', 'payer_prompt'), '');
+                        if (payer === null) return;
+
+                        postForm('/api/staff/requests/panel/materials', {
+                            requestID: id,
+                            itemID: item.ID,
+                            locationID: place.LocationID,
+                            quantity: quantity.trim(),
+                            payer: payer.trim()
+                        });
+                    } catch {
+                        alert(label('script', 'network_error'));
+                    }
+                });
+                actions.appendChild(materialsBtn);
+            }
+
+            if (type === 'ремонт_внутриквартирный' && !r.ParentID) {
+                const visitsBtn = document.createElement('button');
+                visitsBtn.className = 'btn';
+                visitsBtn.textContent = label('script', 'visits');
+                visitsBtn.addEventListener('click', async () => {
+                    try {
+                        const res = await fetch('/api/staff/requests/panel/slots?requestID=' + encodeURIComponent(id), { credentials: 'same-origin' });
+                        const text = await res.text();
+                        let json;
+                        try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                        if (!res.ok) {
+                            alert(json.error || json.raw || ('HTTP ' + res.status));
+                            return;
+                        }
+                        const slots = (json.slots || []).filter(sl => sl.Status !== 'отменено');
+                        const listing = slots.length
+                            ? slots.map((sl, i) => (i + 1) + '. ' + new Date(sl.StartsAt).toLocaleString() + ' – ' + new Date(sl.EndsAt).toLocaleTimeString() + ' [' + label('slot_status', sl.Status) + ']').join('\n')
+                            : label('script', 'no_visit_times');
+
+                        const answer = prompt(listing + '\n\n' + label('script', 'visits_prompt'), 'new');
+                        if (!answer) return;
+
+                        if (answer.trim() === 'new') {
+                            const start = prompt(label('script', 'start_prompt'), '');
+                            if (!start) return;
+                            const minutes = Number(prompt(label('script', 'duration_prompt'), '60'));
+                            const startDate = new Date(start.trim());
+                            if (isNaN(startDate.getTime()) || !(minutes > 0)) { alert(label('script', 'invalid_start_duration')); return; }
+
+                            const endDate = new Date(startDate.getTime() + minutes * 60000);
+                            const pad = n => String(n).padStart(2, '0');
+                            const end = endDate.getFullYear() + '-' + pad(endDate.getMonth() + 1) + '-' + pad(endDate.getDate()) +
+                                'T' + pad(endDate.getHours()) + ':' + pad(endDate.getMinutes());
+
+                            postForm('/api/staff/requests/panel/slots', { requestID: id, start: start.trim(), end });
+                            return;
+                        }
+
+                        const slot = slots[Number(answer) - 1];
+                        if (!slot) { alert(label('script', 'no_such_slot')); return; }
+                        const reason = prompt(label('script', 'reason_prompt'), '');
+                        if (reason === null) return;
+                        postForm('/api/staff/requests/panel/slots/cancel', { slotID: slot.ID, reason });
+                    } catch {
+                        alert(label('script', 'network_error'));
+                    }
+                });
+                actions.appendChild(visitsBtn);
+            }
+
+            if (r.ParentID) {
+                const unmergeBtn = document.createElement('button');
+                unmergeBtn.className = 'btn';
+                unmergeBtn.textContent = label('script', 'unmerge');
+                unmergeBtn.addEventListener('click', () => {
+                    if (!confirm(label('script', 'unmerge_confirm').replace('%s', id).replace('%s', r.ParentID))) return;
+                    postForm('/api/staff/requests/panel/unmerge', { id });
+                });
+                actions.appendChild(unmergeBtn);
+            } else {
+                const duplicatesBtn = document.createElement('button');
+                duplicatesBtn.className = 'btn';
+                duplicatesBtn.textContent = label('script', 'duplicates');
+                duplicatesBtn.addEventListener('click', () => {
+                    if (filterParent) filterParent.value = id;
+                    page = 1;
+                    load();
+                });
+                actions.appendChild(duplicatesBtn);
+
+                const mergeBtn = document.createElement('button');
+                mergeBtn.className = 'btn';
+                mergeBtn.textContent = label('script', 'merge_duplicates');
+                mergeBtn.disabled = closed;
+                mergeBtn.addEventListener('click', () => {
+                    const childIDs = prompt(label('script', 'merge_prompt').replace('%s', id));
+                    if (!childIDs) return;
+                    postForm('/api/staff/requests/panel/merge', { parentID: id, childIDs: childIDs.trim() });
+                });
+                actions.appendChild(mergeBtn);
+            }
+
             actions.appendChild(delBtn);
             card.appendChild(actions);
 
@@ -250,7 +649,7 @@ document.addEventListener("DOMContentLoaded", () => {
     const load = () => {
         clear();
         if (out) {
-            out.textContent = 'Loading...';
+            out.textContent = label('script', 'loading');
             out.className = 'form-output';
         }
 
@@ -289,26 +688,28 @@ document.addEventListener("DOMContentLoaded", () => {
         jobLookupBtn.addEventListener('click', async () => {
             const jobID = (jobInput && jobInput.value) ? jobInput.value.trim() : '';
             if (!jobID) {
-                if (editOutput) { editOutput.textContent = 'Enter jobID'; editOutput.className = 'form-output error'; }
+                if (editOutput) { editOutput.textContent = label('script', 'enter_job_id'); editOutput.className = 'form-output error'; }
                 return;
             }
             try {
-                if (editOutput) { editOutput.textContent = 'Looking up...'; editOutput.className = 'form-output'; }
-                const url = '/api/staff/requests/panel/update/random-assign?jobID=' + encodeURIComponent(jobID);
-                const res = await fetch(url, { method: 'GET', credentials: 'same-origin' });
+                if (editOutput) { editOutput.textContent = label('script', 'looking_up'); editOutput.className = 'form-output'; }
+                const body = new FormData();
+                body.append('jobID', jobID);
+                body.append('priority', document.getElementById("edit-priority").value || 'обычная');
+                const res = await fetch('/api/staff/requests/panel/update/random-assign', { method: 'POST', body, credentials: 'same-origin' });
                 const data = await res.json();
                 if (!res.ok) {
-                    if (editOutput) { editOutput.textContent = data.error || 'Lookup failed'; editOutput.className = 'form-output error'; }
+                    if (editOutput) { editOutput.textContent = data.error || label('script', 'lookup_failed'); editOutput.className = 'form-output error'; }
                     return;
                 }
                 if (data.leastBusy !== undefined && data.leastBusy !== null) {
                     document.getElementById("edit-responsibleID").value = String(data.leastBusy);
-                    if (editOutput) { editOutput.textContent = 'Found responsible ID: ' + data.leastBusy; editOutput.className = 'form-output success'; }
+                    if (editOutput) { editOutput.textContent = label('script', 'found_responsible') + data.leastBusy; editOutput.className = 'form-output success'; }
                 } else {
-                    if (editOutput) { editOutput.textContent = 'No responsible found'; editOutput.className = 'form-output error'; }
+                    if (editOutput) { editOutput.textContent = label('script', 'no_responsible'); editOutput.className = 'form-output error'; }
                 }
             } catch (err) {
-                if (editOutput) { editOutput.textContent = 'Network error'; editOutput.className = 'form-output error'; }
+                if (editOutput) { editOutput.textContent = label('script', 'network_error'); editOutput.className = 'form-output error'; }
             }
         });
     }
@@ -323,7 +724,7 @@ document.addEventListener("DOMContentLoaded", () => {
     if (editForm) {
         editForm.addEventListener('submit', async (e) => {
             e.preventDefault();
-            if (editOutput) { editOutput.textContent = 'Saving...'; editOutput.className = 'form-output'; }
+            if (editOutput) { editOutput.textContent = label('script', 'saving'); editOutput.className = 'form-output'; }
             const endpoint = editForm.dataset.endpoint || '/api/staff/requests/panel/update';
             const body = new FormData(editForm);
             try {
@@ -339,11 +740,11 @@ document.addEventListener("DOMContentLoaded", () => {
                     if (editOutput) { editOutput.textContent = data.error || data.raw || ('HTTP ' + res.status); editOutput.className = 'form-output error'; }
                     return;
                 }
-                if (editOutput) { editOutput.textContent = 'Saved'; editOutput.className = 'form-output success'; }
+                if (editOutput) { editOutput.textContent = label('script', 'saved'); editOutput.className = 'form-output success'; }
                 closeModal();
                 load();
             } catch (err) {
-                if (editOutput) { editOutput.textContent = 'Network error'; editOutput.className = 'form-output error'; }
+                if (editOutput) { editOutput.textContent = label('script', 'network_error'); editOutput.className = 'form-output error'; }
             }
         });
     }
@@ -366,5 +767,5 @@ document.addEventListener("DOMContentLoaded", () => {
     });
     if (applyBtn) applyBtn.addEventListener('click', () => { page = 1; load(); });
 
-    load();
+    loadCategories().then(load);
 });
@@ -0,0 +1,176 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 20;
+    let lastPages = 1;
+
+    const form = document.getElementById("announcement-form");
+    const formOut = document.getElementById("announcement-output");
+    const list = document.getElementById("announcements-list");
+    const out = document.getElementById("announcements-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const houseFilter = document.getElementById("house-filter");
+    const activeOnly = document.getElementById("active-only");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const when = (value) => new Date(value).toLocaleString([], { year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' });
+
+    const act = async (url, options, done) => {
+        try {
+            const res = await fetch(url, { credentials: 'same-origin', ...options });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(out, done, false);
+            load();
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    const finish = (a) => {
+        if (!confirm(label('script', 'finish_confirm').replace('%s', a.Title))) return;
+        const formData = new FormData();
+        formData.append('id', a.ID);
+        act('/api/staff/announcements/finish', { method: 'POST', body: formData }, label('script', 'finished'));
+    };
+
+    const remove = (a) => {
+        if (!confirm(label('script', 'delete_confirm').replace('%s', a.Title))) return;
+        act('/api/staff/announcements/' + encodeURIComponent(a.ID), { method: 'DELETE' }, label('script', 'deleted'));
+    };
+
+    const render = (items) => {
+        if (!list) return;
+        list.innerHTML = '';
+
+        if (!items.length) {
+            const empty = document.createElement('div');
+            empty.style.color = 'var(--muted)';
+            empty.textContent = label('script', 'no_announcements');
+            list.appendChild(empty);
+            return;
+        }
+
+        const now = new Date();
+        items.forEach(a => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const title = document.createElement('div');
+            title.style.fontWeight = '700';
+            title.textContent = label('announcement_kind', a.Kind) + ': ' + a.Title;
+            card.appendChild(title);
+
+            const meta = document.createElement('div');
+            meta.style.fontSize = '14px';
+            meta.style.color = 'var(--muted)';
+            meta.textContent = when(a.StartsAt) + ' — ' + when(a.EndsAt) + label('script', 'houses_sep') + (a.HouseIDs || []).join(', ') +
+                label('script', 'by_sep') + a.CreatedBy;
+            card.appendChild(meta);
+
+            if (a.Body) {
+                const body = document.createElement('div');
+                body.style.whiteSpace = 'pre-wrap';
+                body.textContent = a.Body;
+                card.appendChild(body);
+            }
+
+            const actions = document.createElement('div');
+            actions.style.marginTop = '6px';
+
+            if (new Date(a.EndsAt) > now) {
+                const finishBtn = document.createElement('button');
+                finishBtn.className = 'btn';
+                finishBtn.textContent = label('script', 'finish_now');
+                finishBtn.addEventListener('click', () => finish(a));
+                actions.appendChild(finishBtn);
+            }
+
+            const deleteBtn = document.createElement('button');
+            deleteBtn.className = 'btn';
+            deleteBtn.style.marginLeft = '6px';
+            deleteBtn.textContent = label('script', 'delete');
+            deleteBtn.addEventListener('click', () => remove(a));
+            actions.appendChild(deleteBtn);
+
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const params = new URLSearchParams({ page: page, limit: limit });
+        if (houseFilter && houseFilter.value) params.set('houseID', houseFilter.value);
+        if (activeOnly && activeOnly.checked) params.set('active', 'true');
+
+        try {
+            const res = await fetch('/api/staff/announcements?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+
+            const meta = data.meta || {};
+            lastPages = meta.pages || 1;
+            if (totalCountEl) totalCountEl.textContent = meta.total ?? 0;
+            if (currentPageEl) currentPageEl.textContent = page;
+            if (totalPagesEl) totalPagesEl.textContent = lastPages;
+
+            render(data.announcements || []);
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    if (form) {
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            showMessage(formOut, label('script', 'posting'), false);
+            try {
+                const res = await fetch('/api/staff/announcements', {
+                    method: 'POST',
+                    credentials: 'same-origin',
+                    body: new FormData(form)
+                });
+                const data = await parse(res);
+                if (!res.ok) {
+                    showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                    return;
+                }
+                showMessage(formOut, label('script', 'posted'), false);
+                form.reset();
+                load();
+            } catch (err) {
+                showMessage(formOut, label('script', 'network_error'), true);
+            }
+        });
+    }
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => { page = 1; load(); });
+    if (activeOnly) activeOnly.addEventListener('change', () => { page = 1; load(); });
+    if (prevBtn) prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    if (nextBtn) nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+});
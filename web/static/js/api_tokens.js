@@ -0,0 +1,130 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const list = document.getElementById("tokens-list");
+    const out = document.getElementById("tokens-output");
+    const totalCountEl = document.getElementById("total-count");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const tokenForm = document.getElementById("token-form");
+    const newTokenBox = document.getElementById("new-token");
+    const newTokenValue = document.getElementById("new-token-value");
+
+    const kindLabels = { personal: label('script', 'token_personal'), access: label('script', 'token_access'), refresh: label('script', 'token_refresh') };
+
+    const clear = () => {
+        if (list) list.innerHTML = "";
+        if (out) { out.textContent = ""; out.className = "form-output"; }
+    };
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const escapeHtml = (value) => String(value || '')
+        .replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+
+    const renderTokens = (data) => {
+        clear();
+
+        const tokens = data.tokens || [];
+        if (totalCountEl) totalCountEl.textContent = String(tokens.length);
+
+        if (!tokens.length) {
+            if (out) out.textContent = label('script', 'no_tokens');
+            return;
+        }
+
+        tokens.forEach(t => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const createdAt = t.createdAt ? (new Date(t.createdAt)).toLocaleString() : '';
+            const expiresAt = t.expiresAt ? (new Date(t.expiresAt)).toLocaleString() : '';
+            const lastUsed = t.lastUsedAt ? (new Date(t.lastUsedAt)).toLocaleString() : label('script', 'never');
+
+            card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
+                escapeHtml(t.name || kindLabels[t.kind] || t.kind) +
+                '<br><span style="color:var(--muted);">' + label('script', 'kind') + ' </span>' + escapeHtml(kindLabels[t.kind] || t.kind) +
+                '</div>' +
+                '<div style="font-size:12px;color:var(--muted);">' + label('script', 'token_dates').replace('%s', createdAt).replace('%s', expiresAt).replace('%s', lastUsed) + '</div>';
+
+            const actions = document.createElement('div');
+            actions.style.marginTop = '8px';
+
+            const revokeBtn = document.createElement('button');
+            revokeBtn.className = 'btn';
+            revokeBtn.textContent = label('script', 'revoke');
+            revokeBtn.addEventListener('click', async () => {
+                if (!confirm(label('script', 'revoke_confirm'))) return;
+                try {
+                    const res = await fetch('/api/resident/tokens/' + encodeURIComponent(t.id), {
+                        method: 'DELETE',
+                        credentials: 'same-origin'
+                    });
+                    const json = await parse(res);
+                    if (!res.ok) {
+                        alert(json.error || json.message || label('script', 'revoke_failed').replace('%s', res.status));
+                    } else {
+                        load();
+                    }
+                } catch (err) {
+                    alert(label('script', 'network_error'));
+                }
+            });
+
+            actions.appendChild(revokeBtn);
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = () => {
+        clear();
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
+
+        fetch('/api/resident/tokens', { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) return Promise.reject(json);
+                return json;
+            })
+            .then(renderTokens)
+            .catch(err => {
+                clear();
+                if (out) {
+                    out.className = 'form-output error';
+                    out.textContent = err && err.error ? err.error : (err && err.message ? err.message : String(err));
+                }
+            });
+    };
+
+    if (tokenForm) tokenForm.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        try {
+            const res = await fetch(tokenForm.dataset.endpoint, {
+                method: 'POST',
+                body: new FormData(tokenForm),
+                credentials: 'same-origin'
+            });
+            const json = await parse(res);
+            if (!res.ok) {
+                alert(json.error || json.message || label('script', 'create_failed').replace('%s', res.status));
+                return;
+            }
+            if (newTokenBox && newTokenValue) {
+                newTokenValue.textContent = json.token || '';
+                newTokenBox.style.display = 'block';
+            }
+            tokenForm.reset();
+            load();
+        } catch (err) {
+            alert(label('script', 'network_error'));
+        }
+    });
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => load());
+
+    load();
+});
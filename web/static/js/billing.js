@@ -0,0 +1,174 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const list = document.getElementById("pending-list");
+    const out = document.getElementById("pending-output");
+    const totalCountEl = document.getElementById("total-count");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+
+    const accountantForm = document.getElementById("accountant-form");
+    const accountantMember = document.getElementById("accountant-member");
+    const revokeBtn = document.getElementById("revoke-btn");
+    const accountantOut = document.getElementById("accountant-output");
+
+    const limit = 10;
+    let page = 1;
+    let lastPages = 1;
+
+    const clear = () => {
+        if (list) list.innerHTML = "";
+        if (out) { out.textContent = ""; out.className = "form-output"; }
+    };
+
+    const escapeHtml = (value) => String(value || '')
+        .replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const updateControls = () => {
+        if (currentPageEl) currentPageEl.textContent = String(page);
+        if (totalPagesEl) totalPagesEl.textContent = String(lastPages);
+        if (prevBtn) prevBtn.disabled = page <= 1;
+        if (nextBtn) nextBtn.disabled = page >= lastPages;
+    };
+
+    const review = async (id, decision) => {
+        const comment = prompt(label('script', decision === 'approve' ? 'comment_optional_prompt' : 'rejection_reason_prompt'), '');
+        if (comment === null) return;
+
+        const body = new FormData();
+        body.append('id', id);
+        body.append('decision', decision);
+        body.append('comment', comment);
+
+        try {
+            const res = await fetch('/api/staff/billing/review', { method: 'POST', body, credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                alert(json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+            load();
+        } catch {
+            alert(label('script', 'network_error'));
+        }
+    };
+
+    const renderItems = (data) => {
+        clear();
+
+        const items = data.items || [];
+        const meta = data.meta || {};
+
+        lastPages = (meta.pages && typeof meta.pages === 'number') ? meta.pages : 1;
+        if (totalCountEl) totalCountEl.textContent = String(meta.total || 0);
+
+        if (!items.length) {
+            if (out) out.textContent = label('script', 'nothing_to_review');
+            updateControls();
+            return;
+        }
+
+        items.forEach(i => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
+                escapeHtml(label('billing_item', i.Kind)) + ': ' + escapeHtml(i.Description) + '</div>' +
+                '<div>' + i.Quantity + ' × ' + i.UnitPrice + ' = <b>' + i.Amount + '</b>' + label('script', 'payer_sep') + escapeHtml(label('billing_payer', i.Payer)) + '</div>' +
+                '<div style="font-size:12px;color:var(--muted);">' + label('script', 'request_prefix') + escapeHtml(i.RequestID) + label('script', 'by_sep') + escapeHtml(i.CreatedBy) +
+                ' • ' + new Date(i.CreatedAt).toLocaleString() + '</div>';
+
+            const actions = document.createElement('div');
+            actions.style.marginTop = '8px';
+
+            const approveBtn = document.createElement('button');
+            approveBtn.className = 'btn';
+            approveBtn.textContent = label('script', 'approve');
+            approveBtn.addEventListener('click', () => review(i.ID, 'approve'));
+
+            const rejectBtn = document.createElement('button');
+            rejectBtn.className = 'btn';
+            rejectBtn.textContent = label('script', 'reject');
+            rejectBtn.addEventListener('click', () => review(i.ID, 'reject'));
+
+            actions.appendChild(approveBtn);
+            actions.appendChild(rejectBtn);
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+
+        updateControls();
+    };
+
+    const load = () => {
+        clear();
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
+
+        fetch('/api/staff/billing/pending?page=' + page + '&limit=' + limit, { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) {
+                    if (out) {
+                        out.textContent = json.error || json.raw || ('HTTP ' + res.status);
+                        out.className = 'form-output error';
+                    }
+                    return;
+                }
+                renderItems(json);
+            })
+            .catch(() => {
+                if (out) {
+                    out.textContent = label('script', 'network_error');
+                    out.className = 'form-output error';
+                }
+            });
+    };
+
+    const changeAccountant = async (method) => {
+        const id = accountantMember ? accountantMember.value.trim() : '';
+        if (!id) return;
+
+        let res;
+        try {
+            if (method === 'POST') {
+                const body = new FormData();
+                body.append('staffMemberID', id);
+                res = await fetch('/api/staff/users/staff/accountant', { method, body, credentials: 'same-origin' });
+            } else {
+                res = await fetch('/api/staff/users/staff/accountant?staffMemberID=' + encodeURIComponent(id), { method, credentials: 'same-origin' });
+            }
+        } catch {
+            if (accountantOut) accountantOut.textContent = label('script', 'network_error');
+            return;
+        }
+
+        const json = await parse(res);
+        if (accountantOut) {
+            accountantOut.textContent = res.ok ? (json.message || 'OK') : (json.error || ('HTTP ' + res.status));
+            accountantOut.className = res.ok ? 'form-output' : 'form-output error';
+        }
+    };
+
+    if (accountantForm) {
+        accountantForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            changeAccountant('POST');
+        });
+    }
+    if (revokeBtn) revokeBtn.addEventListener('click', () => changeAccountant('DELETE'));
+
+    if (refreshBtn) refreshBtn.addEventListener('click', load);
+    if (prevBtn) prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    if (nextBtn) nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+});
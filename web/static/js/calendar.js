@@ -0,0 +1,272 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const list = document.getElementById("calendar-list");
+    const out = document.getElementById("calendar-output");
+    const fromInput = document.getElementById("calendar-from");
+    const daysSelect = document.getElementById("calendar-days");
+    const specInput = document.getElementById("calendar-spec");
+    const refreshBtn = document.getElementById("refresh-btn");
+
+    const memberInput = document.getElementById("member-id");
+    const memberLoadBtn = document.getElementById("member-load");
+    const memberSchedule = document.getElementById("member-schedule");
+    const memberOut = document.getElementById("member-output");
+    const shiftForm = document.getElementById("shift-form");
+    const absenceForm = document.getElementById("absence-form");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const hhmm = (minutes) => String(Math.floor(minutes / 60)).padStart(2, '0') + ':' + String(minutes % 60).padStart(2, '0');
+    const time = (value) => new Date(value).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+
+    const showError = (el, message) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = 'form-output error';
+    };
+
+    const renderCalendar = (data) => {
+        if (list) list.innerHTML = '';
+        if (out) { out.textContent = ''; out.className = 'form-output'; }
+
+        (data.days || []).forEach(day => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const title = document.createElement('div');
+            title.style.fontWeight = '700';
+            title.style.marginBottom = '6px';
+            title.textContent = new Date(day.Date).toLocaleDateString([], { weekday: 'long', year: 'numeric', month: '2-digit', day: '2-digit' });
+            card.appendChild(title);
+
+            const shifts = day.Shifts || [];
+            if (!shifts.length) {
+                const empty = document.createElement('div');
+                empty.style.color = 'var(--muted)';
+                empty.textContent = label('script', 'nobody_on_duty');
+                card.appendChild(empty);
+            }
+
+            let currentSpec = null;
+            shifts.forEach(s => {
+                if (s.SpecializationID !== currentSpec) {
+                    currentSpec = s.SpecializationID;
+                    const spec = document.createElement('div');
+                    spec.style.marginTop = '6px';
+                    spec.style.fontWeight = '700';
+                    spec.textContent = s.Specialization;
+                    card.appendChild(spec);
+                }
+
+                const row = document.createElement('div');
+                row.style.fontSize = '14px';
+                row.textContent = (s.Unscheduled ? label('script', 'all_day') : time(s.StartsAt) + '–' + time(s.EndsAt)) +
+                    ' • ' + s.FullName + ' (' + s.MemberID + ', ' + s.Phone + ')';
+                card.appendChild(row);
+            });
+
+            list.appendChild(card);
+        });
+    };
+
+    const load = () => {
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
+
+        const params = new URLSearchParams();
+        if (fromInput && fromInput.value) params.set('from', fromInput.value);
+        if (daysSelect) params.set('days', daysSelect.value);
+        if (specInput && specInput.value.trim()) params.set('specializationID', specInput.value.trim());
+
+        fetch('/api/staff/calendar?' + params.toString(), { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) {
+                    showError(out, json.error || json.raw || ('HTTP ' + res.status));
+                    return;
+                }
+                renderCalendar(json);
+            })
+            .catch(() => showError(out, label('script', 'network_error')));
+    };
+
+    const memberID = () => memberInput ? memberInput.value.trim() : '';
+
+    const removeAndReload = async (url) => {
+        try {
+            const res = await fetch(url, { method: 'DELETE', credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                alert(json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+            loadMember();
+            load();
+        } catch {
+            alert(label('script', 'network_error'));
+        }
+    };
+
+    const renderMember = (data) => {
+        if (!memberSchedule) return;
+        memberSchedule.innerHTML = '';
+
+        const shifts = data.shifts || [];
+        const absences = data.absences || [];
+
+        const shiftsTitle = document.createElement('div');
+        shiftsTitle.style.fontWeight = '700';
+        shiftsTitle.textContent = shifts.length ? label('script', 'weekly_shifts') : label('script', 'no_weekly_shifts');
+        memberSchedule.appendChild(shiftsTitle);
+
+        shifts.forEach(s => {
+            const row = document.createElement('div');
+            row.textContent = label('weekday', s.Weekday) + ' ' + hhmm(s.StartMinute) + '–' + hhmm(s.EndMinute) + ' ';
+
+            const delBtn = document.createElement('button');
+            delBtn.className = 'btn';
+            delBtn.textContent = label('script', 'remove');
+            delBtn.addEventListener('click', () => {
+                removeAndReload('/api/staff/users/staff/schedule?staffMemberID=' + encodeURIComponent(memberID()) + '&weekday=' + s.Weekday);
+            });
+            row.appendChild(delBtn);
+            memberSchedule.appendChild(row);
+        });
+
+        const absencesTitle = document.createElement('div');
+        absencesTitle.style.fontWeight = '700';
+        absencesTitle.style.marginTop = '8px';
+        absencesTitle.textContent = absences.length ? label('script', 'absences') : label('script', 'no_absences');
+        memberSchedule.appendChild(absencesTitle);
+
+        absences.forEach(a => {
+            const row = document.createElement('div');
+            const lastDay = new Date(new Date(a.EndsAt).getTime() - 1);
+            row.textContent = label('absence_kind', a.Kind) + ': ' + new Date(a.StartsAt).toLocaleDateString() + ' — ' + lastDay.toLocaleDateString() +
+                (a.Comment ? ' (' + a.Comment + ')' : '') + ' ';
+
+            const delBtn = document.createElement('button');
+            delBtn.className = 'btn';
+            delBtn.textContent = label('script', 'remove');
+            delBtn.addEventListener('click', () => {
+                removeAndReload('/api/staff/users/staff/absences/' + encodeURIComponent(a.ID) + '?staffMemberID=' + encodeURIComponent(memberID()));
+            });
+            row.appendChild(delBtn);
+            memberSchedule.appendChild(row);
+        });
+    };
+
+    const loadMember = () => {
+        if (!memberID()) return;
+        if (memberOut) { memberOut.textContent = ''; memberOut.className = 'form-output'; }
+
+        fetch('/api/staff/users/staff/schedule?staffMemberID=' + encodeURIComponent(memberID()), { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) {
+                    showError(memberOut, json.error || json.raw || ('HTTP ' + res.status));
+                    return;
+                }
+                renderMember(json);
+            })
+            .catch(() => showError(memberOut, label('script', 'network_error')));
+    };
+
+    const submitMemberForm = async (form, url) => {
+        if (!memberID()) {
+            showError(memberOut, label('script', 'enter_member_id'));
+            return;
+        }
+
+        const body = new FormData(form);
+        body.append('staffMemberID', memberID());
+
+        try {
+            const res = await fetch(url, { method: 'POST', body, credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                showError(memberOut, json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+            form.reset();
+            loadMember();
+            load();
+        } catch {
+            showError(memberOut, label('script', 'network_error'));
+        }
+    };
+
+    if (shiftForm) {
+        shiftForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            submitMemberForm(shiftForm, '/api/staff/users/staff/schedule');
+        });
+    }
+    if (absenceForm) {
+        absenceForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            submitMemberForm(absenceForm, '/api/staff/users/staff/absences');
+        });
+    }
+
+    const visitsList = document.getElementById("visits-list");
+    const visitsOut = document.getElementById("visits-output");
+    const visitsRefreshBtn = document.getElementById("visits-refresh");
+    const visitsFeedBtn = document.getElementById("visits-feed");
+
+    const loadVisits = async () => {
+        if (!visitsList) return;
+        visitsList.innerHTML = '';
+        if (visitsOut) { visitsOut.textContent = ''; visitsOut.className = 'form-output'; }
+
+        try {
+            const res = await fetch('/api/staff/appointments', { credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                showError(visitsOut, json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+
+            const items = json.appointments || [];
+            if (!items.length) { visitsList.textContent = label('script', 'no_visits'); return; }
+
+            items.forEach(a => {
+                const row = document.createElement('div');
+                row.className = 'card';
+                row.style.margin = '6px 0';
+                row.textContent = new Date(a.StartsAt).toLocaleString() + ' – ' + new Date(a.EndsAt).toLocaleTimeString() +
+                    ' • ' + a.Address + ' • ' + a.Complaint;
+                visitsList.appendChild(row);
+            });
+        } catch {
+            showError(visitsOut, label('script', 'network_error'));
+        }
+    };
+
+    if (visitsFeedBtn) visitsFeedBtn.addEventListener('click', async () => {
+        if (!confirm(label('script', 'feed_confirm'))) return;
+        try {
+            const res = await fetch('/api/staff/appointments/feed', { method: 'POST', credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                showError(visitsOut, json.error || json.raw || ('HTTP ' + res.status));
+                return;
+            }
+            visitsOut.className = 'form-output';
+            visitsOut.textContent = json.url + ' — ' + json.message;
+        } catch {
+            showError(visitsOut, label('script', 'network_error'));
+        }
+    });
+
+    if (memberLoadBtn) memberLoadBtn.addEventListener('click', loadMember);
+    if (refreshBtn) refreshBtn.addEventListener('click', load);
+    if (visitsRefreshBtn) visitsRefreshBtn.addEventListener('click', loadVisits);
+
+    load();
+    loadVisits();
+});
@@ -0,0 +1,185 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const form = document.getElementById("category-form");
+    const formOut = document.getElementById("category-output");
+    const parentSelect = document.getElementById("category-parent");
+    const tree = document.getElementById("categories-tree");
+    const out = document.getElementById("categories-output");
+    const showInactive = document.getElementById("show-inactive");
+    const refreshBtn = document.getElementById("refresh-btn");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const post = async (url, fields, done) => {
+        const formData = new FormData();
+        Object.keys(fields).forEach(k => formData.append(k, fields[k]));
+        try {
+            const res = await fetch(url, { method: 'POST', body: formData, credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(out, done, false);
+            load();
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    const edit = (node) => {
+        const title = prompt(label('script', 'title_prompt'), node.Title);
+        if (title === null) return;
+        const sla = prompt(label('script', 'sla_prompt'), node.SLAHours ? String(node.SLAHours) : '');
+        if (sla === null) return;
+        const specs = prompt(label('script', 'specs_prompt'), (node.SpecializationIDs || []).join(', '));
+        if (specs === null) return;
+        post('/api/staff/categories/update', { id: node.ID, title: title.trim(), slaHours: sla.trim(), specializationIDs: specs }, label('script', 'saved'));
+    };
+
+    const renderNode = (node, depth) => {
+        const row = document.createElement('div');
+        row.className = 'card';
+        row.style.margin = '6px 0 6px ' + (depth * 24) + 'px';
+        if (!node.IsActive) row.style.opacity = '0.6';
+
+        const head = document.createElement('div');
+        head.style.fontWeight = '700';
+        head.textContent = node.Title + (node.IsActive ? '' : label('script', 'hidden_suffix'));
+        row.appendChild(head);
+
+        const details = document.createElement('div');
+        details.style.fontSize = '12px';
+        details.style.color = 'var(--muted)';
+        const specs = node.SpecializationIDs || [];
+        details.textContent = label('script', 'sla_prefix') + (node.SLAHours ? node.SLAHours + label('script', 'hours_suffix') : label('script', 'inherited')) +
+            label('script', 'specializations_sep') + (specs.length ? specs.join(', ') : label('script', 'inherited')) + ' • ID: ' + node.ID;
+        row.appendChild(details);
+
+        const actions = document.createElement('div');
+        actions.style.display = 'flex';
+        actions.style.gap = '8px';
+        actions.style.marginTop = '6px';
+
+        const editBtn = document.createElement('button');
+        editBtn.className = 'btn';
+        editBtn.textContent = label('script', 'edit');
+        editBtn.addEventListener('click', () => edit(node));
+        actions.appendChild(editBtn);
+
+        const subBtn = document.createElement('button');
+        subBtn.className = 'btn';
+        subBtn.textContent = label('script', 'add_subcategory');
+        subBtn.addEventListener('click', () => {
+            parentSelect.value = 'category:' + node.ID;
+            form.querySelector('input[name="title"]').focus();
+            window.scrollTo(0, 0);
+        });
+        actions.appendChild(subBtn);
+
+        const activeBtn = document.createElement('button');
+        activeBtn.className = 'btn';
+        activeBtn.textContent = label('script', node.IsActive ? 'hide' : 'show');
+        activeBtn.addEventListener('click', () => {
+            post('/api/staff/categories/active', { id: node.ID, active: String(!node.IsActive) }, label('script', node.IsActive ? 'hidden_done' : 'shown_done'));
+        });
+        actions.appendChild(activeBtn);
+
+        row.appendChild(actions);
+        tree.appendChild(row);
+
+        (node.Children || []).forEach(child => renderNode(child, depth + 1));
+    };
+
+    const addParentOptions = (nodes, depth) => {
+        nodes.forEach(node => {
+            const option = document.createElement('option');
+            option.value = 'category:' + node.ID;
+            option.textContent = '  '.repeat(depth) + node.Title;
+            parentSelect.appendChild(option);
+            addParentOptions(node.Children || [], depth + 1);
+        });
+    };
+
+    const render = (data) => {
+        tree.innerHTML = '';
+        const selected = parentSelect.value;
+        parentSelect.innerHTML = '';
+
+        (data.groups || []).forEach(group => {
+            const typeOption = document.createElement('option');
+            typeOption.value = 'type:' + group.Type;
+            typeOption.textContent = label('request_type', group.Type);
+            parentSelect.appendChild(typeOption);
+            addParentOptions(group.Categories || [], 1);
+
+            const heading = document.createElement('h2');
+            heading.className = 'card-title';
+            heading.textContent = label('request_type', group.Type);
+            tree.appendChild(heading);
+
+            if (!(group.Categories || []).length) {
+                const empty = document.createElement('div');
+                empty.style.color = 'var(--muted)';
+                empty.textContent = label('script', 'no_categories');
+                tree.appendChild(empty);
+            }
+            (group.Categories || []).forEach(node => renderNode(node, 0));
+        });
+
+        if ([...parentSelect.options].some(o => o.value === selected)) parentSelect.value = selected;
+    };
+
+    const load = async () => {
+        const url = new URL('/api/staff/categories', window.location.origin);
+        if (showInactive.checked) url.searchParams.set('inactive', 'true');
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            render(data);
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        const formData = new FormData(form);
+        const [kind, value] = String(formData.get('parent') || '').split(/:(.*)/s);
+        formData.delete('parent');
+        formData.append(kind === 'category' ? 'parentID' : 'type', value || '');
+
+        try {
+            const res = await fetch('/api/staff/categories', { method: 'POST', body: formData, credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(formOut, label('script', 'added').replace('%s', data.category.Title), false);
+            form.querySelector('input[name="title"]').value = '';
+            load();
+        } catch (err) {
+            showMessage(formOut, label('script', 'network_error'), true);
+        }
+    });
+
+    refreshBtn.addEventListener('click', () => load());
+    showInactive.addEventListener('change', () => load());
+
+    load();
+});
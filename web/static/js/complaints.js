@@ -0,0 +1,160 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 10;
+    let lastPages = 1;
+
+    const form = document.getElementById("complaint-form");
+    const formOut = document.getElementById("complaint-output");
+    const list = document.getElementById("complaints-list");
+    const out = document.getElementById("complaints-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+    const warnings = document.getElementById("warnings");
+    const warningsList = document.getElementById("warnings-list");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const when = (value) => new Date(value).toLocaleString();
+
+    const render = (data) => {
+        list.innerHTML = '';
+        const complaints = data.complaints || [];
+        const meta = data.meta || {};
+        lastPages = meta.pages || 1;
+        totalCountEl.textContent = String(meta.total || 0);
+        currentPageEl.textContent = String(page);
+        totalPagesEl.textContent = String(lastPages);
+        prevBtn.disabled = page <= 1;
+        nextBtn.disabled = page >= lastPages;
+
+        if (!complaints.length) {
+            showMessage(out, label('script', 'no_complaints'), false);
+            return;
+        }
+        showMessage(out, '', false);
+
+        complaints.forEach(cm => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const head = document.createElement('div');
+            head.style.fontWeight = '700';
+            head.textContent = label('complaint_kind', cm.Kind) + ' • ' + label('complaint_status', cm.Status) + ' • ' + cm.Address + (cm.Apartment ? label('script', 'apartment_short') + cm.Apartment : '');
+            card.appendChild(head);
+
+            const text = document.createElement('div');
+            text.textContent = cm.Text;
+            card.appendChild(text);
+
+            const details = document.createElement('div');
+            details.style.fontSize = '12px';
+            details.style.color = 'var(--muted)';
+            const parts = [label('script', 'complaint_sent_at') + when(cm.CreatedAt)];
+            if (cm.ReviewedAt) parts.push(label('script', 'complaint_reviewed_at') + when(cm.ReviewedAt));
+            if (cm.WarningAt) parts.push(label('script', 'complaint_warning_at') + when(cm.WarningAt));
+            if (cm.ResolvedAt) parts.push(label('script', 'complaint_resolved_at') + when(cm.ResolvedAt));
+            details.textContent = parts.join(' • ');
+            card.appendChild(details);
+
+            if (cm.Resolution) {
+                const resolution = document.createElement('div');
+                resolution.style.marginTop = '4px';
+                resolution.textContent = label('script', 'resolution') + cm.Resolution;
+                card.appendChild(resolution);
+            }
+
+            list.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const url = new URL('/api/resident/complaints', window.location.origin);
+        url.searchParams.set('page', String(page));
+        url.searchParams.set('limit', String(limit));
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            render(data);
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    const loadWarnings = async () => {
+        try {
+            const res = await fetch('/api/resident/complaints/warnings', { credentials: 'same-origin' });
+            if (!res.ok) return;
+            const data = await parse(res);
+            const items = data.warnings || [];
+            warningsList.innerHTML = '';
+            warnings.classList.toggle('hidden', !items.length);
+
+            items.forEach(w => {
+                const card = document.createElement('div');
+                card.className = 'card';
+                card.style.margin = '8px 0';
+                if (!w.Resolved) card.style.borderLeft = '4px solid var(--danger, #c0392b)';
+
+                const head = document.createElement('div');
+                head.style.fontWeight = '700';
+                head.textContent = label('complaint_kind', w.Kind) + label('script', 'house_sep') + w.HouseID + ' • ' + when(w.IssuedAt) + (w.Resolved ? label('script', 'warning_resolved') : '');
+                card.appendChild(head);
+
+                const text = document.createElement('div');
+                text.textContent = w.Text;
+                card.appendChild(text);
+
+                warningsList.appendChild(card);
+            });
+        } catch (err) {
+            // the warnings are only extra information on this page
+        }
+    };
+
+    form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        const btn = form.querySelector('button[type=submit]');
+        if (btn) btn.disabled = true;
+        try {
+            const res = await fetch('/api/resident/complaints', { method: 'POST', body: new FormData(form), credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(formOut, label('script', 'complaint_sent'), false);
+            form.reset();
+            page = 1;
+            load();
+        } catch (err) {
+            showMessage(formOut, label('script', 'network_error'), true);
+        } finally {
+            if (btn) btn.disabled = false;
+        }
+    });
+
+    prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+    loadWarnings();
+});
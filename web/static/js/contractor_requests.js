@@ -0,0 +1,240 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    let limit = 10;
+    let lastPages = 1;
+
+    const list = document.getElementById("requests-list");
+    const out = document.getElementById("requests-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const limitSelect = document.getElementById("limit-select");
+    const statusSelect = document.getElementById("status-select");
+
+    const completeModal = document.getElementById("complete-modal");
+    const completeForm = document.getElementById("complete-form");
+    const completeId = document.getElementById("complete-id");
+    const completeCancel = document.getElementById("complete-cancel");
+    const completeOutput = document.getElementById("complete-output");
+
+    const readJSON = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || "{}"); } catch { return { raw: text }; }
+    };
+
+    const postForm = async (url, fields) => {
+        const body = new FormData();
+        Object.keys(fields).forEach(k => body.append(k, fields[k]));
+        try {
+            const res = await fetch(url, { method: "POST", body, credentials: "same-origin" });
+            const data = await readJSON(res);
+            if (!res.ok) {
+                alert(data.error || data.raw || ("HTTP " + res.status));
+                return false;
+            }
+            return true;
+        } catch {
+            alert(label("script", "network_error"));
+            return false;
+        }
+    };
+
+    const toggleCompleteModal = (show) => {
+        if (!completeModal) return;
+        if (show) completeModal.classList.remove("hidden");
+        else completeModal.classList.add("hidden");
+        window.scrollTo(0, 0);
+    };
+
+    if (completeCancel) completeCancel.addEventListener("click", () => toggleCompleteModal(false));
+
+    if (completeForm) {
+        completeForm.addEventListener("submit", async (e) => {
+            e.preventDefault();
+            if (completeOutput) { completeOutput.textContent = label("script", "saving"); completeOutput.className = "form-output"; }
+            try {
+                const res = await fetch(completeForm.dataset.endpoint, { method: "POST", body: new FormData(completeForm), credentials: "same-origin" });
+                const data = await readJSON(res);
+                if (!res.ok) {
+                    if (completeOutput) { completeOutput.textContent = data.error || data.raw || ("HTTP " + res.status); completeOutput.className = "form-output error"; }
+                    return;
+                }
+                completeForm.reset();
+                toggleCompleteModal(false);
+                load();
+            } catch {
+                if (completeOutput) { completeOutput.textContent = label("script", "network_error"); completeOutput.className = "form-output error"; }
+            }
+        });
+    }
+
+    const clear = () => {
+        if (list) list.innerHTML = "";
+        if (out) { out.textContent = ""; out.className = "form-output"; }
+    };
+
+    const updateControls = () => {
+        if (currentPageEl) currentPageEl.textContent = String(page);
+        if (totalPagesEl) totalPagesEl.textContent = String(lastPages);
+        if (prevBtn) prevBtn.disabled = page <= 1;
+        if (nextBtn) nextBtn.disabled = page >= lastPages;
+    };
+
+    const buildUrl = () => {
+        const url = new URL("/api/contractor/requests", window.location.origin);
+        url.searchParams.set("page", String(page));
+        url.searchParams.set("limit", String(limit));
+        const status = statusSelect ? statusSelect.value : "";
+        if (status) url.searchParams.set("status", status);
+        return url.toString();
+    };
+
+    const showUpdates = async (id, container) => {
+        container.textContent = label("script", "loading");
+        try {
+            const res = await fetch("/api/contractor/requests/updates?id=" + encodeURIComponent(id), { credentials: "same-origin" });
+            const data = await readJSON(res);
+            if (!res.ok) { container.textContent = data.error || data.raw || ("HTTP " + res.status); return; }
+            const updates = data.updates || [];
+            container.innerHTML = "";
+            if (!updates.length) { container.textContent = label("script", "no_updates_yet"); return; }
+            updates.forEach(u => {
+                const row = document.createElement("div");
+                row.style.fontSize = "13px";
+                row.textContent = new Date(u.CreatedAt).toLocaleString() + " — " + u.Text;
+                container.appendChild(row);
+            });
+        } catch {
+            container.textContent = label("script", "network_error");
+        }
+    };
+
+    const render = (data) => {
+        clear();
+        const requests = data.requests || [];
+        const meta = data.meta || {};
+        if (typeof meta.page === "number") page = meta.page;
+        if (typeof meta.pages === "number") lastPages = meta.pages;
+        if (totalCountEl) totalCountEl.textContent = String(meta.total || 0);
+        updateControls();
+
+        if (!requests.length) {
+            if (out) out.textContent = label("script", "no_requests");
+            return;
+        }
+
+        requests.forEach(r => {
+            const card = document.createElement("div");
+            card.className = "card";
+            card.style.margin = "8px 0";
+
+            const head = document.createElement("div");
+            head.style.fontWeight = "700";
+            head.textContent = "ID: " + r.ID + label("script", "house_sep") + r.HouseID + " • " + label("request_type", r.RequestType) + " • " + label("request_status", r.Status);
+            card.appendChild(head);
+
+            const complaint = document.createElement("div");
+            complaint.style.margin = "6px 0";
+            complaint.textContent = r.Complaint || "";
+            card.appendChild(complaint);
+
+            const info = document.createElement("div");
+            info.style.fontSize = "12px";
+            info.style.color = "var(--muted)";
+            const parts = [];
+            if (r.TransferredAt) parts.push(label("script", "transferred_prefix") + new Date(r.TransferredAt).toLocaleString());
+            if (r.ContractorAcceptedAt) parts.push(label("script", "accepted_prefix") + new Date(r.ContractorAcceptedAt).toLocaleString());
+            if (r.ContractorDoneAt) parts.push(label("script", "done_prefix") + new Date(r.ContractorDoneAt).toLocaleString());
+            if (r.InvoiceAmount !== null && r.InvoiceAmount !== undefined) parts.push(label("script", "cost_prefix") + r.InvoiceAmount);
+            info.textContent = parts.join(" • ");
+            card.appendChild(info);
+
+            const updates = document.createElement("div");
+            updates.style.marginTop = "6px";
+            card.appendChild(updates);
+
+            const actions = document.createElement("div");
+            actions.className = "form-row";
+            actions.style.marginTop = "8px";
+            actions.style.gap = "8px";
+
+            const addButton = (text, handler) => {
+                const btn = document.createElement("button");
+                btn.className = "btn";
+                btn.type = "button";
+                btn.textContent = text;
+                btn.addEventListener("click", handler);
+                actions.appendChild(btn);
+            };
+
+            addButton(label("script", "updates"), () => showUpdates(r.ID, updates));
+
+            if (r.Status === "передана_организации") {
+                if (!r.ContractorAcceptedAt) {
+                    addButton(label("script", "accept"), async () => {
+                        if (await postForm("/api/contractor/requests/accept", { id: r.ID })) load();
+                    });
+                    addButton(label("script", "decline"), async () => {
+                        const reason = prompt(label("script", "decline_prompt"));
+                        if (!reason) return;
+                        if (await postForm("/api/contractor/requests/decline", { id: r.ID, reason })) load();
+                    });
+                }
+
+                addButton(label("script", "post_update"), async () => {
+                    const text = prompt(label("script", "update_prompt").replace("%s", r.ID));
+                    if (!text) return;
+                    if (await postForm("/api/contractor/requests/updates", { id: r.ID, text })) showUpdates(r.ID, updates);
+                });
+
+                if (r.ContractorAcceptedAt) {
+                    addButton(label("script", "complete"), () => {
+                        if (completeId) completeId.value = r.ID;
+                        if (completeOutput) { completeOutput.textContent = ""; completeOutput.className = "form-output"; }
+                        toggleCompleteModal(true);
+                    });
+                }
+            }
+
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = () => {
+        clear();
+        if (out) { out.textContent = label("script", "loading"); out.className = "form-output"; }
+        fetch(buildUrl(), { credentials: "same-origin" })
+            .then(async res => {
+                const data = await readJSON(res);
+                if (!res.ok) return Promise.reject(data);
+                return data;
+            })
+            .then(render)
+            .catch(err => {
+                clear();
+                if (out) {
+                    out.className = "form-output error";
+                    out.textContent = err && err.error ? err.error : (err && err.raw ? err.raw : String(err));
+                }
+                updateControls();
+            });
+    };
+
+    if (prevBtn) prevBtn.addEventListener("click", () => { if (page > 1) { page--; load(); } });
+    if (nextBtn) nextBtn.addEventListener("click", () => { if (page < lastPages) { page++; load(); } });
+    if (refreshBtn) refreshBtn.addEventListener("click", () => load());
+    if (statusSelect) statusSelect.addEventListener("change", () => { page = 1; load(); });
+    if (limitSelect) limitSelect.addEventListener("change", (e) => {
+        const v = parseInt(e.target.value || "10", 10);
+        if (!isNaN(v) && v > 0) { limit = v; page = 1; load(); }
+    });
+
+    load();
+});
@@ -0,0 +1,157 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const form = document.getElementById("request-form");
+    const out = document.getElementById("request-output");
+    const duplicates = document.getElementById("duplicates");
+    const duplicatesList = document.getElementById("duplicates-list");
+    const sendAnywayBtn = document.getElementById("send-anyway");
+    if (!form) return;
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (message, isError) => {
+        if (!out) return;
+        out.textContent = message;
+        out.className = isError ? 'form-output error' : 'form-output success';
+    };
+
+    const typeSelect = document.getElementById("request-type");
+    const categorySelect = document.getElementById("request-category");
+
+    // categories are grouped by the request type, picking one picks its type as well
+    const addCategoryOptions = (group, nodes, depth) => {
+        nodes.forEach(node => {
+            const option = document.createElement('option');
+            option.value = node.ID;
+            option.dataset.type = group.Type;
+            option.textContent = '\u00a0\u00a0'.repeat(depth) + node.Title;
+            group.element.appendChild(option);
+            addCategoryOptions(group, node.Children || [], depth + 1);
+        });
+    };
+
+    const loadCategories = async () => {
+        if (!categorySelect) return;
+        try {
+            const res = await fetch('/api/resident/categories', { credentials: 'same-origin' });
+            if (!res.ok) return;
+            const data = await parse(res);
+            (data.groups || []).forEach(group => {
+                if (!(group.Categories || []).length) return;
+                const optgroup = document.createElement('optgroup');
+                const typeOption = typeSelect ? [...typeSelect.options].find(o => o.value === group.Type) : null;
+                optgroup.label = typeOption ? typeOption.textContent : label('request_type', group.Type);
+                categorySelect.appendChild(optgroup);
+                addCategoryOptions({ Type: group.Type, element: optgroup }, group.Categories, 0);
+            });
+        } catch (err) {
+            // the request can be sent without a category
+        }
+    };
+
+    if (categorySelect && typeSelect) {
+        categorySelect.addEventListener('change', () => {
+            const option = categorySelect.selectedOptions[0];
+            if (option && option.dataset.type) typeSelect.value = option.dataset.type;
+        });
+        typeSelect.addEventListener('change', () => {
+            const option = categorySelect.selectedOptions[0];
+            if (option && option.dataset.type && option.dataset.type !== typeSelect.value) categorySelect.value = '';
+        });
+    }
+
+    const hideDuplicates = () => {
+        if (duplicates) duplicates.classList.add('hidden');
+        if (duplicatesList) duplicatesList.innerHTML = '';
+    };
+
+    // extra holds the answers to the server's questions: confirmed outage, confirmed duplicate or a request to join
+    const send = async (extra) => {
+        const body = new FormData(form);
+        Object.keys(extra).forEach(k => body.set(k, extra[k]));
+
+        const btn = form.querySelector("button[type=submit]");
+        if (btn) btn.disabled = true;
+        showMessage(label('script', 'submitting'), false);
+
+        try {
+            const res = await fetch(form.dataset.endpoint, { method: 'POST', body, credentials: 'same-origin' });
+            const data = await parse(res);
+
+            if (res.status === 409 && data.duplicates) {
+                showMessage(data.error, true);
+                renderDuplicates(data.duplicates, extra);
+                return;
+            }
+
+            if (res.status === 409 && data.confirm) {
+                if (window.confirm(data.error)) {
+                    await send({ ...extra, [data.confirm]: 'on' });
+                } else {
+                    showMessage(data.error, true);
+                }
+                return;
+            }
+
+            if (!res.ok) {
+                showMessage(data.error || ('HTTP ' + res.status), true);
+                return;
+            }
+
+            hideDuplicates();
+            showMessage(data.ParentID
+                ? label('script', 'request_joined').replace('%s', data.ParentID)
+                : label('script', 'request_created').replace('%s', data.ID), false);
+            form.reset();
+        } catch (err) {
+            showMessage(label('script', 'network_error'), true);
+        } finally {
+            if (btn) btn.disabled = false;
+        }
+    };
+
+    const renderDuplicates = (items, extra) => {
+        if (!duplicates || !duplicatesList) return;
+        duplicatesList.innerHTML = '';
+
+        items.forEach(d => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const text = document.createElement('div');
+            text.textContent = d.Complaint;
+            card.appendChild(text);
+
+            const meta = document.createElement('div');
+            meta.style.fontSize = '12px';
+            meta.style.color = 'var(--muted)';
+            meta.textContent = new Date(d.CreatedAt).toLocaleString() + ' • ' + label('request_status', d.Status) + ' • ' + label('script', 'reported_by') + ' ' + d.Reporters;
+            card.appendChild(meta);
+
+            const joinBtn = document.createElement('button');
+            joinBtn.className = 'btn';
+            joinBtn.style.marginTop = '6px';
+            joinBtn.textContent = label('script', 'join');
+            joinBtn.addEventListener('click', () => send({ ...extra, joinRequestID: d.ID }));
+            card.appendChild(joinBtn);
+
+            duplicatesList.appendChild(card);
+        });
+
+        if (sendAnywayBtn) sendAnywayBtn.onclick = () => send({ ...extra, confirmDuplicate: 'on' });
+        duplicates.classList.remove('hidden');
+    };
+
+    form.addEventListener('submit', (e) => {
+        e.preventDefault();
+        hideDuplicates();
+        send({});
+    });
+
+    loadCategories();
+});
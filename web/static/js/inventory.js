@@ -0,0 +1,392 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 20;
+    let lastPages = 1;
+    let locations = [];
+
+    const lowStockEl = document.getElementById("low-stock");
+    const locationForm = document.getElementById("location-form");
+    const locationOut = document.getElementById("location-output");
+    const locationsList = document.getElementById("locations-list");
+    const itemForm = document.getElementById("item-form");
+    const itemOut = document.getElementById("item-output");
+    const itemsList = document.getElementById("items-list");
+    const itemsOut = document.getElementById("items-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const searchInput = document.getElementById("item-search");
+    const locationFilter = document.getElementById("location-filter");
+    const lowOnly = document.getElementById("low-only");
+    const showInactive = document.getElementById("show-inactive");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+    const reportFrom = document.getElementById("report-from");
+    const reportTo = document.getElementById("report-to");
+    const reportLocation = document.getElementById("report-location");
+    const reportBtn = document.getElementById("report-btn");
+    const movementsBtn = document.getElementById("movements-btn");
+    const reportEl = document.getElementById("report");
+    const reportOut = document.getElementById("report-output");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const amount = (value) => Number(value || 0).toFixed(2);
+
+    const act = async (url, fields, done, method) => {
+        const body = new FormData();
+        Object.keys(fields || {}).forEach(k => body.append(k, fields[k]));
+        try {
+            const res = await fetch(url, { method: method || 'POST', body: method === 'DELETE' ? undefined : body, credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(itemsOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(itemsOut, done, false);
+            loadLocations();
+            load();
+            loadLowStock();
+        } catch (err) {
+            showMessage(itemsOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const chooseLocation = (question, options) => {
+        if (!options.length) {
+            alert(label('script', 'no_locations'));
+            return null;
+        }
+        const listing = options.map((l, i) => (i + 1) + '. ' + l.name).join('\n');
+        const answer = prompt(question + '\n' + listing, '1');
+        if (answer === null) return null;
+        const picked = options[Number(answer) - 1];
+        if (!picked) { alert(label('script', 'no_such_location')); return null; }
+        return picked.id;
+    };
+
+    const askQuantity = (unit) => {
+        const quantity = prompt(label('script', 'quantity_unit_prompt').replace('%s', unit), '1');
+        if (quantity === null) return null;
+        if (!(Number(quantity) > 0)) { alert(label('script', 'quantity_positive')); return null; }
+        return quantity.trim();
+    };
+
+    const withStock = (item) => (item.Locations || []).map(l => ({ id: l.LocationID, name: l.LocationName + ' (' + amount(l.Quantity) + ')' }));
+    const allLocations = () => locations.map(l => ({ id: l.ID, name: l.Name }));
+
+    const receive = (item) => {
+        const locationID = chooseLocation(label('script', 'receive_at').replace('%s', item.Name), allLocations());
+        if (!locationID) return;
+        const quantity = askQuantity(item.Unit);
+        if (!quantity) return;
+        const comment = prompt(label('script', 'receive_comment'), '');
+        if (comment === null) return;
+        act('/api/staff/inventory/receive', { itemID: item.ID, locationID, quantity, comment }, label('script', 'received'));
+    };
+
+    const writeOff = (item) => {
+        const locationID = chooseLocation(label('script', 'write_off_from').replace('%s', item.Name), withStock(item));
+        if (!locationID) return;
+        const quantity = askQuantity(item.Unit);
+        if (!quantity) return;
+        const comment = prompt(label('script', 'reason'), '');
+        if (!comment) return;
+        act('/api/staff/inventory/writeoff', { itemID: item.ID, locationID, quantity, comment }, label('script', 'written_off'));
+    };
+
+    const transfer = (item) => {
+        const locationID = chooseLocation(label('script', 'move_from').replace('%s', item.Name), withStock(item));
+        if (!locationID) return;
+        const toLocationID = chooseLocation(label('script', 'move_to'), allLocations().filter(l => l.id !== locationID));
+        if (!toLocationID) return;
+        const quantity = askQuantity(item.Unit);
+        if (!quantity) return;
+        act('/api/staff/inventory/transfer', { itemID: item.ID, locationID, toLocationID, quantity }, label('script', 'moved'));
+    };
+
+    const edit = (item, active) => {
+        const fields = { id: item.ID, name: item.Name, unit: item.Unit, unitPrice: item.UnitPrice, minStock: item.MinStock, active };
+        if (active) {
+            const unitPrice = prompt(label('script', 'unit_price_of').replace('%s', item.Name), amount(item.UnitPrice));
+            if (unitPrice === null) return;
+            const minStock = prompt(label('script', 'low_stock_at'), amount(item.MinStock));
+            if (minStock === null) return;
+            fields.unitPrice = unitPrice.trim();
+            fields.minStock = minStock.trim();
+        } else if (!confirm(label('script', 'archive_confirm').replace('%s', item.Name))) {
+            return;
+        }
+        act('/api/staff/inventory/items/update', fields, label('script', 'updated'));
+    };
+
+    const button = (label, handler) => {
+        const btn = document.createElement('button');
+        btn.className = 'btn';
+        btn.style.marginRight = '6px';
+        btn.textContent = label;
+        btn.addEventListener('click', handler);
+        return btn;
+    };
+
+    const renderItems = (items) => {
+        if (!itemsList) return;
+        itemsList.innerHTML = '';
+
+        if (!items.length) {
+            const empty = document.createElement('div');
+            empty.style.color = 'var(--muted)';
+            empty.textContent = label('script', 'no_items');
+            itemsList.appendChild(empty);
+            return;
+        }
+
+        items.forEach(item => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const title = document.createElement('div');
+            title.style.fontWeight = '700';
+            title.textContent = item.Name + ' — ' + amount(item.Total) + ' ' + item.Unit + (item.Low ? label('script', 'low_suffix') : '') + (item.IsActive ? '' : label('script', 'archived_suffix'));
+            if (item.Low) title.style.color = 'var(--danger, #c0392b)';
+            card.appendChild(title);
+
+            const meta = document.createElement('div');
+            meta.style.fontSize = '14px';
+            meta.style.color = 'var(--muted)';
+            const where = (item.Locations || []).map(l => l.LocationName + ': ' + amount(l.Quantity)).join(', ');
+            meta.textContent = label('script', 'item_price').replace('%s', amount(item.UnitPrice)).replace('%s', item.Unit).replace('%s', amount(item.MinStock)) +
+                (where ? ' • ' + where : label('script', 'out_of_stock'));
+            card.appendChild(meta);
+
+            const actions = document.createElement('div');
+            actions.style.marginTop = '6px';
+            if (item.IsActive) {
+                actions.appendChild(button(label('script', 'receive'), () => receive(item)));
+                actions.appendChild(button(label('script', 'write_off'), () => writeOff(item)));
+                actions.appendChild(button(label('script', 'move'), () => transfer(item)));
+                actions.appendChild(button(label('script', 'edit'), () => edit(item, true)));
+                actions.appendChild(button(label('script', 'archive'), () => edit(item, false)));
+            } else {
+                actions.appendChild(button(label('script', 'restore'), () => act('/api/staff/inventory/items/update',
+                    { id: item.ID, name: item.Name, unit: item.Unit, unitPrice: item.UnitPrice, minStock: item.MinStock, active: true }, label('script', 'restored'))));
+            }
+            card.appendChild(actions);
+
+            itemsList.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const params = new URLSearchParams({ page: page, limit: limit });
+        if (searchInput && searchInput.value.trim()) params.set('search', searchInput.value.trim());
+        if (locationFilter && locationFilter.value) params.set('locationID', locationFilter.value);
+        if (lowOnly && lowOnly.checked) params.set('low', 'true');
+        if (showInactive && showInactive.checked) params.set('inactive', 'true');
+
+        try {
+            const res = await fetch('/api/staff/inventory/items?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(itemsOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+
+            const meta = data.meta || {};
+            lastPages = meta.pages || 1;
+            if (totalCountEl) totalCountEl.textContent = meta.total ?? 0;
+            if (currentPageEl) currentPageEl.textContent = page;
+            if (totalPagesEl) totalPagesEl.textContent = lastPages;
+
+            renderItems(data.items || []);
+        } catch (err) {
+            showMessage(itemsOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const loadLowStock = async () => {
+        if (!lowStockEl) return;
+        try {
+            const res = await fetch('/api/staff/inventory/items?low=true&limit=50', { credentials: 'same-origin' });
+            const data = await parse(res);
+            const items = res.ok ? (data.items || []) : [];
+            lowStockEl.style.display = items.length ? '' : 'none';
+            lowStockEl.textContent = items.length
+                ? label('script', 'running_low') + items.map(i => i.Name + ' (' + label('script', 'left').replace('%s', amount(i.Total) + ' ' + i.Unit) + ')').join(', ')
+                : '';
+        } catch (err) {
+            lowStockEl.style.display = 'none';
+        }
+    };
+
+    const fillSelect = (select, emptyLabel) => {
+        if (!select) return;
+        const current = select.value;
+        select.innerHTML = '';
+        const any = document.createElement('option');
+        any.value = '';
+        any.textContent = emptyLabel;
+        select.appendChild(any);
+        locations.forEach(l => {
+            const option = document.createElement('option');
+            option.value = l.ID;
+            option.textContent = l.Name;
+            select.appendChild(option);
+        });
+        select.value = current;
+    };
+
+    const loadLocations = async () => {
+        try {
+            const res = await fetch('/api/staff/inventory/locations', { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(locationOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            locations = data.locations || [];
+        } catch (err) {
+            showMessage(locationOut, label('script', 'network_error'), true);
+            return;
+        }
+
+        fillSelect(locationFilter, label('script', 'any'));
+        fillSelect(reportLocation, label('script', 'all'));
+
+        if (!locationsList) return;
+        locationsList.innerHTML = '';
+        if (!locations.length) {
+            locationsList.textContent = label('script', 'no_locations');
+            return;
+        }
+        locations.forEach(l => {
+            const row = document.createElement('div');
+            row.style.margin = '4px 0';
+            row.textContent = l.Name + (l.HouseID ? label('script', 'house_sep') + l.HouseID : '') + ' ';
+            row.appendChild(button(label('script', 'delete'), () => {
+                if (!confirm(label('script', 'delete_location_confirm').replace('%s', l.Name))) return;
+                act('/api/staff/inventory/locations/' + encodeURIComponent(l.ID), {}, label('script', 'deleted'), 'DELETE');
+            }));
+            locationsList.appendChild(row);
+        });
+    };
+
+    const periodParams = () => {
+        const params = new URLSearchParams();
+        if (reportFrom && reportFrom.value) params.set('from', reportFrom.value);
+        if (reportTo && reportTo.value) params.set('to', reportTo.value);
+        if (reportLocation && reportLocation.value) params.set('locationID', reportLocation.value);
+        return params;
+    };
+
+    const line = (text, bold) => {
+        const row = document.createElement('div');
+        row.style.margin = '4px 0';
+        if (bold) row.style.fontWeight = '700';
+        row.textContent = text;
+        return row;
+    };
+
+    const loadReport = async () => {
+        if (!reportEl) return;
+        reportEl.innerHTML = '';
+        showMessage(reportOut, label('script', 'loading'), false);
+        try {
+            const res = await fetch('/api/staff/inventory/report?' + periodParams().toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(reportOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            const rows = data.rows || [];
+            showMessage(reportOut, rows.length ? '' : label('script', 'no_items'), false);
+            if (!rows.length) return;
+
+            reportEl.appendChild(line(label('script', 'stock_report_header'), true));
+            rows.forEach(r => {
+                reportEl.appendChild(line(r.Name + ' (' + r.Unit + '): ' + amount(r.Opening) + ' + ' + amount(r.Received) +
+                    ' − ' + amount(r.Used) + ' − ' + amount(r.WrittenOff) + ' ± ' + amount(r.Transferred) + ' = ' + amount(r.Closing)));
+            });
+        } catch (err) {
+            showMessage(reportOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const loadMovements = async () => {
+        if (!reportEl) return;
+        reportEl.innerHTML = '';
+        showMessage(reportOut, label('script', 'loading'), false);
+        const params = periodParams();
+        params.set('limit', '100');
+        try {
+            const res = await fetch('/api/staff/inventory/movements?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(reportOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            const movements = data.movements || [];
+            const meta = data.meta || {};
+            showMessage(reportOut, movements.length ? label('script', 'showing_of').replace('%s', movements.length).replace('%s', meta.total ?? 0) : label('script', 'no_movements'), false);
+
+            movements.forEach(m => {
+                const sign = m.Quantity > 0 ? '+' : '';
+                reportEl.appendChild(line(new Date(m.CreatedAt).toLocaleString() + ' • ' + label('movement_kind', m.Kind) + ' • ' + m.ItemName + ' ' +
+                    sign + amount(m.Quantity) + ' ' + m.Unit + ' • ' + (m.LocationName || label('script', 'deleted_location')) +
+                    (m.RequestID ? label('script', 'request_sep') + m.RequestID : '') + (m.ReturnedAt ? label('script', 'returned_suffix') : '') +
+                    ' • ' + m.Author + (m.Comment ? ' • ' + m.Comment : '')));
+            });
+        } catch (err) {
+            showMessage(reportOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const submitForm = (form, out, url, done, after) => {
+        if (!form) return;
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            showMessage(out, label('script', 'saving'), false);
+            try {
+                const res = await fetch(url, { method: 'POST', credentials: 'same-origin', body: new FormData(form) });
+                const data = await parse(res);
+                if (!res.ok) {
+                    showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                    return;
+                }
+                showMessage(out, done, false);
+                form.reset();
+                after();
+            } catch (err) {
+                showMessage(out, label('script', 'network_error'), true);
+            }
+        });
+    };
+
+    submitForm(locationForm, locationOut, '/api/staff/inventory/locations', label('script', 'location_added'), loadLocations);
+    submitForm(itemForm, itemOut, '/api/staff/inventory/items', label('script', 'item_added'), load);
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => { page = 1; load(); });
+    if (lowOnly) lowOnly.addEventListener('change', () => { page = 1; load(); });
+    if (showInactive) showInactive.addEventListener('change', () => { page = 1; load(); });
+    if (prevBtn) prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    if (nextBtn) nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+    if (reportBtn) reportBtn.addEventListener('click', loadReport);
+    if (movementsBtn) movementsBtn.addEventListener('click', loadMovements);
+
+    loadLocations();
+    load();
+    loadLowStock();
+});
@@ -0,0 +1,97 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const list = document.getElementById("lockouts-list");
+    const out = document.getElementById("lockouts-output");
+    const totalCountEl = document.getElementById("total-count");
+    const refreshBtn = document.getElementById("refresh-btn");
+
+    const clear = () => {
+        if (list) list.innerHTML = "";
+        if (out) { out.textContent = ""; out.className = "form-output"; }
+    };
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const renderLockouts = (data) => {
+        clear();
+
+        const lockouts = data.lockouts || [];
+        if (totalCountEl) totalCountEl.textContent = String(lockouts.length);
+
+        if (!lockouts.length) {
+            if (out) out.textContent = label('script', 'no_lockouts');
+            return;
+        }
+
+        lockouts.forEach(l => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const lockedUntil = l.lockedUntil ? (new Date(l.lockedUntil)).toLocaleString() : '';
+            const lastFailure = l.lastFailure ? (new Date(l.lastFailure)).toLocaleString() : '';
+
+            card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
+                '<span style="color:var(--muted);">' + label('script', 'key') + ' </span>' + (l.key || '') +
+                '<br><span style="color:var(--muted);">' + label('script', 'failures') + ' </span>' + (l.failures || 0) +
+                '</div>' +
+                '<div style="font-size:12px;color:var(--muted);">' + label('script', 'lockout_dates').replace('%s', lockedUntil).replace('%s', lastFailure) + '</div>';
+
+            const actions = document.createElement('div');
+            actions.style.marginTop = '8px';
+
+            const clearBtn = document.createElement('button');
+            clearBtn.className = 'btn';
+            clearBtn.textContent = label('script', 'clear');
+            clearBtn.addEventListener('click', async () => {
+                if (!confirm(label('script', 'clear_confirm').replace('%s', l.key))) return;
+                try {
+                    const res = await fetch('/api/staff/security/lockouts?key=' + encodeURIComponent(l.key), {
+                        method: 'DELETE',
+                        credentials: 'same-origin'
+                    });
+                    const json = await parse(res);
+                    if (!res.ok) {
+                        alert(json.error || json.message || label('script', 'clear_failed').replace('%s', res.status));
+                    } else {
+                        load();
+                    }
+                } catch (err) {
+                    alert(label('script', 'network_error'));
+                }
+            });
+
+            actions.appendChild(clearBtn);
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = () => {
+        clear();
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
+
+        fetch('/api/staff/security/lockouts', { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) return Promise.reject(json);
+                return json;
+            })
+            .then(renderLockouts)
+            .catch(err => {
+                clear();
+                if (out) {
+                    out.className = 'form-output error';
+                    out.textContent = err && err.error ? err.error : (err && err.message ? err.message : String(err));
+                }
+            });
+    };
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => load());
+
+    load();
+});
@@ -0,0 +1,199 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 20;
+    let lastPages = 1;
+
+    const form = document.getElementById("plan-form");
+    const formOut = document.getElementById("plan-output");
+    const previewBtn = document.getElementById("preview-btn");
+    const list = document.getElementById("plans-list");
+    const out = document.getElementById("plans-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const houseFilter = document.getElementById("house-filter");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const runBtn = document.getElementById("run-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const when = (value) => new Date(value).toLocaleString([], { year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' });
+
+    const act = async (url, options, done) => {
+        try {
+            const res = await fetch(url, { credentials: 'same-origin', ...options });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return null;
+            }
+            showMessage(out, done, false);
+            load();
+            return data;
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+            return null;
+        }
+    };
+
+    const setActive = (p, active) => {
+        const formData = new FormData();
+        formData.append('id', p.ID);
+        formData.append('active', String(active));
+        act('/api/staff/maintenance/plans/active', { method: 'POST', body: formData }, label('script', active ? 'resumed' : 'paused'));
+    };
+
+    const remove = (p) => {
+        if (!confirm(label('script', 'plan_delete_confirm').replace('%s', p.Title))) return;
+        act('/api/staff/maintenance/plans/' + encodeURIComponent(p.ID), { method: 'DELETE' }, label('script', 'deleted'));
+    };
+
+    const render = (data) => {
+        list.innerHTML = '';
+        const plans = data.plans || [];
+        const meta = data.meta || {};
+        lastPages = meta.pages || 1;
+        totalCountEl.textContent = String(meta.total || 0);
+        currentPageEl.textContent = String(page);
+        totalPagesEl.textContent = String(lastPages);
+        prevBtn.disabled = page <= 1;
+        nextBtn.disabled = page >= lastPages;
+
+        if (!plans.length) {
+            showMessage(out, label('script', 'no_plans'), false);
+            return;
+        }
+
+        plans.forEach(p => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const head = document.createElement('div');
+            head.style.fontWeight = '700';
+            head.textContent = p.Title + label('script', 'house_dash') + p.HouseID + (p.IsActive ? '' : label('script', 'paused_suffix'));
+            card.appendChild(head);
+
+            if (p.Description) {
+                const body = document.createElement('div');
+                body.style.whiteSpace = 'pre-wrap';
+                body.textContent = p.Description;
+                card.appendChild(body);
+            }
+
+            const meta = document.createElement('div');
+            meta.style.fontSize = '12px';
+            meta.style.color = 'var(--muted)';
+            meta.textContent = p.Rule + label('script', 'specialization_sep') + p.SpecializationID +
+                label('script', 'next_sep') + (p.NextRunAt ? when(p.NextRunAt) : label('script', 'schedule_over')) +
+                (p.LastRunAt ? label('script', 'last_sep') + when(p.LastRunAt) : '');
+            card.appendChild(meta);
+
+            const actions = document.createElement('div');
+            actions.className = 'form-row inline';
+            actions.style.marginTop = '6px';
+
+            const toggleBtn = document.createElement('button');
+            toggleBtn.className = 'btn';
+            toggleBtn.textContent = label('script', p.IsActive ? 'pause' : 'resume');
+            toggleBtn.addEventListener('click', () => setActive(p, !p.IsActive));
+            actions.appendChild(toggleBtn);
+
+            const requestsLink = document.createElement('a');
+            requestsLink.className = 'btn';
+            requestsLink.href = '/staff/requests/panel';
+            requestsLink.textContent = label('script', 'planned_requests');
+            actions.appendChild(requestsLink);
+
+            const deleteBtn = document.createElement('button');
+            deleteBtn.className = 'btn';
+            deleteBtn.textContent = label('script', 'delete');
+            deleteBtn.addEventListener('click', () => remove(p));
+            actions.appendChild(deleteBtn);
+
+            card.appendChild(actions);
+            list.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const url = new URL('/api/staff/maintenance/plans', window.location.origin);
+        url.searchParams.set('page', String(page));
+        url.searchParams.set('limit', String(limit));
+        if (houseFilter.value) url.searchParams.set('houseID', houseFilter.value);
+
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            render(data);
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    previewBtn.addEventListener('click', async () => {
+        const data = new FormData(form);
+        const url = new URL('/api/staff/maintenance/preview', window.location.origin);
+        url.searchParams.set('rule', data.get('rule') || '');
+        url.searchParams.set('startsAt', data.get('startsAt') || '');
+
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                showMessage(formOut, json.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            const dates = (json.occurrences || []).map(when);
+            showMessage(formOut, json.rule + ': ' + (dates.length ? dates.join(', ') : label('script', 'no_occurrences')), false);
+        } catch (err) {
+            showMessage(formOut, label('script', 'network_error'), true);
+        }
+    });
+
+    form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        try {
+            const res = await fetch('/api/staff/maintenance/plans', { method: 'POST', body: new FormData(form), credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(formOut, label('script', 'plan_created') + when(data.plan.NextRunAt), false);
+            form.reset();
+            page = 1;
+            load();
+        } catch (err) {
+            showMessage(formOut, label('script', 'network_error'), true);
+        }
+    });
+
+    runBtn.addEventListener('click', async () => {
+        const data = await act('/api/staff/maintenance/run', { method: 'POST' }, label('script', 'done'));
+        if (data) showMessage(out, label('script', 'generated').replace('%s', (data.generated || []).length), false);
+    });
+
+    refreshBtn.addEventListener('click', () => { page = 1; load(); });
+    prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+});
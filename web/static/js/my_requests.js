@@ -38,6 +38,8 @@ document.addEventListener('DOMContentLoaded', function () {
         clear();
 
         const requests = Array.isArray(data.requests) ? data.requests : [];
+        const ratings = data.ratings || {};
+        const slots = data.slots || {};
         const total = (data.meta && typeof data.meta.total === 'number') ? data.meta.total : (data.totalRequests || 0);
         const pageFromMeta = (data.meta && typeof data.meta.page === 'number') ? data.meta.page : page;
         const pages = (data.meta && typeof data.meta.pages === 'number') ? data.meta.pages : (Math.max(1, Math.ceil(total / limit)));
@@ -50,7 +52,7 @@ document.addEventListener('DOMContentLoaded', function () {
         if (totalPagesEl) totalPagesEl.textContent = String(pages);
 
         if (!requests.length) {
-            if (out) out.textContent = 'No requests found';
+            if (out) out.textContent = label('script', 'no_requests');
             updateControls();
             return;
         }
@@ -69,17 +71,140 @@ document.addEventListener('DOMContentLoaded', function () {
             const createdStr = created ? (new Date(created)).toLocaleString() : '';
 
             card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
-                '<span style="color:var(--muted);">ID: </span> ' + id + '<br><span style="color:var(--muted);">Тип: </span>' + type + '<br><span style="color:var(--muted);">Статус: </span>' + status +
+                '<span style="color:var(--muted);">ID: </span> ' + id + '<br><span style="color:var(--muted);">' + label('script', 'type') + ' </span>' + label('request_type', type) + '<br><span style="color:var(--muted);">' + label('script', 'status') + ' </span>' + label('request_status', status) +
+                '<br><span style="color:var(--muted);">' + label('script', 'priority') + ' </span>' + label('request_priority', r.Priority || 'обычная') +
                 '</div>' +
                 '<div style="margin-bottom:8px;">' + (complaint || '') + '</div>' +
                 '<div style="font-size:12px;color:var(--muted);">' + createdStr + '</div>';
 
+            if (status === 'выполнена') card.appendChild(ratingBlock(id, ratings[id]));
+            if (slots[id] && slots[id].length && status !== 'выполнена' && status !== 'отменена') card.appendChild(visitBlock(slots[id]));
+
             list.appendChild(card);
         });
 
         updateControls();
     };
 
+    const postAndReload = (url, fields) => {
+        const body = new FormData();
+        Object.keys(fields).forEach(k => body.append(k, fields[k]));
+
+        return fetch(url, { method: 'POST', body, credentials: 'same-origin' })
+            .then(res => res.json().catch(() => ({})).then(json => {
+                if (!res.ok) return Promise.reject(json);
+                return json;
+            }))
+            .then(() => load())
+            .catch(err => alert(err && err.error ? err.error : label('script', 'request_failed')));
+    };
+
+    const visitBlock = (requestSlots) => {
+        const block = document.createElement('div');
+        block.style.marginTop = '8px';
+
+        const title = document.createElement('div');
+        title.style.fontWeight = '700';
+        const chosen = requestSlots.find(sl => sl.Status === 'выбрано');
+        title.textContent = chosen ? label('script', 'visit_agreed') : label('script', 'visit_choose');
+        block.appendChild(title);
+
+        requestSlots.forEach(sl => {
+            const row = document.createElement('div');
+            row.style.display = 'flex';
+            row.style.gap = '8px';
+            row.style.alignItems = 'center';
+            row.style.margin = '4px 0';
+
+            const when = document.createElement('span');
+            when.textContent = new Date(sl.StartsAt).toLocaleString() + ' – ' + new Date(sl.EndsAt).toLocaleTimeString();
+            if (sl.Status === 'выбрано') when.style.fontWeight = '700';
+            row.appendChild(when);
+
+            const btn = document.createElement('button');
+            btn.className = 'btn';
+            if (sl.Status === 'выбрано') {
+                btn.textContent = label('script', 'visit_cancel');
+                btn.addEventListener('click', () => {
+                    const reason = prompt(label('script', 'visit_cancel_reason'), '');
+                    if (reason === null) return;
+                    postAndReload('/api/resident/appointments/cancel', { slotID: sl.ID, reason });
+                });
+            } else {
+                btn.textContent = chosen ? label('script', 'visit_move') : label('script', 'visit_pick');
+                btn.disabled = new Date(sl.StartsAt) <= new Date();
+                btn.addEventListener('click', () => postAndReload('/api/resident/appointments/pick', { slotID: sl.ID }));
+            }
+            row.appendChild(btn);
+
+            block.appendChild(row);
+        });
+
+        return block;
+    };
+
+    const ratingBlock = (requestId, rating) => {
+        const block = document.createElement('div');
+        block.style.marginTop = '8px';
+
+        const current = document.createElement('div');
+        current.style.fontSize = '13px';
+        current.textContent = rating
+            ? label('script', 'your_rating') + '★'.repeat(rating.Score) + '☆'.repeat(5 - rating.Score) + (rating.Comment ? ' — ' + rating.Comment : '')
+            : label('script', 'rate_prompt');
+        block.appendChild(current);
+
+        const form = document.createElement('form');
+        form.className = 'form-row inline';
+        form.style.gap = '8px';
+
+        const score = document.createElement('select');
+        score.name = 'score';
+        [5, 4, 3, 2, 1].forEach(v => {
+            const opt = document.createElement('option');
+            opt.value = String(v);
+            opt.textContent = v + ' ★';
+            if (rating && rating.Score === v) opt.selected = true;
+            score.appendChild(opt);
+        });
+        form.appendChild(score);
+
+        const comment = document.createElement('input');
+        comment.name = 'comment';
+        comment.type = 'text';
+        comment.maxLength = 1000;
+        comment.placeholder = label('script', 'comment_optional');
+        comment.value = rating && rating.Comment ? rating.Comment : '';
+        form.appendChild(comment);
+
+        const submit = document.createElement('button');
+        submit.type = 'submit';
+        submit.className = 'btn';
+        submit.textContent = rating ? label('script', 'rate_change') : label('script', 'rate');
+        form.appendChild(submit);
+
+        form.addEventListener('submit', (e) => {
+            e.preventDefault();
+            const body = new FormData(form);
+            body.append('requestID', requestId);
+            submit.disabled = true;
+
+            fetch('/api/resident/requests/rate', { method: 'POST', body, credentials: 'same-origin' })
+                .then(res => res.json().catch(() => ({})).then(json => {
+                    if (!res.ok) return Promise.reject(json);
+                    return json;
+                }))
+                .then(() => load())
+                .catch(err => {
+                    submit.disabled = false;
+                    alert(err && err.error ? err.error : label('script', 'rate_failed'));
+                });
+        });
+
+        block.appendChild(form);
+        return block;
+    };
+
     const buildUrl = () => {
         const url = new URL('/api/resident/requests', window.location.origin);
         url.searchParams.set('page', String(page));
@@ -91,7 +216,7 @@ document.addEventListener('DOMContentLoaded', function () {
     const load = () => {
         clear();
         if (out) {
-            out.textContent = 'Loading...';
+            out.textContent = label('script', 'loading');
             out.className = 'form-output';
         }
 
@@ -149,5 +274,25 @@ document.addEventListener('DOMContentLoaded', function () {
         }
     });
 
+    const feedBtn = document.getElementById('feed-btn');
+    const feedOut = document.getElementById('feed-output');
+    if (feedBtn) feedBtn.addEventListener('click', () => {
+        if (!confirm(label('script', 'feed_confirm'))) return;
+
+        fetch('/api/resident/appointments/feed', { method: 'POST', credentials: 'same-origin' })
+            .then(res => res.json().catch(() => ({})).then(json => {
+                if (!res.ok) return Promise.reject(json);
+                return json;
+            }))
+            .then(json => {
+                feedOut.className = 'form-output';
+                feedOut.textContent = json.url + ' — ' + json.message;
+            })
+            .catch(err => {
+                feedOut.className = 'form-output error';
+                feedOut.textContent = err && err.error ? err.error : label('script', 'feed_failed');
+            });
+    });
+
     load();
-});
\ No newline at end of file
+});
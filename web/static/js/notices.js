@@ -0,0 +1,57 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const section = document.getElementById("house-notices");
+    const list = document.getElementById("house-notices-list");
+    if (!section || !list) return;
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const when = (value) => new Date(value).toLocaleString([], { year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' });
+
+    const load = async () => {
+        try {
+            const res = await fetch('/api/resident/announcements', { credentials: 'same-origin' });
+            if (!res.ok) return;
+            const data = await parse(res);
+            const notices = data.announcements || [];
+            if (!notices.length) return;
+
+            list.innerHTML = '';
+            notices.forEach(n => {
+                const item = document.createElement('div');
+                item.style.margin = '8px 0';
+
+                const title = document.createElement('div');
+                title.style.fontWeight = '700';
+                if (n.Kind === 'отключение') title.style.color = 'var(--error)';
+                title.textContent = label('announcement_kind', n.Kind) + ': ' + n.Title;
+                item.appendChild(title);
+
+                const period = document.createElement('div');
+                period.style.fontSize = '14px';
+                period.style.color = 'var(--muted)';
+                period.textContent = when(n.StartsAt) + ' — ' + when(n.EndsAt) + label('script', 'houses_sep') + (n.HouseIDs || []).join(', ');
+                item.appendChild(period);
+
+                if (n.Body) {
+                    const body = document.createElement('div');
+                    body.style.whiteSpace = 'pre-wrap';
+                    body.textContent = n.Body;
+                    item.appendChild(body);
+                }
+
+                list.appendChild(item);
+            });
+
+            section.classList.remove('hidden');
+        } catch (err) {
+            // notices are a hint, the page works without them
+        }
+    };
+
+    load();
+});
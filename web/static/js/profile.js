@@ -0,0 +1,157 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const phoneEl = document.getElementById("profile-phone");
+    const form = document.getElementById("profile-form");
+    const nameInput = document.getElementById("profile-name");
+    const emailInput = document.getElementById("profile-email");
+    const contactSelect = document.getElementById("profile-contact");
+    const formOut = document.getElementById("profile-output");
+
+    const housesList = document.getElementById("houses-list");
+    const patternInput = document.getElementById("house-pattern");
+    const searchBtn = document.getElementById("house-search");
+    const results = document.getElementById("house-results");
+    const linkRequests = document.getElementById("link-requests");
+    const out = document.getElementById("houses-output");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const row = (text) => {
+        const div = document.createElement('div');
+        div.style.fontSize = '14px';
+        div.style.margin = '4px 0';
+        div.textContent = text;
+        return div;
+    };
+
+    const render = (data) => {
+        const r = data.resident || {};
+        if (phoneEl) phoneEl.textContent = r.Phone || '—';
+        if (nameInput) nameInput.value = r.FullName || '';
+        if (emailInput) emailInput.value = r.Email || '';
+        if (contactSelect && r.PreferredContact) contactSelect.value = r.PreferredContact;
+
+        if (housesList) {
+            housesList.innerHTML = '';
+            const houses = data.houses || [];
+            if (!houses.length) housesList.appendChild(row(label('script', 'no_houses_linked')));
+            houses.forEach(h => housesList.appendChild(row(h.ID + ' • ' + h.Address)));
+        }
+
+        if (linkRequests) {
+            linkRequests.innerHTML = '';
+            const reqs = data.linkRequests || [];
+            if (!reqs.length) linkRequests.appendChild(row(label('script', 'no_link_requests')));
+            reqs.forEach(lr => {
+                let text = new Date(lr.CreatedAt).toLocaleString() + ' • ' + lr.Address + ' • ' + label('link_status', lr.Status);
+                if (lr.ReviewComment) text += ' • ' + lr.ReviewComment;
+                linkRequests.appendChild(row(text));
+            });
+        }
+    };
+
+    const load = async () => {
+        try {
+            const res = await fetch('/api/resident/profile', { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            render(data);
+        } catch (err) {
+            showMessage(formOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const requestLink = async (house) => {
+        const comment = prompt(label('script', 'link_comment'), '');
+        if (comment === null) return;
+
+        const formData = new FormData();
+        formData.append('houseID', house.ID);
+        formData.append('comment', comment);
+
+        try {
+            const res = await fetch('/api/resident/houses/link-requests', {
+                method: 'POST',
+                credentials: 'same-origin',
+                body: formData
+            });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(out, label('script', 'link_sent'), false);
+            load();
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    const search = async () => {
+        const params = new URLSearchParams({ page: 1, limit: 10, pattern: patternInput ? patternInput.value.trim() : '' });
+        try {
+            const res = await fetch('/api/resident/houses?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(out, data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+
+            if (results) {
+                results.innerHTML = '';
+                const houses = data.houses || [];
+                if (!houses.length) results.appendChild(row(label('script', 'nothing_found')));
+                houses.forEach(h => {
+                    const line = row(h.ID + ' • ' + h.Address + ' ');
+                    const btn = document.createElement('button');
+                    btn.className = 'btn';
+                    btn.textContent = label('script', 'link_request');
+                    btn.addEventListener('click', () => requestLink(h));
+                    line.appendChild(btn);
+                    results.appendChild(line);
+                });
+            }
+        } catch (err) {
+            showMessage(out, label('script', 'network_error'), true);
+        }
+    };
+
+    if (form) {
+        form.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            showMessage(formOut, label('script', 'saving'), false);
+            try {
+                const res = await fetch(form.dataset.endpoint || '/api/resident/profile', {
+                    method: 'POST',
+                    credentials: 'same-origin',
+                    body: new FormData(form)
+                });
+                const data = await parse(res);
+                if (!res.ok) {
+                    showMessage(formOut, data.error || (label('script', 'error') + ' ' + res.status), true);
+                    return;
+                }
+                showMessage(formOut, label('script', 'saved'), false);
+            } catch (err) {
+                showMessage(formOut, label('script', 'network_error'), true);
+            }
+        });
+    }
+
+    if (searchBtn) searchBtn.addEventListener('click', (e) => { e.preventDefault(); search(); });
+
+    load();
+});
@@ -0,0 +1,140 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const summaryForm = document.getElementById("summary-form");
+    const summaryList = document.getElementById("summary-list");
+    const summaryOut = document.getElementById("summary-output");
+    const overallEl = document.getElementById("overall");
+
+    const lowList = document.getElementById("low-list");
+    const lowOut = document.getElementById("low-output");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+
+    const limit = 10;
+    let page = 1;
+    let lastPages = 1;
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (el, message, isError) => {
+        if (!el) return;
+        el.textContent = message;
+        el.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    const periodParams = (url) => {
+        const data = new FormData(summaryForm);
+        ['from', 'to'].forEach(k => { if (data.get(k)) url.searchParams.set(k, data.get(k)); });
+        return data;
+    };
+
+    const stars = (score) => '★'.repeat(score) + '☆'.repeat(5 - score);
+
+    const loadSummary = async () => {
+        const url = new URL('/api/staff/analytics/ratings', window.location.origin);
+        const data = periodParams(url);
+        url.searchParams.set('groupBy', data.get('groupBy') || 'staff');
+
+        summaryList.innerHTML = '';
+        showMessage(summaryOut, label('script', 'loading'), false);
+
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) { showMessage(summaryOut, json.error || ('HTTP ' + res.status), true); return; }
+
+            const total = json.total || {};
+            overallEl.textContent = total.Count ? (label('script', 'ratings_average').replace('%s', total.Average).replace('%s', total.Count)) : label('script', 'no_ratings');
+
+            const groups = json.groups || [];
+            if (!groups.length) { showMessage(summaryOut, label('script', 'no_ratings_period'), false); return; }
+            showMessage(summaryOut, '', false);
+
+            groups.forEach(g => {
+                const row = document.createElement('div');
+                row.className = 'card';
+                row.style.margin = '6px 0';
+                row.textContent = g.Name + ' — ' + g.Average + ' (' + g.Count + ')';
+                summaryList.appendChild(row);
+            });
+        } catch {
+            showMessage(summaryOut, label('script', 'network_error'), true);
+        }
+    };
+
+    const updateControls = () => {
+        if (currentPageEl) currentPageEl.textContent = String(page);
+        if (totalPagesEl) totalPagesEl.textContent = String(lastPages);
+        if (prevBtn) prevBtn.disabled = page <= 1;
+        if (nextBtn) nextBtn.disabled = page >= lastPages;
+    };
+
+    const loadLow = async () => {
+        const url = new URL('/api/staff/analytics/ratings/list', window.location.origin);
+        periodParams(url);
+        url.searchParams.set('maxScore', '2');
+        url.searchParams.set('page', String(page));
+        url.searchParams.set('limit', String(limit));
+
+        lowList.innerHTML = '';
+        showMessage(lowOut, label('script', 'loading'), false);
+
+        try {
+            const res = await fetch(url.toString(), { credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) { showMessage(lowOut, json.error || ('HTTP ' + res.status), true); return; }
+
+            lastPages = (json.meta && json.meta.pages) || 1;
+            const items = json.ratings || [];
+            if (!items.length) { showMessage(lowOut, label('script', 'no_low_ratings'), false); updateControls(); return; }
+            showMessage(lowOut, '', false);
+
+            items.forEach(r => {
+                const card = document.createElement('div');
+                card.className = 'card';
+                card.style.margin = '8px 0';
+
+                const head = document.createElement('div');
+                head.style.fontWeight = '700';
+                head.textContent = stars(r.Score) + label('script', 'request_dash') + r.RequestID;
+                card.appendChild(head);
+
+                if (r.Comment) {
+                    const comment = document.createElement('div');
+                    comment.textContent = r.Comment;
+                    card.appendChild(comment);
+                }
+
+                const meta = document.createElement('div');
+                meta.style.fontSize = '12px';
+                meta.style.color = 'var(--muted)';
+                meta.textContent = new Date(r.CreatedAt).toLocaleString();
+                card.appendChild(meta);
+
+                lowList.appendChild(card);
+            });
+            updateControls();
+        } catch {
+            showMessage(lowOut, label('script', 'network_error'), true);
+        }
+    };
+
+    summaryForm.addEventListener('submit', (e) => {
+        e.preventDefault();
+        page = 1;
+        loadSummary();
+        loadLow();
+    });
+
+    if (prevBtn) prevBtn.addEventListener('click', () => { if (page > 1) { page--; loadLow(); } });
+    if (nextBtn) nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; loadLow(); } });
+
+    loadSummary();
+    loadLow();
+});
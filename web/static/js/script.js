@@ -1,6 +1,26 @@
 "use strict";
 
 (() => {
+    // every same-origin request that changes something has to carry the session CSRF token
+    const csrfMeta = document.querySelector('meta[name="csrf-token"]');
+    const csrfToken = csrfMeta ? csrfMeta.content : "";
+    const nativeFetch = window.fetch.bind(window);
+    const safeMethods = ["GET", "HEAD", "OPTIONS"];
+
+    window.fetch = (input, init = {}) => {
+        const isRequest = input instanceof Request;
+        const method = (init.method || (isRequest ? input.method : "GET")).toUpperCase();
+        const url = new URL(isRequest ? input.url : String(input), window.location.href);
+
+        if (!csrfToken || safeMethods.includes(method) || url.origin !== window.location.origin) {
+            return nativeFetch(input, init);
+        }
+
+        const headers = new Headers(init.headers || (isRequest ? input.headers : undefined));
+        headers.set("X-CSRF-Token", csrfToken);
+        return nativeFetch(input, { ...init, headers });
+    };
+
     const asJSON = async (res) => {
         const text = await res.text();
         try {
@@ -20,7 +40,7 @@
             e.preventDefault();
             const endpoint = form.dataset.endpoint || "Gay";
             if (!endpoint || endpoint === "Gay") {
-                out.textContent = "Endpoint is not set or there is no endpoint.";
+                out.textContent = label('script', 'endpoint_missing');
                 out.className = "form-output error";
                 return;
             }
@@ -28,29 +48,40 @@
             const btn = form.querySelector("button[type=submit]");
             if (btn)
                 btn.disabled = true;
-            out.textContent = "Submitting...";
+            out.textContent = label('script', 'submitting');
             out.className = "form-output";
 
             try {
-                const res = await fetch(endpoint, {
+                const body = new FormData(form);
+                const send = () => fetch(endpoint, {
                     method: "POST",
-                    body: new FormData(form),
+                    body: body,
                     credentials: "include"
                 });
 
-                const data = await asJSON(res);
+                let res = await send();
+                let data = await asJSON(res);
+
+                // the server names a field to set when the user has to confirm a warning, e.g. an outage in the house
+                if (res.status === 409 && data.confirm && window.confirm(data.error)) {
+                    body.set(data.confirm, "on");
+                    res = await send();
+                    data = await asJSON(res);
+                }
                 if (!res.ok) {
                     out.textContent = data.error || JSON.stringify(data) || `HTTP ${res.status}`;
                     out.className = "form-output error";
                 } else {
-                    out.textContent = "Success: " + (data.message || "");
-                    if (data.type && data.type.toLowerCase() === "login") {
+                    out.textContent = label('script', 'success') + (data.message || "");
+                    if (data.next) {
+                        window.location.href = data.next;
+                    } else if (data.type && data.type.toLowerCase() === "login") {
                         window.location.href = "/";
                     }
                     out.className = "form-output success";
                 }
             } catch (err) {
-                out.textContent = "Network error";
+                out.textContent = label('script', 'network_error');
                 out.className = "form-output error";
             } finally {
                 if (btn)
@@ -61,7 +92,12 @@
 
     handleSubmit("login-form", "login-output");
     handleSubmit("register-form", "register-output");
-    handleSubmit("request-form", "request-output");
+    handleSubmit("forgot-form", "forgot-output");
+    handleSubmit("reset-form", "reset-output");
+    handleSubmit("signup-form", "signup-output");
+    handleSubmit("signup-status-form", "signup-status-output");
+    handleSubmit("change-password-form", "change-password-output");
+    handleSubmit("two-factor-form", "two-factor-output");
 
     const initUserDropdown = () => {
         const toggleBtn = document.getElementById('user-toggle');
@@ -90,4 +126,28 @@
     };
 
     initUserDropdown();
-})();
\ No newline at end of file
+
+    const initLanguageSelect = () => {
+        const select = document.getElementById('language-select');
+        if (!select) return;
+
+        select.addEventListener('change', async () => {
+            const body = new FormData();
+            body.append('language', select.value);
+            try {
+                const res = await fetch('/api/resident/language', { method: 'POST', body: body, credentials: 'same-origin' });
+                if (res.ok) window.location.reload();
+            } catch (err) {
+                // the page simply stays in the current language
+            }
+        });
+    };
+
+    initLanguageSelect();
+})();
+
+// label translates a stored enum value, e.g. a request status, into the language of the page
+window.label = (group, value) => {
+    const labels = (window.I18N || {})[group] || {};
+    return labels[value] || value;
+};
\ No newline at end of file
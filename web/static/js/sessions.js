@@ -0,0 +1,120 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const list = document.getElementById("sessions-list");
+    const out = document.getElementById("sessions-output");
+    const totalCountEl = document.getElementById("total-count");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const revokeOthersBtn = document.getElementById("revoke-others-btn");
+
+    const clear = () => {
+        if (list) list.innerHTML = "";
+        if (out) { out.textContent = ""; out.className = "form-output"; }
+    };
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const escapeHtml = (value) => String(value || '')
+        .replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+
+    const renderSessions = (data) => {
+        clear();
+
+        const sessions = data.sessions || [];
+        if (totalCountEl) totalCountEl.textContent = String(sessions.length);
+
+        if (!sessions.length) {
+            if (out) out.textContent = label('script', 'no_sessions');
+            return;
+        }
+
+        sessions.forEach(s => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const isCurrent = s.id === data.current;
+            const createdAt = s.createdAt ? (new Date(s.createdAt)).toLocaleString() : '';
+            const lastSeen = s.lastSeen ? (new Date(s.lastSeen)).toLocaleString() : '';
+
+            card.innerHTML = '<div style="font-weight:700;margin-bottom:6px;">' +
+                escapeHtml(s.userAgent || label('script', 'unknown_browser')) + (isCurrent ? ' <span style="color:var(--muted);">' + label('script', 'this_session') + '</span>' : '') +
+                '<br><span style="color:var(--muted);">IP: </span>' + escapeHtml(s.ip) +
+                '</div>' +
+                '<div style="font-size:12px;color:var(--muted);">' + label('script', 'session_dates').replace('%s', createdAt).replace('%s', lastSeen) + '</div>';
+
+            if (!isCurrent) {
+                const actions = document.createElement('div');
+                actions.style.marginTop = '8px';
+
+                const revokeBtn = document.createElement('button');
+                revokeBtn.className = 'btn';
+                revokeBtn.textContent = label('script', 'revoke');
+                revokeBtn.addEventListener('click', async () => {
+                    if (!confirm(label('script', 'logout_session_confirm'))) return;
+                    try {
+                        const res = await fetch('/api/resident/sessions/' + encodeURIComponent(s.id), {
+                            method: 'DELETE',
+                            credentials: 'same-origin'
+                        });
+                        const json = await parse(res);
+                        if (!res.ok) {
+                            alert(json.error || json.message || label('script', 'revoke_failed').replace('%s', res.status));
+                        } else {
+                            load();
+                        }
+                    } catch (err) {
+                        alert(label('script', 'network_error'));
+                    }
+                });
+
+                actions.appendChild(revokeBtn);
+                card.appendChild(actions);
+            }
+
+            list.appendChild(card);
+        });
+    };
+
+    const load = () => {
+        clear();
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
+
+        fetch('/api/resident/sessions', { credentials: 'same-origin' })
+            .then(async res => {
+                const json = await parse(res);
+                if (!res.ok) return Promise.reject(json);
+                return json;
+            })
+            .then(renderSessions)
+            .catch(err => {
+                clear();
+                if (out) {
+                    out.className = 'form-output error';
+                    out.textContent = err && err.error ? err.error : (err && err.message ? err.message : String(err));
+                }
+            });
+    };
+
+    if (revokeOthersBtn) revokeOthersBtn.addEventListener('click', async () => {
+        if (!confirm(label('script', 'logout_others_confirm'))) return;
+        try {
+            const res = await fetch('/api/resident/sessions', { method: 'DELETE', credentials: 'same-origin' });
+            const json = await parse(res);
+            if (!res.ok) {
+                alert(json.error || json.message || label('script', 'revoke_failed').replace('%s', res.status));
+            } else {
+                load();
+            }
+        } catch (err) {
+            alert(label('script', 'network_error'));
+        }
+    });
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => load());
+
+    load();
+});
@@ -0,0 +1,177 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    let page = 1;
+    const limit = 20;
+    let lastPages = 1;
+
+    const list = document.getElementById("signups-list");
+    const out = document.getElementById("signups-output");
+    const totalCountEl = document.getElementById("total-count");
+    const currentPageEl = document.getElementById("current-page");
+    const totalPagesEl = document.getElementById("total-pages");
+    const statusSelect = document.getElementById("status-select");
+    const patternInput = document.getElementById("pattern-input");
+    const refreshBtn = document.getElementById("refresh-btn");
+    const prevBtn = document.getElementById("prev-page");
+    const nextBtn = document.getElementById("next-page");
+
+    const parse = async (res) => {
+        const text = await res.text();
+        try { return JSON.parse(text || '{}'); } catch { return { raw: text }; }
+    };
+
+    const showMessage = (message, isError) => {
+        if (!out) return;
+        out.textContent = message;
+        out.className = isError ? 'form-output error' : 'form-output';
+    };
+
+    // suggests the house when exactly one address matches the claim
+    const guessHouse = async (address) => {
+        const params = new URLSearchParams({ page: 1, limit: 2, pattern: address });
+        try {
+            const res = await fetch('/api/staff/houses/list?' + params.toString(), { credentials: 'same-origin' });
+            if (!res.ok) return '';
+            const data = await parse(res);
+            const houses = data.houses || [];
+            return houses.length === 1 ? String(houses[0].ID) : '';
+        } catch {
+            return '';
+        }
+    };
+
+    const review = async (s, approve) => {
+        const formData = new FormData();
+        formData.append('id', s.ID);
+        formData.append('decision', approve ? 'approve' : 'reject');
+
+        if (approve) {
+            const houseID = prompt(label('script', 'house_for_prompt').replace('%s', s.ClaimedAddress), await guessHouse(s.ClaimedAddress));
+            if (houseID === null) return;
+            formData.append('houseID', houseID.trim());
+            const comment = prompt(label('script', 'comment_optional_prompt'), '');
+            if (comment === null) return;
+            formData.append('comment', comment);
+        } else {
+            const reason = prompt(label('script', 'rejection_reason_prompt'), '');
+            if (reason === null) return;
+            formData.append('comment', reason);
+        }
+
+        try {
+            const res = await fetch('/api/staff/signups/review', {
+                method: 'POST',
+                credentials: 'same-origin',
+                body: formData
+            });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+            showMessage(label('script', approve ? 'signup_approved' : 'signup_rejected'), false);
+            load();
+        } catch (err) {
+            showMessage(label('script', 'network_error'), true);
+        }
+    };
+
+    const render = (signups) => {
+        if (!list) return;
+        list.innerHTML = '';
+
+        if (!signups.length) {
+            const empty = document.createElement('div');
+            empty.style.color = 'var(--muted)';
+            empty.textContent = label('script', 'no_signups');
+            list.appendChild(empty);
+            return;
+        }
+
+        signups.forEach(s => {
+            const card = document.createElement('div');
+            card.className = 'card';
+            card.style.margin = '8px 0';
+
+            const title = document.createElement('div');
+            title.style.fontWeight = '700';
+            title.textContent = s.FullName + ' (' + s.Phone + ')';
+            card.appendChild(title);
+
+            const claim = document.createElement('div');
+            claim.style.fontSize = '14px';
+            claim.textContent = label('script', 'claims_prefix') + s.ClaimedAddress + (s.Apartment ? label('script', 'apartment_short') + s.Apartment : '') +
+                ' • ' + new Date(s.CreatedAt).toLocaleString() + ' • ' + label('signup_status', s.Status);
+            card.appendChild(claim);
+
+            if (s.ReviewedBy) {
+                const reviewed = document.createElement('div');
+                reviewed.style.fontSize = '14px';
+                reviewed.style.color = 'var(--muted)';
+                let text = label('script', 'reviewed_by').replace('%s', s.ReviewedBy).replace('%s', new Date(s.ReviewedAt).toLocaleString());
+                if (s.HouseID) text += label('script', 'house_sep') + s.HouseID;
+                if (s.ReviewComment) text += ' • ' + s.ReviewComment;
+                reviewed.textContent = text;
+                card.appendChild(reviewed);
+            }
+
+            if (s.Status === 'на_рассмотрении') {
+                const actions = document.createElement('div');
+                actions.style.marginTop = '6px';
+
+                const approveBtn = document.createElement('button');
+                approveBtn.className = 'btn';
+                approveBtn.textContent = label('script', 'approve');
+                approveBtn.addEventListener('click', () => review(s, true));
+
+                const rejectBtn = document.createElement('button');
+                rejectBtn.className = 'btn';
+                rejectBtn.style.marginLeft = '6px';
+                rejectBtn.textContent = label('script', 'reject');
+                rejectBtn.addEventListener('click', () => review(s, false));
+
+                actions.appendChild(approveBtn);
+                actions.appendChild(rejectBtn);
+                card.appendChild(actions);
+            }
+
+            list.appendChild(card);
+        });
+    };
+
+    const load = async () => {
+        const params = new URLSearchParams({
+            page: page,
+            limit: limit,
+            status: statusSelect ? statusSelect.value : '',
+            pattern: patternInput ? patternInput.value.trim() : ''
+        });
+
+        try {
+            const res = await fetch('/api/staff/signups?' + params.toString(), { credentials: 'same-origin' });
+            const data = await parse(res);
+            if (!res.ok) {
+                showMessage(data.error || (label('script', 'error') + ' ' + res.status), true);
+                return;
+            }
+
+            const meta = data.meta || {};
+            lastPages = meta.pages || 1;
+            if (totalCountEl) totalCountEl.textContent = meta.total ?? 0;
+            if (currentPageEl) currentPageEl.textContent = page;
+            if (totalPagesEl) totalPagesEl.textContent = lastPages;
+
+            render(data.signups || []);
+        } catch (err) {
+            showMessage(label('script', 'network_error'), true);
+        }
+    };
+
+    if (refreshBtn) refreshBtn.addEventListener('click', () => { page = 1; load(); });
+    if (statusSelect) statusSelect.addEventListener('change', () => { page = 1; load(); });
+    if (prevBtn) prevBtn.addEventListener('click', () => { if (page > 1) { page--; load(); } });
+    if (nextBtn) nextBtn.addEventListener('click', () => { if (page < lastPages) { page++; load(); } });
+
+    load();
+});
@@ -87,7 +87,7 @@ document.addEventListener("DOMContentLoaded", () => {
         updateControls();
 
         if (!specs.length) {
-            if (out) out.textContent = 'No specializations found';
+            if (out) out.textContent = label('script', 'no_specializations');
             return;
         }
 
@@ -119,8 +119,8 @@ document.addEventListener("DOMContentLoaded", () => {
             copyBtn.style.marginLeft = '8px';
             copyBtn.style.padding = '2px 8px';
             copyBtn.style.fontSize = '12px';
-            copyBtn.title = 'Copy ID';
-            copyBtn.textContent = 'Copy';
+            copyBtn.title = label('script', 'copy_id');
+            copyBtn.textContent = label('script', 'copy');
 
             if (!idText) {
                 copyBtn.disabled = true;
@@ -128,14 +128,14 @@ document.addEventListener("DOMContentLoaded", () => {
                 copyBtn.addEventListener('click', async () => {
                     const prevText = copyBtn.textContent;
                     copyBtn.disabled = true;
-                    copyBtn.textContent = 'Copying...';
+                    copyBtn.textContent = label('script', 'copying');
                     try {
                         await copyToClipboard(idText);
-                        copyBtn.textContent = 'Copied';
-                        if (out) { out.textContent = 'ID copied to clipboard'; out.className = 'form-output success'; }
+                        copyBtn.textContent = label('script', 'copied');
+                        if (out) { out.textContent = label('script', 'id_copied'); out.className = 'form-output success'; }
                     } catch (err) {
-                        copyBtn.textContent = 'Failed';
-                        if (out) { out.textContent = 'Copy failed'; out.className = 'form-output error'; }
+                        copyBtn.textContent = label('script', 'failed');
+                        if (out) { out.textContent = label('script', 'copy_failed'); out.className = 'form-output error'; }
                     } finally {
                         setTimeout(() => {
                             copyBtn.disabled = false;
@@ -151,7 +151,7 @@ document.addEventListener("DOMContentLoaded", () => {
             const nameRow = document.createElement('div');
             const nameLabel = document.createElement('span');
             nameLabel.style.color = 'var(--muted)';
-            nameLabel.textContent = 'Name: ';
+            nameLabel.textContent = label('script', 'name_prefix');
             const nameValue = document.createElement('span');
             nameValue.textContent = titleText;
 
@@ -169,7 +169,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     const load = () => {
         clear();
-        if (out) { out.textContent = 'Loading...'; out.className = 'form-output'; }
+        if (out) { out.textContent = label('script', 'loading'); out.className = 'form-output'; }
 
         fetch(buildUrl(), { credentials: 'same-origin' })
             .then(async res => {
@@ -215,7 +215,7 @@ document.addEventListener("DOMContentLoaded", () => {
     if (createForm) {
         createForm.addEventListener('submit', async (e) => {
             e.preventDefault();
-            if (createOutput) { createOutput.textContent = 'Creating...'; createOutput.className = 'form-output'; }
+            if (createOutput) { createOutput.textContent = label('script', 'creating'); createOutput.className = 'form-output'; }
             const endpoint = createForm.dataset.endpoint || '/api/staff/specializations/create';
             const body = new FormData(createForm);
             try {
@@ -231,12 +231,12 @@ document.addEventListener("DOMContentLoaded", () => {
                     if (createOutput) { createOutput.textContent = data.error || data.raw || ('HTTP ' + res.status); createOutput.className = 'form-output error'; }
                     return;
                 }
-                if (createOutput) { createOutput.textContent = 'Created'; createOutput.className = 'form-output success'; }
+                if (createOutput) { createOutput.textContent = label('script', 'created'); createOutput.className = 'form-output success'; }
                 createForm.reset();
                 createModal.classList.add('hidden');
                 load();
             } catch (err) {
-                if (createOutput) { createOutput.textContent = 'Network error'; createOutput.className = 'form-output error'; }
+                if (createOutput) { createOutput.textContent = label('script', 'network_error'); createOutput.className = 'form-output error'; }
             }
         });
     }
@@ -0,0 +1,113 @@
+"use strict";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const statusEl = document.getElementById("tf-status");
+    const enrollBlock = document.getElementById("tf-enroll-block");
+    const secretBlock = document.getElementById("tf-secret-block");
+    const manageBlock = document.getElementById("tf-manage-block");
+    const codesBlock = document.getElementById("tf-codes-block");
+    const secretEl = document.getElementById("tf-secret");
+    const uriEl = document.getElementById("tf-uri");
+    const codesEl = document.getElementById("tf-codes");
+    const continueLink = document.getElementById("tf-continue");
+    const out = document.getElementById("tf-output");
+
+    const startBtn = document.getElementById("tf-start");
+    const confirmForm = document.getElementById("tf-confirm-form");
+    const confirmCode = document.getElementById("tf-confirm-code");
+    const manageCode = document.getElementById("tf-manage-code");
+    const regenerateBtn = document.getElementById("tf-regenerate");
+    const disableBtn = document.getElementById("tf-disable");
+
+    const setOutput = (text, cls) => {
+        if (!out) return;
+        out.textContent = text;
+        out.className = 'form-output' + (cls ? ' ' + cls : '');
+    };
+
+    const post = async (endpoint, code) => {
+        const body = new FormData();
+        if (code !== undefined) body.set('code', code);
+        const res = await fetch(endpoint, { method: 'POST', body, credentials: 'same-origin' });
+        const text = await res.text();
+        let data;
+        try { data = JSON.parse(text || '{}'); } catch { data = { raw: text }; }
+        if (!res.ok) return Promise.reject(data);
+        return data;
+    };
+
+    const showCodes = (codes, next) => {
+        if (!codesBlock || !codesEl) return;
+        codesEl.textContent = (codes || []).join('\n');
+        codesBlock.classList.remove('hidden');
+        if (next && continueLink) {
+            continueLink.href = next;
+            continueLink.classList.remove('hidden');
+        }
+    };
+
+    const loadStatus = async () => {
+        try {
+            const res = await fetch('/api/2fa/status', { credentials: 'same-origin' });
+            const data = await res.json();
+            if (!res.ok) {
+                setOutput(data.error || label('script', 'tfa_status_failed'), 'error');
+                return;
+            }
+
+            if (statusEl) statusEl.textContent = label('script', data.enabled ? 'tfa_enabled' : 'tfa_disabled') + (data.required ? label('script', 'tfa_required') : '');
+            if (enrollBlock) enrollBlock.classList.toggle('hidden', !!data.enabled);
+            if (manageBlock) manageBlock.classList.toggle('hidden', !data.enabled);
+            if (disableBtn) disableBtn.disabled = !!data.required;
+        } catch {
+            setOutput(label('script', 'network_error'), 'error');
+        }
+    };
+
+    if (startBtn) startBtn.addEventListener('click', async () => {
+        try {
+            const data = await post('/api/2fa/enroll');
+            if (secretEl) secretEl.textContent = data.secret || '';
+            if (uriEl) { uriEl.textContent = data.uri || ''; uriEl.href = data.uri || '#'; }
+            if (secretBlock) secretBlock.classList.remove('hidden');
+            setOutput('', '');
+        } catch (err) {
+            setOutput(err && err.error ? err.error : label('script', 'tfa_enroll_failed'), 'error');
+        }
+    });
+
+    if (confirmForm) confirmForm.addEventListener('submit', async () => {
+        try {
+            const data = await post('/api/2fa/enroll/confirm', confirmCode ? confirmCode.value.trim() : '');
+            if (enrollBlock) enrollBlock.classList.add('hidden');
+            if (statusEl) statusEl.textContent = label('script', 'tfa_enabled');
+            showCodes(data.recoveryCodes, data.next);
+            setOutput(data.message || label('script', 'tfa_enabled_done'), 'success');
+        } catch (err) {
+            setOutput(err && err.error ? err.error : label('script', 'tfa_enable_failed'), 'error');
+        }
+    });
+
+    if (regenerateBtn) regenerateBtn.addEventListener('click', async () => {
+        try {
+            const data = await post('/api/staff/2fa/recovery-codes', manageCode ? manageCode.value.trim() : '');
+            showCodes(data.recoveryCodes);
+            setOutput(label('script', 'tfa_codes_regenerated'), 'success');
+        } catch (err) {
+            setOutput(err && err.error ? err.error : label('script', 'tfa_codes_failed'), 'error');
+        }
+    });
+
+    if (disableBtn) disableBtn.addEventListener('click', async () => {
+        if (!confirm(label('script', 'tfa_disable_confirm'))) return;
+        try {
+            const data = await post('/api/staff/2fa/disable', manageCode ? manageCode.value.trim() : '');
+            setOutput(data.message || label('script', 'tfa_disabled_done'), 'success');
+            loadStatus();
+        } catch (err) {
+            setOutput(err && err.error ? err.error : label('script', 'tfa_disable_failed'), 'error');
+        }
+    });
+
+    loadStatus();
+});
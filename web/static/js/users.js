@@ -36,9 +36,13 @@ document.addEventListener("DOMContentLoaded", () => {
     const staffPhoneEl = document.getElementById("staff-phone");
     const staffFullEl = document.getElementById("staff-fullname");
     const staffStatusEl = document.getElementById("staff-status");
+    const staffRatingEl = document.getElementById("staff-rating");
     const specsList = document.getElementById("specs-list");
     const btnGetSpecs = document.getElementById("btn-get-specs");
     const btnAddSpec = document.getElementById("btn-add-spec");
+    const btnEditStaff = document.getElementById("btn-edit-staff");
+    const btnStaffStatus = document.getElementById("btn-staff-status");
+    const btnStaffHistory = document.getElementById("btn-staff-history");
 
     const createModal = document.getElementById("create-modal");
     const createTitle = document.getElementById("create-title");
@@ -80,7 +84,7 @@ document.addEventListener("DOMContentLoaded", () => {
         if (totalCountEl) totalCountEl.textContent = String(total);
         if (out) { out.className = 'form-output'; out.textContent = ''; }
         if (!phones.length) {
-            if (out) out.textContent = 'No users found';
+            if (out) out.textContent = label('script', 'no_users');
             updateControls();
             return;
         }
@@ -93,7 +97,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
             const title = document.createElement('div');
             title.style.fontWeight = '700';
-            title.innerHTML = '<span style="color:var(--muted);">Phone: </span>' + (phone || '—');
+            title.innerHTML = '<span style="color:var(--muted);">' + label('script', 'phone') + ' </span>' + (phone || '—');
             card.appendChild(title);
 
             const actions = document.createElement('div');
@@ -103,30 +107,94 @@ document.addEventListener("DOMContentLoaded", () => {
 
             const detailsBtn = document.createElement('button');
             detailsBtn.className = 'btn';
-            detailsBtn.textContent = 'Details';
+            detailsBtn.textContent = label('script', 'details');
             detailsBtn.addEventListener('click', () => openDetails(phone));
 
             const delBtn = document.createElement('button');
             delBtn.className = 'btn';
-            delBtn.textContent = 'Delete';
+            delBtn.textContent = label('script', 'delete');
             delBtn.addEventListener('click', async () => {
-                if (!confirm('Delete user ' + phone + '?')) return;
+                if (!confirm(label('script', 'delete_user_confirm').replace('%s', phone))) return;
                 try {
                     const res = await fetch('/api/staff/users/delete/' + encodeURIComponent(phone), { method: 'DELETE', credentials: 'same-origin' });
                     const text = await res.text();
                     let json;
                     try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
                     if (!res.ok) {
-                        alert(json.error || json.message || ('Delete failed: ' + res.status));
+                        alert(json.error || json.message || label('script', 'delete_failed').replace('%s', res.status));
                     } else {
                         load();
                     }
                 } catch (err) {
-                    alert('Network error');
+                    alert(label('script', 'network_error'));
+                }
+            });
+
+            const resetBtn = document.createElement('button');
+            resetBtn.className = 'btn';
+            resetBtn.textContent = label('script', 'reset_password');
+            resetBtn.addEventListener('click', async () => {
+                if (!confirm(label('script', 'reset_password_confirm').replace('%s', phone))) return;
+                try {
+                    const res = await fetch('/api/staff/users/password-reset/' + encodeURIComponent(phone), { method: 'POST', credentials: 'same-origin' });
+                    const text = await res.text();
+                    let json;
+                    try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                    alert(json.error || json.message || ('HTTP ' + res.status));
+                } catch (err) {
+                    alert(label('script', 'network_error'));
+                }
+            });
+
+            const resetTwoFactorBtn = document.createElement('button');
+            resetTwoFactorBtn.className = 'btn';
+            resetTwoFactorBtn.textContent = label('script', 'reset_tfa');
+            resetTwoFactorBtn.addEventListener('click', async () => {
+                if (!confirm(label('script', 'reset_tfa_confirm').replace('%s', phone))) return;
+                const code = prompt(label('script', 'reset_tfa_code'), '');
+                if (!code) return;
+                const body = new FormData();
+                body.append('phoneNumber', phone);
+                body.append('code', code.trim());
+                try {
+                    const res = await fetch('/api/staff/users/2fa/reset', { method: 'POST', body: body, credentials: 'same-origin' });
+                    const text = await res.text();
+                    let json;
+                    try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                    alert(json.error || json.message || ('HTTP ' + res.status));
+                } catch (err) {
+                    alert(label('script', 'network_error'));
+                }
+            });
+
+            const sessionsBtn = document.createElement('button');
+            sessionsBtn.className = 'btn';
+            sessionsBtn.textContent = label('script', 'logout_everywhere');
+            sessionsBtn.addEventListener('click', async () => {
+                try {
+                    const listRes = await fetch('/api/staff/users/sessions/' + encodeURIComponent(phone), { credentials: 'same-origin' });
+                    const listJson = await listRes.json().catch(() => ({}));
+                    if (!listRes.ok) {
+                        alert(listJson.error || ('HTTP ' + listRes.status));
+                        return;
+                    }
+                    const count = (listJson.sessions || []).length;
+                    if (!confirm(label('script', 'revoke_sessions_confirm').replace('%s', phone).replace('%s', count))) return;
+
+                    const res = await fetch('/api/staff/users/sessions/' + encodeURIComponent(phone), { method: 'DELETE', credentials: 'same-origin' });
+                    const text = await res.text();
+                    let json;
+                    try { json = JSON.parse(text || '{}'); } catch { json = { raw: text }; }
+                    alert(json.error || label('script', 'revoked_sessions').replace('%s', json.revoked || 0));
+                } catch (err) {
+                    alert(label('script', 'network_error'));
                 }
             });
 
             actions.appendChild(detailsBtn);
+            actions.appendChild(resetBtn);
+            actions.appendChild(resetTwoFactorBtn);
+            actions.appendChild(sessionsBtn);
             actions.appendChild(delBtn);
             card.appendChild(actions);
             listEl.appendChild(card);
@@ -137,7 +205,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     const load = () => {
         clearList();
-        if (out) { out.className = 'form-output'; out.textContent = 'Loading...'; }
+        if (out) { out.className = 'form-output'; out.textContent = label('script', 'loading'); }
         fetch(buildUrl(), { credentials: 'same-origin' })
             .then(async res => {
                 const text = await res.text();
@@ -179,13 +247,13 @@ document.addEventListener("DOMContentLoaded", () => {
     const openDetails = async (phone) => {
         resetModal();
         openModal();
-        if (modalOutput) { modalOutput.textContent = 'Loading...'; modalOutput.className = 'form-output'; }
+        if (modalOutput) { modalOutput.textContent = label('script', 'loading'); modalOutput.className = 'form-output'; }
 
         try {
             const res = await fetch('/api/staff/users/info/' + encodeURIComponent(phone), { method: 'GET', credentials: 'same-origin' });
             const data = await res.json();
             if (!res.ok) {
-                modalOutput.textContent = data.error || 'Failed to fetch details';
+                modalOutput.textContent = data.error || label('script', 'details_failed');
                 modalOutput.className = 'form-output error';
                 return;
             }
@@ -196,13 +264,13 @@ document.addEventListener("DOMContentLoaded", () => {
                 resPhoneEl.textContent = data.resident.Phone || data.resident.phone || '—';
                 resFullEl.textContent = data.resident.FullName || data.resident.full_name || '—';
                 btnGetHouses.onclick = async () => {
-                    housesList.textContent = 'Loading...';
+                    housesList.textContent = label('script', 'loading');
                     try {
                         const r = await fetch('/api/staff/users/resident/info?residentID=' + encodeURIComponent(resIdEl.textContent), { credentials: 'same-origin' });
                         const jd = await r.json();
-                        if (!r.ok) { housesList.textContent = jd.error || 'Failed'; return; }
+                        if (!r.ok) { housesList.textContent = jd.error || label('script', 'failed'); return; }
                         renderHouses(jd.houses || []);
-                    } catch (err) { housesList.textContent = 'Network error'; }
+                    } catch (err) { housesList.textContent = label('script', 'network_error'); }
                 };
                 if (btnAddHouse) {
                     btnAddHouse.onclick = () => openCreateModal('house');
@@ -214,31 +282,98 @@ document.addEventListener("DOMContentLoaded", () => {
                 staffIdEl.textContent = data.staff.ID || data.staff.id || String(data.staff.ID || data.staff.id || '');
                 staffPhoneEl.textContent = data.staff.Phone || data.staff.phone || '—';
                 staffFullEl.textContent = data.staff.FullName || data.staff.full_name || '—';
-                staffStatusEl.textContent = data.staff.Status || data.staff.status || '—';
+                staffStatusEl.textContent = label('staff_status', data.staff.Status || data.staff.status || '—');
+
+                if (staffRatingEl) {
+                    staffRatingEl.textContent = label('script', 'loading');
+                    fetch('/api/staff/users/staff/profile?staffMemberID=' + encodeURIComponent(staffIdEl.textContent), { credentials: 'same-origin' })
+                        .then(r => r.json())
+                        .then(jd => {
+                            const rating = jd.rating;
+                            staffRatingEl.textContent = rating && rating.Count ? label('script', 'ratings_average').replace('%s', rating.Average).replace('%s', rating.Count) : label('script', 'no_ratings_yet');
+                        })
+                        .catch(() => { staffRatingEl.textContent = '—'; });
+                }
 
                 btnGetSpecs.onclick = async () => {
-                    specsList.textContent = 'Loading...';
+                    specsList.textContent = label('script', 'loading');
                     try {
                         const r = await fetch('/api/staff/users/staff/info?staffMemberID=' + encodeURIComponent(staffIdEl.textContent), { credentials: 'same-origin' });
                         const jd = await r.json();
-                        if (!r.ok) { specsList.textContent = jd.error || 'Failed'; return; }
+                        if (!r.ok) { specsList.textContent = jd.error || label('script', 'failed'); return; }
                         renderSpecs(jd.specializations || []);
-                    } catch (err) { specsList.textContent = 'Network error'; }
+                    } catch (err) { specsList.textContent = label('script', 'network_error'); }
                 };
                 if (btnAddSpec) {
                     btnAddSpec.onclick = () => openCreateModal('spec');
                 }
+
+                const staffForm = async (url, fields) => {
+                    const body = new FormData();
+                    Object.keys(fields).forEach(k => body.append(k, fields[k]));
+                    const r = await fetch(url, { method: 'POST', body, credentials: 'same-origin' });
+                    const jd = await r.json().catch(() => ({}));
+                    if (!r.ok) { alert(jd.error || ('HTTP ' + r.status)); return null; }
+                    return jd;
+                };
+
+                if (btnEditStaff) {
+                    btnEditStaff.onclick = async () => {
+                        const fullName = prompt(label('script', 'full_name_prompt'), staffFullEl.textContent);
+                        if (fullName === null) return;
+                        const phone = prompt(label('script', 'phone_prompt'), staffPhoneEl.textContent);
+                        if (phone === null) return;
+                        try {
+                            const jd = await staffForm('/api/staff/users/staff/profile', { staffMemberID: staffIdEl.textContent, fullName, phone });
+                            if (!jd) return;
+                            staffFullEl.textContent = jd.staff.FullName;
+                            staffPhoneEl.textContent = jd.staff.Phone;
+                        } catch (err) { alert(label('script', 'network_error')); }
+                    };
+                }
+
+                if (btnStaffStatus) {
+                    btnStaffStatus.onclick = async () => {
+                        const status = prompt(label('script', 'staff_status_prompt'), '');
+                        if (!status) return;
+                        const reason = prompt(label('script', 'reason_optional'), '');
+                        if (reason === null) return;
+                        try {
+                            const jd = await staffForm('/api/staff/users/staff/status', { staffMemberID: staffIdEl.textContent, status: status.trim(), reason });
+                            if (!jd) return;
+                            const outcome = jd.outcome || {};
+                            staffStatusEl.textContent = label('staff_status', outcome.Change ? outcome.Change.To : status.trim());
+                            alert(label('script', 'reassigned').replace('%s', Object.keys(outcome.Reassigned || {}).length) +
+                                '\n' + label('script', 'unassigned').replace('%s', (outcome.Unassigned || []).length) +
+                                '\n' + label('script', 'deactivated_specs').replace('%s', outcome.DeactivatedSpecializations || 0));
+                        } catch (err) { alert(label('script', 'network_error')); }
+                    };
+                }
+
+                if (btnStaffHistory) {
+                    btnStaffHistory.onclick = async () => {
+                        try {
+                            const r = await fetch('/api/staff/users/staff/status-history?staffMemberID=' + encodeURIComponent(staffIdEl.textContent), { credentials: 'same-origin' });
+                            const jd = await r.json();
+                            if (!r.ok) { alert(jd.error || label('script', 'failed')); return; }
+                            const history = jd.history || [];
+                            alert(history.length
+                                ? history.map(h => new Date(h.CreatedAt).toLocaleString() + ': ' + label('staff_status', h.From) + ' → ' + label('staff_status', h.To) + label('script', 'changed_by') + h.ChangedBy + (h.Reason ? ' (' + h.Reason + ')' : '')).join('\n')
+                                : label('script', 'no_status_changes'));
+                        } catch (err) { alert(label('script', 'network_error')); }
+                    };
+                }
             }
 
             if (modalOutput) { modalOutput.textContent = ''; modalOutput.className = 'form-output'; }
         } catch (err) {
-            if (modalOutput) { modalOutput.textContent = 'Network error'; modalOutput.className = 'form-output error'; }
+            if (modalOutput) { modalOutput.textContent = label('script', 'network_error'); modalOutput.className = 'form-output error'; }
         }
     };
 
     const renderHouses = (houses) => {
         housesList.innerHTML = '';
-        if (!houses.length) { housesList.textContent = 'No houses'; return; }
+        if (!houses.length) { housesList.textContent = label('script', 'no_houses_linked_short'); return; }
         houses.forEach(h => {
             const row = document.createElement('div');
             row.style.display = 'flex';
@@ -254,16 +389,16 @@ document.addEventListener("DOMContentLoaded", () => {
 
             const delBtn = document.createElement('button');
             delBtn.className = 'btn';
-            delBtn.textContent = 'Delete';
+            delBtn.textContent = label('script', 'delete');
             delBtn.addEventListener('click', async () => {
-                if (!confirm('Remove house ' + (h.ID || h.id) + ' from resident?')) return;
+                if (!confirm(label('script', 'remove_house_confirm').replace('%s', h.ID || h.id))) return;
                 try {
                     const url = '/api/staff/users/resident/remove-house?residentID=' + encodeURIComponent(resIdEl.textContent) + '&houseID=' + encodeURIComponent(h.ID || h.id);
                     const r = await fetch(url, { method: 'DELETE', credentials: 'same-origin' });
                     const jd = await r.json();
-                    if (!r.ok) { alert(jd.error || 'Remove failed'); return; }
+                    if (!r.ok) { alert(jd.error || label('script', 'remove_failed')); return; }
                     btnGetHouses.click();
-                } catch (err) { alert('Network error'); }
+                } catch (err) { alert(label('script', 'network_error')); }
             });
 
             right.appendChild(delBtn);
@@ -275,7 +410,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     const renderSpecs = (specs) => {
         specsList.innerHTML = '';
-        if (!specs.length) { specsList.textContent = 'No specializations'; return; }
+        if (!specs.length) { specsList.textContent = label('script', 'no_specs'); return; }
         specs.forEach(s => {
             const row = document.createElement('div');
             row.style.display = 'flex';
@@ -291,16 +426,16 @@ document.addEventListener("DOMContentLoaded", () => {
 
             const deactBtn = document.createElement('button');
             deactBtn.className = 'btn';
-            deactBtn.textContent = 'Deactivate';
+            deactBtn.textContent = label('script', 'deactivate');
             deactBtn.addEventListener('click', async () => {
-                if (!confirm('Deactivate specialization ' + (s.ID || s.id) + '?')) return;
+                if (!confirm(label('script', 'deactivate_spec_confirm').replace('%s', s.ID || s.id))) return;
                 try {
                     const url = '/api/staff/users/staff/delete-spec?staffMemberID=' + encodeURIComponent(staffIdEl.textContent) + '&jobID=' + encodeURIComponent(s.ID || s.id);
                     const r = await fetch(url, { method: 'DELETE', credentials: 'same-origin' });
                     const jd = await r.json();
-                    if (!r.ok) { alert(jd.error || 'Deactivate failed'); return; }
+                    if (!r.ok) { alert(jd.error || label('script', 'deactivate_failed')); return; }
                     btnGetSpecs.click();
-                } catch (err) { alert('Network error'); }
+                } catch (err) { alert(label('script', 'network_error')); }
             });
 
             right.appendChild(deactBtn);
@@ -317,21 +452,21 @@ document.addEventListener("DOMContentLoaded", () => {
         createOutput.className = 'form-output';
 
         if (mode === 'house') {
-            createTitle.textContent = 'Add house';
-            createLabel.textContent = 'House ID';
+            createTitle.textContent = label('script', 'add_house');
+            createLabel.textContent = label('script', 'house_id');
             createField.type = 'number';
             createField.min = '1';
             createField.step = '1';
-            createField.placeholder = 'Enter house ID';
-            createHint.textContent = 'Assign a house by its numeric ID to this resident.';
+            createField.placeholder = label('script', 'enter_house_id');
+            createHint.textContent = label('script', 'add_house_hint');
         } else {
-            createTitle.textContent = 'Add specialization';
-            createLabel.textContent = 'Specialization ID';
+            createTitle.textContent = label('script', 'add_spec');
+            createLabel.textContent = label('script', 'spec_id');
             createField.type = 'text';
             createField.removeAttribute('min');
             createField.removeAttribute('step');
-            createField.placeholder = 'Enter specialization ID';
-            createHint.textContent = 'Assign a specialization by its string ID to this staff member.';
+            createField.placeholder = label('script', 'enter_spec_id');
+            createHint.textContent = label('script', 'add_spec_hint');
         }
         createModal.classList.remove('hidden');
         createField.focus();
@@ -365,36 +500,36 @@ document.addEventListener("DOMContentLoaded", () => {
                     const idVal = parseInt(createField.value.trim(), 10);
                     if (!Number.isFinite(idVal) || idVal <= 0) {
                         createOutput.className = 'form-output error';
-                        createOutput.textContent = 'Please enter a valid positive number.';
+                        createOutput.textContent = label('script', 'positive_number');
                         return;
                     }
                     const residentID = resIdEl.textContent.trim();
                     const url = '/api/staff/users/resident/add-house?residentID=' + encodeURIComponent(residentID);
                     const { ok, json } = await postForm(url, { houseID: idVal });
-                    if (!ok) throw new Error(json.error || 'Add house failed');
+                    if (!ok) throw new Error(json.error || label('script', 'add_house_failed'));
                     createOutput.className = 'form-output success';
-                    createOutput.textContent = 'House added.';
+                    createOutput.textContent = label('script', 'house_added');
                     btnGetHouses && btnGetHouses.click();
                     setTimeout(closeCreateModal, 400);
                 } else if (currentAddMode === 'spec') {
                     const specId = createField.value.trim();
                     if (!specId) {
                         createOutput.className = 'form-output error';
-                        createOutput.textContent = 'Please enter a specialization ID.';
+                        createOutput.textContent = label('script', 'enter_spec_id_please');
                         return;
                     }
                     const staffID = staffIdEl.textContent.trim();
                     const url = '/api/staff/users/staff/add-specialization?staffMemberID=' + encodeURIComponent(staffID);
                     const { ok, json } = await postForm(url, { specializationID: specId });
-                    if (!ok) throw new Error(json.error || 'Add specialization failed');
+                    if (!ok) throw new Error(json.error || label('script', 'add_spec_failed'));
                     createOutput.className = 'form-output success';
-                    createOutput.textContent = 'Specialization added.';
+                    createOutput.textContent = label('script', 'spec_added');
                     btnGetSpecs && btnGetSpecs.click();
                     setTimeout(closeCreateModal, 400);
                 }
             } catch (err) {
                 createOutput.className = 'form-output error';
-                createOutput.textContent = err.message || 'Network or server error.';
+                createOutput.textContent = err.message || label('script', 'network_or_server');
             }
         });
     }